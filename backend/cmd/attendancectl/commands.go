@@ -0,0 +1,431 @@
+package main
+
+import (
+	"attendance-system/internal/app"
+	"attendance-system/internal/health"
+	"attendance-system/internal/models"
+	"attendance-system/internal/repository"
+	"attendance-system/internal/storage"
+	"attendance-system/internal/utils"
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// createAdmin creates or promotes an admin account, password akun yang sudah ada tidak ditimpa
+func createAdmin(c *ctl, flags *flag.FlagSet, args []string) error {
+	email := flags.String("email", "", "Admin email")
+	name := flags.String("name", "Administrator", "Name for a new account")
+	flags.Parse(args)
+	if *email == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	// Password dari stdin supaya tidak tersimpan di shell history, contoh: attendancectl create-admin -email x < pass.txt
+	fmt.Fprint(os.Stderr, "Password: ")
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	password = strings.TrimRight(password, "\r\n")
+
+	admin, err := c.AuthService.EnsureAdmin(*name, *email, password)
+	if err != nil {
+		return err
+	}
+	c.audit("create-admin", args, "employees", strconv.FormatUint(uint64(admin.ID), 10))
+	fmt.Printf("✅ Admin ready: %s (ID: %d)\n", admin.Email, admin.ID)
+	return nil
+}
+
+// listEmployees prints employees with role, consent and status
+func listEmployees(c *ctl, flags *flag.FlagSet, args []string) error {
+	all := flags.Bool("all", false, "Include deactivated employees")
+	flags.Parse(args)
+
+	users, err := c.Users.List(context.Background(), *all)
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "ID\tNAME\tEMAIL\tROLE\tCONSENT\tFACE\tSTATUS")
+	for _, user := range users {
+		consent, err := c.ConsentService.Status(user.ID)
+		if err != nil {
+			return err
+		}
+		face := "enrolled"
+		switch {
+		case user.ImagePurgedAt != nil:
+			face = "purged"
+		case user.FaceDescriptor == "":
+			face = "-"
+		}
+		status := "active"
+		if user.DeactivatedAt != nil {
+			status = "deactivated " + utils.LocalDate(*user.DeactivatedAt, c.cfg.Org.Location)
+		}
+		fmt.Fprintf(writer, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", user.ID, user.Name, user.Email, user.Role, consent, face, status)
+	}
+	writer.Flush()
+	fmt.Fprintf(os.Stderr, "%d employee(s)\n", len(users))
+	return nil
+}
+
+// deactivateEmployee marks an employee as deactivated
+func deactivateEmployee(c *ctl, flags *flag.FlagSet, args []string) error {
+	flags.Parse(args)
+	id, err := parseID(flags)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	user, err := c.Users.FindByID(ctx, id)
+	if err != nil {
+		return employeeError(id, err)
+	}
+	if user.DeactivatedAt != nil {
+		return fmt.Errorf("employee %d was already deactivated on %s", id, utils.LocalDate(*user.DeactivatedAt, c.cfg.Org.Location))
+	}
+	if err := c.Users.Deactivate(ctx, id, time.Now()); err != nil {
+		return employeeError(id, err)
+	}
+
+	c.audit("deactivate-employee", args, "employees", strconv.FormatUint(uint64(id), 10))
+	fmt.Printf("✅ Employee %d (%s) deactivated\n", id, user.Name)
+	return nil
+}
+
+// reextractDescriptors recomputes face descriptors from the stored reference photos
+// Dipakai setelah algoritma descriptor berubah; karyawan tanpa consent aktif dilewati
+func reextractDescriptors(c *ctl, flags *flag.FlagSet, args []string) error {
+	userID := flags.Uint("user", 0, "Only this employee ID")
+	dryRun := flags.Bool("dry-run", false, "Only count the employees that would be updated")
+	flags.Parse(args)
+
+	ctx, stop := c.context()
+	defer stop()
+
+	var users []models.User
+	if *userID != 0 {
+		user, err := c.Users.FindByID(ctx, *userID)
+		if err != nil {
+			return employeeError(*userID, err)
+		}
+		users = append(users, *user)
+	} else {
+		var err error
+		if users, err = c.Users.List(ctx, false); err != nil {
+			return err
+		}
+	}
+
+	var updated, skipped, failed int
+	for _, user := range users {
+		if ctx.Err() != nil {
+			break
+		}
+		if user.FaceImagePath == "" || user.ImagePurgedAt != nil || user.ErasedAt != nil {
+			skipped++
+			continue
+		}
+		if err := c.ConsentService.Require(user.ID); err != nil {
+			skipped++
+			continue
+		}
+		if *dryRun {
+			updated++
+			continue
+		}
+
+		descriptor, err := c.FaceService.ExtractFaceDescriptor(ctx, user.FaceImagePath)
+		if err == nil {
+			err = c.Users.UpdateFace(ctx, user.ID, user.FaceImagePath, descriptor)
+		}
+		if err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "❌ Employee %d: %v\n", user.ID, err)
+			continue
+		}
+		updated++
+	}
+
+	verb := "updated"
+	if *dryRun {
+		verb = "would be updated"
+	}
+	fmt.Printf("%d %s, %d skipped (no photo or consent), %d failed\n", updated, verb, skipped, failed)
+	if !*dryRun && updated > 0 {
+		entityID := ""
+		if *userID != 0 {
+			entityID = strconv.FormatUint(uint64(*userID), 10)
+		}
+		c.audit("reextract-descriptors", args, "employees", entityID)
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d descriptor(s) failed", failed)
+	}
+	return nil
+}
+
+// verifyPhoto compares a photo on disk with an employee's face descriptor, exit code 1 kalau tidak match
+func verifyPhoto(c *ctl, flags *flag.FlagSet, args []string) error {
+	userID := flags.Uint("user", 0, "Employee ID")
+	threshold := flags.Float64("threshold", c.cfg.Face.SimilarityThreshold, "Similarity threshold (default FACE_SIMILARITY_THRESHOLD)")
+	flags.Parse(args)
+	if *userID == 0 || flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	content, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	ctx, stop := c.context()
+	defer stop()
+	user, err := c.Users.FindByID(ctx, *userID)
+	if err != nil {
+		return employeeError(*userID, err)
+	}
+	// Sama seperti check-in: descriptor hanya boleh dipakai selama consent aktif
+	if err := c.ConsentService.Require(user.ID); err != nil {
+		return err
+	}
+	if user.FaceDescriptor == "" {
+		return fmt.Errorf("employee %d has no face descriptor (purged, erased or never enrolled)", user.ID)
+	}
+
+	descriptor, err := c.FaceService.DescribeImage(ctx, content)
+	if err != nil {
+		return err
+	}
+	similarity, err := c.FaceService.CompareFaces(ctx, descriptor, user.FaceDescriptor)
+	if err != nil {
+		return err
+	}
+	c.audit("verify-photo", args, "employees", strconv.FormatUint(uint64(user.ID), 10))
+
+	if similarity < *threshold {
+		fmt.Printf("❌ No match: similarity %.2f%% (threshold %.2f%%)\n", similarity*100, *threshold*100)
+		os.Exit(1)
+	}
+	fmt.Printf("✅ Match: similarity %.2f%% (threshold %.2f%%)\n", similarity*100, *threshold*100)
+	return nil
+}
+
+// exportAttendance writes check-ins in a date range as CSV
+func exportAttendance(c *ctl, flags *flag.FlagSet, args []string) error {
+	from := flags.String("from", "", "First date, YYYY-MM-DD")
+	to := flags.String("to", "", "Last date (inclusive), YYYY-MM-DD")
+	userID := flags.Uint("user", 0, "Only this employee ID")
+	out := flags.String("out", "", "Output file (default: stdout)")
+	flags.Parse(args)
+	if *from == "" || *to == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	loc := c.cfg.Org.Location
+	start, err := utils.StartOfDate(*from, loc)
+	if err != nil {
+		return fmt.Errorf("invalid -from: %w", err)
+	}
+	end, err := utils.StartOfDate(*to, loc)
+	if err != nil {
+		return fmt.Errorf("invalid -to: %w", err)
+	}
+	if end.Before(start) {
+		return fmt.Errorf("-to must not be before -from")
+	}
+	end = end.AddDate(0, 0, 1)
+
+	output := io.Writer(os.Stdout)
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		output = file
+	}
+
+	// Export besar bisa dihentikan dengan Ctrl+C, query batch berikutnya ikut dibatalkan
+	ctx, stop := c.context()
+	defer stop()
+
+	writer := csv.NewWriter(output)
+	writer.Write([]string{"id", "user_id", "name", "date", "check_in_time", "status", "similarity_score", "method", "site_id", "device_id"})
+	rows := 0
+	err = c.Attendances.EachInRange(ctx, start, end, *userID, func(batch []models.Attendance) error {
+		for _, attendance := range batch {
+			siteID := ""
+			if attendance.SiteID != nil {
+				siteID = strconv.FormatUint(uint64(*attendance.SiteID), 10)
+			}
+			writer.Write([]string{
+				strconv.FormatUint(uint64(attendance.ID), 10),
+				strconv.FormatUint(uint64(attendance.UserID), 10),
+				attendance.User.Name,
+				utils.LocalDate(attendance.CheckInTime, loc),
+				attendance.CheckInTime.In(loc).Format(time.RFC3339),
+				attendance.Status,
+				strconv.FormatFloat(attendance.SimilarityScore, 'f', 4, 64),
+				attendance.Method,
+				siteID,
+				attendance.DeviceID,
+			})
+			rows++
+		}
+		writer.Flush()
+		return writer.Error()
+	})
+	if err != nil {
+		return err
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+
+	c.audit("export-attendance", args, "attendance", "")
+	fmt.Fprintf(os.Stderr, "✅ %d check-in(s) exported\n", rows)
+	return nil
+}
+
+// runPurge runs the retention purge once, sama seperti job dan endpoint manual
+func runPurge(c *ctl, flags *flag.FlagSet, args []string) error {
+	dryRun := flags.Bool("dry-run", false, "Only report what would be deleted")
+	flags.Parse(args)
+
+	ctx, stop := c.context()
+	defer stop()
+
+	run, err := c.RetentionService.Purge(ctx, time.Now(), models.RetentionTriggerCLI, nil, *dryRun)
+	if run != nil {
+		fmt.Printf("Run %d (dry run: %t)\n", run.ID, run.DryRun)
+		fmt.Printf("  Successful selfies: %d\n", run.SuccessfulSelfies)
+		fmt.Printf("  Failed selfies:     %d\n", run.FailedSelfies)
+		fmt.Printf("  Reference photos:   %d\n", run.ReferencePhotos)
+		fmt.Printf("  Held (legal hold):  %d\n", run.Held)
+		fmt.Printf("  Failed:             %d\n", run.Failed)
+		if run.Errors != "" {
+			fmt.Printf("  Errors:\n    %s\n", strings.ReplaceAll(run.Errors, "\n", "\n    "))
+		}
+		if !run.DryRun {
+			c.audit("purge", args, "retention_purge_runs", strconv.FormatUint(uint64(run.ID), 10))
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if run.Failed > 0 {
+		return fmt.Errorf("%d item(s) could not be purged", run.Failed)
+	}
+	return nil
+}
+
+// printHealth checks the dependencies of the server, exit code 1 kalau ada yang gagal
+// Check yang sama dengan /readyz, ditambah key enkripsi dan jumlah karyawan aktif
+func printHealth(c *ctl, flags *flag.FlagSet, args []string) error {
+	flags.Parse(args)
+	ctx, stop := c.context()
+	defer stop()
+
+	// Server membuat folder upload / bucket saat start, CLI bisa jalan sebelum server pernah start
+	if err := storage.Init(ctx, c.cfg.Storage.Backend); err != nil {
+		return err
+	}
+
+	checks := append(app.ReadinessChecks(c.cfg, c.db, c.Services),
+		health.Check{Name: "encryption", Run: func(ctx context.Context) (string, error) {
+			return "active key " + c.cfg.Encryption.Envelope.ActiveKeyID(), nil
+		}},
+		health.Check{Name: "employees", Run: func(ctx context.Context) (string, error) {
+			users, err := c.Users.List(ctx, false)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("%d active", len(users)), nil
+		}},
+	)
+	report := health.NewChecker(c.cfg.Health.Timeout, checks...).Run(ctx)
+
+	failed := 0
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, result := range report.Checks {
+		latency := fmt.Sprintf("%.0fms", result.LatencyMs)
+		if result.Status != health.StatusOK {
+			failed++
+			fmt.Fprintf(writer, "❌ %s\t%s\t%s\n", result.Name, latency, result.Error)
+			continue
+		}
+		fmt.Fprintf(writer, "✅ %s\t%s\t%s\n", result.Name, latency, result.Detail)
+	}
+	writer.Flush()
+
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	return nil
+}
+
+// printConfig prints every setting with its effective value and source
+// LoadConfig sudah memvalidasi semua setting, jadi command ini sekaligus cek config sebelum deploy
+func printConfig(c *ctl, flags *flag.FlagSet, args []string) error {
+	reloadable := flags.Bool("reloadable", false, "Only settings applied on SIGHUP without restart")
+	flags.Parse(args)
+
+	if c.cfg.File() != "" {
+		fmt.Printf("Config file: %s (environment variables override it)\n\n", c.cfg.File())
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "KEY\tENV\tVALUE\tSOURCE\tRELOAD")
+	for _, setting := range c.cfg.Effective() {
+		if *reloadable && !setting.Reload {
+			continue
+		}
+		reload := "-"
+		if setting.Reload {
+			reload = "yes"
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n", setting.Key, setting.Env, setting.Value, setting.Source, reload)
+	}
+	return writer.Flush()
+}
+
+// parseID reads the employee ID argument
+func parseID(flags *flag.FlagSet) (uint, error) {
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	id, err := strconv.ParseUint(flags.Arg(0), 10, 64)
+	if err != nil || id == 0 {
+		return 0, fmt.Errorf("invalid employee ID %q", flags.Arg(0))
+	}
+	return uint(id), nil
+}
+
+// employeeError maps repository.ErrNotFound to a readable message
+func employeeError(id uint, err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return fmt.Errorf("employee %d not found", id)
+	}
+	return err
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"attendance-system/internal/app"
+	"attendance-system/internal/config"
+	"attendance-system/internal/models"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"os/user"
+	"strings"
+	_ "time/tzdata" // Embed timezone database supaya ORG_TIMEZONE jalan juga di Windows
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// command is one attendancectl subcommand
+type command struct {
+	name  string
+	usage string
+	help  string
+	run   func(ctl *ctl, flags *flag.FlagSet, args []string) error
+	// rawDB: buka database tanpa cek schema (health tetap bisa melaporkan migration pending)
+	rawDB bool
+	// noDB: tidak butuh database sama sekali
+	noDB bool
+}
+
+var commands = []command{
+	{name: "create-admin", usage: "-email EMAIL [-name NAME]", help: "Create or promote an admin account (password dibaca dari stdin)", run: createAdmin},
+	{name: "list-employees", usage: "[-all]", help: "List employees with consent status", run: listEmployees},
+	{name: "deactivate-employee", usage: "ID", help: "Deactivate an employee (login dan check-in ditolak)", run: deactivateEmployee},
+	{name: "reextract-descriptors", usage: "[-user ID] [-dry-run]", help: "Re-extract face descriptors from stored reference photos", run: reextractDescriptors},
+	{name: "verify-photo", usage: "-user ID PHOTO", help: "Verify a photo on disk against an employee", run: verifyPhoto},
+	{name: "export-attendance", usage: "-from YYYY-MM-DD -to YYYY-MM-DD [-user ID] [-out FILE]", help: "Export check-ins as CSV (tanggal di timezone organisasi)", run: exportAttendance},
+	{name: "purge", usage: "[-dry-run]", help: "Run the biometric retention purge", run: runPurge},
+	{name: "health", usage: "", help: "Check database, migrations, storage, face engine, disk space and encryption key", run: printHealth, rawDB: true},
+	{name: "config", usage: "[-reloadable]", help: "Validate and print the effective configuration (secret disamarkan)", run: printConfig, noDB: true},
+}
+
+// ctl holds the dependencies shared by every subcommand
+type ctl struct {
+	cfg *config.Config
+	db  *gorm.DB
+	*app.Services
+}
+
+// CLI operasional, memakai config loader, repository dan service yang sama dengan server
+// Usage: go run ./cmd/attendancectl <command> [flags]
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == os.Args[1] {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		usage()
+		os.Exit(2)
+	}
+
+	flags := flag.NewFlagSet(cmd.name, flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: attendancectl %s %s\n\n%s\n\n", cmd.name, cmd.usage, cmd.help)
+		flags.PrintDefaults()
+	}
+
+	// Load configuration
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("❌ Failed to load configuration: %v", err)
+	}
+
+	c := &ctl{cfg: cfg}
+	if !cmd.noDB {
+		// Output CLI dibaca manusia, query SQL cukup muncul kalau lambat atau error
+		cfg.Database.LogLevel = logger.Warn
+		if cmd.rawDB {
+			c.db, err = config.OpenDatabase(&cfg.Database)
+		} else {
+			c.db, err = config.InitDatabase(&cfg.Database)
+		}
+		if err != nil {
+			log.Fatalf("❌ Failed to initialize database: %v", err)
+		}
+		c.Services = app.NewServices(cfg, c.db)
+	}
+
+	if err := cmd.run(c, flags, os.Args[2:]); err != nil {
+		log.Fatalf("❌ %s: %v", cmd.name, err)
+	}
+}
+
+// usage prints the list of subcommands
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: attendancectl <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-22s %s\n", cmd.name, cmd.help)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Run 'attendancectl <command> -h' for the flags of a command.")
+}
+
+// context returns a context cancelled by Ctrl+C
+func (c *ctl) context() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt)
+}
+
+// audit appends an audit log entry for a CLI action
+// Aksi dari CLI tidak lewat middleware HTTP, jadi dicatat manual ke hash chain yang sama
+func (c *ctl) audit(name string, args []string, entityType, entityID string) {
+	operator := "unknown"
+	if current, err := user.Current(); err == nil {
+		operator = current.Username
+	}
+	if host, err := os.Hostname(); err == nil {
+		operator += "@" + host
+	}
+
+	entry := models.AuditLog{
+		ActorRole:  models.RoleAdmin,
+		Action:     "CLI " + name,
+		Method:     "CLI",
+		Path:       strings.TrimSpace("attendancectl " + name + " " + strings.Join(args, " ")),
+		StatusCode: 200,
+		EntityType: entityType,
+		EntityID:   entityID,
+		UserAgent:  "attendancectl (" + operator + ")",
+	}
+	if err := c.AuditService.Record(&entry); err != nil {
+		log.Printf("⚠️  Failed to write audit log: %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"archive/zip"
+	"attendance-system/internal/config"
+	"attendance-system/internal/services"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"text/tabwriter"
+	_ "time/tzdata" // Embed timezone database supaya ORG_TIMEZONE jalan juga di Windows
+)
+
+// Bulk employee import dari command line
+// Usage: go run cmd/import/main.go -csv employees.csv -photos photos.zip [-dry-run] [-workers 8] [-report report.json]
+func main() {
+	csvPath := flag.String("csv", "", "CSV file with columns name,email,phone,department,photo,consent")
+	photosPath := flag.String("photos", "", "ZIP archive containing employee photos")
+	dryRun := flag.Bool("dry-run", false, "Validate only, do not create employees")
+	workers := flag.Int("workers", 0, "Parallel descriptor workers (default IMPORT_WORKERS)")
+	reportPath := flag.String("report", "", "Write full JSON report to this file")
+	flag.Parse()
+
+	if *csvPath == "" || *photosPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Load configuration
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("❌ Failed to load configuration: %v", err)
+	}
+	if *workers > 0 {
+		cfg.Import.Workers = *workers
+	}
+
+	// Initialize database
+	db, err := config.InitDatabase(&cfg.Database)
+	if err != nil {
+		log.Fatalf("❌ Failed to initialize database: %v", err)
+	}
+
+	// Parse CSV
+	csvFile, err := os.Open(*csvPath)
+	if err != nil {
+		log.Fatalf("❌ Failed to open CSV: %v", err)
+	}
+	defer csvFile.Close()
+
+	rows, err := services.ParseCSV(csvFile)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	// Open ZIP
+	photos, err := zip.OpenReader(*photosPath)
+	if err != nil {
+		log.Fatalf("❌ Failed to open photos ZIP: %v", err)
+	}
+	defer photos.Close()
+
+	// Ctrl+C membatalkan row yang belum diproses
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	importService := services.NewImportService(db, services.NewFaceService(cfg.Encryption.Envelope, cfg.Storage.Backend, nil), cfg.Consent.PolicyVersion, cfg.Import.Workers)
+	report, err := importService.Import(ctx, rows, &photos.Reader, *dryRun, nil)
+	if err != nil {
+		log.Fatalf("❌ Import failed: %v", err)
+	}
+
+	printReport(report)
+
+	if *reportPath != "" {
+		data, _ := json.MarshalIndent(report, "", "  ")
+		if err := os.WriteFile(*reportPath, data, 0o644); err != nil {
+			log.Fatalf("❌ Failed to write report: %v", err)
+		}
+		log.Printf("📄 Report written to %s", *reportPath)
+	}
+
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// printReport prints per-row results and totals
+func printReport(report *services.ImportReport) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "LINE\tEMAIL\tSTATUS\tMESSAGE")
+	for _, row := range report.Rows {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", row.Line, row.Email, row.Status, row.Message)
+	}
+	w.Flush()
+
+	fmt.Printf("\nTotal: %d, valid: %d, created: %d, skipped: %d, failed: %d (dry run: %t)\n",
+		report.Total, report.Valid, report.Created, report.Skipped, report.Failed, report.DryRun)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"attendance-system/internal/config"
+	"attendance-system/internal/services"
+	"attendance-system/internal/storage"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	_ "time/tzdata" // Embed timezone database supaya ORG_TIMEZONE jalan juga di Windows
+)
+
+// Pindahkan foto dan lampiran antar storage backend
+// Usage: go run cmd/migrate-storage/main.go -from local -to s3 [-dry-run] [-delete-source]
+func main() {
+	from := flag.String("from", storage.DriverLocal, "Source storage driver (local or s3)")
+	to := flag.String("to", storage.DriverS3, "Destination storage driver (local or s3)")
+	dryRun := flag.Bool("dry-run", false, "Only count what would be copied")
+	deleteSource := flag.Bool("delete-source", false, "Delete files from the source after they are copied")
+	flag.Parse()
+
+	if *from == *to {
+		log.Fatalf("❌ -from and -to must be different drivers")
+	}
+
+	// Load configuration
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("❌ Failed to load configuration: %v", err)
+	}
+
+	source, err := cfg.OpenStorage(*from)
+	if err != nil {
+		log.Fatalf("❌ Failed to open source storage: %v", err)
+	}
+	destination, err := cfg.OpenStorage(*to)
+	if err != nil {
+		log.Fatalf("❌ Failed to open destination storage: %v", err)
+	}
+
+	// Ctrl+C berhenti di file berikutnya, file yang sudah disalin tetap aman
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if !*dryRun {
+		if err := storage.Init(ctx, destination); err != nil {
+			log.Fatalf("❌ Failed to initialize destination storage: %v", err)
+		}
+	}
+
+	// Initialize database
+	db, err := config.InitDatabase(&cfg.Database)
+	if err != nil {
+		log.Fatalf("❌ Failed to initialize database: %v", err)
+	}
+
+	migration := services.NewStorageMigrationService(db, source, destination, cfg.Upload.Path)
+	report, err := migration.Migrate(ctx, *dryRun, *deleteSource)
+	printReport(report)
+	if err != nil {
+		log.Fatalf("❌ Storage migration stopped: %v", err)
+	}
+
+	if report.Failed > 0 {
+		log.Println("❌ Some files failed, keep STORAGE_DRIVER unchanged and run again")
+		os.Exit(1)
+	}
+	if !*dryRun && cfg.Storage.Driver != *to {
+		log.Printf("👉 Set STORAGE_DRIVER=%s and restart the server", *to)
+	}
+}
+
+// printReport prints migration totals
+func printReport(report *services.StorageMigrationReport) {
+	for _, message := range report.Errors {
+		fmt.Println("  -", message)
+	}
+	fmt.Printf("\n%s -> %s (dry run: %t)\n", report.From, report.To, report.DryRun)
+	fmt.Printf("Files: checked %d, copied %d, already there %d, missing %d, failed %d, deleted from source %d\n",
+		report.Checked, report.Copied, report.Skipped, report.Missing, report.Failed, report.Deleted)
+	fmt.Printf("Database paths rewritten: %d\n", report.PathsRewritten)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"attendance-system/internal/config"
+	"attendance-system/internal/migrations"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	_ "time/tzdata" // Embed timezone database supaya ORG_TIMEZONE jalan juga di Windows
+
+	"gorm.io/gorm/logger"
+)
+
+const usage = `Usage: go run ./cmd/migrate <command> [flags]
+
+Commands:
+  up [-steps N]        Apply pending migrations (default: all)
+  down [-steps N]      Roll back the latest applied migrations (default: 1)
+  status               List migrations and whether they are applied
+  create [-dir D] NAME Create empty up/down files for every dialect
+`
+
+// Kelola migration schema database (postgres / sqlite sesuai DB_DRIVER)
+// Usage: go run ./cmd/migrate up|down|status|create
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	command, args := os.Args[1], os.Args[2:]
+	switch command {
+	case "up", "down", "status", "create":
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	steps := flags.Int("steps", 0, "Number of migrations to apply or roll back")
+	dir := flags.String("dir", "internal/migrations", "Migrations source folder (create only)")
+	flags.Parse(args)
+
+	// create hanya menulis file, tidak butuh database
+	if command == "create" {
+		if flags.NArg() != 1 {
+			log.Fatalf("❌ create needs exactly one migration name")
+		}
+		created, err := migrations.Create(*dir, flags.Arg(0))
+		if err != nil {
+			log.Fatalf("❌ Failed to create migration: %v", err)
+		}
+		for _, path := range created {
+			fmt.Println("📝", path)
+		}
+		return
+	}
+
+	// Load configuration
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("❌ Failed to load configuration: %v", err)
+	}
+
+	// Buka database tanpa cek schema, karena justru schema yang mau diubah
+	// SQL migration bisa ratusan baris, cukup tampilkan warning dan error
+	cfg.Database.LogLevel = logger.Warn
+	db, err := config.OpenDatabase(&cfg.Database)
+	if err != nil {
+		log.Fatalf("❌ Failed to open database: %v", err)
+	}
+	migrator, err := migrations.New(db)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up(*steps)
+		for _, migration := range applied {
+			fmt.Printf("⬆️  %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatalf("❌ Migration failed: %v", err)
+		}
+		fmt.Printf("✅ %d migration(s) applied\n", len(applied))
+
+	case "down":
+		rolledBack, err := migrator.Down(*steps)
+		for _, migration := range rolledBack {
+			fmt.Printf("⬇️  %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatalf("❌ Rollback failed: %v", err)
+		}
+		fmt.Printf("✅ %d migration(s) rolled back\n", len(rolledBack))
+
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			log.Fatalf("❌ Failed to read migration status: %v", err)
+		}
+		writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(writer, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Local().Format("2006-01-02 15:04:05")
+			}
+			if status.Missing {
+				appliedAt += " (not in this binary)"
+			}
+			fmt.Fprintf(writer, "%04d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		writer.Flush()
+	}
+}
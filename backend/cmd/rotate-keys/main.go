@@ -0,0 +1,118 @@
+package main
+
+import (
+	"attendance-system/internal/config"
+	"attendance-system/internal/services"
+	"attendance-system/internal/utils"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	_ "time/tzdata" // Embed timezone database supaya ORG_TIMEZONE jalan juga di Windows
+)
+
+// Re-encrypt descriptor dan foto biometrik dengan master key aktif
+// Usage: go run cmd/rotate-keys/main.go [-generate] [-dry-run] [-prune]
+// Urutan aman: -generate, SIGHUP / restart server, rotate-keys, lalu -prune (ditolak kalau masih ada data dengan key lama)
+func main() {
+	generate := flag.Bool("generate", false, "Generate a new master key and make it active in ENCRYPTION_KEY_FILE")
+	dryRun := flag.Bool("dry-run", false, "Only count what would be re-encrypted")
+	prune := flag.Bool("prune", false, "Remove old keys from ENCRYPTION_KEY_FILE after a successful rotation")
+	flag.Parse()
+
+	// Load configuration
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("❌ Failed to load configuration: %v", err)
+	}
+
+	// Server yang jalan baru memakai key baru setelah SIGHUP, jadi key lama tidak boleh dihapus di run yang sama
+	if *generate && *prune {
+		log.Fatalf("❌ -generate and -prune cannot be combined: reload the servers after -generate, then run rotate-keys -prune")
+	}
+
+	if *generate || *prune {
+		if cfg.Encryption.FromEnv {
+			log.Fatalf("❌ -generate/-prune only work with ENCRYPTION_KEY_FILE; set the new ENCRYPTION_KEY and move the old one to ENCRYPTION_PREVIOUS_KEYS instead")
+		}
+	}
+
+	// Key baru ditaruh di baris pertama, key lama tetap ada untuk decrypt
+	if *generate && !*dryRun {
+		keys, err := utils.ReadKeyFile(cfg.Encryption.KeyFile)
+		if err != nil {
+			log.Fatalf("❌ Failed to read key file: %v", err)
+		}
+		key, err := utils.GenerateMasterKey()
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		if err := utils.WriteKeyFile(cfg.Encryption.KeyFile, append([][]byte{key}, keys...)); err != nil {
+			log.Fatalf("❌ Failed to write key file: %v", err)
+		}
+		log.Printf("🔑 New master key %s written to %s", utils.KeyID(key), cfg.Encryption.KeyFile)
+		log.Println("⚠️  Send SIGHUP to every running server (or restart it) so new data is sealed with the new key")
+
+		if cfg, err = config.LoadConfig(); err != nil {
+			log.Fatalf("❌ Failed to reload configuration: %v", err)
+		}
+	}
+
+	// Initialize database
+	db, err := config.InitDatabase(&cfg.Database)
+	if err != nil {
+		log.Fatalf("❌ Failed to initialize database: %v", err)
+	}
+
+	// Ctrl+C berhenti di item berikutnya, item yang sudah selesai tetap aman
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	rotation := services.NewKeyRotationService(db, cfg.Encryption.Envelope, cfg.Storage.Backend)
+	report, err := rotation.Rotate(ctx, *dryRun)
+	printReport(report)
+	if err != nil {
+		log.Fatalf("❌ Key rotation stopped: %v", err)
+	}
+
+	if report.Descriptors.Failed > 0 || report.Files.Failed > 0 {
+		log.Println("❌ Some items failed, old keys must be kept")
+		os.Exit(1)
+	}
+
+	if *prune && !*dryRun {
+		// Key lama baru boleh dihapus kalau tidak ada lagi data yang memakainya; server yang belum
+		// di-reload sejak -generate masih men-seal foto dan descriptor baru dengan key lama
+		pending, err := rotation.Pending(ctx)
+		if err != nil {
+			log.Fatalf("❌ Failed to verify rotation: %v", err)
+		}
+		if pending > 0 {
+			log.Fatalf("❌ %d item(s) are still sealed with an old key, old keys kept. "+
+				"Reload (SIGHUP) or restart every server, run rotate-keys again, then -prune", pending)
+		}
+
+		keys, err := utils.ReadKeyFile(cfg.Encryption.KeyFile)
+		if err != nil {
+			log.Fatalf("❌ Failed to read key file: %v", err)
+		}
+		if err := utils.WriteKeyFile(cfg.Encryption.KeyFile, keys[:1]); err != nil {
+			log.Fatalf("❌ Failed to write key file: %v", err)
+		}
+		log.Printf("🔑 Removed %d old key(s) from %s", len(keys)-1, cfg.Encryption.KeyFile)
+	}
+}
+
+// printReport prints rotation totals
+func printReport(report *services.KeyRotationReport) {
+	for _, message := range report.Errors {
+		fmt.Println("  -", message)
+	}
+	fmt.Printf("\nActive key: %s (dry run: %t)\n", report.ActiveKeyID, report.DryRun)
+	fmt.Printf("Descriptors: checked %d, re-encrypted %d, failed %d\n",
+		report.Descriptors.Checked, report.Descriptors.Rotated, report.Descriptors.Failed)
+	fmt.Printf("Images:      checked %d, re-encrypted %d, missing %d, failed %d\n",
+		report.Files.Checked, report.Files.Rotated, report.Files.Missing, report.Files.Failed)
+}
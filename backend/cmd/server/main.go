@@ -1,50 +1,121 @@
 package main
 
 import (
+	"attendance-system/internal/app"
 	"attendance-system/internal/config"
-	"attendance-system/internal/routes"
+	"attendance-system/internal/logging"
+	"attendance-system/internal/services"
+	"attendance-system/internal/storage"
+	"attendance-system/internal/tracing"
+	"context"
 	"fmt"
 	"log"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
-
-	"github.com/gofiber/fiber/v2"
+	"time"
+	_ "time/tzdata" // Embed timezone database supaya ORG_TIMEZONE jalan juga di Windows
 )
 
 func main() {
-	// ASCII Art Banner
-	printBanner()
-
 	// Load configuration
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		log.Fatalf("❌ Failed to load configuration: %v", err)
 	}
-	log.Println("✅ Configuration loaded")
+
+	// Structured logging, LOG_LEVEL bisa diubah lewat SIGHUP
+	logLevel := new(slog.LevelVar)
+	logLevel.Set(cfg.Log.Level)
+	logging.Setup(os.Stdout, cfg.Log.Format, logLevel)
+	// Banner hanya untuk output teks, stream JSON harus satu object per baris
+	if cfg.Log.Format == logging.FormatText {
+		printBanner()
+	}
+	slog.Info("Configuration loaded", "file", cfg.File(), "log_level", cfg.Log.Level.String(), "log_format", cfg.Log.Format)
+
+	// Tracing (OpenTelemetry), span terakhir dikirim saat server berhenti
+	// Exporter stdout menulis ke stderr supaya stream log JSON di stdout tetap satu object per baris
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, os.Stderr)
+	if err != nil {
+		fatal("Failed to initialize tracing", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("Failed to flush traces", logging.Err(err))
+		}
+	}()
+	if cfg.Tracing.Exporter != tracing.ExporterNone {
+		slog.Info("Tracing enabled", "exporter", cfg.Tracing.Exporter, "service", cfg.Tracing.ServiceName, "sample_ratio", cfg.Tracing.SampleRatio)
+	}
 
 	// Initialize database
-	_, err = config.InitDatabase(&cfg.Database)
+	db, err := config.InitDatabase(&cfg.Database)
 	if err != nil {
-		log.Fatalf("❌ Failed to initialize database: %v", err)
+		fatal("Failed to initialize database", err)
 	}
 
-	// Create upload directory
-	if err := os.MkdirAll(cfg.Upload.Path, os.ModePerm); err != nil {
-		log.Fatalf("❌ Failed to create upload directory: %v", err)
+	// Wiring repository, service dan handler
+	api := app.New(cfg, db)
+	slog.Info("Routes configured")
+
+	// Bootstrap admin account
+	if cfg.Auth.AdminEmail != "" && cfg.Auth.AdminPassword != "" {
+		admin, err := api.AuthService.EnsureAdmin(cfg.Auth.AdminName, cfg.Auth.AdminEmail, cfg.Auth.AdminPassword)
+		if err != nil {
+			fatal("Failed to create admin account", err)
+		}
+		slog.Info("Admin account ready", "user_id", admin.ID)
 	}
-	log.Printf("✅ Upload directory ready: %s", cfg.Upload.Path)
 
-	// Initialize Fiber app
-	app := fiber.New(fiber.Config{
-		AppName:      "Attendance System API",
-		ServerHeader: "Fiber",
-		ErrorHandler: customErrorHandler,
-	})
+	// Prepare file storage (upload directory atau S3 bucket)
+	if err := storage.Init(context.Background(), cfg.Storage.Backend); err != nil {
+		fatal("Failed to initialize storage", err, "driver", cfg.Storage.Driver)
+	}
+	slog.Info("File storage ready", "driver", cfg.Storage.Driver)
+
+	// Background job: materialize daily attendance (absent/late) setelah hari lokal berakhir
+	jobCtx, cancelJobs := context.WithCancel(context.Background())
+	defer cancelJobs()
+	if cfg.Summary.Enabled {
+		job := services.NewDailySummaryJob(api.SummaryService, cfg.Summary.Interval, cfg.Summary.CloseAfter, cfg.Summary.BackfillDays)
+		go job.Run(jobCtx)
+		slog.Info("Daily summary job started", "interval", cfg.Summary.Interval.String())
+	}
+
+	// Background job: hapus selfie, foto referensi dan descriptor yang sudah lewat retention
+	if cfg.Retention.Enabled {
+		job := services.NewRetentionJob(api.RetentionService, cfg.Retention.Interval)
+		go job.Run(jobCtx)
+		slog.Info("Retention purge job started", "interval", cfg.Retention.Interval.String())
+	}
 
-	// Setup routes
-	routes.SetupRoutes(app)
-	log.Println("✅ Routes configured")
+	// SIGHUP: baca ulang CONFIG_FILE dan key file, setting aman (threshold, TTL, retention, log level, keyring) langsung berlaku
+	go func(current *config.Config) {
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		for range hup {
+			next, changes, err := config.Reload(current)
+			if err != nil {
+				slog.Error("Config reload rejected, keeping current settings", logging.Err(err))
+				continue
+			}
+			api.Reload(next)
+			logLevel.Set(next.Log.Level)
+			current = next
+			for _, change := range changes {
+				if change.Applied {
+					slog.Info("Setting changed", "key", change.Key, "old", change.Old, "new", change.New)
+				} else {
+					slog.Warn("Setting changed, restart required", "key", change.Key, "old", change.Old, "new", change.New)
+				}
+			}
+			slog.Info("Configuration reloaded", "changes", len(changes))
+		}
+	}(cfg)
 
 	// Server address
 	addr := fmt.Sprintf(":%s", cfg.Server.Port)
@@ -55,38 +126,25 @@ func main() {
 		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 		<-sigChan
 
-		log.Println("\n🛑 Shutting down server...")
-		if err := app.Shutdown(); err != nil {
-			log.Printf("❌ Error during shutdown: %v", err)
+		slog.Info("Shutting down server")
+		cancelJobs()
+		if err := api.Fiber.Shutdown(); err != nil {
+			slog.Error("Error during shutdown", logging.Err(err))
 		}
 	}()
 
 	// Start server
-	log.Printf("🚀 Server starting on http://localhost%s", addr)
-	log.Printf("📡 Health check: http://localhost%s/api/health", addr)
-	log.Printf("📚 API Documentation:")
-	log.Printf("   - POST /api/employees/register (Register employee)")
-	log.Printf("   - GET  /api/employees (Get all employees)")
-	log.Printf("   - POST /api/attendance/checkin (Check-in with face verification)")
-	log.Printf("   - GET  /api/attendance (Get attendance history)")
-	
-	if err := app.Listen(addr); err != nil {
-		log.Fatalf("❌ Failed to start server: %v", err)
-	}
-}
+	slog.Info("Server starting", "addr", addr, "health_check", fmt.Sprintf("http://localhost%s/api/health", addr))
 
-// customErrorHandler handles Fiber errors
-func customErrorHandler(c *fiber.Ctx, err error) error {
-	code := fiber.StatusInternalServerError
-
-	if e, ok := err.(*fiber.Error); ok {
-		code = e.Code
+	if err := api.Fiber.Listen(addr); err != nil {
+		fatal("Failed to start server", err)
 	}
+}
 
-	return c.Status(code).JSON(fiber.Map{
-		"status":  "error",
-		"message": err.Error(),
-	})
+// fatal logs an error and exits, pengganti log.Fatalf setelah logger terpasang
+func fatal(msg string, err error, args ...any) {
+	slog.Error(msg, append(args, logging.Err(err))...)
+	os.Exit(1)
 }
 
 // printBanner prints ASCII art banner
@@ -0,0 +1,183 @@
+//go:build integration
+
+package integration
+
+import (
+	"attendance-system/internal/app"
+	"attendance-system/internal/config"
+	"attendance-system/internal/storage"
+	"attendance-system/internal/tracing"
+	"attendance-system/internal/utils"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	_ "time/tzdata" // Embed timezone database supaya ORG_TIMEZONE jalan juga di Windows
+
+	"gorm.io/gorm"
+)
+
+// suite holds the app under test and state shared between steps
+type suite struct {
+	cfg       *config.Config
+	db        *gorm.DB
+	api       *app.App
+	token     string
+	employees []uint   // ID karyawan hasil register, urut sesuai faces
+	faces     [][]byte // Foto referensi, dipakai lagi sebagai selfie check-in
+}
+
+// apiResponse mirrors utils.APIResponse with raw data
+type apiResponse struct {
+	Status  string          `json:"status"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+	Meta    *utils.PageMeta `json:"meta"`
+
+	RequestID string `json:"request_id"`
+}
+
+// newSuite configures the app for SQLite in dir and wires it like cmd/server
+func newSuite(dir string) (*suite, error) {
+	// Sebagian setting dari CONFIG_FILE, diubah lagi di step config reload
+	configFile := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(configFile, []byte("face:\n  similarity_threshold: 0.6\n"), 0o600); err != nil {
+		return nil, err
+	}
+
+	env := map[string]string{
+		"CONFIG_FILE":         configFile,
+		"DB_DRIVER":           config.DriverSQLite,
+		"DB_PATH":             filepath.Join(dir, "attendance.db"),
+		"DB_AUTO_MIGRATE":     "true",
+		"UPLOAD_PATH":         filepath.Join(dir, "uploads"),
+		"STORAGE_DRIVER":      storage.DriverLocal,
+		"ENCRYPTION_KEY":      "",
+		"ENCRYPTION_KEY_FILE": filepath.Join(dir, "master.key"),
+		"AUTH_SECRET":         "integration-suite-secret-0123456789abcdef",
+		"ADMIN_NAME":          "Integration Admin",
+		"ADMIN_EMAIL":         "admin@integration.test",
+		"ADMIN_PASSWORD":      "integration-password",
+		// Timezone non-UTC supaya filter tanggal lokal ikut teruji
+		"ORG_TIMEZONE":          "Asia/Jakarta",
+		"DAILY_SUMMARY_ENABLED": "false",
+		"RETENTION_ENABLED":     "false",
+	}
+	for key, value := range env {
+		os.Setenv(key, value)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	db, err := config.InitDatabase(&cfg.Database)
+	if err != nil {
+		return nil, err
+	}
+	if err := storage.Init(context.Background(), cfg.Storage.Backend); err != nil {
+		return nil, err
+	}
+	// TRACING_EXPORTER default none: hanya propagator W3C, provider dipasang di step tracing
+	if _, err := tracing.Setup(context.Background(), cfg.Tracing, io.Discard); err != nil {
+		return nil, err
+	}
+
+	api := app.New(cfg, db)
+	if _, err := api.AuthService.EnsureAdmin(cfg.Auth.AdminName, cfg.Auth.AdminEmail, cfg.Auth.AdminPassword); err != nil {
+		return nil, err
+	}
+	return &suite{cfg: cfg, db: db, api: api}, nil
+}
+
+// request sends a request to the app and decodes the JSON envelope
+func (s *suite) request(method, path string, body io.Reader, contentType string) (int, *apiResponse, error) {
+	req := httptest.NewRequest(method, path, body)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.api.Fiber.Test(req, -1)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	var decoded apiResponse
+	if resp.Header.Get("Content-Type") == "application/json" {
+		if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+			return resp.StatusCode, nil, err
+		}
+	}
+	return resp.StatusCode, &decoded, nil
+}
+
+// requestJSON sends a JSON body
+func (s *suite) requestJSON(method, path string, body interface{}) (int, *apiResponse, error) {
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return 0, nil, err
+	}
+	return s.request(method, path, bytes.NewReader(encoded), "application/json")
+}
+
+// requestForm sends a multipart form, files berisi nama field -> isi file PNG
+func (s *suite) requestForm(path string, fields map[string]string, files map[string][]byte) (int, *apiResponse, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	for key, value := range fields {
+		writer.WriteField(key, value)
+	}
+	for key, content := range files {
+		part, err := writer.CreateFormFile(key, key+".png")
+		if err != nil {
+			return 0, nil, err
+		}
+		part.Write(content)
+	}
+	if err := writer.Close(); err != nil {
+		return 0, nil, err
+	}
+	return s.request(http.MethodPost, path, &buf, writer.FormDataContentType())
+}
+
+// expect checks the status code of a response
+func expect(status, want int, resp *apiResponse) error {
+	if status == want {
+		return nil
+	}
+	message := ""
+	if resp != nil {
+		message = resp.Message
+	}
+	return fmt.Errorf("status %d, want %d (%s)", status, want, message)
+}
+
+// testFace generates a deterministic PNG, seed berbeda menghasilkan hash wajah berbeda
+func testFace(seed int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 128, 128))
+	for y := 0; y < 128; y++ {
+		for x := 0; x < 128; x++ {
+			v := uint8((x*(seed+1) + y*(seed+3)*7) % 256)
+			if (x/(8+seed*4)+y/(8+seed*4))%2 == 0 {
+				v = 255 - v
+			}
+			img.Set(x, y, color.RGBA{v, v / 2, 255 - v, 255})
+		}
+	}
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	return buf.Bytes()
+}
@@ -0,0 +1,188 @@
+package app
+
+import (
+	"attendance-system/internal/config"
+	"attendance-system/internal/handlers"
+	"attendance-system/internal/health"
+	"attendance-system/internal/logging"
+	"attendance-system/internal/metrics"
+	"attendance-system/internal/ratelimit"
+	"attendance-system/internal/repository"
+	"attendance-system/internal/routes"
+	"attendance-system/internal/services"
+	"attendance-system/internal/storage"
+	"attendance-system/internal/utils"
+	"context"
+	"path/filepath"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Services holds the repositories and services shared by the server and the CLIs
+// Satu tempat wiring, jadi cmd/server, cmd/attendancectl dan integration test memakai dependency yang sama
+type Services struct {
+	Users            repository.UserRepository
+	Attendances      repository.AttendanceRepository
+	Sites            repository.SiteRepository
+	OrgService       *services.OrgService
+	CalendarService  *services.CalendarService
+	TimezoneService  *services.TimezoneService
+	SummaryService   *services.DailySummaryService
+	FaceService      *services.FaceService
+	ImageService     *services.ImageService
+	ConsentService   *services.ConsentService
+	AuthService      *services.AuthService
+	AuditService     *services.AuditService
+	RetentionService *services.RetentionService
+	Metrics          *metrics.Metrics
+	Limiter          *ratelimit.Limiter // nil = RATE_LIMIT_ENABLED=false
+}
+
+// NewServices builds the repositories and services from config
+func NewServices(cfg *config.Config, db *gorm.DB) *Services {
+	store := cfg.Storage.Backend
+	m := metrics.New(db)
+	orgService := services.NewOrgService(db)
+	tzService := services.NewTimezoneService(db, orgService, cfg.Org.Location)
+	calendarService := services.NewCalendarService(db, cfg.Schedule.DefaultWorkSchedule())
+	faceService := services.NewFaceService(cfg.Encryption.Envelope, store, m)
+
+	var limiter *ratelimit.Limiter
+	if cfg.RateLimit.Enabled {
+		var limitStore ratelimit.Store = ratelimit.NewMemoryStore()
+		if cfg.RateLimit.Store == ratelimit.StoreDatabase {
+			limitStore = ratelimit.NewGormStore(db)
+		}
+		limiter = ratelimit.NewLimiter(limitStore, cfg.RateLimit.Rules)
+		limiter.SetFailOpen(cfg.RateLimit.FailOpen)
+	}
+
+	return &Services{
+		Users:            repository.NewGormUserRepository(db),
+		Attendances:      repository.NewGormAttendanceRepository(db),
+		Sites:            repository.NewGormSiteRepository(db),
+		OrgService:       orgService,
+		CalendarService:  calendarService,
+		TimezoneService:  tzService,
+		SummaryService:   services.NewDailySummaryService(db, tzService, calendarService, cfg.Schedule.DefaultWorkSchedule()),
+		FaceService:      faceService,
+		ImageService:     services.NewImageService(db, orgService, faceService, store, cfg.Auth.Secret, cfg.Upload.URLTTL),
+		ConsentService:   services.NewConsentService(db, store, cfg.Consent.PolicyVersion),
+		AuthService:      services.NewAuthService(db, cfg.Auth.Secret, cfg.Auth.TokenTTL),
+		AuditService:     services.NewAuditService(db),
+		RetentionService: services.NewRetentionService(db, store, cfg.Retention.Policy),
+		Metrics:          m,
+		Limiter:          limiter,
+	}
+}
+
+// ReadinessChecks lists the dependencies checked by /readyz and attendancectl health
+func ReadinessChecks(cfg *config.Config, db *gorm.DB, svc *Services) []health.Check {
+	checks := []health.Check{
+		health.Database(db),
+		health.Migrations(db),
+		health.Storage(cfg.Storage.Backend),
+		{Name: "face_engine", Run: func(ctx context.Context) (string, error) {
+			return "local (perceptual hash)", svc.FaceService.SelfTest(ctx)
+		}},
+	}
+
+	// Disk lokal yang ditulis server: folder upload (driver local) dan file database SQLite
+	var dirs []string
+	if cfg.Storage.Driver == storage.DriverLocal {
+		dirs = append(dirs, cfg.Upload.Path)
+	}
+	if cfg.Database.Driver == config.DriverSQLite {
+		dirs = append(dirs, filepath.Dir(cfg.Database.Path))
+	}
+	if cfg.Health.MinFreeDiskMB > 0 && len(dirs) > 0 {
+		checks = append(checks, health.DiskSpace(uint64(cfg.Health.MinFreeDiskMB)<<20, dirs...))
+	}
+	return checks
+}
+
+// App is the HTTP API with every dependency wired from config
+// Dipakai oleh cmd/server dan integration test supaya wiring-nya sama persis
+type App struct {
+	*Services
+	Fiber *fiber.App
+
+	attendance *handlers.AttendanceHandler
+	envelope   *utils.Envelope // Dipakai bersama semua service, key diganti di tempat saat reload
+}
+
+// New builds repositories, services and handlers and mounts the routes
+func New(cfg *config.Config, db *gorm.DB) *App {
+	// Repositories dan services, semua dependency dibuat di sini lalu di-inject ke handler
+	svc := NewServices(cfg, db)
+	store := cfg.Storage.Backend
+
+	// Initialize Fiber app
+	app := fiber.New(fiber.Config{
+		AppName:      "Attendance System API",
+		ServerHeader: "Fiber",
+		ErrorHandler: errorHandler,
+		BodyLimit:    cfg.Server.BodyLimitMB * 1024 * 1024,
+		// Banner startup Fiber bukan JSON, hanya ditampilkan untuk log format teks
+		DisableStartupMessage: cfg.Log.Format == logging.FormatJSON,
+		// IP client asli di belakang reverse proxy, dipakai rate limit per IP dan log request
+		// Header hanya dibaca kalau koneksi datang dari TrustedProxies
+		ProxyHeader:             cfg.Server.ProxyHeader,
+		EnableTrustedProxyCheck: cfg.Server.ProxyHeader != "",
+		TrustedProxies:          cfg.Server.TrustedProxies,
+	})
+
+	attendance := handlers.NewAttendanceHandler(svc.Users, svc.Attendances, svc.Sites, svc.FaceService, svc.ConsentService,
+		svc.OrgService, svc.TimezoneService, svc.ImageService, svc.Metrics, svc.Limiter, cfg.Face.SimilarityThreshold)
+
+	// /metrics hanya di-mount kalau METRICS_ENABLED
+	var metricsHandler *handlers.MetricsHandler
+	if cfg.Metrics.Enabled {
+		metricsHandler = handlers.NewMetricsHandler(svc.Metrics, cfg.Metrics.Token)
+	}
+
+	routes.SetupRoutes(app, routes.Handlers{
+		Health: handlers.NewHealthHandler(db, health.NewChecker(cfg.Health.Timeout, ReadinessChecks(cfg, db, svc)...)),
+		Auth:   handlers.NewAuthHandler(svc.Users, svc.AuthService, svc.ImageService),
+		User: handlers.NewUserHandler(svc.Users, svc.FaceService, svc.ConsentService, svc.OrgService, svc.ImageService,
+			services.NewImportService(db, svc.FaceService, cfg.Consent.PolicyVersion, cfg.Import.Workers)),
+		Attendance: attendance,
+		Org:        handlers.NewOrgHandler(db, svc.OrgService, svc.TimezoneService),
+		Daily:      handlers.NewDailyAttendanceHandler(db, svc.SummaryService, svc.OrgService),
+		Leave: handlers.NewLeaveHandler(db, services.NewLeaveService(db, svc.OrgService, svc.SummaryService),
+			svc.OrgService, svc.TimezoneService, svc.ImageService, store),
+		Calendar: handlers.NewCalendarHandler(db, svc.CalendarService, svc.SummaryService),
+		Correction: handlers.NewCorrectionHandler(db, services.NewCorrectionService(db, svc.OrgService, svc.TimezoneService, svc.SummaryService),
+			svc.OrgService, svc.TimezoneService),
+		Audit:     handlers.NewAuditHandler(svc.AuditService, cfg.Org.Location),
+		Image:     handlers.NewImageHandler(svc.ImageService),
+		Retention: handlers.NewRetentionHandler(db, svc.Users, svc.Attendances, svc.RetentionService, cfg.Retention.Enabled, cfg.Retention.Interval),
+		Privacy:   handlers.NewPrivacyHandler(db, services.NewPrivacyService(db, svc.FaceService, store)),
+		Consent:   handlers.NewConsentHandler(db, svc.ConsentService, svc.OrgService),
+		Metrics:   metricsHandler,
+	}, svc.AuthService, svc.AuditService, svc.Metrics, svc.Limiter)
+
+	return &App{Services: svc, Fiber: app, attendance: attendance, envelope: cfg.Encryption.Envelope}
+}
+
+// Reload applies the settings that can change without restart, hasil config.Reload saat SIGHUP
+func (a *App) Reload(cfg *config.Config) {
+	a.attendance.SetThreshold(cfg.Face.SimilarityThreshold)
+	a.ImageService.SetTTL(cfg.Upload.URLTTL)
+	a.RetentionService.SetPolicy(cfg.Retention.Policy)
+	a.Limiter.SetRules(cfg.RateLimit.Rules)
+	a.Limiter.SetFailOpen(cfg.RateLimit.FailOpen)
+	a.envelope.Replace(cfg.Encryption.Envelope)
+}
+
+// errorHandler handles Fiber errors
+func errorHandler(c *fiber.Ctx, err error) error {
+	code := fiber.StatusInternalServerError
+
+	if e, ok := err.(*fiber.Error); ok {
+		code = e.Code
+	}
+
+	return utils.ErrorResponse(c, code, err.Error())
+}
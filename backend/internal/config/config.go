@@ -1,40 +1,86 @@
 package config
 
 import (
+	"attendance-system/internal/logging"
+	"attendance-system/internal/models"
+	"attendance-system/internal/ratelimit"
+	"attendance-system/internal/storage"
+	"attendance-system/internal/tracing"
+	"attendance-system/internal/utils"
+	"errors"
 	"fmt"
-	"log"
+	"io/fs"
+	"log/slog"
+	"math"
 	"os"
-	"strconv"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
+	"gorm.io/gorm/logger"
 )
 
 // Config holds all application configuration
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Upload   UploadConfig
-	Face     FaceConfig
+	Server     ServerConfig
+	Database   DatabaseConfig
+	Upload     UploadConfig
+	Face       FaceConfig
+	Import     ImportConfig
+	Org        OrgConfig
+	Schedule   ScheduleConfig
+	Summary    SummaryConfig
+	Retention  RetentionConfig
+	Consent    ConsentConfig
+	Auth       AuthConfig
+	Encryption EncryptionConfig
+	Storage    StorageConfig
+	Metrics    MetricsConfig
+	Log        LogConfig
+	Tracing    tracing.Options
+	Health     HealthConfig
+	RateLimit  RateLimitConfig
+
+	file   string           // CONFIG_FILE, dibaca ulang saat Reload
+	values map[string]value // Nilai mentah per env var, untuk Effective dan Reload
 }
 
 // ServerConfig holds server settings
 type ServerConfig struct {
-	Port string
+	Port        string
+	BodyLimitMB int    // Maximum request body size, termasuk ZIP untuk bulk import
+	ProxyHeader string // Header IP client dari reverse proxy (X-Forwarded-For), kosong = IP koneksi
+	// TrustedProxies are the proxy IPs/CIDRs whose ProxyHeader is honored, request dari alamat lain memakai IP koneksi
+	TrustedProxies []string
 }
 
+// Database drivers for DB_DRIVER
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite" // Satu file database, untuk kantor cabang tanpa server Postgres dan integration test
+)
+
 // DatabaseConfig holds database connection settings
 type DatabaseConfig struct {
+	Driver   string
+	Path     string // File database SQLite, hanya untuk DB_DRIVER=sqlite
 	Host     string
 	Port     string
 	User     string
 	Password string
 	DBName   string
 	SSLMode  string
+	// Jalankan migration yang pending saat start, aman untuk banyak instance karena migration memakai lock
+	AutoMigrate bool
+	// Level log query GORM, 0 = Info (semua query); CLI memakai Warn supaya output tetap terbaca
+	LogLevel logger.LogLevel
 }
 
 // UploadConfig holds file upload settings
 type UploadConfig struct {
-	Path string
+	Path   string
+	URLTTL time.Duration // Masa berlaku signed URL gambar
 }
 
 // FaceConfig holds face verification settings
@@ -42,59 +88,435 @@ type FaceConfig struct {
 	SimilarityThreshold float64
 }
 
-// AppConfig is the global configuration instance
-var AppConfig *Config
+// ImportConfig holds bulk employee import settings
+type ImportConfig struct {
+	Workers int // Jumlah worker paralel untuk extract face descriptor
+}
 
-// LoadConfig loads configuration from environment variables
+// OrgConfig holds organization-wide settings
+type OrgConfig struct {
+	Timezone string         // IANA timezone, contoh: Asia/Jakarta
+	Location *time.Location // Parsed Timezone, default untuk site tanpa timezone sendiri
+}
+
+// ScheduleConfig holds the default work schedule for sites without their own
+type ScheduleConfig struct {
+	StartTime        string // HH:MM waktu lokal
+	LateGraceMinutes int
+	WorkDays         string // ISO weekday, contoh: "1,2,3,4,5"
+}
+
+// SummaryConfig holds daily attendance summary job settings
+type SummaryConfig struct {
+	Enabled      bool
+	Interval     time.Duration // Seberapa sering job mengecek hari yang sudah selesai
+	CloseAfter   time.Duration // Jeda setelah tengah malam lokal sebelum hari dianggap selesai
+	BackfillDays int           // Jumlah hari ke belakang yang dicek kalau server sempat mati
+}
+
+// RetentionConfig holds selfie and biometric data retention settings
+type RetentionConfig struct {
+	Enabled  bool          // Purge job jalan otomatis
+	Interval time.Duration // Seberapa sering purge job jalan
+	Policy   models.RetentionPolicy
+}
+
+// ConsentConfig holds biometric consent settings
+type ConsentConfig struct {
+	PolicyVersion string // Versi kebijakan biometrik, ganti versi = semua karyawan harus consent ulang
+}
+
+// AuthConfig holds authentication settings
+type AuthConfig struct {
+	Secret        []byte        // HMAC key untuk access token
+	TokenTTL      time.Duration // Masa berlaku access token
+	AdminName     string        // Bootstrap admin, dibuat saat startup kalau email & password di-set
+	AdminEmail    string
+	AdminPassword string
+}
+
+// EncryptionConfig holds biometric encryption-at-rest settings
+type EncryptionConfig struct {
+	KeyFile  string          // Dipakai kalau ENCRYPTION_KEY kosong
+	FromEnv  bool            // Master key dari ENCRYPTION_KEY, bukan key file
+	Envelope *utils.Envelope // Encrypt descriptor dan foto wajah/selfie
+}
+
+// StorageConfig holds file storage settings
+type StorageConfig struct {
+	Driver  string            // local (UPLOAD_PATH) atau s3
+	S3      storage.S3Options // Dipakai kalau driver s3
+	Backend storage.Storage   // Tempat foto wajah, selfie dan lampiran cuti
+}
+
+// MetricsConfig holds Prometheus /metrics settings
+type MetricsConfig struct {
+	Enabled bool
+	Token   string // Bearer token untuk scrape, kosong = tanpa auth
+}
+
+// HealthConfig holds /readyz settings
+type HealthConfig struct {
+	Timeout       time.Duration // Batas waktu per dependency check
+	MinFreeDiskMB int           // Ruang disk minimum untuk UPLOAD_PATH / file SQLite, 0 = tidak dicek
+}
+
+// RateLimitConfig holds check-in and registration rate limits
+type RateLimitConfig struct {
+	Enabled  bool
+	Store    string          // ratelimit.StoreMemory atau ratelimit.StoreDatabase (beberapa instance)
+	FailOpen bool            // Request tetap dilayani kalau store error, default ditolak 503
+	Rules    ratelimit.Rules // Per rule, bisa diganti saat config reload
+}
+
+// LogConfig holds structured logging settings
+type LogConfig struct {
+	Level  slog.Level
+	Format string // logging.FormatJSON atau logging.FormatText (lebih enak dibaca saat development)
+}
+
+// DefaultWorkSchedule returns the schedule used by sites without their own
+func (c *ScheduleConfig) DefaultWorkSchedule() models.WorkSchedule {
+	return models.WorkSchedule{
+		Name:             "default",
+		StartTime:        c.StartTime,
+		LateGraceMinutes: c.LateGraceMinutes,
+		WorkDays:         c.WorkDays,
+	}
+}
+
+// LoadConfig loads configuration from CONFIG_FILE and environment variables
+// Semua setting divalidasi dulu, error dikumpulkan supaya bisa diperbaiki sekaligus
 func LoadConfig() (*Config, error) {
 	// Load .env file if exists
 	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found, using system environment variables")
+		slog.Info("No .env file found, using system environment variables")
+	}
+
+	config, err := parse(os.Getenv("CONFIG_FILE"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Side effect (key file, buka storage) baru jalan setelah semua setting valid
+	// Load master key untuk enkripsi data biometrik
+	if config.Encryption, err = config.loadEncryption(true); err != nil {
+		return nil, err
+	}
+
+	if config.Storage.Backend, err = config.OpenStorage(config.Storage.Driver); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// parse reads and validates every setting without side effects, dipakai juga oleh Reload
+func parse(path string) (*Config, error) {
+	src := newSource(path)
+
+	// Parse database driver, setting koneksi yang wajib tergantung driver
+	dbDriver := src.oneOf("DB_DRIVER", DriverPostgres, DriverSQLite)
+	database := DatabaseConfig{
+		Driver:      dbDriver,
+		User:        src.str("DB_USER"),
+		Password:    src.str("DB_PASSWORD"),
+		SSLMode:     src.str("DB_SSLMODE"),
+		AutoMigrate: src.boolean("DB_AUTO_MIGRATE"),
+	}
+	if dbDriver == DriverSQLite {
+		// SQLite membuat file database, tapi tidak membuat folder-nya
+		database.Path = src.filePath("DB_PATH")
+		if _, err := os.Stat(filepath.Dir(database.Path)); database.Path != "" && err != nil {
+			src.fail("DB_PATH", "folder %s does not exist", filepath.Dir(database.Path))
+		}
+	} else {
+		database.Host = src.required("DB_HOST")
+		database.Port = src.port("DB_PORT")
+		database.DBName = src.required("DB_NAME")
 	}
 
-	// Parse face similarity threshold
-	thresholdStr := getEnv("FACE_SIMILARITY_THRESHOLD", "0.6")
-	threshold, err := strconv.ParseFloat(thresholdStr, 64)
+	// Parse organization timezone
+	timezone := src.required("ORG_TIMEZONE")
+	location, err := time.LoadLocation(timezone)
 	if err != nil {
-		threshold = 0.6
+		src.fail("ORG_TIMEZONE", "unknown timezone %q", timezone)
+		location = time.UTC
+	}
+
+	// Validate default work schedule
+	defaultSchedule := models.WorkSchedule{
+		StartTime:        src.str("WORK_START_TIME"),
+		LateGraceMinutes: src.integer("LATE_GRACE_MINUTES", 0, 24*60),
+		WorkDays:         src.str("WORK_DAYS"),
+	}
+	if _, _, err := defaultSchedule.StartClock(); err != nil {
+		src.fail("WORK_START_TIME", "%q is not a time (HH:MM)", defaultSchedule.StartTime)
+	}
+	if _, err := models.ParseWorkDays(defaultSchedule.WorkDays); err != nil {
+		src.fail("WORK_DAYS", "%v", err)
+	}
+
+	// Retention, 0 hari = disimpan selamanya
+	retentionPolicy := models.RetentionPolicy{
+		SuccessfulSelfieDays: src.integer("RETENTION_SUCCESSFUL_SELFIE_DAYS", 0, math.MaxInt32),
+		FailedSelfieDays:     src.integer("RETENTION_FAILED_SELFIE_DAYS", 0, math.MaxInt32),
+		ReferencePhotoDays:   src.integer("RETENTION_REFERENCE_PHOTO_DAYS", 0, math.MaxInt32),
+	}
+
+	// AUTH_SECRET wajib: secret random per proses membuat token dan signed URL tidak valid setelah restart
+	// dan berbeda di tiap instance
+	secret := []byte(src.required("AUTH_SECRET"))
+	if len(secret) > 0 && len(secret) < 32 {
+		src.fail("AUTH_SECRET", "must be at least 32 bytes, got %d", len(secret))
+	}
+
+	// Master key hanya dicek formatnya di sini, key file dibaca / dibuat di LoadConfig
+	encryptionKey := src.str("ENCRYPTION_KEY")
+	if encryptionKey != "" {
+		if _, err := utils.DecodeMasterKey(encryptionKey); err != nil {
+			src.fail("ENCRYPTION_KEY", "%v", err)
+		}
+	}
+	for _, value := range strings.Split(src.str("ENCRYPTION_PREVIOUS_KEYS"), ",") {
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+		if _, err := utils.DecodeMasterKey(value); err != nil {
+			src.fail("ENCRYPTION_PREVIOUS_KEYS", "%v", err)
+		}
+	}
+
+	// File storage, S3 wajib kalau backend jalan lebih dari satu instance
+	storageDriver := src.oneOf("STORAGE_DRIVER", storage.DriverLocal, storage.DriverS3)
+	s3Value := src.str
+	if storageDriver == storage.DriverS3 {
+		s3Value = src.required
+	}
+	storageConfig := StorageConfig{
+		Driver: storageDriver,
+		S3: storage.S3Options{
+			Endpoint:  s3Value("S3_ENDPOINT"),
+			Region:    src.str("S3_REGION"),
+			Bucket:    s3Value("S3_BUCKET"),
+			AccessKey: src.str("S3_ACCESS_KEY"),
+			SecretKey: src.str("S3_SECRET_KEY"),
+			UseSSL:    src.boolean("S3_USE_SSL"),
+			Prefix:    src.str("S3_PREFIX"),
+		},
+	}
+
+	// OpenTelemetry, endpoint collector hanya divalidasi kalau exporter otlp
+	tracingExporter := src.oneOf("TRACING_EXPORTER", tracing.ExporterNone, tracing.ExporterOTLP, tracing.ExporterStdout)
+	otlpEndpoint := src.str
+	if tracingExporter == tracing.ExporterOTLP {
+		otlpEndpoint = src.httpURL
+	}
+
+	// Header proxy hanya dipercaya dari proxy yang terdaftar, kalau tidak client bisa memalsukan IP-nya
+	proxyHeader := src.str("SERVER_PROXY_HEADER")
+	trustedProxies := src.addresses("SERVER_TRUSTED_PROXIES")
+	if proxyHeader != "" && len(trustedProxies) == 0 {
+		src.fail("SERVER_TRUSTED_PROXIES", "must list the reverse proxy addresses when SERVER_PROXY_HEADER is set")
 	}
 
 	config := &Config{
 		Server: ServerConfig{
-			Port: getEnv("SERVER_PORT", "8080"),
-		},
-		Database: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnv("DB_PORT", "5432"),
-			User:     getEnv("DB_USER", "postgres"),
-			Password: getEnv("DB_PASSWORD", "postgres"),
-			DBName:   getEnv("DB_NAME", "attendance_db"),
-			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+			Port:           src.port("SERVER_PORT"),
+			BodyLimitMB:    src.integer("SERVER_BODY_LIMIT_MB", 1, 4096),
+			ProxyHeader:    proxyHeader,
+			TrustedProxies: trustedProxies,
 		},
+		Database: database,
 		Upload: UploadConfig{
-			Path: getEnv("UPLOAD_PATH", "./uploads"),
+			Path:   src.directory("UPLOAD_PATH"),
+			URLTTL: src.duration("IMAGE_URL_TTL", time.Second),
 		},
 		Face: FaceConfig{
-			SimilarityThreshold: threshold,
+			SimilarityThreshold: src.float("FACE_SIMILARITY_THRESHOLD", 0, 1),
+		},
+		Import: ImportConfig{
+			Workers: src.integer("IMPORT_WORKERS", 1, 256),
+		},
+		Org: OrgConfig{
+			Timezone: timezone,
+			Location: location,
+		},
+		Schedule: ScheduleConfig{
+			StartTime:        defaultSchedule.StartTime,
+			LateGraceMinutes: defaultSchedule.LateGraceMinutes,
+			WorkDays:         defaultSchedule.WorkDays,
+		},
+		Summary: SummaryConfig{
+			Enabled:      src.boolean("DAILY_SUMMARY_ENABLED"),
+			Interval:     src.duration("DAILY_SUMMARY_INTERVAL", time.Second),
+			CloseAfter:   src.duration("DAILY_SUMMARY_CLOSE_AFTER", 0),
+			BackfillDays: src.integer("DAILY_SUMMARY_BACKFILL_DAYS", 1, 366),
+		},
+		Retention: RetentionConfig{
+			Enabled:  src.boolean("RETENTION_ENABLED"),
+			Interval: src.duration("RETENTION_INTERVAL", time.Second),
+			Policy:   retentionPolicy,
+		},
+		Consent: ConsentConfig{
+			PolicyVersion: src.required("BIOMETRIC_POLICY_VERSION"),
+		},
+		Auth: AuthConfig{
+			Secret:        secret,
+			TokenTTL:      src.duration("AUTH_TOKEN_TTL", time.Minute),
+			AdminName:     src.str("ADMIN_NAME"),
+			AdminEmail:    src.str("ADMIN_EMAIL"),
+			AdminPassword: src.str("ADMIN_PASSWORD"),
+		},
+		Encryption: EncryptionConfig{
+			KeyFile: src.filePath("ENCRYPTION_KEY_FILE"),
+			FromEnv: encryptionKey != "",
 		},
+		Storage: storageConfig,
+		Metrics: MetricsConfig{
+			Enabled: src.boolean("METRICS_ENABLED"),
+			Token:   src.str("METRICS_TOKEN"),
+		},
+		Log: LogConfig{
+			Level:  src.logLevel("LOG_LEVEL"),
+			Format: src.oneOf("LOG_FORMAT", logging.FormatJSON, logging.FormatText),
+		},
+		Tracing: tracing.Options{
+			Exporter:     tracingExporter,
+			OTLPEndpoint: otlpEndpoint("TRACING_OTLP_ENDPOINT"),
+			OTLPHeaders:  src.pairs("TRACING_OTLP_HEADERS"),
+			ServiceName:  src.required("TRACING_SERVICE_NAME"),
+			SampleRatio:  src.float("TRACING_SAMPLE_RATIO", 0, 1),
+		},
+		Health: HealthConfig{
+			Timeout:       src.duration("HEALTH_CHECK_TIMEOUT", 100*time.Millisecond),
+			MinFreeDiskMB: src.integer("HEALTH_MIN_FREE_DISK_MB", 0, 1<<20),
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:  src.boolean("RATE_LIMIT_ENABLED"),
+			Store:    src.oneOf("RATE_LIMIT_STORE", ratelimit.StoreMemory, ratelimit.StoreDatabase),
+			FailOpen: src.boolean("RATE_LIMIT_FAIL_OPEN"),
+			Rules: ratelimit.Rules{
+				ratelimit.RuleCheckInIP:     src.limit("RATE_LIMIT_CHECKIN_IP"),
+				ratelimit.RuleCheckInDevice: src.limit("RATE_LIMIT_CHECKIN_DEVICE"),
+				ratelimit.RuleCheckInUser:   src.limit("RATE_LIMIT_CHECKIN_USER"),
+				ratelimit.RuleCheckInFailed: src.limit("RATE_LIMIT_CHECKIN_FAILED"),
+				ratelimit.RuleRegisterIP:    src.limit("RATE_LIMIT_REGISTER_IP"),
+				ratelimit.RuleRegisterUser:  src.limit("RATE_LIMIT_REGISTER_USER"),
+			},
+		},
+		file:   path,
+		values: src.values,
+	}
+
+	// Setting yang tidak dipakai driver aktif tetap dicatat untuk Effective dan Reload
+	for _, setting := range settings {
+		if _, ok := src.values[setting.Env]; !ok {
+			src.get(setting.Env)
+		}
 	}
 
-	AppConfig = config
+	if len(src.errs) > 0 {
+		return nil, fmt.Errorf("invalid configuration (%d error(s)):\n%w", len(src.errs), errors.Join(src.errs...))
+	}
 	return config, nil
 }
 
+// loadEncryption reads master keys from ENCRYPTION_KEY or the key file
+// Key file dibuat otomatis (dengan key baru) kalau belum ada dan createKeyFile true (start, bukan reload)
+func (c *Config) loadEncryption(createKeyFile bool) (EncryptionConfig, error) {
+	cfg := c.Encryption
+
+	var keys [][]byte
+	if cfg.FromEnv {
+		key, err := utils.DecodeMasterKey(c.raw("ENCRYPTION_KEY"))
+		if err != nil {
+			return cfg, fmt.Errorf("invalid ENCRYPTION_KEY: %w", err)
+		}
+		keys = append(keys, key)
+	} else {
+		fileKeys, err := utils.ReadKeyFile(cfg.KeyFile)
+		if errors.Is(err, fs.ErrNotExist) && createKeyFile {
+			key, genErr := utils.GenerateMasterKey()
+			if genErr != nil {
+				return cfg, genErr
+			}
+			if err := utils.WriteKeyFile(cfg.KeyFile, [][]byte{key}); err != nil {
+				return cfg, fmt.Errorf("failed to create ENCRYPTION_KEY_FILE: %w", err)
+			}
+			// Tanpa key ini data biometrik tidak bisa dibaca
+			slog.Warn("Generated new master key, back it up", "path", cfg.KeyFile)
+			fileKeys, err = [][]byte{key}, nil
+		}
+		if err != nil {
+			return cfg, fmt.Errorf("failed to read ENCRYPTION_KEY_FILE: %w", err)
+		}
+		keys = fileKeys
+	}
+
+	// Key lama yang masih dibutuhkan untuk decrypt sampai rotate-keys selesai
+	for _, value := range strings.Split(c.raw("ENCRYPTION_PREVIOUS_KEYS"), ",") {
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+		key, err := utils.DecodeMasterKey(value)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid ENCRYPTION_PREVIOUS_KEYS: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	envelope, err := utils.NewEnvelope(keys)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Envelope = envelope
+	return cfg, nil
+}
+
+// OpenStorage creates a storage backend by driver name
+// Dipakai juga oleh migrate-storage untuk membuka backend sumber dan tujuan sekaligus
+func (c *Config) OpenStorage(driver string) (storage.Storage, error) {
+	switch driver {
+	case storage.DriverLocal:
+		return storage.NewLocal(c.Upload.Path), nil
+	case storage.DriverS3:
+		return storage.NewS3(c.Storage.S3)
+	default:
+		return nil, fmt.Errorf("invalid STORAGE_DRIVER %q (local or s3)", driver)
+	}
+}
+
 // GetDSN returns PostgreSQL connection string
 func (c *DatabaseConfig) GetDSN() string {
 	return fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s TimeZone=UTC",
 		c.Host, c.Port, c.User, c.Password, c.DBName, c.SSLMode,
 	)
 }
 
-// getEnv reads environment variable or returns default value
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// File returns the CONFIG_FILE the config was loaded from, kosong = hanya env var
+func (c *Config) File() string {
+	return c.file
+}
+
+// raw returns the effective raw value of a setting
+func (c *Config) raw(env string) string {
+	return strings.TrimSpace(c.values[env].raw)
+}
+
+// Effective lists every setting with its value and source, secret disamarkan
+func (c *Config) Effective() []EffectiveSetting {
+	effective := make([]EffectiveSetting, 0, len(settings))
+	for _, s := range settings {
+		v := c.values[s.Env]
+		effective = append(effective, EffectiveSetting{
+			Key:    s.Key,
+			Env:    s.Env,
+			Value:  s.display(v.raw),
+			Source: v.source,
+			Reload: s.Reload,
+		})
 	}
-	return defaultValue
+	return effective
 }
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setTestEnv sets a valid SQLite configuration in a temp dir, test lalu menimpa setting yang mau diuji
+func setTestEnv(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for key, value := range map[string]string{
+		"CONFIG_FILE":         "",
+		"DB_DRIVER":           DriverSQLite,
+		"DB_PATH":             filepath.Join(dir, "attendance.db"),
+		"UPLOAD_PATH":         filepath.Join(dir, "uploads"),
+		"ENCRYPTION_KEY":      "",
+		"ENCRYPTION_KEY_FILE": filepath.Join(dir, "master.key"),
+		"AUTH_SECRET":         "config-test-secret-0123456789abcdef",
+	} {
+		t.Setenv(key, value)
+	}
+	return dir
+}
+
+func TestParseReportsAllErrors(t *testing.T) {
+	setTestEnv(t)
+	defaults, err := parse("")
+	if err != nil {
+		t.Fatalf("valid configuration rejected: %v", err)
+	}
+	// Rate limit fail closed kecuali RATE_LIMIT_FAIL_OPEN di-set
+	if defaults.RateLimit.FailOpen {
+		t.Fatal("RATE_LIMIT_FAIL_OPEN defaults to true")
+	}
+
+	// Env var kosong dianggap tidak di-set, jadi AUTH_SECRET hilang
+	invalid := map[string]string{
+		"AUTH_SECRET":               "",
+		"FACE_SIMILARITY_THRESHOLD": "1.5",
+		"IMPORT_WORKERS":            "0",
+		"LOG_LEVEL":                 "loud",
+		"RATE_LIMIT_CHECKIN_IP":     "lots",
+		"ORG_TIMEZONE":              "Mars/Olympus_Mons",
+	}
+	for key, value := range invalid {
+		t.Setenv(key, value)
+	}
+
+	config, err := parse("")
+	if err == nil {
+		t.Fatalf("invalid configuration accepted: %+v", config)
+	}
+	if !strings.Contains(err.Error(), "(6 error(s))") {
+		t.Fatalf("error count missing from %q", err)
+	}
+	for key := range invalid {
+		if !strings.Contains(err.Error(), key+": ") {
+			t.Errorf("no error for %s in %q", key, err)
+		}
+	}
+
+	// AUTH_SECRET yang terlalu pendek juga ditolak
+	t.Setenv("AUTH_SECRET", "short")
+	if _, err := parse(""); err == nil || !strings.Contains(err.Error(), "AUTH_SECRET: must be at least 32 bytes") {
+		t.Fatalf("short AUTH_SECRET: err %v", err)
+	}
+}
+
+func TestReloadKeepsConfigOnInvalidValues(t *testing.T) {
+	dir := setTestEnv(t)
+	file := filepath.Join(dir, "config.yaml")
+	writeFile := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("face:\n  similarity_threshold: 0.7\n")
+	t.Setenv("CONFIG_FILE", file)
+
+	current, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// File invalid ditolak utuh, termasuk setting valid yang ikut berubah di file yang sama
+	writeFile("face:\n  similarity_threshold: 1.5\nimport:\n  workers: 0\nupload:\n  url_ttl: 10m\n")
+	next, changes, err := Reload(current)
+	if err == nil || next != nil || changes != nil {
+		t.Fatalf("invalid reload accepted: %+v, %v", next, changes)
+	}
+	for _, key := range []string{"face.similarity_threshold", "import.workers"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("no error for %s in %q", key, err)
+		}
+	}
+	if current.Face.SimilarityThreshold != 0.7 || current.Upload.URLTTL.String() != "5m0s" {
+		t.Fatalf("current config changed: threshold %v, url ttl %v", current.Face.SimilarityThreshold, current.Upload.URLTTL)
+	}
+
+	// Reload berikutnya yang valid tetap dihitung dari config lama
+	writeFile("face:\n  similarity_threshold: 0.8\n")
+	next, changes, err = Reload(current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.Face.SimilarityThreshold != 0.8 {
+		t.Fatalf("threshold %v, want 0.8", next.Face.SimilarityThreshold)
+	}
+	if len(changes) != 1 || changes[0].Key != "face.similarity_threshold" || changes[0].Old != "0.7" || !changes[0].Applied {
+		t.Fatalf("changes %+v, want only face.similarity_threshold 0.7 -> 0.8", changes)
+	}
+
+	// fail_open bisa dinyalakan lewat reload, misalnya selama database bermasalah
+	writeFile("face:\n  similarity_threshold: 0.8\nrate_limit:\n  fail_open: true\n")
+	next, changes, err = Reload(next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !next.RateLimit.FailOpen || len(changes) != 1 || changes[0].Key != "rate_limit.fail_open" || !changes[0].Applied {
+		t.Fatalf("fail open %v, changes %+v, want rate_limit.fail_open applied", next.RateLimit.FailOpen, changes)
+	}
+}
@@ -1,29 +1,65 @@
 package config
 
 import (
-	"attendance-system/internal/models"
+	"attendance-system/internal/logging"
+	"attendance-system/internal/migrations"
+	"attendance-system/internal/tracing"
 	"fmt"
-	"log"
+	"log/slog"
+	"time"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
-// DB is the global database instance
-var DB *gorm.DB
-
-// InitDatabase initializes database connection and runs migrations
+// InitDatabase opens the database and refuses to continue when the schema is not migrated
+// Schema dikelola migration berversi (cmd/migrate), bukan AutoMigrate
 func InitDatabase(config *DatabaseConfig) (*gorm.DB, error) {
-	dsn := config.GetDSN()
+	db, err := OpenDatabase(config)
+	if err != nil {
+		return nil, err
+	}
+
+	migrator, err := migrations.New(db)
+	if err != nil {
+		return nil, err
+	}
+	if config.AutoMigrate {
+		applied, err := migrator.Up(0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to run migrations: %w", err)
+		}
+		for _, migration := range applied {
+			slog.Info("Migration applied", "version", migration.Version, "migration", migration.Name)
+		}
+	}
+	if err := migrator.Check(); err != nil {
+		return nil, err
+	}
+
+	slog.Info("Database connected, schema up to date", "driver", config.Driver)
 
+	return db, nil
+}
+
+// OpenDatabase opens the connection pool without checking the schema (dipakai cmd/migrate)
+func OpenDatabase(config *DatabaseConfig) (*gorm.DB, error) {
 	// Open database connection
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+	logLevel := logger.Info
+	if config.LogLevel != 0 {
+		logLevel = config.LogLevel
+	}
+	db, err := gorm.Open(config.dialector(), &gorm.Config{
+		Logger: logging.NewGormLogger(logLevel, 200*time.Millisecond),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
+	// Span per query, hanya untuk query yang dijalankan dengan context dari request yang di-trace
+	if err := db.Use(tracing.GormPlugin()); err != nil {
+		return nil, fmt.Errorf("failed to register tracing plugin: %w", err)
+	}
 
 	// Get underlying SQL DB for connection pooling
 	sqlDB, err := db.DB()
@@ -35,22 +71,13 @@ func InitDatabase(config *DatabaseConfig) (*gorm.DB, error) {
 	sqlDB.SetMaxIdleConns(10)
 	sqlDB.SetMaxOpenConns(100)
 
-	// Auto migrate database schema
-	err = db.AutoMigrate(
-		&models.User{},
-		&models.Attendance{},
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to run migrations: %w", err)
-	}
-
-	log.Println("✅ Database connected and migrated successfully")
-	
-	DB = db
 	return db, nil
 }
 
-// GetDB returns the global database instance
-func GetDB() *gorm.DB {
-	return DB
+// dialector returns the GORM dialector for DB_DRIVER
+func (c *DatabaseConfig) dialector() gorm.Dialector {
+	if c.Driver == DriverSQLite {
+		return newSQLiteDialector(c.Path)
+	}
+	return postgres.Open(c.GetDSN())
 }
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Change is a setting whose value differs after Reload
+type Change struct {
+	Key     string // Key di CONFIG_FILE
+	Env     string
+	Old     string // Secret sudah disamarkan
+	New     string
+	Applied bool // false = baru berlaku setelah restart
+}
+
+// Reload re-reads CONFIG_FILE and env vars (dipanggil saat SIGHUP)
+// Yang diterapkan hanya setting bertanda Reload; setting lain yang berubah dilaporkan
+// dengan Applied false. Config yang invalid ditolak utuh, current tetap dipakai
+func Reload(current *Config) (*Config, []Change, error) {
+	next, err := parse(current.file)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Keyring dibaca ulang tanpa membuat key file baru
+	if next.Encryption, err = next.loadEncryption(false); err != nil {
+		return nil, nil, err
+	}
+
+	updated := *current
+	updated.values = make(map[string]value, len(current.values))
+	for env, v := range current.values {
+		updated.values[env] = v
+	}
+
+	var changes []Change
+	for _, s := range settings {
+		before, after := current.values[s.Env], next.values[s.Env]
+		if before.raw == after.raw {
+			continue
+		}
+		changes = append(changes, Change{
+			Key:     s.Key,
+			Env:     s.Env,
+			Old:     s.display(before.raw),
+			New:     s.display(after.raw),
+			Applied: s.Reload,
+		})
+		if s.Reload {
+			updated.values[s.Env] = after
+		}
+	}
+
+	// Keyring enkripsi dibaca ulang (setelah rotate-keys -generate / -prune)
+	before, after := current.Encryption.Envelope, next.Encryption.Envelope
+	if !slices.Contains(after.KeyIDs(), before.ActiveKeyID()) {
+		// Data yang di-seal sejak start / reload terakhir memakai key ini, tanpanya data itu tidak bisa dibuka
+		return nil, nil, fmt.Errorf("encryption keys: active key %s is missing from the new keyring, "+
+			"restore it and run rotate-keys before removing it", before.ActiveKeyID())
+	}
+	if before.ActiveKeyID() != after.ActiveKeyID() || !slices.Equal(before.KeyIDs(), after.KeyIDs()) {
+		changes = append(changes, Change{
+			Key:     "encryption.keys",
+			Env:     "ENCRYPTION_KEY_FILE",
+			Old:     describeKeyring(before.ActiveKeyID(), before.KeyIDs()),
+			New:     describeKeyring(after.ActiveKeyID(), after.KeyIDs()),
+			Applied: true,
+		})
+	}
+
+	// Harus sejalan dengan flag Reload di tabel settings
+	updated.Face.SimilarityThreshold = next.Face.SimilarityThreshold
+	updated.Upload.URLTTL = next.Upload.URLTTL
+	updated.Retention.Policy = next.Retention.Policy
+	updated.Log.Level = next.Log.Level
+	updated.RateLimit.Rules = next.RateLimit.Rules
+	updated.RateLimit.FailOpen = next.RateLimit.FailOpen
+	updated.Encryption = next.Encryption
+	return &updated, changes, nil
+}
+
+// describeKeyring formats key fingerprints for the reload log, key sendiri tidak pernah ditampilkan
+func describeKeyring(activeID string, ids []string) string {
+	return fmt.Sprintf("active %s, %d key(s)", activeID, len(ids))
+}
@@ -0,0 +1,442 @@
+package config
+
+import (
+	"attendance-system/internal/logging"
+	"attendance-system/internal/ratelimit"
+	"attendance-system/internal/storage"
+	"attendance-system/internal/tracing"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// setting describes one configuration value
+// Urutan prioritas: environment variable > CONFIG_FILE > default
+type setting struct {
+	Env     string // Nama environment variable
+	Key     string // Key di CONFIG_FILE, contoh face.similarity_threshold
+	Default string
+	Secret  bool // Disamarkan saat config efektif ditampilkan
+	Reload  bool // Ikut diterapkan saat SIGHUP tanpa restart (lihat Reload)
+}
+
+// settings lists every setting read by LoadConfig
+var settings = []setting{
+	{Env: "SERVER_PORT", Key: "server.port", Default: "8080"},
+	{Env: "SERVER_BODY_LIMIT_MB", Key: "server.body_limit_mb", Default: "64"},
+	{Env: "SERVER_PROXY_HEADER", Key: "server.proxy_header"},
+	{Env: "SERVER_TRUSTED_PROXIES", Key: "server.trusted_proxies"},
+
+	{Env: "LOG_LEVEL", Key: "log.level", Default: "info", Reload: true},
+	{Env: "LOG_FORMAT", Key: "log.format", Default: logging.FormatJSON},
+
+	{Env: "DB_DRIVER", Key: "database.driver", Default: DriverPostgres},
+	{Env: "DB_PATH", Key: "database.path", Default: "./attendance.db"},
+	{Env: "DB_HOST", Key: "database.host", Default: "localhost"},
+	{Env: "DB_PORT", Key: "database.port", Default: "5432"},
+	{Env: "DB_USER", Key: "database.user", Default: "postgres"},
+	{Env: "DB_PASSWORD", Key: "database.password", Default: "postgres", Secret: true},
+	{Env: "DB_NAME", Key: "database.name", Default: "attendance_db"},
+	{Env: "DB_SSLMODE", Key: "database.sslmode", Default: "disable"},
+	{Env: "DB_AUTO_MIGRATE", Key: "database.auto_migrate", Default: "false"},
+
+	{Env: "UPLOAD_PATH", Key: "upload.path", Default: "./uploads"},
+	{Env: "IMAGE_URL_TTL", Key: "upload.url_ttl", Default: "5m", Reload: true},
+
+	{Env: "STORAGE_DRIVER", Key: "storage.driver", Default: storage.DriverLocal},
+	{Env: "S3_ENDPOINT", Key: "storage.s3.endpoint", Default: "localhost:9000"},
+	{Env: "S3_REGION", Key: "storage.s3.region", Default: "us-east-1"},
+	{Env: "S3_BUCKET", Key: "storage.s3.bucket", Default: "attendance"},
+	{Env: "S3_ACCESS_KEY", Key: "storage.s3.access_key", Secret: true},
+	{Env: "S3_SECRET_KEY", Key: "storage.s3.secret_key", Secret: true},
+	{Env: "S3_USE_SSL", Key: "storage.s3.use_ssl", Default: "false"},
+	{Env: "S3_PREFIX", Key: "storage.s3.prefix"},
+
+	{Env: "FACE_SIMILARITY_THRESHOLD", Key: "face.similarity_threshold", Default: "0.6", Reload: true},
+	{Env: "IMPORT_WORKERS", Key: "import.workers", Default: "4"},
+	{Env: "ORG_TIMEZONE", Key: "org.timezone", Default: "UTC"},
+
+	{Env: "WORK_START_TIME", Key: "schedule.start_time", Default: "09:00"},
+	{Env: "LATE_GRACE_MINUTES", Key: "schedule.late_grace_minutes", Default: "15"},
+	{Env: "WORK_DAYS", Key: "schedule.work_days", Default: "1,2,3,4,5"},
+
+	{Env: "DAILY_SUMMARY_ENABLED", Key: "summary.enabled", Default: "true"},
+	{Env: "DAILY_SUMMARY_INTERVAL", Key: "summary.interval", Default: "15m"},
+	{Env: "DAILY_SUMMARY_CLOSE_AFTER", Key: "summary.close_after", Default: "1h"},
+	{Env: "DAILY_SUMMARY_BACKFILL_DAYS", Key: "summary.backfill_days", Default: "7"},
+
+	{Env: "RETENTION_ENABLED", Key: "retention.enabled", Default: "false"},
+	{Env: "RETENTION_INTERVAL", Key: "retention.interval", Default: "6h"},
+	{Env: "RETENTION_SUCCESSFUL_SELFIE_DAYS", Key: "retention.successful_selfie_days", Default: "30", Reload: true},
+	{Env: "RETENTION_FAILED_SELFIE_DAYS", Key: "retention.failed_selfie_days", Default: "180", Reload: true},
+	{Env: "RETENTION_REFERENCE_PHOTO_DAYS", Key: "retention.reference_photo_days", Default: "90", Reload: true},
+
+	{Env: "METRICS_ENABLED", Key: "metrics.enabled", Default: "true"},
+	{Env: "METRICS_TOKEN", Key: "metrics.token", Secret: true},
+
+	{Env: "TRACING_EXPORTER", Key: "tracing.exporter", Default: tracing.ExporterNone},
+	{Env: "TRACING_OTLP_ENDPOINT", Key: "tracing.otlp_endpoint", Default: "http://localhost:4318"},
+	{Env: "TRACING_OTLP_HEADERS", Key: "tracing.otlp_headers", Secret: true},
+	{Env: "TRACING_SERVICE_NAME", Key: "tracing.service_name", Default: "attendance-api"},
+	{Env: "TRACING_SAMPLE_RATIO", Key: "tracing.sample_ratio", Default: "1"},
+
+	{Env: "RATE_LIMIT_ENABLED", Key: "rate_limit.enabled", Default: "true"},
+	{Env: "RATE_LIMIT_STORE", Key: "rate_limit.store", Default: ratelimit.StoreMemory},
+	{Env: "RATE_LIMIT_FAIL_OPEN", Key: "rate_limit.fail_open", Default: "false", Reload: true},
+	{Env: "RATE_LIMIT_CHECKIN_IP", Key: "rate_limit.checkin_ip", Default: "120/1m", Reload: true},
+	{Env: "RATE_LIMIT_CHECKIN_DEVICE", Key: "rate_limit.checkin_device", Default: "60/1m", Reload: true},
+	{Env: "RATE_LIMIT_CHECKIN_USER", Key: "rate_limit.checkin_user", Default: "5/1m", Reload: true},
+	{Env: "RATE_LIMIT_CHECKIN_FAILED", Key: "rate_limit.checkin_failed", Default: "5/15m", Reload: true},
+	{Env: "RATE_LIMIT_REGISTER_IP", Key: "rate_limit.register_ip", Default: "30/1m", Reload: true},
+	{Env: "RATE_LIMIT_REGISTER_USER", Key: "rate_limit.register_user", Default: "60/1m", Reload: true},
+
+	{Env: "HEALTH_CHECK_TIMEOUT", Key: "health.check_timeout", Default: "3s"},
+	{Env: "HEALTH_MIN_FREE_DISK_MB", Key: "health.min_free_disk_mb", Default: "500"},
+
+	{Env: "BIOMETRIC_POLICY_VERSION", Key: "consent.policy_version", Default: "1"},
+
+	{Env: "AUTH_SECRET", Key: "auth.secret", Secret: true},
+	{Env: "AUTH_TOKEN_TTL", Key: "auth.token_ttl", Default: "12h"},
+	{Env: "ADMIN_NAME", Key: "auth.admin_name", Default: "Administrator"},
+	{Env: "ADMIN_EMAIL", Key: "auth.admin_email"},
+	{Env: "ADMIN_PASSWORD", Key: "auth.admin_password", Secret: true},
+
+	// Keyring dibaca ulang saat SIGHUP, key aktif yang sedang dipakai harus tetap ada (lihat Reload)
+	{Env: "ENCRYPTION_KEY", Key: "encryption.key", Secret: true, Reload: true},
+	{Env: "ENCRYPTION_KEY_FILE", Key: "encryption.key_file", Default: "./keys/master.key", Reload: true},
+	{Env: "ENCRYPTION_PREVIOUS_KEYS", Key: "encryption.previous_keys", Secret: true, Reload: true},
+}
+
+// Sources of an effective setting value
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+)
+
+// redacted replaces secret values when config is printed or logged
+const redacted = "[redacted]"
+
+// value is the raw effective value of a setting
+type value struct {
+	raw    string
+	source string
+}
+
+// EffectiveSetting is one setting as printed by attendancectl config
+type EffectiveSetting struct {
+	Key    string
+	Env    string
+	Value  string // Secret sudah disamarkan
+	Source string // default / file / env
+	Reload bool
+}
+
+// settingFor returns the table entry of an env var
+func settingFor(env string) setting {
+	for _, s := range settings {
+		if s.Env == env {
+			return s
+		}
+	}
+	panic("config: unknown setting " + env)
+}
+
+// display returns the value safe to print
+func (s setting) display(raw string) string {
+	if s.Secret && raw != "" {
+		return redacted
+	}
+	return raw
+}
+
+// source reads raw values and collects every invalid setting instead of stopping at the first one
+type source struct {
+	path   string            // CONFIG_FILE, kosong = hanya env var
+	file   map[string]string // Nilai dari CONFIG_FILE per env var
+	values map[string]value
+	errs   []error
+}
+
+// newSource reads the optional config file
+func newSource(path string) *source {
+	s := &source{path: path, file: make(map[string]string), values: make(map[string]value)}
+	if path != "" {
+		s.readFile()
+	}
+	return s
+}
+
+// readFile parses CONFIG_FILE, format YAML atau TOML dipilih dari extension
+func (s *source) readFile() {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		s.errs = append(s.errs, fmt.Errorf("CONFIG_FILE: %w", err))
+		return
+	}
+
+	var tree map[string]interface{}
+	switch strings.ToLower(filepath.Ext(s.path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &tree)
+	case ".toml":
+		err = toml.Unmarshal(data, &tree)
+	default:
+		err = fmt.Errorf("unsupported format %q (.yaml, .yml or .toml)", filepath.Ext(s.path))
+	}
+	if err != nil {
+		s.errs = append(s.errs, fmt.Errorf("CONFIG_FILE %s: %w", s.path, err))
+		return
+	}
+
+	flat := make(map[string]string)
+	flatten("", tree, flat)
+	keys := make([]string, 0, len(flat))
+	for key := range flat {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// Key yang tidak dikenal ditolak, typo di file tidak boleh diam-diam memakai default
+	for _, key := range keys {
+		env := ""
+		for _, setting := range settings {
+			if setting.Key == key {
+				env = setting.Env
+			}
+		}
+		if env == "" {
+			s.errs = append(s.errs, fmt.Errorf("%s in %s: unknown setting", key, s.path))
+			continue
+		}
+		s.file[env] = flat[key]
+	}
+}
+
+// flatten turns nested sections into dotted keys, list digabung dengan koma (contoh work_days)
+func flatten(prefix string, node interface{}, out map[string]string) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			flatten(key, child, out)
+		}
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = fmt.Sprint(item)
+		}
+		out[prefix] = strings.Join(items, ",")
+	case nil:
+		out[prefix] = ""
+	default:
+		out[prefix] = fmt.Sprint(v)
+	}
+}
+
+// get returns the raw value of a setting and records where it came from
+func (s *source) get(env string) string {
+	setting := settingFor(env)
+	v := value{raw: setting.Default, source: SourceDefault}
+	if raw, ok := s.file[env]; ok {
+		v = value{raw: raw, source: SourceFile}
+	}
+	// Sama seperti sebelumnya, env var kosong dianggap tidak di-set
+	if raw := os.Getenv(env); raw != "" {
+		v = value{raw: raw, source: SourceEnv}
+	}
+	s.values[env] = v
+	return strings.TrimSpace(v.raw)
+}
+
+// fail records an invalid setting, nama yang ditampilkan sesuai tempat nilai itu di-set
+func (s *source) fail(env, format string, args ...interface{}) {
+	name := env
+	if s.values[env].source == SourceFile {
+		name = fmt.Sprintf("%s in %s", settingFor(env).Key, s.path)
+	}
+	s.errs = append(s.errs, fmt.Errorf("%s: %s", name, fmt.Sprintf(format, args...)))
+}
+
+// str returns a setting as is
+func (s *source) str(env string) string {
+	return s.get(env)
+}
+
+// required returns a setting that must not be empty
+func (s *source) required(env string) string {
+	raw := s.get(env)
+	if raw == "" {
+		s.fail(env, "must not be empty")
+	}
+	return raw
+}
+
+// integer parses a whole number in [min, max]
+func (s *source) integer(env string, min, max int) int {
+	raw := s.get(env)
+	n, err := strconv.Atoi(raw)
+	if err != nil {
+		s.fail(env, "%q is not a whole number", raw)
+		return 0
+	}
+	if n < min {
+		s.fail(env, "must be at least %d, got %d", min, n)
+	} else if n > max {
+		s.fail(env, "must be at most %d, got %d", max, n)
+	}
+	return n
+}
+
+// float parses a number in [min, max]
+func (s *source) float(env string, min, max float64) float64 {
+	raw := s.get(env)
+	f, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		s.fail(env, "%q is not a number", raw)
+		return 0
+	}
+	if f < min || f > max {
+		s.fail(env, "must be between %g and %g, got %g", min, max, f)
+	}
+	return f
+}
+
+// boolean parses true/false
+func (s *source) boolean(env string) bool {
+	raw := s.get(env)
+	b, err := strconv.ParseBool(raw)
+	if err != nil {
+		s.fail(env, "%q is not a boolean (true or false)", raw)
+	}
+	return b
+}
+
+// duration parses a Go duration (contoh 15m, 12h) of at least min
+func (s *source) duration(env string, min time.Duration) time.Duration {
+	raw := s.get(env)
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		s.fail(env, "%q is not a duration (e.g. 30s, 15m, 12h)", raw)
+		return 0
+	}
+	if d < min {
+		s.fail(env, "must be at least %s, got %s", min, d)
+	}
+	return d
+}
+
+// oneOf returns a lowercased setting that must be one of options
+func (s *source) oneOf(env string, options ...string) string {
+	raw := strings.ToLower(s.get(env))
+	for _, option := range options {
+		if raw == option {
+			return raw
+		}
+	}
+	s.fail(env, "%q is not one of %s", raw, strings.Join(options, ", "))
+	return raw
+}
+
+// limit returns a rate limit rule ("10/1m" atau "off")
+func (s *source) limit(env string) ratelimit.Limit {
+	limit, err := ratelimit.ParseLimit(s.get(env))
+	if err != nil {
+		s.fail(env, "%v", err)
+	}
+	return limit
+}
+
+// logLevel returns a slog level (debug, info, warn, error)
+func (s *source) logLevel(env string) slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s.oneOf(env, "debug", "info", "warn", "error"))); err != nil {
+		return slog.LevelInfo
+	}
+	return level
+}
+
+// httpURL returns an http or https URL with a host
+func (s *source) httpURL(env string) string {
+	raw := s.get(env)
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		s.fail(env, "%q is not an http(s) URL (e.g. http://localhost:4318)", raw)
+	}
+	return raw
+}
+
+// pairs parses "key=value,key2=value2", dipakai untuk header HTTP tambahan
+func (s *source) pairs(env string) map[string]string {
+	pairs := make(map[string]string)
+	for _, item := range strings.Split(s.get(env), ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(item, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			// Nilai tidak ikut ditampilkan, biasanya berisi API key
+			s.fail(env, "entries must be key=value")
+			continue
+		}
+		pairs[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return pairs
+}
+
+// addresses parses a comma-separated list of IP addresses or CIDR ranges
+func (s *source) addresses(env string) []string {
+	var addresses []string
+	for _, item := range strings.Split(s.get(env), ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(item); err != nil && net.ParseIP(item) == nil {
+			s.fail(env, "%q is not an IP address or CIDR range", item)
+			continue
+		}
+		addresses = append(addresses, item)
+	}
+	return addresses
+}
+
+// port returns a TCP port number
+func (s *source) port(env string) string {
+	raw := s.get(env)
+	if n, err := strconv.Atoi(raw); err != nil || n < 1 || n > 65535 {
+		s.fail(env, "%q is not a port number (1-65535)", raw)
+	}
+	return raw
+}
+
+// directory returns a path that must be a directory if it already exists
+// Folder yang belum ada dibuat saat start (storage.Init)
+func (s *source) directory(env string) string {
+	path := s.required(env)
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		s.fail(env, "%s is not a directory", path)
+	}
+	return path
+}
+
+// filePath returns a path that must not be a directory
+func (s *source) filePath(env string) string {
+	path := s.required(env)
+	if info, err := os.Stat(path); path != "" && err == nil && info.IsDir() {
+		s.fail(env, "%s is a directory", path)
+	}
+	return path
+}
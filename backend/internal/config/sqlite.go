@@ -0,0 +1,43 @@
+package config
+
+import (
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// sqlitePragmas are applied to every SQLite connection
+// WAL + busy_timeout supaya request paralel menunggu lock, bukan langsung gagal "database is locked"
+// _txlock=immediate: transaksi langsung ambil write lock, menghindari deadlock upgrade read -> write
+const sqlitePragmas = "_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(10000)&_txlock=immediate"
+
+// sqliteDialector is the pure Go SQLite dialector (tanpa cgo) with UTC time binding
+// SQLite menyimpan waktu sebagai teks dan membandingkannya secara leksikal, jadi semua time.Time
+// harus ditulis dengan offset yang sama. Filter tanggal dihitung di timezone lokal site,
+// tanpa konversi ini "00:00+07:00" dibandingkan dengan "17:00+00:00" dan hasilnya salah
+type sqliteDialector struct {
+	*sqlite.Dialector
+}
+
+// newSQLiteDialector opens the SQLite database file at path
+func newSQLiteDialector(path string) gorm.Dialector {
+	return sqliteDialector{Dialector: sqlite.Open(path + "?" + sqlitePragmas).(*sqlite.Dialector)}
+}
+
+// BindVarTo converts the time argument just added to the statement to UTC
+func (d sqliteDialector) BindVarTo(writer clause.Writer, stmt *gorm.Statement, v interface{}) {
+	if n := len(stmt.Vars); n > 0 {
+		switch t := stmt.Vars[n-1].(type) {
+		case time.Time:
+			stmt.Vars[n-1] = t.UTC()
+		case *time.Time:
+			if t != nil {
+				utc := t.UTC()
+				stmt.Vars[n-1] = &utc
+			}
+		}
+	}
+	d.Dialector.BindVarTo(writer, stmt, v)
+}
@@ -1,37 +1,99 @@
 package handlers
 
 import (
-	"attendance-system/internal/config"
+	"attendance-system/internal/logging"
+	"attendance-system/internal/metrics"
+	"attendance-system/internal/middleware"
 	"attendance-system/internal/models"
+	"attendance-system/internal/ratelimit"
+	"attendance-system/internal/repository"
 	"attendance-system/internal/services"
 	"attendance-system/internal/utils"
-	"log"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+// ConsentChecker reports whether an employee may use face check-in
+// Diimplementasikan oleh services.ConsentService, bisa diganti fake di handler test
+type ConsentChecker interface {
+	Require(userID uint) error
+	Status(userID uint) (string, error)
+}
+
 // AttendanceHandler handles attendance-related requests
 type AttendanceHandler struct {
-	faceService *services.FaceService
+	users          repository.UserRepository
+	attendances    repository.AttendanceRepository
+	sites          repository.SiteRepository
+	faceService    *services.FaceService
+	consentService ConsentChecker
+	orgService     *services.OrgService
+	tzService      *services.TimezoneService
+	imageService   *services.ImageService
+	metrics        *metrics.Metrics
+	limiter        *ratelimit.Limiter // nil = rate limiting mati
+	mu             sync.RWMutex
+	threshold      float64 // Minimum similarity score untuk face match, bisa diganti saat config reload
 }
 
 // NewAttendanceHandler creates a new AttendanceHandler
-func NewAttendanceHandler() *AttendanceHandler {
+func NewAttendanceHandler(users repository.UserRepository, attendances repository.AttendanceRepository, sites repository.SiteRepository,
+	faceService *services.FaceService, consentService ConsentChecker, orgService *services.OrgService,
+	tzService *services.TimezoneService, imageService *services.ImageService, m *metrics.Metrics, limiter *ratelimit.Limiter,
+	threshold float64) *AttendanceHandler {
 	return &AttendanceHandler{
-		faceService: services.NewFaceService(),
+		users:          users,
+		attendances:    attendances,
+		sites:          sites,
+		faceService:    faceService,
+		consentService: consentService,
+		orgService:     orgService,
+		tzService:      tzService,
+		imageService:   imageService,
+		metrics:        m,
+		limiter:        limiter,
+		threshold:      threshold,
 	}
 }
 
+// SetThreshold replaces the face match threshold (config reload)
+func (h *AttendanceHandler) SetThreshold(threshold float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.threshold = threshold
+}
+
+// attendanceResponse converts an attendance and signs its selfie URLs for the current viewer
+func (h *AttendanceHandler) attendanceResponse(c *fiber.Ctx, attendance *models.Attendance) models.AttendanceResponse {
+	response := attendance.ToResponse()
+	h.imageService.SignAttendance(&response, attendance, middleware.CurrentUserID(c))
+	return response
+}
+
 // CheckIn handles employee check-in dengan face verification
 // POST /api/attendance/checkin
-// Form data: user_id, selfie_image (file)
+// Form data: user_id, selfie_image (file), site_id (optional), device_id (optional)
 func (h *AttendanceHandler) CheckIn(c *fiber.Ctx) error {
 	// Parse user_id
-	userID := c.FormValue("user_id")
-	if userID == "" {
+	if c.FormValue("user_id") == "" {
 		return utils.BadRequestResponse(c, "User ID is required")
 	}
+	userID, err := strconv.ParseUint(c.FormValue("user_id"), 10, 64)
+	if err != nil || userID == 0 {
+		return utils.BadRequestResponse(c, "Invalid user ID")
+	}
+
+	// Parse site_id dan device_id (optional)
+	siteID, deviceID, err := h.checkInLocation(c)
+	if err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
 
 	// Get uploaded selfie
 	selfieImage, err := c.FormFile("selfie_image")
@@ -40,24 +102,41 @@ func (h *AttendanceHandler) CheckIn(c *fiber.Ctx) error {
 	}
 
 	// Get user dari database
-	db := config.GetDB()
-	var user models.User
-	if err := db.First(&user, userID).Error; err != nil {
+	user, err := h.users.FindByID(c.UserContext(), uint(userID))
+	if err != nil {
 		return utils.NotFoundResponse(c, "Employee not found")
 	}
+	// Check-in kiosk tanpa login, jadi karyawan nonaktif ditolak di sini (bukan oleh RequireAuth)
+	if user.DeactivatedAt != nil {
+		return utils.ForbiddenResponse(c, "Employee is deactivated")
+	}
+	// Tanpa consent biometrik yang aktif, karyawan harus pakai fallback check-in
+	if err := h.consentService.Require(user.ID); err != nil {
+		if errors.Is(err, services.ErrConsentRequired) {
+			return utils.ForbiddenResponse(c, "Biometric consent is missing or withdrawn, use the fallback check-in")
+		}
+		slog.ErrorContext(c.UserContext(), "Error checking consent", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to check biometric consent")
+	}
+	// Foto referensi bisa sudah di-purge oleh retention policy
+	if user.FaceDescriptor == "" {
+		return utils.BadRequestResponse(c, "Employee has no reference photo")
+	}
 
 	// Save selfie image
-	selfiePath, err := utils.SaveUploadedFile(selfieImage, config.AppConfig.Upload.Path)
+	selfiePath, err := h.faceService.SaveUploadedImage(c.UserContext(), selfieImage)
 	if err != nil {
-		log.Printf("Error saving selfie: %v", err)
+		slog.ErrorContext(c.UserContext(), "Error saving selfie", logging.Err(err))
 		return utils.InternalServerErrorResponse(c, "Failed to save selfie image")
 	}
 
 	// Verify face
-	threshold := config.AppConfig.Face.SimilarityThreshold
-	isMatch, similarity, err := h.faceService.VerifyFace(selfiePath, user.FaceDescriptor, threshold)
+	h.mu.RLock()
+	threshold := h.threshold
+	h.mu.RUnlock()
+	isMatch, similarity, err := h.faceService.VerifyFace(c.UserContext(), selfiePath, user.FaceDescriptor, threshold)
 	if err != nil {
-		log.Printf("Error verifying face: %v", err)
+		slog.ErrorContext(c.UserContext(), "Error verifying face", logging.Err(err))
 		return utils.InternalServerErrorResponse(c, "Failed to verify face")
 	}
 
@@ -70,92 +149,230 @@ func (h *AttendanceHandler) CheckIn(c *fiber.Ctx) error {
 	// Create attendance record
 	attendance := models.Attendance{
 		UserID:          user.ID,
-		CheckInTime:     time.Now(),
+		CheckInTime:     time.Now().UTC(),
 		FaceImagePath:   selfiePath,
 		SimilarityScore: similarity,
 		Status:          status,
+		SiteID:          siteID,
+		DeviceID:        deviceID,
+		Method:          models.AttendanceMethodFace,
 	}
 
-	if err := db.Create(&attendance).Error; err != nil {
-		log.Printf("Error creating attendance: %v", err)
+	if err := h.attendances.Create(c.UserContext(), &attendance); err != nil {
+		slog.ErrorContext(c.UserContext(), "Error creating attendance", logging.Err(err))
 		return utils.InternalServerErrorResponse(c, "Failed to record attendance")
 	}
+	attendance.User = *user
+	h.metrics.ObserveCheckIn(status, attendance.Method, siteID, deviceID, &similarity)
+	if !isMatch {
+		// Setelah beberapa verifikasi gagal, check-in karyawan ini ditolak sampai bucket terisi lagi
+		key := ratelimit.Key{Rule: ratelimit.RuleCheckInFailed, ID: strconv.FormatUint(userID, 10)}
+		if err := h.limiter.Consume(c.UserContext(), key); err != nil {
+			slog.ErrorContext(c.UserContext(), "Error recording failed verification", logging.Err(err))
+		}
+	}
 
-	// Load user relation untuk response
-	db.Model(&attendance).Association("User").Find(&attendance.User)
-
-	log.Printf("✅ Check-in: %s (ID: %d) - Status: %s, Similarity: %.2f%%",
-		user.Name, user.ID, status, similarity*100)
+	// Nama karyawan tidak di-log, cukup ID
+	slog.InfoContext(c.UserContext(), "Check-in", "user_id", user.ID, "status", status, "similarity", similarity)
+	middleware.AuditEntity(c, "attendance", attendance.ID, nil, attendance.ToResponse())
 
 	// Return response dengan verification result
 	responseData := map[string]interface{}{
-		"attendance":        attendance.ToResponse(),
-		"verification":      isMatch,
-		"similarity_score":  similarity,
-		"threshold":         threshold,
-		"message":           h.getVerificationMessage(isMatch, similarity),
+		"attendance":       h.attendanceResponse(c, &attendance),
+		"verification":     isMatch,
+		"similarity_score": similarity,
+		"threshold":        threshold,
+		"message":          h.getVerificationMessage(isMatch, similarity),
 	}
 
 	return utils.CreatedResponse(c, "Check-in processed", responseData)
 }
 
-// GetAttendances returns attendance history
+// FallbackCheckIn records a check-in without face verification
+// Hanya untuk karyawan tanpa consent biometrik aktif (atau tanpa foto referensi), identitas dari login password
+// POST /api/attendance/checkin/fallback
+// Form data: site_id (optional), device_id (optional)
+func (h *AttendanceHandler) FallbackCheckIn(c *fiber.Ctx) error {
+	siteID, deviceID, err := h.checkInLocation(c)
+	if err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+
+	user, err := h.users.FindByID(c.UserContext(), middleware.CurrentUserID(c))
+	if err != nil {
+		return utils.NotFoundResponse(c, "Employee not found")
+	}
+
+	status, err := h.consentService.Status(user.ID)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "Error checking consent", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to check biometric consent")
+	}
+	if status == models.ConsentStatusConsented && user.FaceDescriptor != "" {
+		return utils.BadRequestResponse(c, "Employee has biometric consent, use face check-in")
+	}
+
+	attendance := models.Attendance{
+		UserID:      user.ID,
+		CheckInTime: time.Now().UTC(),
+		Status:      models.AttendanceStatusSuccess,
+		SiteID:      siteID,
+		DeviceID:    deviceID,
+		Method:      models.AttendanceMethodFallback,
+	}
+	if err := h.attendances.Create(c.UserContext(), &attendance); err != nil {
+		slog.ErrorContext(c.UserContext(), "Error creating attendance", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to record attendance")
+	}
+	attendance.User = *user
+	h.metrics.ObserveCheckIn(attendance.Status, attendance.Method, siteID, deviceID, nil)
+
+	slog.InfoContext(c.UserContext(), "Fallback check-in", "user_id", user.ID, "consent", status)
+	middleware.AuditEntity(c, "attendance", attendance.ID, nil, attendance.ToResponse())
+
+	return utils.CreatedResponse(c, "Check-in recorded", h.attendanceResponse(c, &attendance))
+}
+
+// checkInLocation parses the optional site_id and device_id of a check-in
+func (h *AttendanceHandler) checkInLocation(c *fiber.Ctx) (*uint, string, error) {
+	var siteID *uint
+	if value := c.FormValue("site_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return nil, "", errors.New("Site not found")
+		}
+		site, err := h.sites.FindByID(c.UserContext(), uint(id))
+		if err != nil {
+			return nil, "", errors.New("Site not found")
+		}
+		siteID = &site.ID
+	}
+	deviceID := strings.TrimSpace(c.FormValue("device_id"))
+	if len(deviceID) > 100 {
+		return nil, "", errors.New("Device ID is too long")
+	}
+	return siteID, deviceID, nil
+}
+
+// attendanceSortFields are the allowed sort keys for GetAttendances
+var attendanceSortFields = map[string]utils.SortField{
+	"check_in_time":    {Column: "attendances.check_in_time", Kind: utils.SortKindTime},
+	"similarity_score": {Column: "attendances.similarity_score", Kind: utils.SortKindNumber},
+	"created_at":       {Column: "attendances.created_at", Kind: utils.SortKindTime},
+}
+
+// GetAttendances returns attendance history visible to the caller
 // GET /api/attendance
-// Query params: user_id (optional), limit (optional)
+// Query params (optional): user_id, from, to, status, site_id, device_id,
+// department_id, team_id, manager_id, q (nama/email karyawan),
+// sort (check_in_time, similarity_score, created_at; prefix "-" untuk DESC), limit, cursor
 func (h *AttendanceHandler) GetAttendances(c *fiber.Ctx) error {
-	db := config.GetDB()
-	query := db.Preload("User")
+	page, err := utils.ParsePageParams(c, attendanceSortFields, "attendances.id", "-check_in_time")
+	if err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
 
-	// Filter by user_id jika ada
-	if userID := c.Query("user_id"); userID != "" {
-		query = query.Where("user_id = ?", userID)
+	visible, err := callerVisibility(c, h.orgService)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "Error building manager scope", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to fetch attendance records")
+	}
+	filter := repository.AttendanceFilter{
+		Visible:  visible,
+		UserID:   queryUint(c, "user_id"),
+		SiteID:   queryUint(c, "site_id"),
+		Status:   c.Query("status"),
+		DeviceID: c.Query("device_id"),
+		Search:   c.Query("q"), // Nama/email karyawan
 	}
 
-	// Limit results
-	limit := c.QueryInt("limit", 50)
-	query = query.Limit(limit)
+	// Tanggal from/to dibaca di kalender lokal site (jika difilter), karyawan, atau organisasi
+	loc := h.tzService.DefaultLocation()
+	if filter.UserID != 0 {
+		loc = h.tzService.LocationForUser(filter.UserID, time.Now())
+	}
+	if filter.SiteID != 0 {
+		loc = h.tzService.LocationForSite(&filter.SiteID)
+	}
 
-	// Order by latest
-	query = query.Order("check_in_time DESC")
+	// Filter by date range
+	if from := c.Query("from"); from != "" {
+		if filter.From, err = parseDateOrTime(from, loc); err != nil {
+			return utils.BadRequestResponse(c, "from must be RFC3339 or YYYY-MM-DD")
+		}
+	}
+	if to := c.Query("to"); to != "" {
+		if filter.To, err = parseRangeEnd(to, loc); err != nil {
+			return utils.BadRequestResponse(c, "to must be RFC3339 or YYYY-MM-DD")
+		}
+	}
+
+	// Filter by org unit pada saat check-in
+	if orgFilter := parseOrgFilter(c); !orgFilter.IsEmpty() {
+		if filter.Org, err = h.orgService.AssignmentFilter(orgFilter); err != nil {
+			slog.ErrorContext(c.UserContext(), "Error building org filter", logging.Err(err))
+			return utils.InternalServerErrorResponse(c, "Failed to fetch attendance records")
+		}
+	}
 
-	var attendances []models.Attendance
-	if err := query.Find(&attendances).Error; err != nil {
-		log.Printf("Error fetching attendances: %v", err)
+	attendances, total, err := h.attendances.Page(c.UserContext(), filter, page)
+	if errors.Is(err, utils.ErrInvalidCursor) {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "Error fetching attendances", logging.Err(err))
 		return utils.InternalServerErrorResponse(c, "Failed to fetch attendance records")
 	}
 
+	meta := utils.PageMeta{Total: total, Limit: page.Limit, Sort: page.Sort()}
+	if len(attendances) > page.Limit {
+		attendances = attendances[:page.Limit]
+		last := attendances[len(attendances)-1]
+		meta.NextCursor = page.NextCursor(attendanceSortValue(&last, page.SortKey), last.ID)
+	}
+
 	// Convert to response format
 	responses := make([]models.AttendanceResponse, len(attendances))
-	for i, attendance := range attendances {
-		responses[i] = attendance.ToResponse()
+	for i := range attendances {
+		responses[i] = h.attendanceResponse(c, &attendances[i])
 	}
 
-	return utils.SuccessResponse(c, "Attendance records fetched successfully", responses)
+	return utils.PaginatedResponse(c, "Attendance records fetched successfully", responses, meta)
+}
+
+// attendanceSortValue returns the value of the sort field for cursor building
+func attendanceSortValue(a *models.Attendance, key string) interface{} {
+	switch key {
+	case "similarity_score":
+		return a.SimilarityScore
+	case "created_at":
+		return a.CreatedAt
+	default:
+		return a.CheckInTime
+	}
 }
 
 // GetTodayAttendance returns today's attendance for a user
+// "Hari ini" dihitung di kalender lokal site karyawan, bukan timezone server
 // GET /api/attendance/today/:user_id
 func (h *AttendanceHandler) GetTodayAttendance(c *fiber.Ctx) error {
-	userID := c.Params("user_id")
-
-	db := config.GetDB()
-	
-	// Get today's start time (00:00:00)
-	now := time.Now()
-	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	userID, err := c.ParamsInt("user_id")
+	if err != nil || userID <= 0 {
+		return utils.BadRequestResponse(c, "Invalid user ID")
+	}
+	if !canViewEmployee(c, h.orgService, uint(userID)) {
+		return utils.NotFoundResponse(c, "No attendance record found for today")
+	}
 
-	var attendance models.Attendance
-	err := db.Preload("User").
-		Where("user_id = ? AND check_in_time >= ?", userID, startOfDay).
-		Where("status = ?", models.AttendanceStatusSuccess).
-		Order("check_in_time DESC").
-		First(&attendance).Error
+	// Get today's range in employee local time
+	startOfDay, endOfDay, _ := h.tzService.UserDayBounds(uint(userID), time.Now())
 
+	attendance, err := h.attendances.LatestSuccessful(c.UserContext(), uint(userID), startOfDay, endOfDay)
 	if err != nil {
 		return utils.NotFoundResponse(c, "No attendance record found for today")
 	}
 
-	return utils.SuccessResponse(c, "Today's attendance fetched successfully", attendance.ToResponse())
+	return utils.SuccessResponse(c, "Today's attendance fetched successfully", h.attendanceResponse(c, attendance))
 }
 
 // getVerificationMessage returns user-friendly message based on verification result
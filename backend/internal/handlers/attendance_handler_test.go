@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"attendance-system/internal/models"
+	"attendance-system/internal/repository"
+	"attendance-system/internal/services"
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+)
+
+// fakeConsent is a ConsentChecker with a fixed status for every employee
+type fakeConsent struct {
+	status string
+}
+
+func (f fakeConsent) Require(userID uint) error {
+	if f.status != models.ConsentStatusConsented {
+		return services.ErrConsentRequired
+	}
+	return nil
+}
+
+func (f fakeConsent) Status(userID uint) (string, error) {
+	return f.status, nil
+}
+
+// newTestAttendanceHandler builds an AttendanceHandler on the in-memory repositories, tanpa face engine dan rate limit
+func newTestAttendanceHandler(users repository.UserRepository, attendances repository.AttendanceRepository,
+	sites repository.SiteRepository) *AttendanceHandler {
+	orgService := services.NewOrgService(nil)
+	return NewAttendanceHandler(users, attendances, sites, nil, fakeConsent{status: models.ConsentStatusMissing},
+		orgService, services.NewTimezoneService(nil, orgService, time.UTC), testImageService(), nil, nil, 0.6)
+}
+
+func TestFallbackCheckInLocation(t *testing.T) {
+	users := testEmployees()
+	attendances := repository.NewMemoryAttendanceRepository(users)
+	sites := repository.NewMemorySiteRepository(models.Site{ID: 7, Name: "Jakarta"})
+	h := newTestAttendanceHandler(users, attendances, sites)
+
+	tests := []struct {
+		name       string
+		fields     url.Values
+		wantStatus int
+		wantSite   *uint
+	}{
+		{"without site", url.Values{"device_id": {"kiosk-1"}}, http.StatusCreated, nil},
+		{"known site", url.Values{"site_id": {"7"}}, http.StatusCreated, ptr(uint(7))},
+		{"unknown site", url.Values{"site_id": {"8"}}, http.StatusBadRequest, nil},
+		{"site id is not a number", url.Values{"site_id": {"7 OR 1=1"}}, http.StatusBadRequest, nil},
+		{"device id too long", url.Values{"device_id": {string(make([]byte, 101))}}, http.StatusBadRequest, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(http.MethodPost, "/api/attendance/checkin/fallback", 2, models.RoleEmployee, h.FallbackCheckIn)
+			status, resp := postForm(t, app, "/api/attendance/checkin/fallback", tt.fields)
+			if status != tt.wantStatus {
+				t.Fatalf("status %d, want %d (%s)", status, tt.wantStatus, resp.Message)
+			}
+			if status != http.StatusCreated {
+				return
+			}
+
+			var attendance models.AttendanceResponse
+			decodeData(t, resp, &attendance)
+			stored, err := attendances.FindByID(context.Background(), attendance.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored.UserID != 2 || stored.Method != models.AttendanceMethodFallback {
+				t.Fatalf("stored check-in %+v, want fallback for employee 2", stored)
+			}
+			if (stored.SiteID == nil) != (tt.wantSite == nil) || (stored.SiteID != nil && *stored.SiteID != *tt.wantSite) {
+				t.Fatalf("site %v, want %v", stored.SiteID, tt.wantSite)
+			}
+		})
+	}
+}
+
+func TestGetAttendancesVisibility(t *testing.T) {
+	users := testEmployees()
+	attendances := repository.NewMemoryAttendanceRepository(users)
+	base := time.Date(2024, 3, 4, 2, 0, 0, 0, time.UTC)
+	for i, userID := range []uint{2, 3, 2, 4} {
+		status := models.AttendanceStatusSuccess
+		if userID == 4 {
+			status = models.AttendanceStatusFailed
+		}
+		attendance := &models.Attendance{UserID: userID, CheckInTime: base.Add(time.Duration(i) * time.Hour), Status: status}
+		if err := attendances.Create(context.Background(), attendance); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h := newTestAttendanceHandler(users, attendances, repository.NewMemorySiteRepository())
+
+	tests := []struct {
+		name     string
+		callerID uint
+		role     string
+		target   string
+		wantIDs  []uint
+	}{
+		{"employee only sees own check-ins", 2, models.RoleEmployee, "/api/attendance", []uint{3, 1}},
+		{"hr sees everything, newest first", 1, models.RoleHR, "/api/attendance", []uint{4, 3, 2, 1}},
+		{"status filter", 1, models.RoleHR, "/api/attendance?status=failed", []uint{4}},
+		{"search by employee name", 1, models.RoleHR, "/api/attendance?q=citra", []uint{2}},
+		{"date range in org timezone", 1, models.RoleHR, "/api/attendance?from=2024-03-04T03:00:00Z&to=2024-03-04T05:00:00Z&sort=check_in_time", []uint{2, 3}},
+		{"no check-ins on another day", 1, models.RoleHR, "/api/attendance?from=2024-03-05", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(http.MethodGet, "/api/attendance", tt.callerID, tt.role, h.GetAttendances)
+			status, resp := getJSON(t, app, tt.target)
+			if status != http.StatusOK {
+				t.Fatalf("status %d, want 200 (%s)", status, resp.Message)
+			}
+			var records []models.AttendanceResponse
+			decodeData(t, resp, &records)
+			var ids []uint
+			for _, record := range records {
+				ids = append(ids, record.ID)
+			}
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Fatalf("check-ins %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+
+	app := newTestApp(http.MethodGet, "/api/attendance", 1, models.RoleHR, h.GetAttendances)
+	if status, resp := getJSON(t, app, "/api/attendance?cursor=%%%"); status != http.StatusBadRequest {
+		t.Fatalf("invalid cursor: status %d, want 400 (%s)", status, resp.Message)
+	}
+}
+
+// ptr returns a pointer to v
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestCheckInDeactivatedEmployee(t *testing.T) {
+	deactivatedAt := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	users := repository.NewMemoryUserRepository(
+		models.User{ID: 2, Name: "Budi", Email: "budi@example.com", Role: models.RoleEmployee},
+		models.User{ID: 3, Name: "Citra", Email: "citra@example.com", Role: models.RoleEmployee, DeactivatedAt: &deactivatedAt},
+	)
+	h := newTestAttendanceHandler(users, repository.NewMemoryAttendanceRepository(users), repository.NewMemorySiteRepository())
+	// Consent aktif supaya karyawan aktif lolos sampai cek foto referensi (tanpa face engine)
+	h.consentService = fakeConsent{status: models.ConsentStatusConsented}
+
+	tests := []struct {
+		name       string
+		userID     string
+		wantStatus int
+	}{
+		{"active employee without reference photo", "2", http.StatusBadRequest},
+		{"deactivated employee", "3", http.StatusForbidden},
+		{"unknown employee", "9", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body bytes.Buffer
+			writer := multipart.NewWriter(&body)
+			writer.WriteField("user_id", tt.userID)
+			part, err := writer.CreateFormFile("selfie_image", "selfie.jpg")
+			if err != nil {
+				t.Fatal(err)
+			}
+			part.Write([]byte("selfie"))
+			writer.Close()
+
+			app := newTestApp(http.MethodPost, "/api/attendance/checkin", 0, "", h.CheckIn)
+			status, resp := doRequest(t, app, http.MethodPost, "/api/attendance/checkin", writer.FormDataContentType(), body.String())
+			if status != tt.wantStatus {
+				t.Fatalf("status %d, want %d (%s)", status, tt.wantStatus, resp.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"attendance-system/internal/logging"
+	"attendance-system/internal/middleware"
+	"attendance-system/internal/models"
+	"attendance-system/internal/services"
+	"attendance-system/internal/utils"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// auditExportBatchSize is how many rows are read per query during export
+const auditExportBatchSize = 500
+
+// AuditHandler serves the audit log to auditors
+type AuditHandler struct {
+	auditService *services.AuditService
+	loc          *time.Location // Timezone organisasi untuk filter tanggal
+}
+
+// NewAuditHandler creates a new AuditHandler
+func NewAuditHandler(auditService *services.AuditService, loc *time.Location) *AuditHandler {
+	return &AuditHandler{auditService: auditService, loc: loc}
+}
+
+// auditSortFields are the allowed sort keys for GetAuditLogs
+var auditSortFields = map[string]utils.SortField{
+	"sequence":   {Column: "audit_logs.sequence", Kind: utils.SortKindNumber},
+	"created_at": {Column: "audit_logs.created_at", Kind: utils.SortKindTime},
+}
+
+// GetAuditLogs returns audit log entries
+// GET /api/audit
+// Query params (optional): actor_id, action, entity_type, entity_id, request_id,
+// from, to (RFC3339 atau YYYY-MM-DD), sort (sequence, created_at), limit, cursor
+func (h *AuditHandler) GetAuditLogs(c *fiber.Ctx) error {
+	page, err := utils.ParsePageParams(c, auditSortFields, "audit_logs.id", "-sequence")
+	if err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+
+	filter, err := parseAuditFilter(c, h.loc)
+	if err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+	query := h.auditService.Query(filter)
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		slog.ErrorContext(c.UserContext(), "Error counting audit logs", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to fetch audit logs")
+	}
+
+	query, err = page.Apply(query)
+	if err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+
+	var entries []models.AuditLog
+	if err := query.Find(&entries).Error; err != nil {
+		slog.ErrorContext(c.UserContext(), "Error fetching audit logs", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to fetch audit logs")
+	}
+
+	meta := utils.PageMeta{Total: total, Limit: page.Limit, Sort: page.Sort()}
+	if len(entries) > page.Limit {
+		entries = entries[:page.Limit]
+		last := entries[len(entries)-1]
+		var value interface{} = last.Sequence
+		if page.SortKey == "created_at" {
+			value = last.CreatedAt
+		}
+		meta.NextCursor = page.NextCursor(value, last.ID)
+	}
+
+	return utils.PaginatedResponse(c, "Audit logs fetched successfully", entries, meta)
+}
+
+// ExportAuditLogs streams matching entries ordered by sequence
+// GET /api/audit/export
+// Query params: format (csv default / jsonl), plus filter yang sama dengan GET /api/audit
+func (h *AuditHandler) ExportAuditLogs(c *fiber.Ctx) error {
+	filter, err := parseAuditFilter(c, h.loc)
+	if err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+
+	format := c.Query("format", "csv")
+	if format != "csv" && format != "jsonl" {
+		return utils.BadRequestResponse(c, "format must be csv or jsonl")
+	}
+
+	// Export sendiri ikut tercatat beserta filternya
+	middleware.AuditEntity(c, "audit_log", "export", nil, fiber.Map{"format": format, "query": string(c.Request().URI().QueryString())})
+
+	filename := fmt.Sprintf("audit-%s.%s", time.Now().UTC().Format("20060102-150405"), format)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	if format == "csv" {
+		c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	} else {
+		c.Set(fiber.HeaderContentType, "application/x-ndjson")
+	}
+
+	// Ditulis bertahap supaya export besar tidak dimuat ke memory sekaligus
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		var err error
+		if format == "csv" {
+			err = h.writeCSV(w, filter)
+		} else {
+			err = h.writeJSONL(w, filter)
+		}
+		if err != nil {
+			slog.ErrorContext(c.UserContext(), "Error exporting audit logs", logging.Err(err))
+		}
+		w.Flush()
+	})
+	return nil
+}
+
+// VerifyAuditLogs recomputes the hash chain
+// GET /api/audit/verify
+func (h *AuditHandler) VerifyAuditLogs(c *fiber.Ctx) error {
+	result, err := h.auditService.Verify()
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "Error verifying audit logs", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to verify audit logs")
+	}
+
+	if !result.Valid {
+		slog.WarnContext(c.UserContext(), "Audit log chain broken", "sequence", result.BrokenAt, "reason", result.Reason)
+		return c.Status(fiber.StatusConflict).JSON(utils.APIResponse{
+			Status:  "error",
+			Message: "Audit log chain is broken",
+			Data:    result,
+		})
+	}
+
+	return utils.SuccessResponse(c, "Audit log chain is intact", result)
+}
+
+// writeCSV writes entries as CSV with a header row
+func (h *AuditHandler) writeCSV(w *bufio.Writer, filter services.AuditFilter) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{
+		"sequence", "created_at", "actor_id", "actor_role", "action", "method", "path", "status_code",
+		"entity_type", "entity_id", "request_id", "ip", "user_agent", "diff", "prev_hash", "hash",
+	})
+
+	err := h.auditService.Each(filter, auditExportBatchSize, func(entry *models.AuditLog) error {
+		actorID := ""
+		if entry.ActorID != nil {
+			actorID = strconv.FormatUint(uint64(*entry.ActorID), 10)
+		}
+		return writer.Write([]string{
+			strconv.FormatUint(entry.Sequence, 10),
+			entry.CreatedAt.UTC().Format(time.RFC3339Nano),
+			actorID,
+			entry.ActorRole,
+			entry.Action,
+			entry.Method,
+			entry.Path,
+			strconv.Itoa(entry.StatusCode),
+			entry.EntityType,
+			entry.EntityID,
+			entry.RequestID,
+			entry.IP,
+			entry.UserAgent,
+			entry.Diff,
+			entry.PrevHash,
+			entry.Hash,
+		})
+	})
+	writer.Flush()
+	if err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// writeJSONL writes one JSON object per line
+func (h *AuditHandler) writeJSONL(w *bufio.Writer, filter services.AuditFilter) error {
+	encoder := json.NewEncoder(w)
+	return h.auditService.Each(filter, auditExportBatchSize, func(entry *models.AuditLog) error {
+		entry.CreatedAt = entry.CreatedAt.UTC()
+		return encoder.Encode(entry)
+	})
+}
+
+// parseAuditFilter reads audit filters from query params
+func parseAuditFilter(c *fiber.Ctx, loc *time.Location) (services.AuditFilter, error) {
+	filter := services.AuditFilter{
+		ActorID:    queryUint(c, "actor_id"),
+		Action:     c.Query("action"),
+		EntityType: c.Query("entity_type"),
+		EntityID:   c.Query("entity_id"),
+		RequestID:  c.Query("request_id"),
+	}
+
+	if from := c.Query("from"); from != "" {
+		start, err := parseDateOrTime(from, loc)
+		if err != nil {
+			return filter, fmt.Errorf("from must be RFC3339 or YYYY-MM-DD")
+		}
+		filter.From = &start
+	}
+	if to := c.Query("to"); to != "" {
+		end, err := parseRangeEnd(to, loc)
+		if err != nil {
+			return filter, fmt.Errorf("to must be RFC3339 or YYYY-MM-DD")
+		}
+		filter.To = &end
+	}
+	return filter, nil
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"attendance-system/internal/logging"
+	"attendance-system/internal/middleware"
+	"attendance-system/internal/models"
+	"attendance-system/internal/repository"
+	"attendance-system/internal/services"
+	"attendance-system/internal/utils"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AuthHandler handles login and account credentials
+type AuthHandler struct {
+	users        repository.UserRepository
+	authService  *services.AuthService
+	imageService *services.ImageService
+}
+
+// NewAuthHandler creates a new AuthHandler
+func NewAuthHandler(users repository.UserRepository, authService *services.AuthService, imageService *services.ImageService) *AuthHandler {
+	return &AuthHandler{users: users, authService: authService, imageService: imageService}
+}
+
+// userResponse converts a user and signs its photo URLs for the viewer
+func (h *AuthHandler) userResponse(user *models.User, viewerID uint) models.UserResponse {
+	response := user.ToResponse()
+	h.imageService.SignUser(&response, user, viewerID)
+	return response
+}
+
+// loginRequest is the body for password login
+type loginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// credentialsRequest is the body for setting role and password of an employee
+type credentialsRequest struct {
+	Role     string `json:"role"`
+	Password string `json:"password"`
+}
+
+// Login authenticates with email and password
+// POST /api/auth/login
+// JSON body: email, password
+func (h *AuthHandler) Login(c *fiber.Ctx) error {
+	var req loginRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+	if req.Email == "" || req.Password == "" {
+		return utils.BadRequestResponse(c, "Email and password are required")
+	}
+
+	token, user, err := h.authService.Login(req.Email, req.Password)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCredentials) {
+			return utils.UnauthorizedResponse(c, err.Error())
+		}
+		slog.ErrorContext(c.UserContext(), "Error during login", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to login")
+	}
+
+	return utils.SuccessResponse(c, "Login successful", fiber.Map{
+		"token":      token,
+		"expires_in": int(h.authService.TTL().Seconds()),
+		"user":       h.userResponse(user, user.ID),
+	})
+}
+
+// Me returns the authenticated user
+// GET /api/auth/me
+func (h *AuthHandler) Me(c *fiber.Ctx) error {
+	user, err := h.users.FindByID(c.UserContext(), middleware.CurrentUserID(c))
+	if err != nil {
+		return utils.NotFoundResponse(c, "User not found")
+	}
+
+	return utils.SuccessResponse(c, "User fetched successfully", h.userResponse(user, user.ID))
+}
+
+// SetCredentials sets role and/or password of an employee (admin only)
+// PUT /api/employees/:id/credentials
+// JSON body: role (employee, manager, hr, admin), password (optional)
+func (h *AuthHandler) SetCredentials(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return utils.NotFoundResponse(c, "Employee not found")
+	}
+	user, err := h.users.FindByID(c.UserContext(), uint(id))
+	if err != nil {
+		return utils.NotFoundResponse(c, "Employee not found")
+	}
+	before := fiber.Map{"role": user.Role}
+
+	var req credentialsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+
+	role := strings.TrimSpace(req.Role)
+	if role != "" && !models.IsValidRole(role) {
+		return utils.BadRequestResponse(c, "Role must be employee, manager, hr, admin or auditor")
+	}
+	var passwordHash string
+	if req.Password != "" {
+		if passwordHash, err = services.HashPassword(req.Password); err != nil {
+			return utils.BadRequestResponse(c, err.Error())
+		}
+	}
+	if role == "" && passwordHash == "" {
+		return utils.BadRequestResponse(c, "Role or password is required")
+	}
+
+	if err := h.users.UpdateCredentials(c.UserContext(), user.ID, role, passwordHash); err != nil {
+		slog.ErrorContext(c.UserContext(), "Error updating credentials", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to update credentials")
+	}
+	if role != "" {
+		user.Role = role
+	}
+
+	slog.InfoContext(c.UserContext(), "Credentials updated", "user_id", user.ID, "role", user.Role)
+	// Hash password tidak pernah masuk audit log, cukup penanda bahwa password diganti
+	middleware.AuditEntity(c, "employee", user.ID, before, fiber.Map{"role": user.Role, "password_changed": req.Password != ""})
+
+	return utils.SuccessResponse(c, "Credentials updated successfully", h.userResponse(user, middleware.CurrentUserID(c)))
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"attendance-system/internal/models"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestSetCredentials(t *testing.T) {
+	tests := []struct {
+		name         string
+		userID       uint
+		body         string
+		wantStatus   int
+		wantRole     string
+		wantPassword string // Kosong = hash tidak boleh berubah
+	}{
+		{"promote to manager", 2, `{"role":"manager"}`, http.StatusOK, models.RoleManager, ""},
+		{"set password only", 2, `{"password":"correct horse battery"}`, http.StatusOK, models.RoleEmployee, "correct horse battery"},
+		{"role and password", 3, `{"role":" hr ","password":"correct horse battery"}`, http.StatusOK, models.RoleHR, "correct horse battery"},
+		{"invalid role", 2, `{"role":"root"}`, http.StatusBadRequest, models.RoleEmployee, ""},
+		{"password too short", 2, `{"password":"short"}`, http.StatusBadRequest, models.RoleEmployee, ""},
+		{"nothing to change", 2, `{}`, http.StatusBadRequest, models.RoleEmployee, ""},
+		{"unknown employee", 99, `{"role":"hr"}`, http.StatusNotFound, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := testEmployees()
+			h := NewAuthHandler(users, nil, testImageService())
+			app := newTestApp(http.MethodPut, "/api/employees/:id/credentials", 1, models.RoleAdmin, h.SetCredentials)
+
+			target := fmt.Sprintf("/api/employees/%d/credentials", tt.userID)
+			status, resp := doRequest(t, app, http.MethodPut, target, fiber.MIMEApplicationJSON, tt.body)
+			if status != tt.wantStatus {
+				t.Fatalf("status %d, want %d (%s)", status, tt.wantStatus, resp.Message)
+			}
+			if tt.wantRole == "" {
+				return
+			}
+
+			stored, err := users.FindByID(context.Background(), tt.userID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored.Role != tt.wantRole {
+				t.Fatalf("role %q, want %q", stored.Role, tt.wantRole)
+			}
+			if tt.wantPassword == "" {
+				if stored.PasswordHash != "" {
+					t.Fatalf("password hash changed")
+				}
+				return
+			}
+			if bcrypt.CompareHashAndPassword([]byte(stored.PasswordHash), []byte(tt.wantPassword)) != nil {
+				t.Fatalf("password was not stored as a hash of the new password")
+			}
+
+			var user models.UserResponse
+			decodeData(t, resp, &user)
+			if user.Role != tt.wantRole {
+				t.Fatalf("response role %q, want %q", user.Role, tt.wantRole)
+			}
+		})
+	}
+}
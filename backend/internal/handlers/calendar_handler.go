@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"attendance-system/internal/logging"
+	"attendance-system/internal/middleware"
+	"attendance-system/internal/models"
+	"attendance-system/internal/services"
+	"attendance-system/internal/utils"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// CalendarHandler handles holidays and working-day overrides
+type CalendarHandler struct {
+	db              *gorm.DB
+	calendarService *services.CalendarService
+	summaryService  *services.DailySummaryService
+}
+
+// NewCalendarHandler creates a new CalendarHandler
+func NewCalendarHandler(db *gorm.DB, calendarService *services.CalendarService, summaryService *services.DailySummaryService) *CalendarHandler {
+	return &CalendarHandler{db: db, calendarService: calendarService, summaryService: summaryService}
+}
+
+// calendarEntryRequest is the body for creating a calendar entry
+type calendarEntryRequest struct {
+	Date   string `json:"date"` // YYYY-MM-DD
+	Kind   string `json:"kind"` // holiday (default) / working_day
+	Name   string `json:"name"`
+	SiteID *uint  `json:"site_id"` // kosong = seluruh organisasi
+}
+
+// GetCalendar returns every date in the range resolved to working day or not
+// GET /api/calendar
+// Query params: from, to (YYYY-MM-DD, maks 366 hari), site_id (optional)
+func (h *CalendarHandler) GetCalendar(c *fiber.Ctx) error {
+	from, to := c.Query("from"), c.Query("to")
+	if from == "" || to == "" {
+		return utils.BadRequestResponse(c, "from and to are required")
+	}
+
+	var siteID *uint
+	if id := queryUint(c, "site_id"); id != 0 {
+		siteID = &id
+	}
+
+	days, err := h.calendarService.Days(from, to, siteID)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidDateRange) {
+			return utils.BadRequestResponse(c, err.Error())
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.NotFoundResponse(c, "Site not found")
+		}
+		slog.ErrorContext(c.UserContext(), "Error fetching calendar", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to fetch calendar")
+	}
+
+	return utils.SuccessResponse(c, "Calendar fetched successfully", days)
+}
+
+// GetCalendarEntries returns raw holiday/working-day entries
+// GET /api/calendar/entries
+// Query params (optional): from, to (YYYY-MM-DD), site_id, kind
+func (h *CalendarHandler) GetCalendarEntries(c *fiber.Ctx) error {
+	query := h.db.Preload("Site").Order("date ASC")
+
+	if from := c.Query("from"); from != "" {
+		if _, err := time.Parse(utils.DateLayout, from); err != nil {
+			return utils.BadRequestResponse(c, "from must be YYYY-MM-DD")
+		}
+		query = query.Where("date >= ?", from)
+	}
+	if to := c.Query("to"); to != "" {
+		if _, err := time.Parse(utils.DateLayout, to); err != nil {
+			return utils.BadRequestResponse(c, "to must be YYYY-MM-DD")
+		}
+		query = query.Where("date <= ?", to)
+	}
+	if siteID := queryUint(c, "site_id"); siteID != 0 {
+		query = query.Where("site_id = ? OR site_id IS NULL", siteID)
+	}
+	if kind := c.Query("kind"); kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+
+	var entries []models.CalendarDay
+	if err := query.Find(&entries).Error; err != nil {
+		slog.ErrorContext(c.UserContext(), "Error fetching calendar entries", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to fetch calendar entries")
+	}
+
+	return utils.SuccessResponse(c, "Calendar entries fetched successfully", entries)
+}
+
+// CreateCalendarEntry creates or replaces the entry for a date and site
+// POST /api/calendar/entries
+// JSON body: date, kind (holiday / working_day), name, site_id (optional)
+func (h *CalendarHandler) CreateCalendarEntry(c *fiber.Ctx) error {
+	var req calendarEntryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+
+	if req.SiteID != nil && h.db.First(&models.Site{}, *req.SiteID).Error != nil {
+		return utils.BadRequestResponse(c, "Site not found")
+	}
+	if req.Kind == "" {
+		req.Kind = models.CalendarKindHoliday
+	}
+
+	entry := models.CalendarDay{
+		Date:   strings.TrimSpace(req.Date),
+		Kind:   req.Kind,
+		Name:   strings.TrimSpace(req.Name),
+		SiteID: req.SiteID,
+		Source: "manual",
+	}
+	if err := h.calendarService.Save(&entry); err != nil {
+		if errors.Is(err, services.ErrInvalidCalendar) {
+			return utils.BadRequestResponse(c, err.Error())
+		}
+		slog.ErrorContext(c.UserContext(), "Error saving calendar entry", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to save calendar entry")
+	}
+
+	slog.InfoContext(c.UserContext(), "Calendar entry saved", "date", entry.Date, "kind", entry.Kind)
+	middleware.AuditEntity(c, "calendar_day", entry.ID, nil, entry)
+	h.recomputeIfClosed(c.UserContext(), entry.Date)
+
+	return utils.CreatedResponse(c, "Calendar entry saved successfully", entry)
+}
+
+// DeleteCalendarEntry removes a calendar entry
+// DELETE /api/calendar/entries/:id
+func (h *CalendarHandler) DeleteCalendarEntry(c *fiber.Ctx) error {
+	var entry models.CalendarDay
+	if err := h.db.First(&entry, c.Params("id")).Error; err != nil {
+		return utils.NotFoundResponse(c, "Calendar entry not found")
+	}
+
+	if err := h.db.Delete(&entry).Error; err != nil {
+		slog.ErrorContext(c.UserContext(), "Error deleting calendar entry", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to delete calendar entry")
+	}
+
+	slog.InfoContext(c.UserContext(), "Calendar entry deleted", "date", entry.Date)
+	middleware.AuditEntity(c, "calendar_day", entry.ID, entry, nil)
+	h.recomputeIfClosed(c.UserContext(), entry.Date)
+
+	return utils.SuccessResponse(c, "Calendar entry deleted successfully", nil)
+}
+
+// ImportCalendar imports holidays from an iCalendar (.ics) file
+// POST /api/calendar/import
+// Form data: ics_file (file), site_id (optional), kind (optional, default holiday)
+func (h *CalendarHandler) ImportCalendar(c *fiber.Ctx) error {
+	file, err := c.FormFile("ics_file")
+	if err != nil {
+		return utils.BadRequestResponse(c, "ICS file is required")
+	}
+
+	var siteID *uint
+	if value := c.FormValue("site_id"); value != "" {
+		var site models.Site
+		if err := h.db.Select("id").First(&site, value).Error; err != nil {
+			return utils.BadRequestResponse(c, "Site not found")
+		}
+		siteID = &site.ID
+	}
+	kind := c.FormValue("kind", models.CalendarKindHoliday)
+
+	src, err := file.Open()
+	if err != nil {
+		return utils.BadRequestResponse(c, "Failed to read ICS file")
+	}
+	defer src.Close()
+
+	result, err := h.calendarService.ImportICS(src, siteID, kind)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCalendar) {
+			return utils.BadRequestResponse(c, err.Error())
+		}
+		slog.ErrorContext(c.UserContext(), "Error importing calendar", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to import calendar")
+	}
+
+	slog.InfoContext(c.UserContext(), "Calendar imported", "events", result.Events, "created", result.Created, "updated", result.Updated)
+	middleware.AuditEntity(c, "calendar_import", file.Filename, nil, result)
+
+	return utils.SuccessResponse(c, "Calendar imported successfully", result)
+}
+
+// recomputeIfClosed refreshes daily rows when a past date changes
+func (h *CalendarHandler) recomputeIfClosed(ctx context.Context, date string) {
+	if date > h.summaryService.ClosedUntil(time.Now()) {
+		return
+	}
+	if _, err := h.summaryService.MaterializeDate(date, nil, ""); err != nil {
+		slog.ErrorContext(ctx, "Error recomputing daily attendance", "date", date, logging.Err(err))
+	}
+}
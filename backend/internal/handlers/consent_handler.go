@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"attendance-system/internal/logging"
+	"attendance-system/internal/middleware"
+	"attendance-system/internal/models"
+	"attendance-system/internal/services"
+	"attendance-system/internal/utils"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// ConsentHandler handles biometric consent records and coverage reporting
+type ConsentHandler struct {
+	db             *gorm.DB
+	consentService *services.ConsentService
+	orgService     *services.OrgService
+}
+
+// NewConsentHandler creates a new ConsentHandler
+func NewConsentHandler(db *gorm.DB, consentService *services.ConsentService, orgService *services.OrgService) *ConsentHandler {
+	return &ConsentHandler{db: db, consentService: consentService, orgService: orgService}
+}
+
+// consentRequest is the body for recording consent
+type consentRequest struct {
+	Method string `json:"method"` // paper / form, diabaikan kalau karyawan consent sendiri
+}
+
+// withdrawConsentRequest is the body for withdrawing consent
+type withdrawConsentRequest struct {
+	Reason string `json:"reason"`
+}
+
+// consentError maps consent service errors to HTTP responses
+func consentError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return utils.NotFoundResponse(c, "Employee not found")
+	case errors.Is(err, services.ErrInvalidConsentMethod), errors.Is(err, services.ErrInvalidConsentStatus):
+		return utils.BadRequestResponse(c, err.Error())
+	case errors.Is(err, services.ErrConsentAlreadyGiven), errors.Is(err, services.ErrConsentNotActive):
+		return utils.ConflictResponse(c, err.Error())
+	}
+	slog.ErrorContext(c.UserContext(), "Error processing consent", logging.Err(err))
+	return utils.InternalServerErrorResponse(c, "Failed to process consent")
+}
+
+// consentTarget resolves the employee of a consent route
+// Karyawan hanya boleh untuk dirinya sendiri, HR/admin untuk siapa saja
+func (h *ConsentHandler) consentTarget(c *fiber.Ctx) (*models.User, bool, error) {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return nil, false, utils.BadRequestResponse(c, "Invalid employee ID")
+	}
+
+	self := uint(id) == middleware.CurrentUserID(c)
+	role := middleware.CurrentRole(c)
+	if !self && role != models.RoleHR && role != models.RoleAdmin {
+		return nil, false, utils.ForbiddenResponse(c, "Not allowed to manage this employee's consent")
+	}
+
+	var user models.User
+	if err := h.db.Select("id", "name", "deactivated_at").First(&user, id).Error; err != nil {
+		return nil, false, utils.NotFoundResponse(c, "Employee not found")
+	}
+	return &user, self, nil
+}
+
+// GetConsent returns the consent status and history of an employee
+// GET /api/employees/:id/consent
+func (h *ConsentHandler) GetConsent(c *fiber.Ctx) error {
+	user, _, errResponse := h.consentTarget(c)
+	if user == nil {
+		return errResponse
+	}
+
+	history, err := h.consentService.History(user.ID)
+	if err != nil {
+		return consentError(c, err)
+	}
+	var latest *models.BiometricConsent
+	if len(history) > 0 {
+		latest = &history[0]
+	}
+
+	return utils.SuccessResponse(c, "Consent fetched successfully", fiber.Map{
+		"policy_version": h.consentService.PolicyVersion(),
+		"status":         latest.Status(h.consentService.PolicyVersion()),
+		"history":        history,
+	})
+}
+
+// GiveConsent records consent to the current biometric policy version
+// Dari karyawan sendiri method = self_service, HR/admin mencatat consent kertas atau formulir
+// POST /api/employees/:id/consent
+// JSON body: method (paper / form, HR/admin saja, default paper)
+func (h *ConsentHandler) GiveConsent(c *fiber.Ctx) error {
+	user, self, errResponse := h.consentTarget(c)
+	if user == nil {
+		return errResponse
+	}
+	if user.DeactivatedAt != nil {
+		return utils.BadRequestResponse(c, "Employee is deactivated")
+	}
+
+	method := models.ConsentMethodSelfService
+	var recordedByID *uint
+	if !self {
+		var req consentRequest
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(&req); err != nil {
+				return utils.BadRequestResponse(c, "Invalid request body")
+			}
+		}
+		method = strings.TrimSpace(req.Method)
+		if method == "" {
+			method = models.ConsentMethodPaper
+		}
+		if method != models.ConsentMethodPaper && method != models.ConsentMethodForm {
+			return utils.BadRequestResponse(c, "method must be paper or form")
+		}
+		actorID := middleware.CurrentUserID(c)
+		recordedByID = &actorID
+	}
+
+	consent, err := h.consentService.Give(user.ID, method, recordedByID)
+	if err != nil {
+		return consentError(c, err)
+	}
+
+	slog.InfoContext(c.UserContext(), "Biometric consent recorded", "user_id", user.ID, "policy_version", consent.PolicyVersion, "method", consent.Method)
+	middleware.AuditEntity(c, "biometric_consent", consent.ID, nil, consent)
+
+	return utils.CreatedResponse(c, "Consent recorded successfully", consent)
+}
+
+// WithdrawConsent withdraws biometric consent and deletes the reference photo and face descriptor
+// Setelah withdraw karyawan hanya bisa check-in lewat fallback sampai consent dan foto diberikan lagi
+// POST /api/employees/:id/consent/withdraw
+// JSON body: reason (optional)
+func (h *ConsentHandler) WithdrawConsent(c *fiber.Ctx) error {
+	user, _, errResponse := h.consentTarget(c)
+	if user == nil {
+		return errResponse
+	}
+
+	var req withdrawConsentRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return utils.BadRequestResponse(c, "Invalid request body")
+		}
+	}
+
+	actorID := middleware.CurrentUserID(c)
+	consent, err := h.consentService.Withdraw(c.UserContext(), user.ID, strings.TrimSpace(req.Reason), &actorID)
+	if err != nil {
+		return consentError(c, err)
+	}
+
+	slog.InfoContext(c.UserContext(), "Biometric consent withdrawn", "user_id", user.ID)
+	middleware.AuditEntity(c, "biometric_consent", consent.ID, nil, consent)
+
+	return utils.SuccessResponse(c, "Consent withdrawn successfully", consent)
+}
+
+// GetConsentCoverage reports consent status of active employees
+// GET /api/consent/coverage
+// Query params (optional): department_id, team_id, manager_id
+func (h *ConsentHandler) GetConsentCoverage(c *fiber.Ctx) error {
+	var scopes []func(*gorm.DB) *gorm.DB
+	if filter := parseOrgFilter(c); !filter.IsEmpty() {
+		scope, err := h.orgService.UsersScope(filter)
+		if err != nil {
+			slog.ErrorContext(c.UserContext(), "Error building org filter", logging.Err(err))
+			return utils.InternalServerErrorResponse(c, "Failed to fetch consent coverage")
+		}
+		scopes = append(scopes, scope)
+	}
+
+	coverage, err := h.consentService.Coverage(scopes...)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "Error fetching consent coverage", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to fetch consent coverage")
+	}
+
+	return utils.SuccessResponse(c, "Consent coverage fetched successfully", coverage)
+}
@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"attendance-system/internal/logging"
+	"attendance-system/internal/middleware"
+	"attendance-system/internal/models"
+	"attendance-system/internal/services"
+	"attendance-system/internal/utils"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// CorrectionHandler handles manual attendance corrections
+type CorrectionHandler struct {
+	db                *gorm.DB
+	correctionService *services.CorrectionService
+	orgService        *services.OrgService
+	tzService         *services.TimezoneService
+}
+
+// NewCorrectionHandler creates a new CorrectionHandler
+func NewCorrectionHandler(db *gorm.DB, correctionService *services.CorrectionService,
+	orgService *services.OrgService, tzService *services.TimezoneService) *CorrectionHandler {
+	return &CorrectionHandler{
+		db:                db,
+		correctionService: correctionService,
+		orgService:        orgService,
+		tzService:         tzService,
+	}
+}
+
+// correctionRequest is the body for proposing a correction
+type correctionRequest struct {
+	UserID       uint   `json:"user_id"`       // Default diri sendiri
+	AttendanceID *uint  `json:"attendance_id"` // Kosong = check-in yang hilang
+	CheckInTime  string `json:"check_in_time"` // RFC3339, atau "YYYY-MM-DD HH:MM" waktu lokal karyawan
+	Status       string `json:"status"`        // success (default) / failed
+	Reason       string `json:"reason"`
+}
+
+// correctionSortFields are the allowed sort keys for GetCorrections
+var correctionSortFields = map[string]utils.SortField{
+	"created_at":    {Column: "attendance_corrections.created_at", Kind: utils.SortKindTime},
+	"check_in_time": {Column: "attendance_corrections.check_in_time", Kind: utils.SortKindTime},
+}
+
+// SubmitCorrection proposes a corrected check-in
+// POST /api/attendance/corrections
+// JSON body: user_id (optional), attendance_id (optional), check_in_time, status, reason
+func (h *CorrectionHandler) SubmitCorrection(c *fiber.Ctx) error {
+	var req correctionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+	if req.UserID == 0 {
+		req.UserID = middleware.CurrentUserID(c)
+	}
+	if req.CheckInTime == "" {
+		return utils.BadRequestResponse(c, "check_in_time is required")
+	}
+
+	checkIn, err := time.Parse(time.RFC3339, req.CheckInTime)
+	if err != nil {
+		loc := h.tzService.LocationForUser(req.UserID, time.Now())
+		checkIn, err = time.ParseInLocation("2006-01-02 15:04", req.CheckInTime, loc)
+		if err != nil {
+			return utils.BadRequestResponse(c, "check_in_time must be RFC3339 or YYYY-MM-DD HH:MM")
+		}
+	}
+
+	correction, err := h.correctionService.Submit(middleware.CurrentUserID(c), middleware.CurrentRole(c), services.CorrectionSubmission{
+		UserID:       req.UserID,
+		AttendanceID: req.AttendanceID,
+		CheckInTime:  checkIn,
+		Status:       req.Status,
+		Reason:       req.Reason,
+	})
+	if err != nil {
+		return correctionError(c, err, "Failed to submit correction")
+	}
+
+	slog.InfoContext(c.UserContext(), "Attendance correction submitted", "correction_id", correction.ID, "user_id", correction.UserID)
+	middleware.AuditEntity(c, "attendance_correction", correction.ID, nil, correction)
+
+	return utils.CreatedResponse(c, "Correction submitted successfully", correction.ToResponse())
+}
+
+// GetCorrections returns corrections visible to the caller
+// GET /api/attendance/corrections
+// Query params (optional): status, user_id, attendance_id, sort (created_at, check_in_time), limit, cursor
+func (h *CorrectionHandler) GetCorrections(c *fiber.Ctx) error {
+	page, err := utils.ParsePageParams(c, correctionSortFields, "attendance_corrections.id", "-created_at")
+	if err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+
+	query, err := visibleUsersOnly(c, h.db.Model(&models.AttendanceCorrection{}), h.orgService, "attendance_corrections.user_id")
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "Error building manager scope", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to fetch corrections")
+	}
+
+	if userID := queryUint(c, "user_id"); userID != 0 {
+		query = query.Where("attendance_corrections.user_id = ?", userID)
+	}
+	if attendanceID := queryUint(c, "attendance_id"); attendanceID != 0 {
+		query = query.Where("attendance_corrections.attendance_id = ?", attendanceID)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("attendance_corrections.status = ?", status)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		slog.ErrorContext(c.UserContext(), "Error counting corrections", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to fetch corrections")
+	}
+
+	query, err = page.Apply(query.Preload("User").Preload("RequestedBy").Preload("Approver"))
+	if err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+
+	var corrections []models.AttendanceCorrection
+	if err := query.Find(&corrections).Error; err != nil {
+		slog.ErrorContext(c.UserContext(), "Error fetching corrections", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to fetch corrections")
+	}
+
+	meta := utils.PageMeta{Total: total, Limit: page.Limit, Sort: page.Sort()}
+	if len(corrections) > page.Limit {
+		corrections = corrections[:page.Limit]
+		last := corrections[len(corrections)-1]
+		value := last.CreatedAt
+		if page.SortKey == "check_in_time" {
+			value = last.CheckInTime
+		}
+		meta.NextCursor = page.NextCursor(value, last.ID)
+	}
+
+	responses := make([]models.CorrectionResponse, len(corrections))
+	for i := range corrections {
+		responses[i] = corrections[i].ToResponse()
+	}
+
+	return utils.PaginatedResponse(c, "Corrections fetched successfully", responses, meta)
+}
+
+// GetCorrection returns a correction with its history events
+// GET /api/attendance/corrections/:id
+func (h *CorrectionHandler) GetCorrection(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return utils.BadRequestResponse(c, "Invalid correction ID")
+	}
+
+	correction, err := h.correctionService.Get(uint(id))
+	if err != nil || !h.canView(c, correction.UserID) {
+		return utils.NotFoundResponse(c, "Correction not found")
+	}
+
+	return utils.SuccessResponse(c, "Correction fetched successfully", correction.ToResponse())
+}
+
+// ApproveCorrection approves a pending correction
+// POST /api/attendance/corrections/:id/approve
+// JSON body: note (optional)
+func (h *CorrectionHandler) ApproveCorrection(c *fiber.Ctx) error {
+	return h.decide(c, true)
+}
+
+// RejectCorrection rejects a pending correction
+// POST /api/attendance/corrections/:id/reject
+// JSON body: note (optional)
+func (h *CorrectionHandler) RejectCorrection(c *fiber.Ctx) error {
+	return h.decide(c, false)
+}
+
+// CancelCorrection withdraws a pending correction
+// POST /api/attendance/corrections/:id/cancel
+func (h *CorrectionHandler) CancelCorrection(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return utils.BadRequestResponse(c, "Invalid correction ID")
+	}
+
+	correction, err := h.correctionService.Cancel(uint(id), middleware.CurrentUserID(c))
+	if err != nil {
+		return correctionError(c, err, "Failed to cancel correction")
+	}
+
+	middleware.AuditEntity(c, "attendance_correction", correction.ID,
+		fiber.Map{"status": models.CorrectionStatusPending}, fiber.Map{"status": correction.Status})
+
+	return utils.SuccessResponse(c, "Correction cancelled successfully", correction.ToResponse())
+}
+
+// GetAttendanceHistory returns the original record, effective value and all corrections
+// GET /api/attendance/:id/history
+func (h *CorrectionHandler) GetAttendanceHistory(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return utils.BadRequestResponse(c, "Invalid attendance ID")
+	}
+
+	history, err := h.correctionService.History(uint(id))
+	if err != nil || !h.canView(c, history.Attendance.UserID) {
+		return utils.NotFoundResponse(c, "Attendance not found")
+	}
+
+	return utils.SuccessResponse(c, "Attendance history fetched successfully", history)
+}
+
+// decide handles approve and reject
+func (h *CorrectionHandler) decide(c *fiber.Ctx, approve bool) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return utils.BadRequestResponse(c, "Invalid correction ID")
+	}
+
+	var req decisionRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return utils.BadRequestResponse(c, "Invalid request body")
+		}
+	}
+
+	correction, err := h.correctionService.Decide(uint(id), middleware.CurrentUserID(c), middleware.CurrentRole(c), approve, req.Note)
+	if err != nil {
+		return correctionError(c, err, "Failed to update correction")
+	}
+
+	slog.InfoContext(c.UserContext(), "Attendance correction reviewed", "correction_id", correction.ID, "status", correction.Status, "reviewer_id", middleware.CurrentUserID(c))
+	middleware.AuditEntity(c, "attendance_correction", correction.ID,
+		fiber.Map{"status": models.CorrectionStatusPending},
+		fiber.Map{"status": correction.Status, "decision_note": correction.DecisionNote})
+
+	return utils.SuccessResponse(c, "Correction "+correction.Status+" successfully", correction.ToResponse())
+}
+
+// canView checks if the caller may see corrections of the employee
+func (h *CorrectionHandler) canView(c *fiber.Ctx, userID uint) bool {
+	allowed, err := h.correctionService.CanView(middleware.CurrentUserID(c), middleware.CurrentRole(c), userID)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "Error checking correction permission", logging.Err(err))
+		return false
+	}
+	return allowed
+}
+
+// correctionError maps correction service errors to HTTP responses
+func correctionError(c *fiber.Ctx, err error, fallback string) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return utils.NotFoundResponse(c, "Correction not found")
+	case errors.Is(err, services.ErrInvalidCorrection):
+		return utils.BadRequestResponse(c, err.Error())
+	case errors.Is(err, services.ErrCorrectionForbidden):
+		return utils.ForbiddenResponse(c, err.Error())
+	case errors.Is(err, services.ErrCorrectionNotPending), errors.Is(err, services.ErrCorrectionDuplicate):
+		return utils.ConflictResponse(c, err.Error())
+	}
+	slog.ErrorContext(c.UserContext(), "Error processing correction", logging.Err(err))
+	return utils.InternalServerErrorResponse(c, fallback)
+}
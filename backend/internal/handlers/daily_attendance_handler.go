@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"attendance-system/internal/logging"
+	"attendance-system/internal/middleware"
+	"attendance-system/internal/models"
+	"attendance-system/internal/services"
+	"attendance-system/internal/utils"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// DailyAttendanceHandler handles daily attendance summary requests
+type DailyAttendanceHandler struct {
+	db             *gorm.DB
+	summaryService *services.DailySummaryService
+	orgService     *services.OrgService
+}
+
+// NewDailyAttendanceHandler creates a new DailyAttendanceHandler
+func NewDailyAttendanceHandler(db *gorm.DB, summaryService *services.DailySummaryService, orgService *services.OrgService) *DailyAttendanceHandler {
+	return &DailyAttendanceHandler{db: db, summaryService: summaryService, orgService: orgService}
+}
+
+// recomputeRequest is the body for recomputing daily summaries
+type recomputeRequest struct {
+	From    string `json:"from"` // YYYY-MM-DD
+	To      string `json:"to"`   // YYYY-MM-DD
+	UserIDs []uint `json:"user_ids"`
+}
+
+// dailySortFields are the allowed sort keys for GetDailyAttendances
+var dailySortFields = map[string]utils.SortField{
+	"date":         {Column: "daily_attendances.date", Kind: utils.SortKindString},
+	"late_minutes": {Column: "daily_attendances.late_minutes", Kind: utils.SortKindNumber},
+}
+
+// GetDailyAttendances returns materialized daily attendance rows visible to the caller
+// GET /api/attendance/daily
+// Query params (optional): from, to (YYYY-MM-DD lokal), user_id, status, site_id,
+// department_id (termasuk sub-department), team_id, sort (date, late_minutes), limit, cursor
+func (h *DailyAttendanceHandler) GetDailyAttendances(c *fiber.Ctx) error {
+	page, err := utils.ParsePageParams(c, dailySortFields, "daily_attendances.id", "-date")
+	if err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+
+	query, err := visibleUsersOnly(c, h.db.Model(&models.DailyAttendance{}), h.orgService, "daily_attendances.user_id")
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "Error building manager scope", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to fetch daily attendance")
+	}
+
+	// Date range dibandingkan sebagai string YYYY-MM-DD (kalender lokal karyawan)
+	if from := c.Query("from"); from != "" {
+		if _, err := time.Parse(utils.DateLayout, from); err != nil {
+			return utils.BadRequestResponse(c, "from must be YYYY-MM-DD")
+		}
+		query = query.Where("daily_attendances.date >= ?", from)
+	}
+	if to := c.Query("to"); to != "" {
+		if _, err := time.Parse(utils.DateLayout, to); err != nil {
+			return utils.BadRequestResponse(c, "to must be YYYY-MM-DD")
+		}
+		query = query.Where("daily_attendances.date <= ?", to)
+	}
+
+	if userID := queryUint(c, "user_id"); userID != 0 {
+		query = query.Where("daily_attendances.user_id = ?", userID)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("daily_attendances.status = ?", status)
+	}
+	if siteID := queryUint(c, "site_id"); siteID != 0 {
+		query = query.Where("daily_attendances.site_id = ?", siteID)
+	}
+	if teamID := queryUint(c, "team_id"); teamID != 0 {
+		query = query.Where("daily_attendances.team_id = ?", teamID)
+	}
+
+	// Department snapshot di-roll up ke semua sub-department
+	if departmentID := queryUint(c, "department_id"); departmentID != 0 {
+		ids, err := h.orgService.DescendantDepartmentIDs(departmentID)
+		if err != nil {
+			slog.ErrorContext(c.UserContext(), "Error loading departments", logging.Err(err))
+			return utils.InternalServerErrorResponse(c, "Failed to fetch daily attendance")
+		}
+		query = query.Where("daily_attendances.department_id IN ?", ids)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		slog.ErrorContext(c.UserContext(), "Error counting daily attendance", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to fetch daily attendance")
+	}
+
+	query, err = page.Apply(query.Preload("User"))
+	if err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+
+	var rows []models.DailyAttendance
+	if err := query.Find(&rows).Error; err != nil {
+		slog.ErrorContext(c.UserContext(), "Error fetching daily attendance", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to fetch daily attendance")
+	}
+
+	meta := utils.PageMeta{Total: total, Limit: page.Limit, Sort: page.Sort()}
+	if len(rows) > page.Limit {
+		rows = rows[:page.Limit]
+		last := rows[len(rows)-1]
+		var value interface{} = last.Date
+		if page.SortKey == "late_minutes" {
+			value = last.LateMinutes
+		}
+		meta.NextCursor = page.NextCursor(value, last.ID)
+	}
+
+	responses := make([]models.DailyAttendanceResponse, len(rows))
+	for i, row := range rows {
+		responses[i] = row.ToResponse()
+	}
+
+	return utils.PaginatedResponse(c, "Daily attendance fetched successfully", responses, meta)
+}
+
+// RecomputeDailyAttendances rebuilds daily rows after corrections
+// POST /api/attendance/daily/recompute
+// JSON body: from, to (YYYY-MM-DD), user_ids (optional)
+func (h *DailyAttendanceHandler) RecomputeDailyAttendances(c *fiber.Ctx) error {
+	var req recomputeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+	if req.From == "" || req.To == "" {
+		return utils.BadRequestResponse(c, "from and to are required")
+	}
+
+	count, err := h.summaryService.Recompute(req.From, req.To, req.UserIDs)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidDateRange) {
+			return utils.BadRequestResponse(c, err.Error())
+		}
+		slog.ErrorContext(c.UserContext(), "Error recomputing daily attendance", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to recompute daily attendance")
+	}
+
+	slog.InfoContext(c.UserContext(), "Daily attendance recomputed", "from", req.From, "to", req.To, "rows", count)
+	middleware.AuditEntity(c, "daily_attendance", req.From+".."+req.To, nil, fiber.Map{"user_ids": req.UserIDs, "rows": count})
+
+	return utils.SuccessResponse(c, "Daily attendance recomputed successfully", fiber.Map{
+		"from": req.From,
+		"to":   req.To,
+		"rows": count,
+	})
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"attendance-system/internal/middleware"
+	"attendance-system/internal/models"
+	"attendance-system/internal/repository"
+	"attendance-system/internal/services"
+	"attendance-system/internal/utils"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// testResponse mirrors utils.APIResponse with raw data
+type testResponse struct {
+	Status  string          `json:"status"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+	Meta    *utils.PageMeta `json:"meta"`
+}
+
+// testCaller sets the caller like middleware.RequireAuth, userID 0 = tanpa login
+func testCaller(userID uint, role string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if userID != 0 {
+			c.Locals(middleware.LocalUserID, userID)
+			c.Locals(middleware.LocalRole, role)
+		}
+		return c.Next()
+	}
+}
+
+// newTestApp mounts one handler behind testCaller
+func newTestApp(method, path string, userID uint, role string, handler fiber.Handler) *fiber.App {
+	app := fiber.New()
+	app.Add(method, path, testCaller(userID, role), handler)
+	return app
+}
+
+// doRequest sends a request to app and decodes the API envelope
+// body string dikirim apa adanya dengan contentType, kosong = tanpa body
+func doRequest(t *testing.T, app *fiber.App, method, target, contentType, body string) (int, testResponse) {
+	t.Helper()
+
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, target, reader)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, target, err)
+	}
+	defer resp.Body.Close()
+
+	var decoded testResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		t.Fatalf("%s %s: decode response: %v", method, target, err)
+	}
+	return resp.StatusCode, decoded
+}
+
+// getJSON sends a GET request and decodes the envelope
+func getJSON(t *testing.T, app *fiber.App, target string) (int, testResponse) {
+	t.Helper()
+	return doRequest(t, app, http.MethodGet, target, "", "")
+}
+
+// postForm sends a form-encoded POST request
+func postForm(t *testing.T, app *fiber.App, target string, fields url.Values) (int, testResponse) {
+	t.Helper()
+	return doRequest(t, app, http.MethodPost, target, fiber.MIMEApplicationForm, fields.Encode())
+}
+
+// decodeData unmarshals the data field of a response
+func decodeData(t *testing.T, resp testResponse, out interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(resp.Data, out); err != nil {
+		t.Fatalf("decode data %s: %v", resp.Data, err)
+	}
+}
+
+// testEmployees seeds a MemoryUserRepository, ID 1 = HR, 2-4 karyawan
+func testEmployees() *repository.MemoryUserRepository {
+	return repository.NewMemoryUserRepository(
+		models.User{ID: 1, Name: "Hana HR", Email: "hana@example.com", Role: models.RoleHR},
+		models.User{ID: 2, Name: "Budi", Email: "budi@example.com", Role: models.RoleEmployee},
+		models.User{ID: 3, Name: "Citra", Email: "citra@example.com", Role: models.RoleEmployee},
+		models.User{ID: 4, Name: "Dewi", Email: "dewi@example.com", Role: models.RoleEmployee},
+	)
+}
+
+// testImageService signs URLs without database or storage, cukup untuk response handler
+func testImageService() *services.ImageService {
+	return services.NewImageService(nil, nil, nil, nil, []byte("test-secret"), time.Minute)
+}
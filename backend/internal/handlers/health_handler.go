@@ -1,26 +1,58 @@
 package handlers
 
 import (
-	"attendance-system/internal/config"
+	"attendance-system/internal/health"
+	"log/slog"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
-// HealthHandler handles health check
-type HealthHandler struct{}
+// HealthHandler handles health check and the liveness / readiness probes
+type HealthHandler struct {
+	db      *gorm.DB
+	checker *health.Checker
+	started time.Time
+}
 
 // NewHealthHandler creates a new HealthHandler
-func NewHealthHandler() *HealthHandler {
-	return &HealthHandler{}
+func NewHealthHandler(db *gorm.DB, checker *health.Checker) *HealthHandler {
+	return &HealthHandler{db: db, checker: checker, started: time.Now()}
+}
+
+// Liveness reports that the process is up, tanpa cek dependency
+// Database yang down tidak boleh membuat orchestrator me-restart semua instance
+// GET /livez
+func (h *HealthHandler) Liveness(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"status":         health.StatusOK,
+		"uptime_seconds": int64(time.Since(h.started).Seconds()),
+		"timestamp":      time.Now(),
+	})
+}
+
+// Readiness checks every dependency, 503 kalau ada yang gagal supaya instance dikeluarkan dari load balancer
+// GET /readyz
+func (h *HealthHandler) Readiness(c *fiber.Ctx) error {
+	report := h.checker.Run(c.UserContext())
+	if !report.OK() {
+		for _, result := range report.Checks {
+			if result.Status != health.StatusOK {
+				slog.WarnContext(c.UserContext(), "Readiness check failed", "check", result.Name, "error", result.Error)
+			}
+		}
+		return c.Status(fiber.StatusServiceUnavailable).JSON(report)
+	}
+	return c.JSON(report)
 }
 
-// HealthCheck returns server health status
+// HealthCheck returns server health status, hanya ping database (dipakai frontend)
+// Probe orchestrator sebaiknya memakai /livez dan /readyz
 // GET /api/health
 func (h *HealthHandler) HealthCheck(c *fiber.Ctx) error {
 	// Check database connection
-	db := config.GetDB()
-	sqlDB, err := db.DB()
+	sqlDB, err := h.db.DB()
 	if err != nil {
 		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
 			"status":    "error",
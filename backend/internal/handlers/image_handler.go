@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"attendance-system/internal/logging"
+	"attendance-system/internal/middleware"
+	"attendance-system/internal/services"
+	"attendance-system/internal/utils"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ImageHandler serves employee photos, selfies and leave attachments
+type ImageHandler struct {
+	imageService *services.ImageService
+}
+
+// NewImageHandler creates a new ImageHandler
+func NewImageHandler(imageService *services.ImageService) *ImageHandler {
+	return &ImageHandler{imageService: imageService}
+}
+
+// GetImage returns a protected image
+// GET /api/images/:kind/:id (kind: employees, attendances, leave-attachments)
+// Query params: size (thumb / medium, optional), plus viewer, exp, sig dari signed URL
+// Tanpa signature wajib header Authorization. Yang boleh melihat: pemilik, manager-nya, HR dan admin
+func (h *ImageHandler) GetImage(c *fiber.Ctx) error {
+	kind := c.Params("kind")
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return utils.BadRequestResponse(c, "Invalid image ID")
+	}
+	size := c.Query("size")
+	if !services.IsValidImageSize(size) {
+		return utils.BadRequestResponse(c, "size must be thumb or medium")
+	}
+
+	viewerID := middleware.CurrentUserID(c)
+	if sig := c.Query("sig"); sig != "" {
+		viewerID, err = h.imageService.VerifyURL(kind, uint(id), size, c.Query("viewer"), c.Query("exp"), sig)
+		if err != nil {
+			return utils.ForbiddenResponse(c, err.Error())
+		}
+	}
+	if viewerID == 0 {
+		return utils.UnauthorizedResponse(c, "Authentication required")
+	}
+
+	content, err := h.imageService.Fetch(c.UserContext(), kind, uint(id), viewerID, size)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrImageNotFound):
+			return utils.NotFoundResponse(c, "Image not found")
+		case errors.Is(err, services.ErrImageForbidden):
+			return utils.ForbiddenResponse(c, err.Error())
+		}
+		slog.ErrorContext(c.UserContext(), "Error serving image", "kind", kind, "id", id, logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to load image")
+	}
+
+	// Viewer dari signed URL dicatat sebagai actor di audit log
+	c.Locals(middleware.LocalUserID, viewerID)
+	middleware.AuditEntity(c, kind, id, nil, nil)
+
+	if content.RedirectURL != "" {
+		return c.Redirect(content.RedirectURL, fiber.StatusFound)
+	}
+
+	c.Set(fiber.HeaderContentType, content.ContentType)
+	c.Set(fiber.HeaderCacheControl, fmt.Sprintf("private, max-age=%d", int(h.imageService.TTL().Seconds())))
+	return c.Send(content.Data)
+}
@@ -0,0 +1,470 @@
+package handlers
+
+import (
+	"attendance-system/internal/logging"
+	"attendance-system/internal/middleware"
+	"attendance-system/internal/models"
+	"attendance-system/internal/services"
+	"attendance-system/internal/storage"
+	"attendance-system/internal/utils"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// LeaveHandler handles leave types, requests and balances
+type LeaveHandler struct {
+	db           *gorm.DB
+	leaveService *services.LeaveService
+	orgService   *services.OrgService
+	tzService    *services.TimezoneService
+	imageService *services.ImageService
+	storage      storage.Storage // Lampiran cuti
+}
+
+// NewLeaveHandler creates a new LeaveHandler
+func NewLeaveHandler(db *gorm.DB, leaveService *services.LeaveService, orgService *services.OrgService,
+	tzService *services.TimezoneService, imageService *services.ImageService, store storage.Storage) *LeaveHandler {
+	return &LeaveHandler{
+		db:           db,
+		leaveService: leaveService,
+		orgService:   orgService,
+		tzService:    tzService,
+		imageService: imageService,
+		storage:      store,
+	}
+}
+
+// leaveResponse converts a leave request and signs its attachment URL for the current viewer
+func (h *LeaveHandler) leaveResponse(c *fiber.Ctx, request *models.LeaveRequest) models.LeaveRequestResponse {
+	response := request.ToResponse()
+	h.imageService.SignLeave(&response, middleware.CurrentUserID(c))
+	return response
+}
+
+// leaveTypeRequest is the body for creating/updating a leave type
+type leaveTypeRequest struct {
+	Code               string   `json:"code"`
+	Name               string   `json:"name"`
+	AnnualAllowance    *float64 `json:"annual_allowance"`
+	MaxCarryOver       *float64 `json:"max_carry_over"`
+	RequiresAttachment *bool    `json:"requires_attachment"`
+}
+
+// decisionRequest is the body for approve/reject (leave, correction)
+type decisionRequest struct {
+	Note string `json:"note"`
+}
+
+// leaveSortFields are the allowed sort keys for GetLeaveRequests
+var leaveSortFields = map[string]utils.SortField{
+	"start_date": {Column: "leave_requests.start_date", Kind: utils.SortKindString},
+	"created_at": {Column: "leave_requests.created_at", Kind: utils.SortKindTime},
+}
+
+// CreateLeaveType creates a new leave type
+// POST /api/leave/types
+// JSON body: code, name, annual_allowance, max_carry_over, requires_attachment
+func (h *LeaveHandler) CreateLeaveType(c *fiber.Ctx) error {
+	var req leaveTypeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+
+	leaveType := models.LeaveType{
+		Code: strings.TrimSpace(req.Code),
+		Name: strings.TrimSpace(req.Name),
+	}
+	if leaveType.Code == "" || leaveType.Name == "" {
+		return utils.BadRequestResponse(c, "Code and name are required")
+	}
+	applyLeaveType(&leaveType, &req)
+	if leaveType.AnnualAllowance < 0 || leaveType.MaxCarryOver < 0 {
+		return utils.BadRequestResponse(c, "Allowance and carry over must not be negative")
+	}
+
+	if err := h.db.Create(&leaveType).Error; err != nil {
+		slog.ErrorContext(c.UserContext(), "Error creating leave type", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to create leave type")
+	}
+
+	middleware.AuditEntity(c, "leave_type", leaveType.ID, nil, leaveType)
+
+	return utils.CreatedResponse(c, "Leave type created successfully", leaveType)
+}
+
+// GetLeaveTypes returns all leave types
+// GET /api/leave/types
+func (h *LeaveHandler) GetLeaveTypes(c *fiber.Ctx) error {
+	var types []models.LeaveType
+	if err := h.db.Order("name ASC").Find(&types).Error; err != nil {
+		slog.ErrorContext(c.UserContext(), "Error fetching leave types", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to fetch leave types")
+	}
+
+	return utils.SuccessResponse(c, "Leave types fetched successfully", types)
+}
+
+// UpdateLeaveType updates a leave type, balance yang sudah di-accrue tidak berubah
+// PUT /api/leave/types/:id
+func (h *LeaveHandler) UpdateLeaveType(c *fiber.Ctx) error {
+	var leaveType models.LeaveType
+	if err := h.db.First(&leaveType, c.Params("id")).Error; err != nil {
+		return utils.NotFoundResponse(c, "Leave type not found")
+	}
+	before := leaveType
+
+	var req leaveTypeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+	if code := strings.TrimSpace(req.Code); code != "" {
+		leaveType.Code = code
+	}
+	if name := strings.TrimSpace(req.Name); name != "" {
+		leaveType.Name = name
+	}
+	applyLeaveType(&leaveType, &req)
+	if leaveType.AnnualAllowance < 0 || leaveType.MaxCarryOver < 0 {
+		return utils.BadRequestResponse(c, "Allowance and carry over must not be negative")
+	}
+
+	if err := h.db.Save(&leaveType).Error; err != nil {
+		slog.ErrorContext(c.UserContext(), "Error updating leave type", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to update leave type")
+	}
+
+	middleware.AuditEntity(c, "leave_type", leaveType.ID, before, leaveType)
+
+	return utils.SuccessResponse(c, "Leave type updated successfully", leaveType)
+}
+
+// SubmitLeaveRequest submits a leave request
+// POST /api/leave/requests
+// Form data: leave_type_id, start_date, end_date (YYYY-MM-DD), half_day (am/pm, optional),
+// reason, attachment (file, optional), user_id (hanya HR/admin, untuk karyawan lain)
+func (h *LeaveHandler) SubmitLeaveRequest(c *fiber.Ctx) error {
+	actorID := middleware.CurrentUserID(c)
+	role := middleware.CurrentRole(c)
+
+	userID := actorID
+	if requested := c.FormValue("user_id"); requested != "" && requested != "0" {
+		if role != models.RoleHR && role != models.RoleAdmin {
+			return utils.ForbiddenResponse(c, "Only HR can submit leave for other employees")
+		}
+		var user models.User
+		if err := h.db.Select("id").First(&user, requested).Error; err != nil {
+			return utils.BadRequestResponse(c, "Employee not found")
+		}
+		userID = user.ID
+	}
+
+	leaveTypeID, err := strconv.ParseUint(c.FormValue("leave_type_id"), 10, 64)
+	if err != nil || leaveTypeID == 0 {
+		return utils.BadRequestResponse(c, "Valid leave_type_id is required")
+	}
+
+	submission := services.LeaveSubmission{
+		UserID:      userID,
+		LeaveTypeID: uint(leaveTypeID),
+		StartDate:   c.FormValue("start_date"),
+		EndDate:     c.FormValue("end_date"),
+		HalfDay:     c.FormValue("half_day"),
+		Reason:      c.FormValue("reason"),
+	}
+	if submission.StartDate == "" {
+		return utils.BadRequestResponse(c, "start_date is required")
+	}
+
+	// Attachment optional, disimpan terpisah dari foto wajah
+	if attachment, err := c.FormFile("attachment"); err == nil {
+		path, err := utils.SaveAttachment(c.UserContext(), h.storage, attachment, "leave")
+		if err != nil {
+			return utils.BadRequestResponse(c, err.Error())
+		}
+		submission.AttachmentPath = path
+	}
+
+	request, err := h.leaveService.Submit(submission)
+	if err != nil {
+		if submission.AttachmentPath != "" {
+			utils.DeleteFile(c.UserContext(), h.storage, submission.AttachmentPath)
+		}
+		return leaveError(c, err, "Failed to submit leave request")
+	}
+
+	slog.InfoContext(c.UserContext(), "Leave request submitted",
+		"leave_request_id", request.ID, "user_id", request.UserID, "start_date", request.StartDate, "end_date", request.EndDate)
+
+	request, err = h.leaveService.Get(request.ID)
+	if err != nil {
+		return utils.InternalServerErrorResponse(c, "Failed to load leave request")
+	}
+
+	middleware.AuditEntity(c, "leave_request", request.ID, nil, request)
+
+	return utils.CreatedResponse(c, "Leave request submitted successfully", h.leaveResponse(c, request))
+}
+
+// GetLeaveRequests returns leave requests visible to the caller
+// GET /api/leave/requests
+// Karyawan: milik sendiri, manager: sendiri + bawahan, HR/admin: semua
+// Query params (optional): status, user_id, from, to (YYYY-MM-DD), sort (start_date, created_at), limit, cursor
+func (h *LeaveHandler) GetLeaveRequests(c *fiber.Ctx) error {
+	page, err := utils.ParsePageParams(c, leaveSortFields, "leave_requests.id", "-created_at")
+	if err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+
+	query, err := visibleUsersOnly(c, h.db.Model(&models.LeaveRequest{}), h.orgService, "leave_requests.user_id")
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "Error building manager scope", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to fetch leave requests")
+	}
+
+	if userID := queryUint(c, "user_id"); userID != 0 {
+		query = query.Where("leave_requests.user_id = ?", userID)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("leave_requests.status = ?", status)
+	}
+	if from := c.Query("from"); from != "" {
+		if _, err := time.Parse(utils.DateLayout, from); err != nil {
+			return utils.BadRequestResponse(c, "from must be YYYY-MM-DD")
+		}
+		query = query.Where("leave_requests.end_date >= ?", from)
+	}
+	if to := c.Query("to"); to != "" {
+		if _, err := time.Parse(utils.DateLayout, to); err != nil {
+			return utils.BadRequestResponse(c, "to must be YYYY-MM-DD")
+		}
+		query = query.Where("leave_requests.start_date <= ?", to)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		slog.ErrorContext(c.UserContext(), "Error counting leave requests", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to fetch leave requests")
+	}
+
+	query, err = page.Apply(query.Preload("User").Preload("LeaveType").Preload("Approver"))
+	if err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+
+	var requests []models.LeaveRequest
+	if err := query.Find(&requests).Error; err != nil {
+		slog.ErrorContext(c.UserContext(), "Error fetching leave requests", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to fetch leave requests")
+	}
+
+	meta := utils.PageMeta{Total: total, Limit: page.Limit, Sort: page.Sort()}
+	if len(requests) > page.Limit {
+		requests = requests[:page.Limit]
+		last := requests[len(requests)-1]
+		var value interface{} = last.CreatedAt
+		if page.SortKey == "start_date" {
+			value = last.StartDate
+		}
+		meta.NextCursor = page.NextCursor(value, last.ID)
+	}
+
+	responses := make([]models.LeaveRequestResponse, len(requests))
+	for i := range requests {
+		responses[i] = h.leaveResponse(c, &requests[i])
+	}
+
+	return utils.PaginatedResponse(c, "Leave requests fetched successfully", responses, meta)
+}
+
+// GetLeaveRequest returns a single leave request
+// GET /api/leave/requests/:id
+func (h *LeaveHandler) GetLeaveRequest(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return utils.BadRequestResponse(c, "Invalid leave request ID")
+	}
+
+	request, err := h.leaveService.Get(uint(id))
+	if err != nil {
+		return utils.NotFoundResponse(c, "Leave request not found")
+	}
+	if !h.canView(c, request.UserID) {
+		return utils.NotFoundResponse(c, "Leave request not found")
+	}
+
+	return utils.SuccessResponse(c, "Leave request fetched successfully", h.leaveResponse(c, request))
+}
+
+// ApproveLeaveRequest approves a pending request
+// POST /api/leave/requests/:id/approve
+// JSON body: note (optional)
+func (h *LeaveHandler) ApproveLeaveRequest(c *fiber.Ctx) error {
+	return h.decide(c, true)
+}
+
+// RejectLeaveRequest rejects a pending request
+// POST /api/leave/requests/:id/reject
+// JSON body: note (optional)
+func (h *LeaveHandler) RejectLeaveRequest(c *fiber.Ctx) error {
+	return h.decide(c, false)
+}
+
+// CancelLeaveRequest withdraws a request
+// POST /api/leave/requests/:id/cancel
+func (h *LeaveHandler) CancelLeaveRequest(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return utils.BadRequestResponse(c, "Invalid leave request ID")
+	}
+
+	request, err := h.leaveService.Cancel(uint(id), middleware.CurrentUserID(c), middleware.CurrentRole(c))
+	if err != nil {
+		return leaveError(c, err, "Failed to cancel leave request")
+	}
+
+	slog.InfoContext(c.UserContext(), "Leave request cancelled", "leave_request_id", request.ID)
+	middleware.AuditEntity(c, "leave_request", request.ID, nil, fiber.Map{"status": request.Status})
+
+	return utils.SuccessResponse(c, "Leave request cancelled successfully", h.leaveResponse(c, request))
+}
+
+// GetLeaveBalances returns leave balances of an employee
+// GET /api/leave/balances
+// Query params (optional): user_id (default diri sendiri), year (default tahun ini)
+func (h *LeaveHandler) GetLeaveBalances(c *fiber.Ctx) error {
+	userID := queryUint(c, "user_id")
+	if userID == 0 {
+		userID = middleware.CurrentUserID(c)
+	}
+	if !h.canView(c, userID) {
+		return utils.ForbiddenResponse(c, "Not allowed to view this employee's balances")
+	}
+
+	year := c.QueryInt("year", time.Now().In(h.tzService.DefaultLocation()).Year())
+	if year < 2000 || year > 2100 {
+		return utils.BadRequestResponse(c, "Invalid year")
+	}
+
+	balances, err := h.leaveService.Balances(userID, year)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "Error fetching leave balances", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to fetch leave balances")
+	}
+
+	responses := make([]models.LeaveBalanceResponse, len(balances))
+	for i := range balances {
+		responses[i] = balances[i].ToResponse()
+	}
+
+	return utils.SuccessResponse(c, "Leave balances fetched successfully", responses)
+}
+
+// AccrueLeaveBalances creates yearly balances for all active employees
+// POST /api/leave/accrue
+// JSON body: year (default tahun ini)
+func (h *LeaveHandler) AccrueLeaveBalances(c *fiber.Ctx) error {
+	var req struct {
+		Year int `json:"year"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return utils.BadRequestResponse(c, "Invalid request body")
+		}
+	}
+	if req.Year == 0 {
+		req.Year = time.Now().In(h.tzService.DefaultLocation()).Year()
+	}
+	if req.Year < 2000 || req.Year > 2100 {
+		return utils.BadRequestResponse(c, "Invalid year")
+	}
+
+	count, err := h.leaveService.AccrueYear(req.Year)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "Error accruing leave balances", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to accrue leave balances")
+	}
+
+	slog.InfoContext(c.UserContext(), "Leave balances accrued", "year", req.Year, "balances", count)
+	middleware.AuditEntity(c, "leave_balance", req.Year, nil, fiber.Map{"year": req.Year, "balances": count})
+
+	return utils.SuccessResponse(c, "Leave balances accrued successfully", fiber.Map{
+		"year":     req.Year,
+		"balances": count,
+	})
+}
+
+// decide handles approve and reject
+func (h *LeaveHandler) decide(c *fiber.Ctx, approve bool) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return utils.BadRequestResponse(c, "Invalid leave request ID")
+	}
+
+	var req decisionRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return utils.BadRequestResponse(c, "Invalid request body")
+		}
+	}
+
+	request, err := h.leaveService.Decide(uint(id), middleware.CurrentUserID(c), middleware.CurrentRole(c), approve, req.Note)
+	if err != nil {
+		return leaveError(c, err, "Failed to update leave request")
+	}
+
+	slog.InfoContext(c.UserContext(), "Leave request reviewed", "leave_request_id", request.ID, "status", request.Status, "reviewer_id", middleware.CurrentUserID(c))
+	middleware.AuditEntity(c, "leave_request", request.ID,
+		fiber.Map{"status": models.LeaveStatusPending},
+		fiber.Map{"status": request.Status, "decision_note": request.DecisionNote})
+
+	return utils.SuccessResponse(c, "Leave request "+request.Status+" successfully", h.leaveResponse(c, request))
+}
+
+// canView checks if the caller may see leave data of the employee
+func (h *LeaveHandler) canView(c *fiber.Ctx, userID uint) bool {
+	actorID := middleware.CurrentUserID(c)
+	if actorID == userID {
+		return true
+	}
+	allowed, err := h.leaveService.CanManage(actorID, middleware.CurrentRole(c), userID)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "Error checking leave permission", logging.Err(err))
+		return false
+	}
+	return allowed
+}
+
+// applyLeaveType copies optional numeric/bool fields from the request
+func applyLeaveType(leaveType *models.LeaveType, req *leaveTypeRequest) {
+	if req.AnnualAllowance != nil {
+		leaveType.AnnualAllowance = *req.AnnualAllowance
+	}
+	if req.MaxCarryOver != nil {
+		leaveType.MaxCarryOver = *req.MaxCarryOver
+	}
+	if req.RequiresAttachment != nil {
+		leaveType.RequiresAttachment = *req.RequiresAttachment
+	}
+}
+
+// leaveError maps leave service errors to HTTP responses
+func leaveError(c *fiber.Ctx, err error, fallback string) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return utils.NotFoundResponse(c, "Leave request not found")
+	case errors.Is(err, services.ErrInvalidLeave), errors.Is(err, services.ErrInsufficientBalance):
+		return utils.BadRequestResponse(c, err.Error())
+	case errors.Is(err, services.ErrLeaveForbidden):
+		return utils.ForbiddenResponse(c, err.Error())
+	case errors.Is(err, services.ErrLeaveOverlap), errors.Is(err, services.ErrLeaveNotPending):
+		return utils.ConflictResponse(c, err.Error())
+	}
+	slog.ErrorContext(c.UserContext(), "Error processing leave request", logging.Err(err))
+	return utils.InternalServerErrorResponse(c, fallback)
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"attendance-system/internal/metrics"
+	"attendance-system/internal/utils"
+	"crypto/subtle"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+)
+
+// MetricsHandler serves Prometheus metrics
+type MetricsHandler struct {
+	handler fiber.Handler
+	token   string // Kosong = tanpa auth, endpoint sebaiknya hanya terbuka di jaringan internal
+}
+
+// NewMetricsHandler creates a new MetricsHandler
+func NewMetricsHandler(m *metrics.Metrics, token string) *MetricsHandler {
+	return &MetricsHandler{handler: adaptor.HTTPHandler(m.Handler()), token: token}
+}
+
+// Metrics returns the metrics in Prometheus text format
+// GET /metrics (Authorization: Bearer METRICS_TOKEN kalau di-set)
+func (h *MetricsHandler) Metrics(c *fiber.Ctx) error {
+	if h.token != "" && subtle.ConstantTimeCompare([]byte(c.Get(fiber.HeaderAuthorization)), []byte("Bearer "+h.token)) != 1 {
+		return utils.UnauthorizedResponse(c, "Invalid metrics token")
+	}
+	return h.handler(c)
+}
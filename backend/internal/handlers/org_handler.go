@@ -0,0 +1,552 @@
+package handlers
+
+import (
+	"attendance-system/internal/logging"
+	"attendance-system/internal/middleware"
+	"attendance-system/internal/models"
+	"attendance-system/internal/services"
+	"attendance-system/internal/utils"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// OrgHandler handles department, team and assignment requests
+type OrgHandler struct {
+	db         *gorm.DB
+	orgService *services.OrgService
+	tzService  *services.TimezoneService
+}
+
+// NewOrgHandler creates a new OrgHandler
+func NewOrgHandler(db *gorm.DB, orgService *services.OrgService, tzService *services.TimezoneService) *OrgHandler {
+	return &OrgHandler{db: db, orgService: orgService, tzService: tzService}
+}
+
+// departmentRequest is the body for creating/updating a department
+type departmentRequest struct {
+	Name      string `json:"name"`
+	Code      string `json:"code"`
+	ParentID  *uint  `json:"parent_id"`
+	ManagerID *uint  `json:"manager_id"`
+}
+
+// teamRequest is the body for creating/updating a team
+type teamRequest struct {
+	Name         string `json:"name"`
+	DepartmentID uint   `json:"department_id"`
+	ManagerID    *uint  `json:"manager_id"`
+}
+
+// siteRequest is the body for creating/updating a site
+type siteRequest struct {
+	Name           string `json:"name"`
+	Code           string `json:"code"`
+	Address        string `json:"address"`
+	Timezone       string `json:"timezone"` // IANA timezone, kosong = ORG_TIMEZONE
+	WorkScheduleID *uint  `json:"work_schedule_id"`
+}
+
+// scheduleRequest is the body for creating/updating a work schedule
+type scheduleRequest struct {
+	Name             string `json:"name"`
+	StartTime        string `json:"start_time"` // HH:MM
+	LateGraceMinutes *int   `json:"late_grace_minutes"`
+	WorkDays         string `json:"work_days"` // contoh: "1,2,3,4,5"
+}
+
+// assignmentRequest is the body for assigning an employee
+type assignmentRequest struct {
+	DepartmentID  uint   `json:"department_id"`
+	TeamID        *uint  `json:"team_id"`
+	SiteID        *uint  `json:"site_id"`
+	EffectiveFrom string `json:"effective_from"` // RFC3339 atau YYYY-MM-DD, default sekarang
+}
+
+// CreateDepartment creates a new department
+// POST /api/departments
+// JSON body: name, code, parent_id (optional), manager_id (optional)
+func (h *OrgHandler) CreateDepartment(c *fiber.Ctx) error {
+	var req departmentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	req.Code = strings.TrimSpace(req.Code)
+	if req.Name == "" || req.Code == "" {
+		return utils.BadRequestResponse(c, "Name and code are required")
+	}
+
+	if req.ParentID != nil && h.db.First(&models.Department{}, *req.ParentID).Error != nil {
+		return utils.BadRequestResponse(c, "Parent department not found")
+	}
+	if req.ManagerID != nil && h.db.First(&models.User{}, *req.ManagerID).Error != nil {
+		return utils.BadRequestResponse(c, "Manager not found")
+	}
+
+	department := models.Department{
+		Name:      req.Name,
+		Code:      req.Code,
+		ParentID:  req.ParentID,
+		ManagerID: req.ManagerID,
+	}
+	if err := h.db.Create(&department).Error; err != nil {
+		slog.ErrorContext(c.UserContext(), "Error creating department", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to create department")
+	}
+
+	middleware.AuditEntity(c, "department", department.ID, nil, department)
+
+	return utils.CreatedResponse(c, "Department created successfully", department)
+}
+
+// GetDepartments returns all departments
+// GET /api/departments
+// Query params: parent_id (optional)
+func (h *OrgHandler) GetDepartments(c *fiber.Ctx) error {
+	query := h.db.Preload("Manager", selectManagerName).Order("name ASC")
+
+	if parentID := c.Query("parent_id"); parentID != "" {
+		query = query.Where("parent_id = ?", parentID)
+	}
+
+	var departments []models.Department
+	if err := query.Find(&departments).Error; err != nil {
+		slog.ErrorContext(c.UserContext(), "Error fetching departments", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to fetch departments")
+	}
+
+	responses := make([]models.DepartmentResponse, len(departments))
+	for i := range departments {
+		responses[i] = departments[i].ToResponse()
+	}
+	return utils.SuccessResponse(c, "Departments fetched successfully", responses)
+}
+
+// GetDepartment returns single department with its teams
+// GET /api/departments/:id
+func (h *OrgHandler) GetDepartment(c *fiber.Ctx) error {
+	var department models.Department
+	err := h.db.Preload("Manager", selectManagerName).Preload("Teams").First(&department, c.Params("id")).Error
+	if err != nil {
+		return utils.NotFoundResponse(c, "Department not found")
+	}
+
+	return utils.SuccessResponse(c, "Department fetched successfully", department.ToResponse())
+}
+
+// UpdateDepartment updates department name, parent or manager
+// PUT /api/departments/:id
+func (h *OrgHandler) UpdateDepartment(c *fiber.Ctx) error {
+	var department models.Department
+	if err := h.db.First(&department, c.Params("id")).Error; err != nil {
+		return utils.NotFoundResponse(c, "Department not found")
+	}
+	before := department
+
+	var req departmentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+
+	if name := strings.TrimSpace(req.Name); name != "" {
+		department.Name = name
+	}
+	if code := strings.TrimSpace(req.Code); code != "" {
+		department.Code = code
+	}
+	if req.ParentID != nil {
+		if h.db.First(&models.Department{}, *req.ParentID).Error != nil {
+			return utils.BadRequestResponse(c, "Parent department not found")
+		}
+		if err := h.orgService.ValidateParent(department.ID, *req.ParentID); err != nil {
+			return h.assignmentError(c, err)
+		}
+		department.ParentID = req.ParentID
+	}
+	if req.ManagerID != nil {
+		if h.db.First(&models.User{}, *req.ManagerID).Error != nil {
+			return utils.BadRequestResponse(c, "Manager not found")
+		}
+		department.ManagerID = req.ManagerID
+	}
+
+	if err := h.db.Save(&department).Error; err != nil {
+		slog.ErrorContext(c.UserContext(), "Error updating department", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to update department")
+	}
+
+	middleware.AuditEntity(c, "department", department.ID, before, department)
+
+	return utils.SuccessResponse(c, "Department updated successfully", department)
+}
+
+// CreateTeam creates a new team inside a department
+// POST /api/teams
+// JSON body: name, department_id, manager_id (optional)
+func (h *OrgHandler) CreateTeam(c *fiber.Ctx) error {
+	var req teamRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || req.DepartmentID == 0 {
+		return utils.BadRequestResponse(c, "Name and department_id are required")
+	}
+
+	if h.db.First(&models.Department{}, req.DepartmentID).Error != nil {
+		return utils.BadRequestResponse(c, "Department not found")
+	}
+	if req.ManagerID != nil && h.db.First(&models.User{}, *req.ManagerID).Error != nil {
+		return utils.BadRequestResponse(c, "Manager not found")
+	}
+
+	team := models.Team{
+		Name:         req.Name,
+		DepartmentID: req.DepartmentID,
+		ManagerID:    req.ManagerID,
+	}
+	if err := h.db.Create(&team).Error; err != nil {
+		slog.ErrorContext(c.UserContext(), "Error creating team", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to create team")
+	}
+
+	middleware.AuditEntity(c, "team", team.ID, nil, team)
+
+	return utils.CreatedResponse(c, "Team created successfully", team)
+}
+
+// GetTeams returns teams
+// GET /api/teams
+// Query params: department_id (optional)
+func (h *OrgHandler) GetTeams(c *fiber.Ctx) error {
+	query := h.db.Preload("Manager", selectManagerName).Order("name ASC")
+
+	if departmentID := c.Query("department_id"); departmentID != "" {
+		query = query.Where("department_id = ?", departmentID)
+	}
+
+	var teams []models.Team
+	if err := query.Find(&teams).Error; err != nil {
+		slog.ErrorContext(c.UserContext(), "Error fetching teams", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to fetch teams")
+	}
+
+	responses := make([]models.TeamResponse, len(teams))
+	for i := range teams {
+		responses[i] = teams[i].ToResponse()
+	}
+	return utils.SuccessResponse(c, "Teams fetched successfully", responses)
+}
+
+// UpdateTeam updates team name or manager
+// PUT /api/teams/:id
+func (h *OrgHandler) UpdateTeam(c *fiber.Ctx) error {
+	var team models.Team
+	if err := h.db.First(&team, c.Params("id")).Error; err != nil {
+		return utils.NotFoundResponse(c, "Team not found")
+	}
+	before := team
+
+	var req teamRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+
+	if name := strings.TrimSpace(req.Name); name != "" {
+		team.Name = name
+	}
+	if req.ManagerID != nil {
+		if h.db.First(&models.User{}, *req.ManagerID).Error != nil {
+			return utils.BadRequestResponse(c, "Manager not found")
+		}
+		team.ManagerID = req.ManagerID
+	}
+
+	if err := h.db.Save(&team).Error; err != nil {
+		slog.ErrorContext(c.UserContext(), "Error updating team", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to update team")
+	}
+
+	middleware.AuditEntity(c, "team", team.ID, before, team)
+
+	return utils.SuccessResponse(c, "Team updated successfully", team)
+}
+
+// CreateSite creates a new site
+// POST /api/sites
+// JSON body: name, code, address, timezone, work_schedule_id (optional)
+func (h *OrgHandler) CreateSite(c *fiber.Ctx) error {
+	var req siteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	req.Code = strings.TrimSpace(req.Code)
+	if req.Name == "" || req.Code == "" {
+		return utils.BadRequestResponse(c, "Name and code are required")
+	}
+
+	req.Timezone = strings.TrimSpace(req.Timezone)
+	if _, err := h.tzService.LoadLocation(req.Timezone); err != nil {
+		return utils.BadRequestResponse(c, "Invalid timezone, use an IANA name such as Asia/Jakarta")
+	}
+
+	if req.WorkScheduleID != nil && h.db.First(&models.WorkSchedule{}, *req.WorkScheduleID).Error != nil {
+		return utils.BadRequestResponse(c, "Work schedule not found")
+	}
+
+	site := models.Site{
+		Name:           req.Name,
+		Code:           req.Code,
+		Address:        strings.TrimSpace(req.Address),
+		Timezone:       req.Timezone,
+		WorkScheduleID: req.WorkScheduleID,
+	}
+	if err := h.db.Create(&site).Error; err != nil {
+		slog.ErrorContext(c.UserContext(), "Error creating site", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to create site")
+	}
+
+	middleware.AuditEntity(c, "site", site.ID, nil, site)
+
+	return utils.CreatedResponse(c, "Site created successfully", site)
+}
+
+// GetSites returns all sites
+// GET /api/sites
+func (h *OrgHandler) GetSites(c *fiber.Ctx) error {
+	var sites []models.Site
+	if err := h.db.Preload("WorkSchedule").Order("name ASC").Find(&sites).Error; err != nil {
+		slog.ErrorContext(c.UserContext(), "Error fetching sites", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to fetch sites")
+	}
+
+	return utils.SuccessResponse(c, "Sites fetched successfully", sites)
+}
+
+// UpdateSite updates site name, code, address, timezone or work schedule
+// PUT /api/sites/:id
+func (h *OrgHandler) UpdateSite(c *fiber.Ctx) error {
+	var site models.Site
+	if err := h.db.First(&site, c.Params("id")).Error; err != nil {
+		return utils.NotFoundResponse(c, "Site not found")
+	}
+	before := site
+
+	var req siteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+
+	if name := strings.TrimSpace(req.Name); name != "" {
+		site.Name = name
+	}
+	if code := strings.TrimSpace(req.Code); code != "" {
+		site.Code = code
+	}
+	if address := strings.TrimSpace(req.Address); address != "" {
+		site.Address = address
+	}
+	if timezone := strings.TrimSpace(req.Timezone); timezone != "" {
+		if _, err := h.tzService.LoadLocation(timezone); err != nil {
+			return utils.BadRequestResponse(c, "Invalid timezone, use an IANA name such as Asia/Jakarta")
+		}
+		site.Timezone = timezone
+	}
+	if req.WorkScheduleID != nil {
+		if h.db.First(&models.WorkSchedule{}, *req.WorkScheduleID).Error != nil {
+			return utils.BadRequestResponse(c, "Work schedule not found")
+		}
+		site.WorkScheduleID = req.WorkScheduleID
+	}
+
+	if err := h.db.Save(&site).Error; err != nil {
+		slog.ErrorContext(c.UserContext(), "Error updating site", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to update site")
+	}
+
+	middleware.AuditEntity(c, "site", site.ID, before, site)
+
+	return utils.SuccessResponse(c, "Site updated successfully", site)
+}
+
+// CreateWorkSchedule creates a new work schedule
+// POST /api/schedules
+// JSON body: name, start_time (HH:MM), late_grace_minutes, work_days (ISO weekday, "1,2,3,4,5")
+func (h *OrgHandler) CreateWorkSchedule(c *fiber.Ctx) error {
+	var req scheduleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+
+	schedule := models.WorkSchedule{
+		Name:      strings.TrimSpace(req.Name),
+		StartTime: strings.TrimSpace(req.StartTime),
+		WorkDays:  strings.TrimSpace(req.WorkDays),
+	}
+	if req.LateGraceMinutes != nil {
+		schedule.LateGraceMinutes = *req.LateGraceMinutes
+	}
+	if schedule.Name == "" {
+		return utils.BadRequestResponse(c, "Name is required")
+	}
+	if err := schedule.Validate(); err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+
+	if err := h.db.Create(&schedule).Error; err != nil {
+		slog.ErrorContext(c.UserContext(), "Error creating work schedule", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to create work schedule")
+	}
+
+	middleware.AuditEntity(c, "work_schedule", schedule.ID, nil, schedule)
+
+	return utils.CreatedResponse(c, "Work schedule created successfully", schedule)
+}
+
+// GetWorkSchedules returns all work schedules
+// GET /api/schedules
+func (h *OrgHandler) GetWorkSchedules(c *fiber.Ctx) error {
+	var schedules []models.WorkSchedule
+	if err := h.db.Order("name ASC").Find(&schedules).Error; err != nil {
+		slog.ErrorContext(c.UserContext(), "Error fetching work schedules", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to fetch work schedules")
+	}
+
+	return utils.SuccessResponse(c, "Work schedules fetched successfully", schedules)
+}
+
+// UpdateWorkSchedule updates a work schedule
+// PUT /api/schedules/:id
+func (h *OrgHandler) UpdateWorkSchedule(c *fiber.Ctx) error {
+	var schedule models.WorkSchedule
+	if err := h.db.First(&schedule, c.Params("id")).Error; err != nil {
+		return utils.NotFoundResponse(c, "Work schedule not found")
+	}
+	before := schedule
+
+	var req scheduleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+
+	if name := strings.TrimSpace(req.Name); name != "" {
+		schedule.Name = name
+	}
+	if startTime := strings.TrimSpace(req.StartTime); startTime != "" {
+		schedule.StartTime = startTime
+	}
+	if workDays := strings.TrimSpace(req.WorkDays); workDays != "" {
+		schedule.WorkDays = workDays
+	}
+	if req.LateGraceMinutes != nil {
+		schedule.LateGraceMinutes = *req.LateGraceMinutes
+	}
+	if err := schedule.Validate(); err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+
+	if err := h.db.Save(&schedule).Error; err != nil {
+		slog.ErrorContext(c.UserContext(), "Error updating work schedule", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to update work schedule")
+	}
+
+	middleware.AuditEntity(c, "work_schedule", schedule.ID, before, schedule)
+
+	return utils.SuccessResponse(c, "Work schedule updated successfully", schedule)
+}
+
+// AssignEmployee moves an employee to a department/team from a given date
+// POST /api/employees/:id/assignments
+// JSON body: department_id, team_id (optional), site_id (optional), effective_from (optional)
+func (h *OrgHandler) AssignEmployee(c *fiber.Ctx) error {
+	var user models.User
+	if err := h.db.First(&user, c.Params("id")).Error; err != nil {
+		return utils.NotFoundResponse(c, "Employee not found")
+	}
+
+	var req assignmentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body")
+	}
+	if req.DepartmentID == 0 {
+		return utils.BadRequestResponse(c, "department_id is required")
+	}
+
+	// Tanggal effective_from dibaca di timezone site tujuan
+	effectiveFrom := time.Now().UTC()
+	if req.EffectiveFrom != "" {
+		parsed, err := parseDateOrTime(req.EffectiveFrom, h.tzService.LocationForSite(req.SiteID))
+		if err != nil {
+			return utils.BadRequestResponse(c, "effective_from must be RFC3339 or YYYY-MM-DD")
+		}
+		effectiveFrom = parsed
+	}
+
+	assignment, err := h.orgService.AssignEmployee(user.ID, req.DepartmentID, req.TeamID, req.SiteID, effectiveFrom)
+	if err != nil {
+		return h.assignmentError(c, err)
+	}
+
+	slog.InfoContext(c.UserContext(), "Employee assigned to department", "user_id", user.ID, "department_id", req.DepartmentID)
+	middleware.AuditEntity(c, "employee_assignment", assignment.ID, nil, assignment)
+
+	return utils.CreatedResponse(c, "Employee assigned successfully", assignment.ToResponse())
+}
+
+// GetAssignments returns assignment history of an employee
+// GET /api/employees/:id/assignments
+func (h *OrgHandler) GetAssignments(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 || !canViewEmployee(c, h.orgService, uint(id)) {
+		return utils.NotFoundResponse(c, "Employee not found")
+	}
+
+	var assignments []models.EmployeeAssignment
+	err = h.db.Preload("Department").Preload("Team").Preload("Site").
+		Where("user_id = ?", id).
+		Order("effective_from DESC").
+		Find(&assignments).Error
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "Error fetching assignments", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to fetch assignments")
+	}
+
+	responses := make([]models.AssignmentResponse, len(assignments))
+	for i, assignment := range assignments {
+		responses[i] = assignment.ToResponse()
+	}
+
+	return utils.SuccessResponse(c, "Assignments fetched successfully", responses)
+}
+
+// assignmentError maps org service errors to responses
+func (h *OrgHandler) assignmentError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, services.ErrInvalidAssignment) {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+	slog.ErrorContext(c.UserContext(), "Error updating organization", logging.Err(err))
+	return utils.InternalServerErrorResponse(c, "Failed to update organization")
+}
+
+// parseOrgFilter reads department_id, team_id and manager_id query params
+func parseOrgFilter(c *fiber.Ctx) services.OrgFilter {
+	return services.OrgFilter{
+		DepartmentID: queryUint(c, "department_id"),
+		TeamID:       queryUint(c, "team_id"),
+		ManagerID:    queryUint(c, "manager_id"),
+	}
+}
+
+// selectManagerName loads only the manager columns shown in DepartmentResponse / TeamResponse
+func selectManagerName(db *gorm.DB) *gorm.DB {
+	return db.Select("id", "name")
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"attendance-system/internal/logging"
+	"attendance-system/internal/middleware"
+	"attendance-system/internal/models"
+	"attendance-system/internal/services"
+	"attendance-system/internal/utils"
+	"bufio"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// PrivacyHandler handles data subject access and erasure requests
+type PrivacyHandler struct {
+	db             *gorm.DB
+	privacyService *services.PrivacyService
+}
+
+// NewPrivacyHandler creates a new PrivacyHandler
+func NewPrivacyHandler(db *gorm.DB, privacyService *services.PrivacyService) *PrivacyHandler {
+	return &PrivacyHandler{db: db, privacyService: privacyService}
+}
+
+// eraseRequest is the body for an erasure request
+type eraseRequest struct {
+	ConfirmEmail string `json:"confirm_email"`
+}
+
+// privacyError maps privacy service errors to HTTP responses
+func privacyError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return utils.NotFoundResponse(c, "Employee not found")
+	case errors.Is(err, services.ErrErasureNotConfirmed):
+		return utils.BadRequestResponse(c, err.Error())
+	case errors.Is(err, services.ErrEmployeeErased), errors.Is(err, services.ErrErasureLegalHold):
+		return utils.ConflictResponse(c, err.Error())
+	}
+	slog.ErrorContext(c.UserContext(), "Error processing data subject request", logging.Err(err))
+	return utils.InternalServerErrorResponse(c, "Failed to process data subject request")
+}
+
+// ExportEmployeeData downloads a ZIP of all personal and biometric data of an employee
+// Karyawan bisa export data sendiri, HR/admin bisa export data siapa saja
+// GET /api/employees/:id/export
+func (h *PrivacyHandler) ExportEmployeeData(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return utils.BadRequestResponse(c, "Invalid employee ID")
+	}
+	userID := uint(id)
+
+	role := middleware.CurrentRole(c)
+	if userID != middleware.CurrentUserID(c) && role != models.RoleHR && role != models.RoleAdmin {
+		return utils.ForbiddenResponse(c, "Not allowed to export this employee's data")
+	}
+
+	// Cek dulu sebelum streaming, setelah itu status code sudah tidak bisa diubah
+	var user models.User
+	if err := h.db.Select("id").First(&user, userID).Error; err != nil {
+		return privacyError(c, err)
+	}
+
+	middleware.AuditEntity(c, "employee", userID, nil, fiber.Map{"action": "export"})
+
+	c.Set(fiber.HeaderContentType, "application/zip")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("employee-%d-export.zip", userID)))
+
+	// Foto ditulis bertahap supaya export besar tidak dimuat ke memory sekaligus
+	ctx := c.UserContext()
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := h.privacyService.Export(ctx, userID, w); err != nil {
+			slog.ErrorContext(ctx, "Error exporting employee data", "user_id", userID, logging.Err(err))
+		}
+		w.Flush()
+	})
+	return nil
+}
+
+// EraseEmployeeData removes biometric data and personal details of an employee (admin only)
+// Attendance history tetap ada tapi sudah tidak bisa dikaitkan ke orangnya
+// POST /api/employees/:id/erase
+// JSON body: confirm_email (harus sama dengan email karyawan)
+func (h *PrivacyHandler) EraseEmployeeData(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return utils.BadRequestResponse(c, "Invalid employee ID")
+	}
+	userID := uint(id)
+	if userID == middleware.CurrentUserID(c) {
+		return utils.BadRequestResponse(c, "Cannot erase your own account")
+	}
+
+	var req eraseRequest
+	if err := c.BodyParser(&req); err != nil || req.ConfirmEmail == "" {
+		return utils.BadRequestResponse(c, "confirm_email is required")
+	}
+
+	report, err := h.privacyService.Erase(c.UserContext(), userID, req.ConfirmEmail)
+	if err != nil {
+		return privacyError(c, err)
+	}
+
+	slog.InfoContext(c.UserContext(), "Employee data erased", "user_id", userID, "files_deleted", report.FilesDeleted)
+	// Hanya report yang dicatat, snapshot data pribadi tidak boleh masuk audit log
+	middleware.AuditEntity(c, "employee", userID, nil, report)
+
+	// Bukan erasure total: audit log tetap disimpan, lihat report.retained
+	return utils.SuccessResponse(c, "Employee data erased, audit records are retained (see retained)", report)
+}
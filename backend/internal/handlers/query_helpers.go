@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"attendance-system/internal/logging"
+	"attendance-system/internal/middleware"
+	"attendance-system/internal/models"
+	"attendance-system/internal/repository"
+	"attendance-system/internal/services"
+	"attendance-system/internal/utils"
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// queryUint reads a positive integer query param, 0 jika kosong atau tidak valid
+func queryUint(c *fiber.Ctx, key string) uint {
+	value := c.QueryInt(key, 0)
+	if value < 0 {
+		return 0
+	}
+	return uint(value)
+}
+
+// parseDateOrTime parses RFC3339 timestamp or YYYY-MM-DD date (midnight in loc)
+func parseDateOrTime(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	day, err := utils.StartOfDate(value, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return day.UTC(), nil
+}
+
+// parseRangeEnd parses an exclusive range end
+// Tanggal (YYYY-MM-DD) dianggap inklusif, jadi end = awal hari berikutnya di loc
+func parseRangeEnd(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	day, err := utils.StartOfDate(value, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	_, end := utils.DayBounds(day, loc)
+	return end.UTC(), nil
+}
+
+// visibleUsersOnly restricts query to records of employees the caller may see
+// Karyawan: milik sendiri, manager: sendiri + bawahan, HR/admin: semua
+func visibleUsersOnly(c *fiber.Ctx, query *gorm.DB, orgService *services.OrgService, column string) (*gorm.DB, error) {
+	actorID := middleware.CurrentUserID(c)
+
+	switch middleware.CurrentRole(c) {
+	case models.RoleHR, models.RoleAdmin:
+		return query, nil
+	case models.RoleManager:
+		scope, err := orgService.UsersScope(services.OrgFilter{ManagerID: actorID})
+		if err != nil {
+			return nil, err
+		}
+		reports := query.Session(&gorm.Session{NewDB: true}).Model(&models.User{}).Select("users.id").Scopes(scope)
+		return query.Where(column+" = ? OR "+column+" IN (?)", actorID, reports), nil
+	}
+	return query.Where(column+" = ?", actorID), nil
+}
+
+// callerVisibility returns the employees the caller may see for the repositories, scope sama dengan visibleUsersOnly
+// nil = semua karyawan (HR/admin)
+func callerVisibility(c *fiber.Ctx, orgService *services.OrgService) (*repository.Visibility, error) {
+	actorID := middleware.CurrentUserID(c)
+
+	switch middleware.CurrentRole(c) {
+	case models.RoleHR, models.RoleAdmin:
+		return nil, nil
+	case models.RoleManager:
+		reports, err := orgService.AssignmentFilter(services.OrgFilter{ManagerID: actorID})
+		if err != nil {
+			return nil, err
+		}
+		return &repository.Visibility{UserID: actorID, Reports: reports}, nil
+	}
+	return &repository.Visibility{UserID: actorID}, nil
+}
+
+// canViewEmployee checks if the caller may see an employee's data, scope sama dengan visibleUsersOnly
+func canViewEmployee(c *fiber.Ctx, orgService *services.OrgService, userID uint) bool {
+	actorID := middleware.CurrentUserID(c)
+	if actorID != 0 && actorID == userID {
+		return true
+	}
+	allowed, err := orgService.CanManageEmployee(actorID, middleware.CurrentRole(c), userID)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "Error checking employee permission", logging.Err(err))
+		return false
+	}
+	return allowed
+}
@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"attendance-system/internal/logging"
+	"attendance-system/internal/middleware"
+	"attendance-system/internal/models"
+	"attendance-system/internal/repository"
+	"attendance-system/internal/services"
+	"attendance-system/internal/utils"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// RetentionHandler handles biometric data retention, purge reports and legal holds
+type RetentionHandler struct {
+	db               *gorm.DB
+	users            repository.UserRepository
+	attendances      repository.AttendanceRepository
+	retentionService *services.RetentionService
+	jobEnabled       bool
+	jobInterval      time.Duration
+}
+
+// NewRetentionHandler creates a new RetentionHandler
+// jobEnabled dan jobInterval hanya ditampilkan di GetPolicy, job-nya dijalankan dari main
+func NewRetentionHandler(db *gorm.DB, users repository.UserRepository, attendances repository.AttendanceRepository,
+	retentionService *services.RetentionService, jobEnabled bool, jobInterval time.Duration) *RetentionHandler {
+	return &RetentionHandler{
+		db:               db,
+		users:            users,
+		attendances:      attendances,
+		retentionService: retentionService,
+		jobEnabled:       jobEnabled,
+		jobInterval:      jobInterval,
+	}
+}
+
+// legalHoldRequest is the body for setting or releasing a legal hold
+type legalHoldRequest struct {
+	LegalHold *bool `json:"legal_hold"`
+}
+
+// retentionRunSortFields are the allowed sort keys for GetPurgeRuns
+var retentionRunSortFields = map[string]utils.SortField{
+	"started_at": {Column: "retention_purge_runs.started_at", Kind: utils.SortKindTime},
+}
+
+// retentionItemSortFields are the allowed sort keys for GetPurgeItems
+var retentionItemSortFields = map[string]utils.SortField{
+	"recorded_at": {Column: "retention_purge_items.recorded_at", Kind: utils.SortKindTime},
+}
+
+// GetPolicy returns the active retention policy
+// GET /api/retention/policy
+func (h *RetentionHandler) GetPolicy(c *fiber.Ctx) error {
+	return utils.SuccessResponse(c, "Retention policy fetched successfully", fiber.Map{
+		"enabled":  h.jobEnabled,
+		"interval": h.jobInterval.String(),
+		"policy":   h.retentionService.Policy(),
+	})
+}
+
+// Purge runs the retention purge now (admin only)
+// POST /api/retention/purge
+// Query params: dry_run (true = hanya laporan, tidak ada yang dihapus)
+func (h *RetentionHandler) Purge(c *fiber.Ctx) error {
+	dryRun := c.QueryBool("dry_run", false)
+	actorID := middleware.CurrentUserID(c)
+
+	run, err := h.retentionService.Purge(c.UserContext(), time.Now(), models.RetentionTriggerManual, &actorID, dryRun)
+	if errors.Is(err, services.ErrPurgeRunning) {
+		return utils.ErrorResponse(c, fiber.StatusConflict, err.Error())
+	}
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "Error running retention purge", logging.Err(err))
+		if run == nil {
+			return utils.InternalServerErrorResponse(c, "Failed to run retention purge")
+		}
+	}
+
+	middleware.AuditEntity(c, "retention_purge", run.ID, nil, run)
+
+	return utils.SuccessResponse(c, "Retention purge completed", run)
+}
+
+// GetPurgeRuns returns purge reports
+// GET /api/retention/runs
+// Query params (optional): trigger (job / manual), dry_run, sort (started_at), limit, cursor
+func (h *RetentionHandler) GetPurgeRuns(c *fiber.Ctx) error {
+	page, err := utils.ParsePageParams(c, retentionRunSortFields, "retention_purge_runs.id", "-started_at")
+	if err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+
+	query := h.db.Model(&models.RetentionPurgeRun{})
+	if trigger := c.Query("trigger"); trigger != "" {
+		query = query.Where("trigger = ?", trigger)
+	}
+	if dryRun := c.Query("dry_run"); dryRun != "" {
+		query = query.Where("dry_run = ?", dryRun == "true")
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		slog.ErrorContext(c.UserContext(), "Error counting purge runs", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to fetch purge runs")
+	}
+
+	query, err = page.Apply(query)
+	if err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+
+	var runs []models.RetentionPurgeRun
+	if err := query.Find(&runs).Error; err != nil {
+		slog.ErrorContext(c.UserContext(), "Error fetching purge runs", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to fetch purge runs")
+	}
+
+	meta := utils.PageMeta{Total: total, Limit: page.Limit, Sort: page.Sort()}
+	if len(runs) > page.Limit {
+		runs = runs[:page.Limit]
+		last := runs[len(runs)-1]
+		meta.NextCursor = page.NextCursor(last.StartedAt, last.ID)
+	}
+
+	return utils.PaginatedResponse(c, "Purge runs fetched successfully", runs, meta)
+}
+
+// GetPurgeItems returns the records purged by one run
+// GET /api/retention/runs/:id/items
+// Query params (optional): category, user_id, sort (recorded_at), limit, cursor
+func (h *RetentionHandler) GetPurgeItems(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return utils.BadRequestResponse(c, "Invalid purge run ID")
+	}
+	page, err := utils.ParsePageParams(c, retentionItemSortFields, "retention_purge_items.id", "recorded_at")
+	if err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+
+	var run models.RetentionPurgeRun
+	if err := h.db.First(&run, id).Error; err != nil {
+		return utils.NotFoundResponse(c, "Purge run not found")
+	}
+
+	query := h.db.Model(&models.RetentionPurgeItem{}).Where("run_id = ?", run.ID)
+	if category := c.Query("category"); category != "" {
+		query = query.Where("category = ?", category)
+	}
+	if userID := queryUint(c, "user_id"); userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		slog.ErrorContext(c.UserContext(), "Error counting purge items", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to fetch purge items")
+	}
+
+	query, err = page.Apply(query)
+	if err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+
+	var items []models.RetentionPurgeItem
+	if err := query.Find(&items).Error; err != nil {
+		slog.ErrorContext(c.UserContext(), "Error fetching purge items", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to fetch purge items")
+	}
+
+	meta := utils.PageMeta{Total: total, Limit: page.Limit, Sort: page.Sort()}
+	if len(items) > page.Limit {
+		items = items[:page.Limit]
+		last := items[len(items)-1]
+		meta.NextCursor = page.NextCursor(last.RecordedAt, last.ID)
+	}
+
+	return utils.PaginatedResponse(c, "Purge items fetched successfully", items, meta)
+}
+
+// SetAttendanceLegalHold sets or releases the legal hold of a check-in selfie
+// PUT /api/attendance/:id/legal-hold
+// JSON body: legal_hold (bool)
+func (h *RetentionHandler) SetAttendanceLegalHold(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return utils.NotFoundResponse(c, "Attendance not found")
+	}
+	var req legalHoldRequest
+	if err := c.BodyParser(&req); err != nil || req.LegalHold == nil {
+		return utils.BadRequestResponse(c, "legal_hold is required")
+	}
+
+	attendance, err := h.attendances.FindByID(c.UserContext(), uint(id))
+	if err != nil {
+		return utils.NotFoundResponse(c, "Attendance not found")
+	}
+	before := fiber.Map{"legal_hold": attendance.LegalHold}
+
+	if err := h.attendances.SetLegalHold(c.UserContext(), attendance.ID, *req.LegalHold); err != nil {
+		slog.ErrorContext(c.UserContext(), "Error updating legal hold", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to update legal hold")
+	}
+	attendance.LegalHold = *req.LegalHold
+
+	slog.InfoContext(c.UserContext(), "Legal hold updated", "attendance_id", attendance.ID, "legal_hold", attendance.LegalHold)
+	middleware.AuditEntity(c, "attendance", attendance.ID, before, fiber.Map{"legal_hold": attendance.LegalHold})
+
+	return utils.SuccessResponse(c, "Legal hold updated successfully", attendance.ToResponse())
+}
+
+// SetEmployeeLegalHold sets or releases the legal hold of an employee
+// Hold karyawan melindungi foto referensi, descriptor dan semua selfie karyawan tersebut
+// PUT /api/employees/:id/legal-hold
+// JSON body: legal_hold (bool)
+func (h *RetentionHandler) SetEmployeeLegalHold(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return utils.NotFoundResponse(c, "Employee not found")
+	}
+	var req legalHoldRequest
+	if err := c.BodyParser(&req); err != nil || req.LegalHold == nil {
+		return utils.BadRequestResponse(c, "legal_hold is required")
+	}
+
+	user, err := h.users.FindByID(c.UserContext(), uint(id))
+	if err != nil {
+		return utils.NotFoundResponse(c, "Employee not found")
+	}
+	before := fiber.Map{"legal_hold": user.LegalHold}
+
+	if err := h.users.SetLegalHold(c.UserContext(), user.ID, *req.LegalHold); err != nil {
+		slog.ErrorContext(c.UserContext(), "Error updating legal hold", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to update legal hold")
+	}
+	user.LegalHold = *req.LegalHold
+
+	slog.InfoContext(c.UserContext(), "Legal hold updated", "user_id", user.ID, "legal_hold", user.LegalHold)
+	middleware.AuditEntity(c, "employee", user.ID, before, fiber.Map{"legal_hold": user.LegalHold})
+
+	return utils.SuccessResponse(c, "Legal hold updated successfully", user.ToResponse())
+}
@@ -1,30 +1,57 @@
 package handlers
 
 import (
-	"attendance-system/internal/config"
+	"archive/zip"
+	"attendance-system/internal/logging"
+	"attendance-system/internal/middleware"
 	"attendance-system/internal/models"
+	"attendance-system/internal/repository"
 	"attendance-system/internal/services"
 	"attendance-system/internal/utils"
-	"log"
+	"errors"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 // UserHandler handles user-related requests
 type UserHandler struct {
-	faceService *services.FaceService
+	users          repository.UserRepository
+	faceService    *services.FaceService
+	consentService *services.ConsentService
+	orgService     *services.OrgService
+	imageService   *services.ImageService
+	importService  *services.ImportService
 }
 
 // NewUserHandler creates a new UserHandler
-func NewUserHandler() *UserHandler {
+func NewUserHandler(users repository.UserRepository, faceService *services.FaceService,
+	consentService *services.ConsentService, orgService *services.OrgService,
+	imageService *services.ImageService, importService *services.ImportService) *UserHandler {
 	return &UserHandler{
-		faceService: services.NewFaceService(),
+		users:          users,
+		faceService:    faceService,
+		consentService: consentService,
+		orgService:     orgService,
+		imageService:   imageService,
+		importService:  importService,
 	}
 }
 
-// RegisterEmployee handles employee registration
+// userResponse converts a user and signs its photo URLs for the current viewer
+func (h *UserHandler) userResponse(c *fiber.Ctx, user *models.User) models.UserResponse {
+	response := user.ToResponse()
+	h.imageService.SignUser(&response, user, middleware.CurrentUserID(c))
+	return response
+}
+
+// RegisterEmployee handles employee registration (HR/admin)
 // POST /api/employees/register
-// Form data: name, email, phone, face_image (file)
+// Form data: name, email, phone, face_image (file), consent ("true", wajib),
+// consent_method (optional: form default, atau paper)
 func (h *UserHandler) RegisterEmployee(c *fiber.Ctx) error {
 	// Parse form data
 	name := c.FormValue("name")
@@ -36,6 +63,17 @@ func (h *UserHandler) RegisterEmployee(c *fiber.Ctx) error {
 		return utils.BadRequestResponse(c, "Name and email are required")
 	}
 
+	// Wajah tidak boleh di-enroll tanpa consent biometrik
+	if c.FormValue("consent") != "true" {
+		return utils.BadRequestResponse(c, "Biometric consent is required to enroll a face")
+	}
+	consentMethod := c.FormValue("consent_method", models.ConsentMethodForm)
+	if consentMethod != models.ConsentMethodForm && consentMethod != models.ConsentMethodPaper {
+		return utils.BadRequestResponse(c, "consent_method must be form or paper")
+	}
+	// Route memakai requireAuth + requireHR, consent tercatat atas nama HR/admin yang mendaftarkan
+	recordedByID := middleware.CurrentUserID(c)
+
 	// Get uploaded file
 	faceImage, err := c.FormFile("face_image")
 	if err != nil {
@@ -43,18 +81,18 @@ func (h *UserHandler) RegisterEmployee(c *fiber.Ctx) error {
 	}
 
 	// Save uploaded file
-	imagePath, err := utils.SaveUploadedFile(faceImage, config.AppConfig.Upload.Path)
+	imagePath, err := h.faceService.SaveUploadedImage(c.UserContext(), faceImage)
 	if err != nil {
-		log.Printf("Error saving file: %v", err)
+		slog.ErrorContext(c.UserContext(), "Error saving file", logging.Err(err))
 		return utils.InternalServerErrorResponse(c, "Failed to save face image")
 	}
 
 	// Extract face descriptor
-	faceDescriptor, err := h.faceService.ExtractFaceDescriptor(imagePath)
+	faceDescriptor, err := h.faceService.ExtractFaceDescriptor(c.UserContext(), imagePath)
 	if err != nil {
 		// Cleanup uploaded file jika gagal extract
-		utils.DeleteFile(imagePath)
-		log.Printf("Error extracting face descriptor: %v", err)
+		h.faceService.DeleteImage(c.UserContext(), imagePath)
+		slog.ErrorContext(c.UserContext(), "Error extracting face descriptor", logging.Err(err))
 		return utils.InternalServerErrorResponse(c, "Failed to process face image")
 	}
 
@@ -67,51 +105,261 @@ func (h *UserHandler) RegisterEmployee(c *fiber.Ctx) error {
 		FaceDescriptor: faceDescriptor,
 	}
 
-	// Save to database
-	db := config.GetDB()
-	if err := db.Create(&user).Error; err != nil {
+	// Save to database, user dan consent-nya dalam satu transaksi
+	consent := models.BiometricConsent{
+		PolicyVersion: h.consentService.PolicyVersion(),
+		Method:        consentMethod,
+		GivenAt:       time.Now().UTC(),
+		RecordedByID:  &recordedByID,
+	}
+	if err := h.users.Create(c.UserContext(), &user, &consent); err != nil {
 		// Cleanup uploaded file jika gagal save
-		utils.DeleteFile(imagePath)
-		log.Printf("Error creating user: %v", err)
+		h.faceService.DeleteImage(c.UserContext(), imagePath)
+		slog.ErrorContext(c.UserContext(), "Error creating user", logging.Err(err))
 		return utils.InternalServerErrorResponse(c, "Failed to register employee")
 	}
 
-	log.Printf("✅ Employee registered: %s (ID: %d)", user.Name, user.ID)
+	slog.InfoContext(c.UserContext(), "Employee registered", "user_id", user.ID)
+	// Nama, email dan telepon disamarkan AuditDiff, audit log hanya mencatat field yang diisi
+	middleware.AuditEntity(c, "employee", user.ID, nil, fiber.Map{
+		"name": user.Name, "email": user.Email, "phone": user.Phone, "role": user.Role, "face_image": "enrolled",
+	})
 
-	return utils.CreatedResponse(c, "Employee registered successfully", user.ToResponse())
+	return utils.CreatedResponse(c, "Employee registered successfully", h.userResponse(c, &user))
 }
 
-// GetEmployees returns list of all employees
+// ImportEmployees handles bulk employee import (HR/admin)
+// POST /api/employees/import
+// Form data: csv_file (file), photos_zip (file), dry_run (optional, "true")
+func (h *UserHandler) ImportEmployees(c *fiber.Ctx) error {
+	csvFile, err := c.FormFile("csv_file")
+	if err != nil {
+		return utils.BadRequestResponse(c, "CSV file is required")
+	}
+	photosZip, err := c.FormFile("photos_zip")
+	if err != nil {
+		return utils.BadRequestResponse(c, "Photos ZIP file is required")
+	}
+	dryRun := c.FormValue("dry_run") == "true"
+
+	// Parse CSV
+	csvSrc, err := csvFile.Open()
+	if err != nil {
+		return utils.BadRequestResponse(c, "Failed to read CSV file")
+	}
+	defer csvSrc.Close()
+
+	rows, err := services.ParseCSV(csvSrc)
+	if err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+
+	// Open ZIP langsung dari multipart file (implements io.ReaderAt)
+	zipSrc, err := photosZip.Open()
+	if err != nil {
+		return utils.BadRequestResponse(c, "Failed to read photos ZIP file")
+	}
+	defer zipSrc.Close()
+
+	photos, err := zip.NewReader(zipSrc, photosZip.Size)
+	if err != nil {
+		return utils.BadRequestResponse(c, "Photos file is not a valid ZIP archive")
+	}
+
+	// Route dilindungi requireHR, consent dari CSV selalu tercatat atas nama HR/admin yang meng-import
+	recordedByID := middleware.CurrentUserID(c)
+
+	report, err := h.importService.Import(c.UserContext(), rows, photos, dryRun, &recordedByID)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "Error importing employees", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to import employees")
+	}
+
+	slog.InfoContext(c.UserContext(), "Employee import finished",
+		"dry_run", dryRun, "created", report.Created, "skipped", report.Skipped, "failed", report.Failed)
+	middleware.AuditEntity(c, "employee_import", csvFile.Filename, nil, fiber.Map{
+		"dry_run": dryRun, "created": report.Created, "skipped": report.Skipped, "failed": report.Failed,
+	})
+
+	return utils.SuccessResponse(c, "Employee import processed", report)
+}
+
+// employeeSortFields are the allowed sort keys for GetEmployees
+var employeeSortFields = map[string]utils.SortField{
+	"name":       {Column: "users.name", Kind: utils.SortKindString},
+	"email":      {Column: "users.email", Kind: utils.SortKindString},
+	"created_at": {Column: "users.created_at", Kind: utils.SortKindTime},
+}
+
+// GetEmployees returns employees visible to the caller
 // GET /api/employees
+// Query params (optional): q (nama/email), department_id, team_id, manager_id,
+// consent (consented / outdated / withdrawn / missing),
+// sort (name, email, created_at; prefix "-" untuk DESC), limit, cursor
 func (h *UserHandler) GetEmployees(c *fiber.Ctx) error {
-	var users []models.User
-	db := config.GetDB()
+	page, err := utils.ParsePageParams(c, employeeSortFields, "users.id", "name")
+	if err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+
+	visible, err := callerVisibility(c, h.orgService)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "Error building manager scope", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to fetch employees")
+	}
+	filter := repository.UserFilter{
+		Visible:       visible,
+		Search:        c.Query("q"), // Nama/email
+		ConsentStatus: c.Query("consent"),
+		PolicyVersion: h.consentService.PolicyVersion(),
+	}
+
+	// Filter by org unit jika ada
+	if orgFilter := parseOrgFilter(c); !orgFilter.IsEmpty() {
+		if filter.Org, err = h.orgService.AssignmentFilter(orgFilter); err != nil {
+			slog.ErrorContext(c.UserContext(), "Error building org filter", logging.Err(err))
+			return utils.InternalServerErrorResponse(c, "Failed to fetch employees")
+		}
+	}
 
-	if err := db.Find(&users).Error; err != nil {
-		log.Printf("Error fetching employees: %v", err)
+	users, total, err := h.users.Page(c.UserContext(), filter, page)
+	if errors.Is(err, repository.ErrInvalidConsentStatus) || errors.Is(err, utils.ErrInvalidCursor) {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "Error fetching employees", logging.Err(err))
 		return utils.InternalServerErrorResponse(c, "Failed to fetch employees")
 	}
 
+	meta := utils.PageMeta{Total: total, Limit: page.Limit, Sort: page.Sort()}
+	if len(users) > page.Limit {
+		users = users[:page.Limit]
+		last := users[len(users)-1]
+		meta.NextCursor = page.NextCursor(employeeSortValue(&last, page.SortKey), last.ID)
+	}
+
 	// Convert to response format
 	responses := make([]models.UserResponse, len(users))
-	for i, user := range users {
-		responses[i] = user.ToResponse()
+	for i := range users {
+		responses[i] = h.userResponse(c, &users[i])
 	}
 
-	return utils.SuccessResponse(c, "Employees fetched successfully", responses)
+	return utils.PaginatedResponse(c, "Employees fetched successfully", responses, meta)
+}
+
+// GetDirectory returns active employees for the kiosk check-in picker
+// GET /api/employees/directory
+// Tanpa login, jadi hanya ID dan nama; karyawan nonaktif atau sudah di-erase tidak ikut
+func (h *UserHandler) GetDirectory(c *fiber.Ctx) error {
+	users, err := h.users.List(c.UserContext(), false)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "Error fetching employee directory", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to fetch employees")
+	}
+
+	entries := make([]models.DirectoryEntry, 0, len(users))
+	for _, user := range users {
+		if user.ErasedAt != nil {
+			continue
+		}
+		entries = append(entries, models.DirectoryEntry{ID: user.ID, Name: user.Name})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return strings.ToLower(entries[i].Name) < strings.ToLower(entries[j].Name)
+	})
+
+	return utils.SuccessResponse(c, "Employee directory fetched successfully", entries)
+}
+
+// employeeSortValue returns the value of the sort field for cursor building
+func employeeSortValue(u *models.User, key string) interface{} {
+	switch key {
+	case "email":
+		return u.Email
+	case "created_at":
+		return u.CreatedAt
+	default:
+		return u.Name
+	}
 }
 
 // GetEmployee returns single employee by ID
 // GET /api/employees/:id
 func (h *UserHandler) GetEmployee(c *fiber.Ctx) error {
-	id := c.Params("id")
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return utils.NotFoundResponse(c, "Employee not found")
+	}
 
-	var user models.User
-	db := config.GetDB()
+	// Karyawan di luar scope caller dijawab 404, sama seperti yang tidak ada
+	if !canViewEmployee(c, h.orgService, uint(id)) {
+		return utils.NotFoundResponse(c, "Employee not found")
+	}
+	user, err := h.users.FindByID(c.UserContext(), uint(id))
+	if err != nil {
+		return utils.NotFoundResponse(c, "Employee not found")
+	}
+
+	return utils.SuccessResponse(c, "Employee fetched successfully", h.userResponse(c, user))
+}
 
-	if err := db.First(&user, id).Error; err != nil {
+// UpdateFaceImage replaces the reference photo and face descriptor of an employee
+// Dipakai untuk enroll ulang setelah consent diberikan lagi atau foto di-purge, butuh consent aktif
+// PUT /api/employees/:id/face
+// Form data: face_image (file)
+func (h *UserHandler) UpdateFaceImage(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
 		return utils.NotFoundResponse(c, "Employee not found")
 	}
+	user, err := h.users.FindByID(c.UserContext(), uint(id))
+	if err != nil {
+		return utils.NotFoundResponse(c, "Employee not found")
+	}
+	if user.DeactivatedAt != nil {
+		return utils.BadRequestResponse(c, "Employee is deactivated")
+	}
+	if err := h.consentService.Require(user.ID); err != nil {
+		if errors.Is(err, services.ErrConsentRequired) {
+			return utils.BadRequestResponse(c, "Biometric consent is required to enroll a face")
+		}
+		slog.ErrorContext(c.UserContext(), "Error checking consent", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to check biometric consent")
+	}
+
+	faceImage, err := c.FormFile("face_image")
+	if err != nil {
+		return utils.BadRequestResponse(c, "Face image is required")
+	}
+	imagePath, err := h.faceService.SaveUploadedImage(c.UserContext(), faceImage)
+	if err != nil {
+		slog.ErrorContext(c.UserContext(), "Error saving file", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to save face image")
+	}
+	faceDescriptor, err := h.faceService.ExtractFaceDescriptor(c.UserContext(), imagePath)
+	if err != nil {
+		h.faceService.DeleteImage(c.UserContext(), imagePath)
+		slog.ErrorContext(c.UserContext(), "Error extracting face descriptor", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to process face image")
+	}
+
+	oldPath := user.FaceImagePath
+	if err := h.users.UpdateFace(c.UserContext(), user.ID, imagePath, faceDescriptor); err != nil {
+		h.faceService.DeleteImage(c.UserContext(), imagePath)
+		slog.ErrorContext(c.UserContext(), "Error updating face image", logging.Err(err))
+		return utils.InternalServerErrorResponse(c, "Failed to update face image")
+	}
+	user.FaceImagePath, user.FaceDescriptor, user.ImagePurgedAt = imagePath, faceDescriptor, nil
+
+	// Foto lama dihapus setelah database menunjuk ke foto baru
+	if oldPath != "" {
+		if err := h.faceService.DeleteImage(c.UserContext(), oldPath); err != nil {
+			slog.ErrorContext(c.UserContext(), "Error deleting old face image", logging.Err(err))
+		}
+	}
+
+	slog.InfoContext(c.UserContext(), "Face re-enrolled", "user_id", user.ID)
+	middleware.AuditEntity(c, "employee", user.ID, nil, fiber.Map{"face_image": "updated"})
 
-	return utils.SuccessResponse(c, "Employee fetched successfully", user.ToResponse())
+	return utils.SuccessResponse(c, "Face image updated successfully", h.userResponse(c, user))
 }
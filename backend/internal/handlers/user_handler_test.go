@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"attendance-system/internal/models"
+	"attendance-system/internal/repository"
+	"attendance-system/internal/services"
+	"context"
+	"net/http"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+// newTestUserHandler builds a UserHandler on the in-memory repository
+// OrgService tanpa database cukup untuk caller HR/admin dan karyawan biasa (scope manager butuh database)
+func newTestUserHandler(users repository.UserRepository) *UserHandler {
+	return NewUserHandler(users, nil, services.NewConsentService(nil, nil, "1"), services.NewOrgService(nil),
+		testImageService(), nil)
+}
+
+func TestGetEmployeeScope(t *testing.T) {
+	h := newTestUserHandler(testEmployees())
+
+	tests := []struct {
+		name       string
+		callerID   uint
+		role       string
+		target     string
+		wantStatus int
+	}{
+		{"employee sees themself", 2, models.RoleEmployee, "/api/employees/2", http.StatusOK},
+		{"employee cannot see a colleague", 2, models.RoleEmployee, "/api/employees/3", http.StatusNotFound},
+		{"hr sees everyone", 1, models.RoleHR, "/api/employees/3", http.StatusOK},
+		{"unknown employee", 1, models.RoleHR, "/api/employees/99", http.StatusNotFound},
+		{"invalid id", 1, models.RoleHR, "/api/employees/abc", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(http.MethodGet, "/api/employees/:id", tt.callerID, tt.role, h.GetEmployee)
+			status, resp := getJSON(t, app, tt.target)
+			if status != tt.wantStatus {
+				t.Fatalf("status %d, want %d (%s)", status, tt.wantStatus, resp.Message)
+			}
+		})
+	}
+}
+
+func TestGetEmployeesVisibility(t *testing.T) {
+	h := newTestUserHandler(testEmployees())
+
+	tests := []struct {
+		name     string
+		callerID uint
+		role     string
+		target   string
+		wantIDs  []uint
+	}{
+		{"employee only sees themself", 3, models.RoleEmployee, "/api/employees", []uint{3}},
+		{"hr sees everyone sorted by name", 1, models.RoleHR, "/api/employees", []uint{2, 3, 4, 1}},
+		{"search by email", 1, models.RoleHR, "/api/employees?q=CITRA@", []uint{3}},
+		{"search outside the caller scope", 2, models.RoleEmployee, "/api/employees?q=citra", nil},
+		{"descending sort", 1, models.RoleHR, "/api/employees?sort=-name", []uint{1, 4, 3, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(http.MethodGet, "/api/employees", tt.callerID, tt.role, h.GetEmployees)
+			status, resp := getJSON(t, app, tt.target)
+			if status != http.StatusOK {
+				t.Fatalf("status %d, want 200 (%s)", status, resp.Message)
+			}
+			var employees []models.UserResponse
+			decodeData(t, resp, &employees)
+			if got := employeeIDs(employees); !slices.Equal(got, tt.wantIDs) {
+				t.Fatalf("employees %v, want %v", got, tt.wantIDs)
+			}
+			if resp.Meta == nil || resp.Meta.Total != int64(len(tt.wantIDs)) {
+				t.Fatalf("meta %+v, want total %d", resp.Meta, len(tt.wantIDs))
+			}
+		})
+	}
+}
+
+func TestGetEmployeesCursorPagination(t *testing.T) {
+	app := newTestApp(http.MethodGet, "/api/employees", 1, models.RoleHR, newTestUserHandler(testEmployees()).GetEmployees)
+
+	var seen []uint
+	target := "/api/employees?limit=3"
+	for pages := 0; target != ""; pages++ {
+		if pages > 3 {
+			t.Fatalf("pagination did not stop, seen %v", seen)
+		}
+		status, resp := getJSON(t, app, target)
+		if status != http.StatusOK {
+			t.Fatalf("status %d, want 200 (%s)", status, resp.Message)
+		}
+		var employees []models.UserResponse
+		decodeData(t, resp, &employees)
+		seen = append(seen, employeeIDs(employees)...)
+
+		target = ""
+		if resp.Meta.NextCursor != "" {
+			target = "/api/employees?limit=3&cursor=" + resp.Meta.NextCursor
+		}
+	}
+	if want := []uint{2, 3, 4, 1}; !slices.Equal(seen, want) {
+		t.Fatalf("pages returned %v, want %v", seen, want)
+	}
+
+	// Cursor dari sort lain atau yang rusak ditolak
+	for _, target := range []string{"/api/employees?cursor=not-base64!", "/api/employees?sort=email&cursor=eyJzIjoibmFtZSJ9"} {
+		if status, resp := getJSON(t, app, target); status != http.StatusBadRequest {
+			t.Fatalf("%s: status %d, want 400 (%s)", target, status, resp.Message)
+		}
+	}
+}
+
+func TestGetEmployeesConsentFilter(t *testing.T) {
+	users := testEmployees()
+	consented := models.User{Name: "Eka", Email: "eka@example.com", Role: models.RoleEmployee}
+	err := users.Create(context.Background(), &consented, &models.BiometricConsent{PolicyVersion: "1", Method: models.ConsentMethodForm})
+	if err != nil {
+		t.Fatal(err)
+	}
+	outdated := models.User{Name: "Fajar", Email: "fajar@example.com", Role: models.RoleEmployee}
+	err = users.Create(context.Background(), &outdated, &models.BiometricConsent{PolicyVersion: "0", Method: models.ConsentMethodForm})
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := newTestApp(http.MethodGet, "/api/employees", 1, models.RoleHR, newTestUserHandler(users).GetEmployees)
+
+	tests := []struct {
+		consent string
+		wantIDs []uint
+	}{
+		{models.ConsentStatusConsented, []uint{consented.ID}},
+		{models.ConsentStatusOutdated, []uint{outdated.ID}},
+		{models.ConsentStatusMissing, []uint{2, 3, 4, 1}},
+		{models.ConsentStatusWithdrawn, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.consent, func(t *testing.T) {
+			status, resp := getJSON(t, app, "/api/employees?consent="+tt.consent)
+			if status != http.StatusOK {
+				t.Fatalf("status %d, want 200 (%s)", status, resp.Message)
+			}
+			var employees []models.UserResponse
+			decodeData(t, resp, &employees)
+			if got := employeeIDs(employees); !slices.Equal(got, tt.wantIDs) {
+				t.Fatalf("employees %v, want %v", got, tt.wantIDs)
+			}
+		})
+	}
+
+	if status, resp := getJSON(t, app, "/api/employees?consent=maybe"); status != http.StatusBadRequest {
+		t.Fatalf("invalid consent status: status %d, want 400 (%s)", status, resp.Message)
+	}
+}
+
+func TestGetDirectory(t *testing.T) {
+	now := time.Now()
+	users := repository.NewMemoryUserRepository(
+		models.User{ID: 1, Name: "citra", Email: "citra@example.com", Phone: "0812", Role: models.RoleEmployee},
+		models.User{ID: 2, Name: "Budi", Email: "budi@example.com", Role: models.RoleEmployee},
+		models.User{ID: 3, Name: "Dewi", Email: "dewi@example.com", Role: models.RoleEmployee, DeactivatedAt: &now},
+		models.User{ID: 4, Name: "Erased 4", Email: "erased-4@invalid", Role: models.RoleEmployee, ErasedAt: &now},
+	)
+	// Kiosk tanpa login
+	app := newTestApp(http.MethodGet, "/api/employees/directory", 0, "", newTestUserHandler(users).GetDirectory)
+
+	status, resp := getJSON(t, app, "/api/employees/directory")
+	if status != http.StatusOK {
+		t.Fatalf("status %d, want 200 (%s)", status, resp.Message)
+	}
+	var entries []map[string]interface{}
+	decodeData(t, resp, &entries)
+	want := []map[string]interface{}{{"id": float64(2), "name": "Budi"}, {"id": float64(1), "name": "citra"}}
+	if !reflect.DeepEqual(entries, want) {
+		t.Fatalf("directory %v, want %v (active employees sorted by name, id and name only)", entries, want)
+	}
+}
+
+// employeeIDs returns the IDs in response order
+func employeeIDs(employees []models.UserResponse) []uint {
+	var ids []uint
+	for _, employee := range employees {
+		ids = append(ids, employee.ID)
+	}
+	return ids
+}
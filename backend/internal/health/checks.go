@@ -0,0 +1,88 @@
+package health
+
+import (
+	"attendance-system/internal/migrations"
+	"attendance-system/internal/storage"
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Database pings the connection pool
+func Database(db *gorm.DB) Check {
+	return Check{Name: "database", Run: func(ctx context.Context) (string, error) {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return "", err
+		}
+		if err := sqlDB.PingContext(ctx); err != nil {
+			return "", err
+		}
+		stats := sqlDB.Stats()
+		return fmt.Sprintf("%s, %d open connection(s)", db.Dialector.Name(), stats.OpenConnections), nil
+	}}
+}
+
+// Migrations fails while an embedded migration is not applied (deploy baru sebelum `migrate up`)
+func Migrations(db *gorm.DB) Check {
+	return Check{Name: "migrations", Run: func(ctx context.Context) (string, error) {
+		migrator, err := migrations.New(db.WithContext(ctx))
+		if err != nil {
+			return "", err
+		}
+		statuses, err := migrator.Status()
+		if err != nil {
+			return "", err
+		}
+		if err := migrator.Check(); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%d applied", len(statuses)), nil
+	}}
+}
+
+// Storage writes and deletes a small object, membuktikan storage bisa ditulis (bukan hanya dibaca)
+func Storage(store storage.Storage) Check {
+	return Check{Name: "storage", Run: func(ctx context.Context) (string, error) {
+		key := fmt.Sprintf(".healthcheck-%d", time.Now().UnixNano())
+		probe := []byte("ok")
+		if err := store.Put(ctx, key, bytes.NewReader(probe), int64(len(probe)), "text/plain"); err != nil {
+			return "", err
+		}
+		if err := store.Delete(ctx, key); err != nil {
+			return "", err
+		}
+		return store.Driver() + ", writable", nil
+	}}
+}
+
+// DiskSpace fails when a directory has less than minFree bytes available
+// Dipakai untuk UPLOAD_PATH (driver local) dan file database SQLite
+func DiskSpace(minFree uint64, dirs ...string) Check {
+	return Check{Name: "disk_space", Run: func(ctx context.Context) (string, error) {
+		details := make([]string, 0, len(dirs))
+		for _, dir := range dirs {
+			free, total, err := diskUsage(dir)
+			if err != nil {
+				return "", fmt.Errorf("%s: %w", dir, err)
+			}
+			if free < minFree {
+				return "", fmt.Errorf("%s: %s free, below %s", dir, formatBytes(free), formatBytes(minFree))
+			}
+			details = append(details, fmt.Sprintf("%s: %s free of %s", dir, formatBytes(free), formatBytes(total)))
+		}
+		return strings.Join(details, "; "), nil
+	}}
+}
+
+// formatBytes formats a size in MiB / GiB
+func formatBytes(n uint64) string {
+	if n >= 1<<30 {
+		return fmt.Sprintf("%.1f GiB", float64(n)/(1<<30))
+	}
+	return fmt.Sprintf("%.0f MiB", float64(n)/(1<<20))
+}
@@ -0,0 +1,17 @@
+//go:build windows
+
+package health
+
+import "golang.org/x/sys/windows"
+
+// diskUsage returns the bytes available to the current user and the size of the volume of dir
+func diskUsage(dir string) (free, total uint64, err error) {
+	path, err := windows.UTF16PtrFromString(dir)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := windows.GetDiskFreeSpaceEx(path, &free, &total, nil); err != nil {
+		return 0, 0, err
+	}
+	return free, total, nil
+}
@@ -0,0 +1,100 @@
+package health
+
+import (
+	"attendance-system/internal/logging"
+	"context"
+	"sync"
+	"time"
+)
+
+// Status values of a check and of the whole report
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// Check is one dependency probe, Run mengembalikan keterangan singkat untuk report (driver, jumlah, dll.)
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) (string, error)
+}
+
+// Result is the outcome of one check
+type Result struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Detail    string  `json:"detail,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report is the body of /readyz
+type Report struct {
+	Status    string    `json:"status"`
+	Checks    []Result  `json:"checks"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// OK reports whether every check passed
+func (r Report) OK() bool {
+	return r.Status == StatusOK
+}
+
+// Checker runs the readiness checks, dipakai /readyz dan attendancectl health
+type Checker struct {
+	checks  []Check
+	timeout time.Duration
+}
+
+// NewChecker creates a Checker, timeout berlaku per check
+func NewChecker(timeout time.Duration, checks ...Check) *Checker {
+	return &Checker{checks: checks, timeout: timeout}
+}
+
+// Run executes every check concurrently
+// Check yang lambat dibatasi timeout supaya probe dari orchestrator tidak ikut menggantung
+func (c *Checker) Run(ctx context.Context) Report {
+	results := make([]Result, len(c.checks))
+	var wg sync.WaitGroup
+	for i, check := range c.checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			results[i] = c.run(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: results, Timestamp: time.Now()}
+	for _, result := range results {
+		if result.Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+	return report
+}
+
+// run executes one check with the timeout
+func (c *Checker) run(ctx context.Context, check Check) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	started := time.Now()
+	detail, err := check.Run(ctx)
+	if err == nil {
+		// Check yang mengabaikan context tetap dianggap gagal kalau melewati timeout
+		err = ctx.Err()
+	}
+	result := Result{
+		Name:      check.Name,
+		Status:    StatusOK,
+		LatencyMs: float64(time.Since(started).Microseconds()) / 1000,
+		Detail:    detail,
+	}
+	if err != nil {
+		result.Status = StatusFail
+		// Error database / S3 bisa memuat email, disamarkan sama seperti di log
+		result.Error = logging.Redact(err.Error())
+	}
+	return result
+}
@@ -0,0 +1,74 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// gormLogger sends GORM logs to slog
+// Query normal di level DEBUG, query lambat WARN, query gagal ERROR
+// SQL dicatat dengan placeholder (lihat ParamsFilter), nilai parameter bisa berisi nama, email atau descriptor
+type gormLogger struct {
+	level gormlogger.LogLevel
+	slow  time.Duration
+}
+
+// NewGormLogger creates the GORM logger, level tetap membatasi apa yang diteruskan ke slog
+func NewGormLogger(level gormlogger.LogLevel, slow time.Duration) gormlogger.Interface {
+	return &gormLogger{level: level, slow: slow}
+}
+
+func (l *gormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	copied := *l
+	copied.level = level
+	return &copied
+}
+
+func (l *gormLogger) Info(ctx context.Context, format string, args ...interface{}) {
+	if l.level >= gormlogger.Info {
+		slog.InfoContext(ctx, fmt.Sprintf(format, args...))
+	}
+}
+
+func (l *gormLogger) Warn(ctx context.Context, format string, args ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		slog.WarnContext(ctx, fmt.Sprintf(format, args...))
+	}
+}
+
+func (l *gormLogger) Error(ctx context.Context, format string, args ...interface{}) {
+	if l.level >= gormlogger.Error {
+		slog.ErrorContext(ctx, fmt.Sprintf(format, args...))
+	}
+}
+
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+
+	switch {
+	// "record not found" adalah hasil normal (misalnya cek email), bukan error yang perlu di-log
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= gormlogger.Error:
+		sql, rows := fc()
+		slog.ErrorContext(ctx, "SQL error", Err(err), "sql", sql, "rows", rows, Duration(elapsed))
+	case l.slow > 0 && elapsed > l.slow && l.level >= gormlogger.Warn:
+		sql, rows := fc()
+		slog.WarnContext(ctx, "Slow SQL", "sql", sql, "rows", rows, Duration(elapsed))
+	case l.level >= gormlogger.Info:
+		sql, rows := fc()
+		slog.DebugContext(ctx, "SQL", "sql", sql, "rows", rows, Duration(elapsed))
+	}
+}
+
+// ParamsFilter drops the query parameters, GORM lalu mencatat SQL dengan placeholder
+func (l *gormLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
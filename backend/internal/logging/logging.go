@@ -0,0 +1,63 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"time"
+)
+
+// Output formats for LOG_FORMAT
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Attribute keys shared by every log line
+const (
+	KeyRequestID = "request_id"
+	KeyTraceID   = "trace_id"
+	KeySpanID    = "span_id"
+	KeyError     = "error"
+)
+
+// Setup installs a slog logger as default, output lewat redaction dan request/trace ID dari context
+// log.Printf yang tersisa ikut diteruskan ke handler ini (level INFO)
+// level boleh *slog.LevelVar supaya LOG_LEVEL bisa diubah saat reload
+func Setup(w io.Writer, format string, level slog.Leveler) *slog.Logger {
+	options := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	if format == FormatText {
+		handler = slog.NewTextHandler(w, options)
+	} else {
+		handler = slog.NewJSONHandler(w, options)
+	}
+
+	logger := slog.New(&redactHandler{next: handler})
+	slog.SetDefault(logger)
+	return logger
+}
+
+// Err is the attribute for an error, supaya key-nya seragam di semua log
+func Err(err error) slog.Attr {
+	return slog.Any(KeyError, err)
+}
+
+// Duration is the attribute for a latency in milliseconds, lebih mudah dibaca dan di-query daripada nanodetik
+func Duration(d time.Duration) slog.Attr {
+	return slog.Float64("duration_ms", float64(d.Microseconds())/1000)
+}
+
+// requestIDKey is the context key of the request ID
+type requestIDKey struct{}
+
+// WithRequestID returns a context carrying the request ID, dipasang middleware RequestID di UserContext
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID of ctx, string kosong di luar request (background job)
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
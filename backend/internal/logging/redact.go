@@ -0,0 +1,172 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Pengganti nilai yang disamarkan
+const (
+	redacted      = "[redacted]"
+	redactedEmail = "[email]"
+	redactedPhone = "[phone]"
+)
+
+// sensitiveKeys are attribute keys whose value is always redacted, juga dengan prefix (admin_email, smtp_password)
+var sensitiveKeys = []string{"email", "phone", "password", "token", "secret"}
+
+// personNameKeys always hold a person's name, apa pun entity-nya (contoh user_name di response attendance)
+var personNameKeys = []string{"employee_name", "user_name", "manager_name", "approver_name", "requested_by_name", "admin_name"}
+
+// personEntities are entities whose plain "name" is a person's name: entity_type audit log atau key object nested
+// "name" di entity lain (department, team, site, hari libur, jenis cuti) bukan data pribadi dan tetap terbaca
+var personEntities = map[string]bool{
+	"employee":     true,
+	"user":         true,
+	"manager":      true,
+	"approver":     true,
+	"requested_by": true,
+}
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	// Nomor dengan kode negara (+62 812-3456-7890) atau awalan 0 (0812 3456 7890)
+	phonePattern = regexp.MustCompile(`(?:\+|\b0)\d[\d .()-]{6,}\d`)
+)
+
+// minPhoneDigits menghindari tanggal dan angka pendek ikut dianggap nomor telepon
+const minPhoneDigits = 9
+
+// redactHandler masks names, emails and phone numbers before the record reaches the output
+// Nama hanya bisa dikenali dari key attribute; email dan nomor telepon juga dicari di message,
+// string dan error (contoh: error unique constraint dari database yang memuat email)
+type redactHandler struct {
+	next slog.Handler
+}
+
+func (h *redactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *redactHandler) Handle(ctx context.Context, record slog.Record) error {
+	out := slog.NewRecord(record.Time, record.Level, Redact(record.Message), record.PC)
+	hasRequestID := false
+	record.Attrs(func(attr slog.Attr) bool {
+		hasRequestID = hasRequestID || attr.Key == KeyRequestID
+		out.AddAttrs(redactAttr(attr))
+		return true
+	})
+	if id := RequestID(ctx); id != "" && !hasRequestID {
+		out.AddAttrs(slog.String(KeyRequestID, id))
+	}
+	// Trace ID supaya log bisa dibuka langsung dari trace di Jaeger / Tempo (dan sebaliknya)
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		out.AddAttrs(slog.String(KeyTraceID, span.TraceID().String()), slog.String(KeySpanID, span.SpanID().String()))
+	}
+	return h.next.Handle(ctx, out)
+}
+
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	masked := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		masked[i] = redactAttr(attr)
+	}
+	return &redactHandler{next: h.next.WithAttrs(masked)}
+}
+
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{next: h.next.WithGroup(name)}
+}
+
+// redactAttr masks one attribute, group diproses rekursif
+func redactAttr(attr slog.Attr) slog.Attr {
+	attr.Value = attr.Value.Resolve()
+	// Attribute log tidak punya entity, jadi "name" selalu dianggap nama orang
+	if isSensitive("", attr.Key) {
+		return slog.String(attr.Key, redacted)
+	}
+
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		attr.Value = slog.StringValue(Redact(attr.Value.String()))
+	case slog.KindGroup:
+		group := attr.Value.Group()
+		masked := make([]slog.Attr, len(group))
+		for i, member := range group {
+			masked[i] = redactAttr(member)
+		}
+		attr.Value = slog.GroupValue(masked...)
+	case slog.KindAny:
+		if err, ok := attr.Value.Any().(error); ok && err != nil {
+			attr.Value = slog.StringValue(Redact(err.Error()))
+		}
+	}
+	return attr
+}
+
+// isSensitive reports whether the key of an entity names personal data or a secret
+// Entity kosong = tidak diketahui, "name" diperlakukan sebagai nama orang
+func isSensitive(entity, key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if key == sensitive || strings.HasSuffix(key, "_"+sensitive) {
+			return true
+		}
+	}
+	if key == "name" {
+		return entity == "" || personEntities[strings.ToLower(entity)]
+	}
+	for _, personName := range personNameKeys {
+		if key == personName || strings.HasSuffix(key, "_"+personName) {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact replaces emails and phone numbers in free text, dipakai juga untuk pesan error di span tracing
+func Redact(text string) string {
+	text = emailPattern.ReplaceAllString(text, redactedEmail)
+	return phonePattern.ReplaceAllStringFunc(text, func(match string) string {
+		digits := 0
+		for _, r := range match {
+			if r >= '0' && r <= '9' {
+				digits++
+			}
+		}
+		if digits < minPhoneDigits {
+			return match
+		}
+		return redactedPhone
+	})
+}
+
+// RedactValue masks personal data in a decoded JSON value (map, slice, string), dipakai untuk diff audit log
+// entity adalah entity_type pemilik key; object nested memakai key-nya sebagai entity (contoh "user" di attendance)
+// Nilai dengan key sensitif diganti seluruhnya, email dan nomor telepon di string lain ikut disamarkan
+func RedactValue(entity, key string, value interface{}) interface{} {
+	if isSensitive(entity, key) && value != nil && value != "" {
+		return redacted
+	}
+	switch v := value.(type) {
+	case string:
+		return Redact(v)
+	case map[string]interface{}:
+		masked := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			masked[k] = RedactValue(key, k, item)
+		}
+		return masked
+	case []interface{}:
+		masked := make([]interface{}, len(v))
+		for i, item := range v {
+			masked[i] = RedactValue(entity, key, item)
+		}
+		return masked
+	}
+	return value
+}
@@ -0,0 +1,182 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"gorm.io/gorm"
+)
+
+// namespace prefixes every metric name
+const namespace = "attendance"
+
+// Batas jumlah nilai label yang berasal dari data (site) atau input client (device)
+const (
+	maxSiteLabels   = 100
+	maxDeviceLabels = 200
+)
+
+// Label values for requests and check-ins without a matched route, site or device
+const (
+	LabelUnmatched = "unmatched"
+	LabelNone      = "none"
+	LabelOther     = "other"
+)
+
+// Metrics holds the Prometheus collectors of the API
+// Registry sendiri (bukan default global), jadi server, CLI dan integration suite tidak saling bentrok
+// Method pada receiver nil tidak melakukan apa-apa, untuk CLI yang tidak butuh metrics
+type Metrics struct {
+	registry   *prometheus.Registry
+	requests   *prometheus.CounterVec
+	latency    *prometheus.HistogramVec
+	uploads    *prometheus.HistogramVec
+	checkIns   *prometheus.CounterVec
+	similarity prometheus.Histogram
+	extraction prometheus.Histogram
+	limited    *prometheus.CounterVec
+	sites      *labelSet
+	devices    *labelSet
+}
+
+// New creates the collectors and registers them with DB pool, Go runtime and process metrics
+func New(db *gorm.DB) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by method, route pattern and status code.",
+		}, []string{"method", "route", "status"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by method and route pattern.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		uploads: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "upload_size_bytes",
+			Help:      "Size of multipart uploads (face photos, selfies, attachments, imports) by route pattern.",
+			Buckets:   prometheus.ExponentialBuckets(16*1024, 2, 13), // 16 KB - 64 MB
+		}, []string{"route"}),
+		checkIns: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "checkins_total",
+			Help:      "Check-ins by status, method, site and device.",
+		}, []string{"status", "method", "site", "device"}),
+		similarity: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "face_similarity_score",
+			Help:      "Similarity score of face check-ins (0-1).",
+			Buckets:   prometheus.LinearBuckets(0.1, 0.1, 10),
+		}),
+		extraction: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "face_descriptor_extraction_seconds",
+			Help:      "Time to compute a face descriptor from an image.",
+			Buckets:   prometheus.ExponentialBuckets(0.005, 2, 10), // 5 ms - 2.5 s
+		}),
+		limited: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rate_limited_total",
+			Help:      "Requests rejected by rate limiting, by rule.",
+		}, []string{"rule"}),
+		sites:   newLabelSet(maxSiteLabels),
+		devices: newLabelSet(maxDeviceLabels),
+	}
+
+	m.registry.MustRegister(m.requests, m.latency, m.uploads, m.checkIns, m.similarity, m.extraction, m.limited,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	// Pool koneksi dari sqlDB.Stats(): open, in use, idle, wait count/duration
+	if sqlDB, err := db.DB(); err == nil {
+		m.registry.MustRegister(collectors.NewDBStatsCollector(sqlDB, db.Dialector.Name()))
+	}
+	return m
+}
+
+// Handler serves the metrics in Prometheus text format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ObserveRequest records one HTTP request
+// route harus pola route (contoh /api/employees/:id), bukan path asli, supaya label tetap terbatas
+func (m *Metrics) ObserveRequest(method, route string, status int, duration time.Duration, uploadBytes int) {
+	if m == nil {
+		return
+	}
+	m.requests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	m.latency.WithLabelValues(method, route).Observe(duration.Seconds())
+	if uploadBytes > 0 {
+		m.uploads.WithLabelValues(route).Observe(float64(uploadBytes))
+	}
+}
+
+// ObserveCheckIn records a check-in outcome, similarity hanya untuk check-in wajah
+func (m *Metrics) ObserveCheckIn(status, method string, siteID *uint, deviceID string, similarity *float64) {
+	if m == nil {
+		return
+	}
+	site := LabelNone
+	if siteID != nil {
+		site = m.sites.value(strconv.FormatUint(uint64(*siteID), 10))
+	}
+	device := LabelNone
+	if deviceID != "" {
+		device = m.devices.value(deviceID)
+	}
+	m.checkIns.WithLabelValues(status, method, site, device).Inc()
+	if similarity != nil {
+		m.similarity.Observe(*similarity)
+	}
+}
+
+// ObserveExtraction records how long a face descriptor took to compute
+func (m *Metrics) ObserveExtraction(duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.extraction.Observe(duration.Seconds())
+}
+
+// ObserveRateLimited records a request rejected by a rate limit rule
+func (m *Metrics) ObserveRateLimited(rule string) {
+	if m == nil {
+		return
+	}
+	m.limited.WithLabelValues(rule).Inc()
+}
+
+// labelSet caps the distinct values of a label, nilai baru setelah limit tercapai menjadi "other"
+type labelSet struct {
+	mu    sync.Mutex
+	limit int
+	seen  map[string]bool
+}
+
+// newLabelSet creates a labelSet with room for limit values
+func newLabelSet(limit int) *labelSet {
+	return &labelSet{limit: limit, seen: make(map[string]bool)}
+}
+
+// value returns v while there is room, LabelOther afterwards
+func (s *labelSet) value(v string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.seen[v] {
+		return v
+	}
+	if len(s.seen) >= s.limit {
+		return LabelOther
+	}
+	s.seen[v] = true
+	return v
+}
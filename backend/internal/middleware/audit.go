@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"attendance-system/internal/logging"
+	"attendance-system/internal/models"
+	"attendance-system/internal/services"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Locals key untuk perubahan entity yang dilaporkan handler
+const localAuditChange = "audit_change"
+
+// auditChange is what a handler reports about the entity it touched
+type auditChange struct {
+	entityType string
+	entityID   string
+	before     interface{}
+	after      interface{}
+}
+
+// AuditEntity records which entity a handler changed and its state before/after
+// Dipanggil handler setelah perubahan berhasil; before nil = create, after nil = delete
+func AuditEntity(c *fiber.Ctx, entityType string, entityID interface{}, before, after interface{}) {
+	c.Locals(localAuditChange, &auditChange{
+		entityType: entityType,
+		entityID:   fmt.Sprint(entityID),
+		before:     before,
+		after:      after,
+	})
+}
+
+// Audit appends an audit log entry for every mutating request (POST/PUT/PATCH/DELETE)
+// Entry ditulis setelah handler selesai, jadi actor dari RequireAuth dan status response ikut tercatat
+func Audit(auditService *services.AuditService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		switch c.Method() {
+		case fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch, fiber.MethodDelete:
+		default:
+			return c.Next()
+		}
+
+		err := c.Next()
+		recordAudit(c, auditService, c.Route().Path, err)
+		return err
+	}
+}
+
+// AuditRead appends an audit log entry for successful reads of sensitive resources
+// Dipakai untuk foto wajah/selfie (biometrik), export data karyawan dan export audit log
+func AuditRead(auditService *services.AuditService, entityType string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// Route dicatat sebelum Next, setelahnya c.Route() sudah menunjuk handler berikutnya (misalnya Static)
+		route := c.Route().Path
+		err := c.Next()
+		if err == nil && c.Response().StatusCode() < fiber.StatusBadRequest {
+			if _, ok := c.Locals(localAuditChange).(*auditChange); !ok {
+				AuditEntity(c, entityType, path.Base(c.Path()), nil, nil)
+			}
+			recordAudit(c, auditService, route, nil)
+		}
+		return err
+	}
+}
+
+// recordAudit builds and appends the entry, gagal tulis hanya di-log karena response sudah jadi
+func recordAudit(c *fiber.Ctx, auditService *services.AuditService, route string, handlerErr error) {
+	status := c.Response().StatusCode()
+	if handlerErr != nil {
+		status = fiber.StatusInternalServerError
+		var fiberErr *fiber.Error
+		if errors.As(handlerErr, &fiberErr) {
+			status = fiberErr.Code
+		}
+	}
+
+	entry := models.AuditLog{
+		ActorRole:  CurrentRole(c),
+		Action:     c.Method() + " " + route,
+		Method:     c.Method(),
+		Path:       auditPath(c),
+		StatusCode: status,
+		IP:         c.IP(),
+		UserAgent:  c.Get(fiber.HeaderUserAgent),
+	}
+	if id := CurrentUserID(c); id != 0 {
+		entry.ActorID = &id
+	}
+	if requestID, ok := c.Locals(LocalRequestID).(string); ok {
+		entry.RequestID = requestID
+	}
+
+	if change, ok := c.Locals(localAuditChange).(*auditChange); ok {
+		entry.EntityType = change.entityType
+		entry.EntityID = change.entityID
+		entry.Diff = services.AuditDiff(change.entityType, change.before, change.after)
+	} else if id := c.Params("id"); id != "" {
+		// Handler tidak melapor: ambil entity dari route, contoh /api/leave/requests/:id
+		entry.EntityType = auditEntityFromRoute(route)
+		entry.EntityID = id
+	}
+
+	if err := auditService.Record(&entry); err != nil {
+		slog.ErrorContext(c.UserContext(), "Error writing audit log", "action", entry.Action, logging.Err(err))
+	}
+}
+
+// auditPath returns the request path and query without the signature of a signed image URL
+// URL dengan sig yang masih berlaku bisa dipakai ulang oleh siapa pun yang membaca audit log
+func auditPath(c *fiber.Ctx) string {
+	query, err := url.ParseQuery(string(c.Request().URI().QueryString()))
+	if err != nil {
+		return c.Path()
+	}
+	query.Del("sig")
+	if len(query) == 0 {
+		return c.Path()
+	}
+	return c.Path() + "?" + query.Encode()
+}
+
+// auditEntityFromRoute returns the resource segment before ":id"
+func auditEntityFromRoute(route string) string {
+	segments := strings.Split(strings.Trim(route, "/"), "/")
+	for i, segment := range segments {
+		if segment == ":id" && i > 0 {
+			return segments[i-1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"attendance-system/internal/services"
+	"attendance-system/internal/utils"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Locals keys untuk identitas user yang sudah login
+const (
+	LocalUserID = "auth_user_id"
+	LocalRole   = "auth_role"
+)
+
+// RequireAuth rejects requests without a valid "Authorization: Bearer <token>" header
+func RequireAuth(authService *services.AuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		header := c.Get(fiber.HeaderAuthorization)
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || token == "" {
+			return utils.UnauthorizedResponse(c, "Authentication required")
+		}
+
+		claims, err := authService.VerifyToken(token)
+		if err != nil {
+			return utils.UnauthorizedResponse(c, err.Error())
+		}
+
+		c.Locals(LocalUserID, claims.UserID)
+		c.Locals(LocalRole, claims.Role)
+		return c.Next()
+	}
+}
+
+// OptionalAuth sets the user identity when a valid token is present, tanpa menolak request anonim
+// Dipasang global supaya route terbuka (kiosk) tetap tercatat dengan actor di audit log
+func OptionalAuth(authService *services.AuthService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		token, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+		if ok && token != "" {
+			if claims, err := authService.VerifyToken(token); err == nil {
+				c.Locals(LocalUserID, claims.UserID)
+				c.Locals(LocalRole, claims.Role)
+			}
+		}
+		return c.Next()
+	}
+}
+
+// RequireRole allows only users with one of the given roles, harus dipasang setelah RequireAuth
+func RequireRole(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role := CurrentRole(c)
+		for _, r := range roles {
+			if role == r {
+				return c.Next()
+			}
+		}
+		return utils.ForbiddenResponse(c, "Insufficient permissions")
+	}
+}
+
+// CurrentUserID returns the authenticated user ID, 0 kalau belum login
+func CurrentUserID(c *fiber.Ctx) uint {
+	id, _ := c.Locals(LocalUserID).(uint)
+	return id
+}
+
+// CurrentRole returns the authenticated user role
+func CurrentRole(c *fiber.Ctx) string {
+	role, _ := c.Locals(LocalRole).(string)
+	return role
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"attendance-system/internal/config"
+	"attendance-system/internal/models"
+	"attendance-system/internal/services"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm/logger"
+)
+
+func TestRequireAuthAndRole(t *testing.T) {
+	db, err := config.InitDatabase(&config.DatabaseConfig{
+		Driver:      config.DriverSQLite,
+		Path:        filepath.Join(t.TempDir(), "test.db"),
+		AutoMigrate: true,
+		LogLevel:    logger.Silent,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth := services.NewAuthService(db, []byte("test-secret-that-is-at-least-32-bytes"), time.Hour)
+
+	tokens := make(map[string]string)
+	for _, role := range []string{models.RoleEmployee, models.RoleHR, models.RoleAdmin} {
+		user := &models.User{Name: role, Email: role + "@example.com", Role: role}
+		if err := db.Create(user).Error; err != nil {
+			t.Fatal(err)
+		}
+		if tokens[role], err = auth.IssueToken(user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expired, err := services.NewAuthService(db, []byte("test-secret-that-is-at-least-32-bytes"), -time.Minute).
+		IssueToken(&models.User{ID: 1, Role: models.RoleEmployee})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := fiber.New()
+	whoami := func(c *fiber.Ctx) error {
+		return c.SendString(CurrentRole(c))
+	}
+	app.Get("/me", RequireAuth(auth), whoami)
+	app.Get("/hr", RequireAuth(auth), RequireRole(models.RoleHR, models.RoleAdmin), whoami)
+	app.Get("/open", OptionalAuth(auth), whoami)
+
+	tests := []struct {
+		name          string
+		path          string
+		authorization string
+		wantStatus    int
+		wantRole      string
+	}{
+		{"valid token", "/me", "Bearer " + tokens[models.RoleEmployee], http.StatusOK, models.RoleEmployee},
+		{"no header", "/me", "", http.StatusUnauthorized, ""},
+		{"not a bearer token", "/me", "Basic " + tokens[models.RoleEmployee], http.StatusUnauthorized, ""},
+		{"expired token", "/me", "Bearer " + expired, http.StatusUnauthorized, ""},
+		{"employee on hr route", "/hr", "Bearer " + tokens[models.RoleEmployee], http.StatusForbidden, ""},
+		{"hr on hr route", "/hr", "Bearer " + tokens[models.RoleHR], http.StatusOK, models.RoleHR},
+		{"admin on hr route", "/hr", "Bearer " + tokens[models.RoleAdmin], http.StatusOK, models.RoleAdmin},
+		{"anonymous on hr route", "/hr", "", http.StatusUnauthorized, ""},
+		{"optional auth with token", "/open", "Bearer " + tokens[models.RoleHR], http.StatusOK, models.RoleHR},
+		{"optional auth ignores a bad token", "/open", "Bearer " + expired, http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.authorization != "" {
+				req.Header.Set(fiber.HeaderAuthorization, tt.authorization)
+			}
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(body); got != tt.wantRole {
+				t.Fatalf("role %q, want %q", got, tt.wantRole)
+			}
+		})
+	}
+}
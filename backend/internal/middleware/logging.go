@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"attendance-system/internal/logging"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	fiberutils "github.com/gofiber/fiber/v2/utils"
+)
+
+// LocalRequestID is the Locals key of the request ID, dipakai audit log dan response
+const LocalRequestID = "requestid"
+
+// maxRequestIDLength membatasi X-Request-ID dari client (gateway / load balancer)
+const maxRequestIDLength = 64
+
+// RequestID assigns every request an ID, X-Request-ID dari client dipakai kalau formatnya aman
+// ID dikirim balik di header response dan disimpan di UserContext supaya ikut di setiap log line
+func RequestID() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// Di-copy, string dari fasthttp dipakai ulang untuk request berikutnya
+		id := strings.Clone(c.Get(fiber.HeaderXRequestID))
+		if !validRequestID(id) {
+			id = fiberutils.UUIDv4()
+		}
+
+		c.Set(fiber.HeaderXRequestID, id)
+		c.Locals(LocalRequestID, id)
+		c.SetUserContext(logging.WithRequestID(c.UserContext(), id))
+		return c.Next()
+	}
+}
+
+// validRequestID accepts IDs made of letters, digits, '-', '_' and '.'
+// Nilai lain (newline, spasi, terlalu panjang) bisa merusak log, jadi diganti ID baru
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// RequestLogger writes one structured log line per request, menggantikan middleware logger Fiber
+// Path dicatat tanpa query string (filter pencarian bisa berisi nama karyawan)
+func RequestLogger() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		started := time.Now()
+		own := c.Route()
+		err := c.Next()
+
+		status := responseStatus(c, err)
+		level := slog.LevelInfo
+		if status >= fiber.StatusInternalServerError {
+			level = slog.LevelError
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Method()),
+			slog.String("path", c.Path()),
+			slog.Int("status", status),
+			logging.Duration(time.Since(started)),
+			slog.String("ip", c.IP()),
+		}
+		if route, ok := routePattern(c, own); ok {
+			attrs = append(attrs, slog.String("route", route))
+		}
+		if id := CurrentUserID(c); id != 0 {
+			attrs = append(attrs, slog.Uint64("user_id", uint64(id)))
+		}
+		if err != nil {
+			attrs = append(attrs, logging.Err(err))
+		}
+		slog.LogAttrs(c.UserContext(), level, "Request", attrs...)
+		return err
+	}
+}
+
+// responseStatus returns the status code the client receives
+// Error dari handler baru diubah jadi response oleh ErrorHandler setelah middleware selesai
+func responseStatus(c *fiber.Ctx, err error) int {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return fiberErr.Code
+	}
+	if err != nil {
+		return fiber.StatusInternalServerError
+	}
+	return c.Response().StatusCode()
+}
+
+// routePattern returns the matched route pattern (contoh /api/employees/:id) after c.Next
+// Tanpa route yang cocok, request berakhir di 404 handler (Use "/", sama seperti middleware global)
+func routePattern(c *fiber.Ctx, own *fiber.Route) (string, bool) {
+	route := c.Route().Path
+	return route, route != own.Path
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"attendance-system/internal/metrics"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Metrics records count, latency and upload size of every request per route pattern
+func Metrics(m *metrics.Metrics) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		started := time.Now()
+		own := c.Route()
+		err := c.Next()
+
+		// Label memakai pola route, path asli (ID, scan URL acak) akan membuat label tak terbatas
+		status := responseStatus(c, err)
+		route, ok := routePattern(c, own)
+		if !ok {
+			route = metrics.LabelUnmatched
+		}
+
+		upload := 0
+		if strings.HasPrefix(string(c.Request().Header.ContentType()), fiber.MIMEMultipartForm) {
+			upload = len(c.Request().Body())
+		}
+		// Method di-copy, string dari fasthttp dipakai ulang untuk request berikutnya
+		m.ObserveRequest(strings.Clone(c.Method()), route, status, time.Since(started), upload)
+		return err
+	}
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"attendance-system/internal/logging"
+	"attendance-system/internal/metrics"
+	"attendance-system/internal/ratelimit"
+	"attendance-system/internal/utils"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RateLimit rejects the request with 429 and Retry-After when one of its buckets is empty
+// keys menentukan bucket request (IP, device, user target); limiter nil = rate limiting mati
+// Kalau store error request ditolak 503, kecuali RATE_LIMIT_FAIL_OPEN
+func RateLimit(limiter *ratelimit.Limiter, m *metrics.Metrics, keys func(c *fiber.Ctx) []ratelimit.Key) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if limiter == nil {
+			return c.Next()
+		}
+
+		decision, err := limiter.Allow(c.UserContext(), keys(c)...)
+		if err != nil {
+			if limiter.FailOpen() {
+				slog.ErrorContext(c.UserContext(), "Rate limit store unavailable, request allowed", logging.Err(err))
+				return c.Next()
+			}
+			// Tanpa limit, foto bisa dicoba terus sampai lolos threshold, jadi default-nya ditolak
+			slog.ErrorContext(c.UserContext(), "Rate limit store unavailable, request rejected", logging.Err(err))
+			return utils.ErrorResponse(c, fiber.StatusServiceUnavailable, "Service temporarily unavailable, try again later")
+		}
+		if decision.Allowed {
+			return c.Next()
+		}
+
+		retryAfter := int(math.Ceil(decision.RetryAfter.Seconds()))
+		if retryAfter < 1 {
+			retryAfter = 1
+		}
+		m.ObserveRateLimited(decision.Rule)
+		slog.WarnContext(c.UserContext(), "Rate limited", "rule", decision.Rule, "ip", c.IP(), "retry_after_s", retryAfter)
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+		return utils.ErrorResponse(c, fiber.StatusTooManyRequests, fmt.Sprintf("Too many requests, try again in %d seconds", retryAfter))
+	}
+}
+
+// CheckInKeys returns the buckets of a face check-in: IP, device, karyawan target, dan verifikasi gagal
+// String di-copy karena dipakai sebagai key map di MemoryStore (buffer fasthttp dipakai ulang)
+func CheckInKeys(c *fiber.Ctx) []ratelimit.Key {
+	// user_id dinormalisasi ("007" = "7"), kalau tidak penyerang bisa memakai bucket baru untuk karyawan yang sama
+	// user_id yang invalid tidak punya bucket, handler menolaknya dengan 400
+	userID := ""
+	if id, err := strconv.ParseUint(strings.TrimSpace(c.FormValue("user_id")), 10, 64); err == nil && id != 0 {
+		userID = strconv.FormatUint(id, 10)
+	}
+	return []ratelimit.Key{
+		{Rule: ratelimit.RuleCheckInIP, ID: strings.Clone(c.IP())},
+		{Rule: ratelimit.RuleCheckInDevice, ID: strings.Clone(strings.TrimSpace(c.FormValue("device_id")))},
+		{Rule: ratelimit.RuleCheckInUser, ID: userID},
+		// Token verifikasi gagal diambil handler, di sini hanya dicek masih ada sisa
+		{Rule: ratelimit.RuleCheckInFailed, ID: userID, Peek: true},
+	}
+}
+
+// RegistrationKeys returns the buckets of an employee registration: IP dan user yang login
+func RegistrationKeys(c *fiber.Ctx) []ratelimit.Key {
+	keys := []ratelimit.Key{{Rule: ratelimit.RuleRegisterIP, ID: strings.Clone(c.IP())}}
+	if id := CurrentUserID(c); id != 0 {
+		keys = append(keys, ratelimit.Key{Rule: ratelimit.RuleRegisterUser, ID: strconv.FormatUint(uint64(id), 10)})
+	}
+	return keys
+}
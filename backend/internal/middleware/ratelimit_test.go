@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"attendance-system/internal/ratelimit"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// flakyStore wraps a MemoryStore, selama down semua Take error seperti database yang mati
+type flakyStore struct {
+	*ratelimit.MemoryStore
+	down bool
+}
+
+func (s *flakyStore) Take(ctx context.Context, key string, limit ratelimit.Limit, n int, now time.Time) (ratelimit.Result, error) {
+	if s.down {
+		return ratelimit.Result{}, errors.New("database is locked")
+	}
+	return s.MemoryStore.Take(ctx, key, limit, n, now)
+}
+
+func TestRateLimitStoreErrors(t *testing.T) {
+	store := &flakyStore{MemoryStore: ratelimit.NewMemoryStore()}
+	limiter := ratelimit.NewLimiter(store, ratelimit.Rules{
+		ratelimit.RuleCheckInUser: {Requests: 1, Per: time.Minute},
+	})
+
+	app := fiber.New()
+	app.Post("/checkin", RateLimit(limiter, nil, CheckInKeys), func(c *fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+	checkIn := func(userID string) *http.Response {
+		t.Helper()
+		form := url.Values{"user_id": {userID}, "device_id": {"kiosk-1"}}
+		req := httptest.NewRequest(http.MethodPost, "/checkin", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	steps := []struct {
+		name       string
+		down       bool
+		failOpen   bool
+		userID     string
+		wantStatus int
+	}{
+		{"store up", false, false, "2", fiber.StatusOK},
+		{"bucket empty", false, false, "2", fiber.StatusTooManyRequests},
+		// Default RATE_LIMIT_FAIL_OPEN=false: ditolak walaupun bucket karyawan lain masih penuh
+		{"store down fails closed", true, false, "3", fiber.StatusServiceUnavailable},
+		{"store down, empty bucket not reachable", true, false, "2", fiber.StatusServiceUnavailable},
+		{"store down with fail open", true, true, "2", fiber.StatusOK},
+		{"store back, limit applies again", false, true, "2", fiber.StatusTooManyRequests},
+		{"store back, other employee", false, false, "3", fiber.StatusOK},
+	}
+	for _, step := range steps {
+		store.down = step.down
+		limiter.SetFailOpen(step.failOpen)
+		resp := checkIn(step.userID)
+		if resp.StatusCode != step.wantStatus {
+			t.Fatalf("%s: status %d, want %d", step.name, resp.StatusCode, step.wantStatus)
+		}
+		if step.wantStatus == fiber.StatusTooManyRequests && resp.Header.Get(fiber.HeaderRetryAfter) == "" {
+			t.Fatalf("%s: missing Retry-After", step.name)
+		}
+	}
+
+	// Limiter nil (RATE_LIMIT_ENABLED=false) tidak pernah menolak
+	var disabled *ratelimit.Limiter
+	if !disabled.FailOpen() {
+		t.Fatal("disabled limiter fails closed")
+	}
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"attendance-system/internal/logging"
+	"attendance-system/internal/tracing"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// headerCarrier reads and writes W3C trace headers on the fasthttp request
+type headerCarrier struct {
+	c *fiber.Ctx
+}
+
+func (h headerCarrier) Get(key string) string {
+	return strings.Clone(h.c.Get(key))
+}
+
+func (h headerCarrier) Set(key, value string) {
+	h.c.Request().Header.Set(key, value)
+}
+
+func (h headerCarrier) Keys() []string {
+	var keys []string
+	h.c.Request().Header.VisitAll(func(key, _ []byte) {
+		keys = append(keys, string(key))
+	})
+	return keys
+}
+
+// Tracing starts the server span of every request, traceparent dari client / gateway dipakai sebagai parent
+// Span disimpan di UserContext; handler, repository (GORM) dan storage membuat child span dari context itu
+func Tracing() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), headerCarrier{c})
+		// Nama sementara, diganti dengan route pattern setelah handler selesai (path bisa memuat ID)
+		method := strings.Clone(c.Method())
+		ctx, span := tracing.Tracer().Start(ctx, method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", method),
+				attribute.String("url.path", strings.Clone(c.Path())),
+			),
+		)
+		defer span.End()
+
+		own := c.Route()
+		c.SetUserContext(ctx)
+		err := c.Next()
+
+		status := responseStatus(c, err)
+		if route, ok := routePattern(c, own); ok {
+			span.SetName(method + " " + route)
+			span.SetAttributes(attribute.String("http.route", route))
+		} else {
+			span.SetName(method + " unmatched")
+		}
+		span.SetAttributes(
+			attribute.Int("http.response.status_code", status),
+			attribute.String(logging.KeyRequestID, logging.RequestID(ctx)),
+		)
+		if id := CurrentUserID(c); id != 0 {
+			span.SetAttributes(attribute.Int64("user_id", int64(id)))
+		}
+		if status >= fiber.StatusInternalServerError {
+			message := "internal server error"
+			if err != nil {
+				message = logging.Redact(err.Error())
+			}
+			span.SetStatus(codes.Error, message)
+		}
+		return err
+	}
+}
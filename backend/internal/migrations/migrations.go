@@ -0,0 +1,122 @@
+package migrations
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Satu folder per dialect, nama file: NNNN_nama.up.sql dan NNNN_nama.down.sql
+//
+//go:embed postgres/*.sql sqlite/*.sql
+var files embed.FS
+
+// Dialects lists the supported database dialects (sama dengan gorm Dialector.Name())
+var Dialects = []string{"postgres", "sqlite"}
+
+// ErrUnsupportedDialect is returned for databases without a migration folder
+var ErrUnsupportedDialect = errors.New("no migrations for this database dialect")
+
+var fileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration is one versioned schema change with its rollback
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Load returns the embedded migrations for a dialect, urut berdasarkan version
+func Load(dialect string) ([]Migration, error) {
+	return load(files, dialect)
+}
+
+func load(fsys fs.FS, dialect string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dialect)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedDialect, dialect)
+		}
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		content, err := fs.ReadFile(fsys, dialect+"/"+entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("%s: version %d is used by %q and %q", dialect, version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		// Setiap migration wajib punya pasangan down supaya rollback selalu mungkin
+		if strings.TrimSpace(migration.Up) == "" || strings.TrimSpace(migration.Down) == "" {
+			return nil, fmt.Errorf("%s: migration %04d_%s needs both .up.sql and .down.sql", dialect, migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Create writes empty up/down files for a new migration to every dialect folder under dir
+// Version = version tertinggi + 1, jadi semua dialect selalu punya nomor yang sama
+func Create(dir, name string) ([]string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+	if !regexp.MustCompile(`^[a-z0-9_]+$`).MatchString(name) {
+		return nil, fmt.Errorf("invalid migration name %q (use letters, digits and underscores)", name)
+	}
+
+	var latest int64
+	for _, dialect := range Dialects {
+		migrations, err := load(os.DirFS(dir), dialect)
+		if err != nil {
+			return nil, err
+		}
+		if n := len(migrations); n > 0 && migrations[n-1].Version > latest {
+			latest = migrations[n-1].Version
+		}
+	}
+
+	var created []string
+	for _, dialect := range Dialects {
+		for _, direction := range []string{"up", "down"} {
+			path := filepath.Join(dir, dialect, fmt.Sprintf("%04d_%s.%s.sql", latest+1, name, direction))
+			content := fmt.Sprintf("-- %04d_%s (%s, %s)\n", latest+1, name, dialect, direction)
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				return created, err
+			}
+			created = append(created, path)
+		}
+	}
+	return created, nil
+}
@@ -0,0 +1,219 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// advisoryLockKey is the PostgreSQL advisory lock held while migrating
+// Angka bebas, cukup unik di database ini ("attendance" dalam hex)
+const advisoryLockKey int64 = 0x617474656e64
+
+// ErrPendingMigrations is returned by Check when the schema is behind the binary
+var ErrPendingMigrations = errors.New("database schema is not up to date")
+
+// SchemaMigration records an applied migration in schema_migrations
+type SchemaMigration struct {
+	Version   int64     `gorm:"primaryKey;autoIncrement:false" json:"version"`
+	Name      string    `gorm:"type:varchar(255);not null" json:"name"`
+	AppliedAt time.Time `gorm:"not null" json:"applied_at"`
+}
+
+// TableName specifies the table name
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Status is one migration with its applied state
+type Status struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+	// Missing: tercatat di schema_migrations tapi tidak ada di binary ini (binary lebih lama dari database)
+	Missing bool `json:"missing,omitempty"`
+}
+
+// Migrator applies and rolls back the embedded migrations
+type Migrator struct {
+	db         *gorm.DB
+	dialect    string
+	migrations []Migration
+}
+
+// New creates a migrator for the dialect of db (postgres / sqlite)
+func New(db *gorm.DB) (*Migrator, error) {
+	dialect := db.Dialector.Name()
+	migrations, err := Load(dialect)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, dialect: dialect, migrations: migrations}, nil
+}
+
+// Up applies pending migrations in order, steps <= 0 berarti semua
+// Setiap migration jalan di transaksi sendiri bersama baris schema_migrations-nya
+func (m *Migrator) Up(steps int) ([]Migration, error) {
+	var applied []Migration
+	err := m.locked(func(tx *gorm.DB) error {
+		done, err := m.applied(tx, true)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			if _, ok := done[migration.Version]; ok {
+				continue
+			}
+			if steps > 0 && len(applied) == steps {
+				break
+			}
+			err := tx.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(migration.Up).Error; err != nil {
+					return err
+				}
+				return tx.Create(&SchemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %04d_%s: %w", migration.Version, migration.Name, err)
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down rolls back the latest applied migrations, steps <= 0 dianggap 1
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	if steps <= 0 {
+		steps = 1
+	}
+
+	var rolledBack []Migration
+	err := m.locked(func(tx *gorm.DB) error {
+		done, err := m.applied(tx, true)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0 && len(rolledBack) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := done[migration.Version]; !ok {
+				continue
+			}
+			err := tx.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(migration.Down).Error; err != nil {
+					return err
+				}
+				return tx.Delete(&SchemaMigration{}, migration.Version).Error
+			})
+			if err != nil {
+				return fmt.Errorf("rollback %04d_%s: %w", migration.Version, migration.Name, err)
+			}
+			rolledBack = append(rolledBack, migration)
+		}
+		return nil
+	})
+	return rolledBack, err
+}
+
+// Status lists every known migration and whether it has been applied
+func (m *Migrator) Status() ([]Status, error) {
+	done, err := m.applied(m.db, false)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if record, ok := done[migration.Version]; ok {
+			appliedAt := record.AppliedAt
+			status.AppliedAt = &appliedAt
+			delete(done, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, record := range done {
+		appliedAt := record.AppliedAt
+		statuses = append(statuses, Status{Version: record.Version, Name: record.Name, AppliedAt: &appliedAt, Missing: true})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+// Check returns ErrPendingMigrations when any embedded migration has not been applied
+// Migration yang hanya ada di database (binary lama saat rollback deploy) tidak dianggap error
+func (m *Migrator) Check() error {
+	statuses, err := m.Status()
+	if err != nil {
+		return err
+	}
+	pending := 0
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending++
+		}
+	}
+	if pending > 0 {
+		return fmt.Errorf("%w: %d pending migration(s), run `go run ./cmd/migrate up`", ErrPendingMigrations, pending)
+	}
+	return nil
+}
+
+// applied returns the schema_migrations rows keyed by version
+// create=false dipakai status/check supaya read-only: tabel belum ada berarti belum ada migration
+func (m *Migrator) applied(db *gorm.DB, create bool) (map[int64]SchemaMigration, error) {
+	if create {
+		if err := db.Exec(m.createTable()).Error; err != nil {
+			return nil, fmt.Errorf("failed to create schema_migrations: %w", err)
+		}
+	} else if !db.Migrator().HasTable(&SchemaMigration{}) {
+		return map[int64]SchemaMigration{}, nil
+	}
+	var records []SchemaMigration
+	if err := db.Order("version").Find(&records).Error; err != nil {
+		return nil, err
+	}
+	done := make(map[int64]SchemaMigration, len(records))
+	for _, record := range records {
+		done[record.Version] = record
+	}
+	return done, nil
+}
+
+// createTable returns the DDL for schema_migrations, ditulis manual supaya tidak bergantung AutoMigrate
+func (m *Migrator) createTable() string {
+	if m.dialect == "postgres" {
+		return `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version bigint PRIMARY KEY,
+	name varchar(255) NOT NULL,
+	applied_at timestamptz NOT NULL
+)`
+	}
+	return `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version integer PRIMARY KEY,
+	name varchar(255) NOT NULL,
+	applied_at datetime NOT NULL
+)`
+}
+
+// locked runs fn while holding the migration lock, supaya beberapa instance yang start bersamaan tidak balapan
+func (m *Migrator) locked(fn func(tx *gorm.DB) error) error {
+	if m.dialect != "postgres" {
+		// SQLite tidak punya advisory lock: satu transaksi (BEGIN IMMEDIATE) mengunci file database sampai commit,
+		// migration di dalamnya jadi savepoint
+		return m.db.Transaction(fn)
+	}
+
+	// Advisory lock milik session, jadi lock, migration dan unlock harus di koneksi yang sama
+	return m.db.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", advisoryLockKey).Error; err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		defer conn.Exec("SELECT pg_advisory_unlock(?)", advisoryLockKey)
+		return fn(conn)
+	})
+}
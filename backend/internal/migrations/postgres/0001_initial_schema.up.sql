@@ -0,0 +1,523 @@
+-- Skema awal, sama dengan hasil AutoMigrate sebelum migration berversi dipakai.
+-- IF NOT EXISTS supaya database lama (dibuat AutoMigrate) bisa langsung diadopsi dengan `migrate up`.
+-- Database lama bisa berasal dari versi mana saja, jadi setiap kolom juga ditambah dengan ADD COLUMN IF NOT EXISTS
+-- sebelum index dibuat; kolom yang sudah ada tidak disentuh.
+
+CREATE TABLE IF NOT EXISTS "users" (
+    "id" bigserial,
+    "name" text NOT NULL,
+    "email" text NOT NULL,
+    "phone" text,
+    "face_image_path" text NOT NULL,
+    "face_descriptor" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deactivated_at" timestamptz,
+    "legal_hold" boolean NOT NULL DEFAULT false,
+    "image_purged_at" timestamptz,
+    "erased_at" timestamptz,
+    "role" varchar(20) NOT NULL DEFAULT 'employee',
+    "password_hash" text,
+    PRIMARY KEY ("id")
+);
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "name" text NOT NULL;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "email" text NOT NULL;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "phone" text;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "face_image_path" text NOT NULL;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "face_descriptor" text;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "deactivated_at" timestamptz;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "legal_hold" boolean NOT NULL DEFAULT false;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "image_purged_at" timestamptz;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "erased_at" timestamptz;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "role" varchar(20) NOT NULL DEFAULT 'employee';
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "password_hash" text;
+CREATE INDEX IF NOT EXISTS "idx_users_deactivated_at" ON "users" ("deactivated_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_users_email" ON "users" ("email");
+
+CREATE TABLE IF NOT EXISTS "attendances" (
+    "id" bigserial,
+    "user_id" bigint NOT NULL,
+    "check_in_time" timestamptz NOT NULL,
+    "face_image_path" text,
+    "similarity_score" decimal,
+    "status" varchar(20) NOT NULL,
+    "site_id" bigint,
+    "device_id" varchar(100),
+    "method" varchar(20) NOT NULL DEFAULT 'face',
+    "created_at" timestamptz,
+    "legal_hold" boolean NOT NULL DEFAULT false,
+    "image_purged_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_users_attendances" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+ALTER TABLE "attendances" ADD COLUMN IF NOT EXISTS "user_id" bigint NOT NULL;
+ALTER TABLE "attendances" ADD COLUMN IF NOT EXISTS "check_in_time" timestamptz NOT NULL;
+ALTER TABLE "attendances" ADD COLUMN IF NOT EXISTS "face_image_path" text;
+ALTER TABLE "attendances" ADD COLUMN IF NOT EXISTS "similarity_score" decimal;
+ALTER TABLE "attendances" ADD COLUMN IF NOT EXISTS "status" varchar(20) NOT NULL;
+ALTER TABLE "attendances" ADD COLUMN IF NOT EXISTS "site_id" bigint;
+ALTER TABLE "attendances" ADD COLUMN IF NOT EXISTS "device_id" varchar(100);
+ALTER TABLE "attendances" ADD COLUMN IF NOT EXISTS "method" varchar(20) NOT NULL DEFAULT 'face';
+ALTER TABLE "attendances" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "attendances" ADD COLUMN IF NOT EXISTS "legal_hold" boolean NOT NULL DEFAULT false;
+ALTER TABLE "attendances" ADD COLUMN IF NOT EXISTS "image_purged_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_attendances_device_id" ON "attendances" ("device_id");
+CREATE INDEX IF NOT EXISTS "idx_attendances_site_id" ON "attendances" ("site_id");
+CREATE INDEX IF NOT EXISTS "idx_attendances_user_id" ON "attendances" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "departments" (
+    "id" bigserial,
+    "name" text NOT NULL,
+    "code" varchar(50) NOT NULL,
+    "parent_id" bigint,
+    "manager_id" bigint,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_departments_manager" FOREIGN KEY ("manager_id") REFERENCES "users"("id")
+);
+ALTER TABLE "departments" ADD COLUMN IF NOT EXISTS "name" text NOT NULL;
+ALTER TABLE "departments" ADD COLUMN IF NOT EXISTS "code" varchar(50) NOT NULL;
+ALTER TABLE "departments" ADD COLUMN IF NOT EXISTS "parent_id" bigint;
+ALTER TABLE "departments" ADD COLUMN IF NOT EXISTS "manager_id" bigint;
+ALTER TABLE "departments" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "departments" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_departments_manager_id" ON "departments" ("manager_id");
+CREATE INDEX IF NOT EXISTS "idx_departments_parent_id" ON "departments" ("parent_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_departments_code" ON "departments" ("code");
+
+CREATE TABLE IF NOT EXISTS "teams" (
+    "id" bigserial,
+    "name" text NOT NULL,
+    "department_id" bigint NOT NULL,
+    "manager_id" bigint,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_teams_manager" FOREIGN KEY ("manager_id") REFERENCES "users"("id"),
+    CONSTRAINT "fk_departments_teams" FOREIGN KEY ("department_id") REFERENCES "departments"("id")
+);
+ALTER TABLE "teams" ADD COLUMN IF NOT EXISTS "name" text NOT NULL;
+ALTER TABLE "teams" ADD COLUMN IF NOT EXISTS "department_id" bigint NOT NULL;
+ALTER TABLE "teams" ADD COLUMN IF NOT EXISTS "manager_id" bigint;
+ALTER TABLE "teams" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "teams" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_teams_manager_id" ON "teams" ("manager_id");
+CREATE INDEX IF NOT EXISTS "idx_teams_department_id" ON "teams" ("department_id");
+
+CREATE TABLE IF NOT EXISTS "work_schedules" (
+    "id" bigserial,
+    "name" text NOT NULL,
+    "start_time" varchar(5) NOT NULL,
+    "late_grace_minutes" bigint NOT NULL DEFAULT 0,
+    "work_days" varchar(20) NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+ALTER TABLE "work_schedules" ADD COLUMN IF NOT EXISTS "name" text NOT NULL;
+ALTER TABLE "work_schedules" ADD COLUMN IF NOT EXISTS "start_time" varchar(5) NOT NULL;
+ALTER TABLE "work_schedules" ADD COLUMN IF NOT EXISTS "late_grace_minutes" bigint NOT NULL DEFAULT 0;
+ALTER TABLE "work_schedules" ADD COLUMN IF NOT EXISTS "work_days" varchar(20) NOT NULL;
+ALTER TABLE "work_schedules" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "work_schedules" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+
+CREATE TABLE IF NOT EXISTS "sites" (
+    "id" bigserial,
+    "name" text NOT NULL,
+    "code" varchar(50) NOT NULL,
+    "address" text,
+    "timezone" varchar(64),
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "work_schedule_id" bigint,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_sites_work_schedule" FOREIGN KEY ("work_schedule_id") REFERENCES "work_schedules"("id")
+);
+ALTER TABLE "sites" ADD COLUMN IF NOT EXISTS "name" text NOT NULL;
+ALTER TABLE "sites" ADD COLUMN IF NOT EXISTS "code" varchar(50) NOT NULL;
+ALTER TABLE "sites" ADD COLUMN IF NOT EXISTS "address" text;
+ALTER TABLE "sites" ADD COLUMN IF NOT EXISTS "timezone" varchar(64);
+ALTER TABLE "sites" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "sites" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+ALTER TABLE "sites" ADD COLUMN IF NOT EXISTS "work_schedule_id" bigint;
+CREATE INDEX IF NOT EXISTS "idx_sites_work_schedule_id" ON "sites" ("work_schedule_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_sites_code" ON "sites" ("code");
+
+CREATE TABLE IF NOT EXISTS "employee_assignments" (
+    "id" bigserial,
+    "user_id" bigint NOT NULL,
+    "department_id" bigint NOT NULL,
+    "team_id" bigint,
+    "site_id" bigint,
+    "effective_from" timestamptz NOT NULL,
+    "effective_to" timestamptz,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_employee_assignments_site" FOREIGN KEY ("site_id") REFERENCES "sites"("id"),
+    CONSTRAINT "fk_users_assignments" FOREIGN KEY ("user_id") REFERENCES "users"("id"),
+    CONSTRAINT "fk_employee_assignments_department" FOREIGN KEY ("department_id") REFERENCES "departments"("id"),
+    CONSTRAINT "fk_employee_assignments_team" FOREIGN KEY ("team_id") REFERENCES "teams"("id")
+);
+ALTER TABLE "employee_assignments" ADD COLUMN IF NOT EXISTS "user_id" bigint NOT NULL;
+ALTER TABLE "employee_assignments" ADD COLUMN IF NOT EXISTS "department_id" bigint NOT NULL;
+ALTER TABLE "employee_assignments" ADD COLUMN IF NOT EXISTS "team_id" bigint;
+ALTER TABLE "employee_assignments" ADD COLUMN IF NOT EXISTS "site_id" bigint;
+ALTER TABLE "employee_assignments" ADD COLUMN IF NOT EXISTS "effective_from" timestamptz NOT NULL;
+ALTER TABLE "employee_assignments" ADD COLUMN IF NOT EXISTS "effective_to" timestamptz;
+ALTER TABLE "employee_assignments" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_employee_assignments_effective_to" ON "employee_assignments" ("effective_to");
+CREATE INDEX IF NOT EXISTS "idx_employee_assignments_effective_from" ON "employee_assignments" ("effective_from");
+CREATE INDEX IF NOT EXISTS "idx_employee_assignments_site_id" ON "employee_assignments" ("site_id");
+CREATE INDEX IF NOT EXISTS "idx_employee_assignments_team_id" ON "employee_assignments" ("team_id");
+CREATE INDEX IF NOT EXISTS "idx_employee_assignments_department_id" ON "employee_assignments" ("department_id");
+CREATE INDEX IF NOT EXISTS "idx_employee_assignments_user_id" ON "employee_assignments" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "daily_attendances" (
+    "id" bigserial,
+    "user_id" bigint NOT NULL,
+    "date" varchar(10) NOT NULL,
+    "status" varchar(20) NOT NULL,
+    "site_id" bigint,
+    "department_id" bigint,
+    "team_id" bigint,
+    "timezone" varchar(64),
+    "scheduled_start" timestamptz,
+    "first_check_in" timestamptz,
+    "attendance_id" bigint,
+    "correction_id" bigint,
+    "late_minutes" bigint,
+    "failed_attempts" bigint,
+    "leave_request_id" bigint,
+    "computed_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_daily_attendances_user" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+ALTER TABLE "daily_attendances" ADD COLUMN IF NOT EXISTS "user_id" bigint NOT NULL;
+ALTER TABLE "daily_attendances" ADD COLUMN IF NOT EXISTS "date" varchar(10) NOT NULL;
+ALTER TABLE "daily_attendances" ADD COLUMN IF NOT EXISTS "status" varchar(20) NOT NULL;
+ALTER TABLE "daily_attendances" ADD COLUMN IF NOT EXISTS "site_id" bigint;
+ALTER TABLE "daily_attendances" ADD COLUMN IF NOT EXISTS "department_id" bigint;
+ALTER TABLE "daily_attendances" ADD COLUMN IF NOT EXISTS "team_id" bigint;
+ALTER TABLE "daily_attendances" ADD COLUMN IF NOT EXISTS "timezone" varchar(64);
+ALTER TABLE "daily_attendances" ADD COLUMN IF NOT EXISTS "scheduled_start" timestamptz;
+ALTER TABLE "daily_attendances" ADD COLUMN IF NOT EXISTS "first_check_in" timestamptz;
+ALTER TABLE "daily_attendances" ADD COLUMN IF NOT EXISTS "attendance_id" bigint;
+ALTER TABLE "daily_attendances" ADD COLUMN IF NOT EXISTS "correction_id" bigint;
+ALTER TABLE "daily_attendances" ADD COLUMN IF NOT EXISTS "late_minutes" bigint;
+ALTER TABLE "daily_attendances" ADD COLUMN IF NOT EXISTS "failed_attempts" bigint;
+ALTER TABLE "daily_attendances" ADD COLUMN IF NOT EXISTS "leave_request_id" bigint;
+ALTER TABLE "daily_attendances" ADD COLUMN IF NOT EXISTS "computed_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_daily_attendances_department_id" ON "daily_attendances" ("department_id");
+CREATE INDEX IF NOT EXISTS "idx_daily_attendances_site_id" ON "daily_attendances" ("site_id");
+CREATE INDEX IF NOT EXISTS "idx_daily_attendances_status" ON "daily_attendances" ("status");
+CREATE INDEX IF NOT EXISTS "idx_daily_attendances_date" ON "daily_attendances" ("date");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_daily_user_date" ON "daily_attendances" ("user_id","date");
+CREATE INDEX IF NOT EXISTS "idx_daily_attendances_team_id" ON "daily_attendances" ("team_id");
+
+CREATE TABLE IF NOT EXISTS "daily_summary_runs" (
+    "id" bigserial,
+    "scope" varchar(50) NOT NULL,
+    "date" varchar(10) NOT NULL,
+    "employees" bigint,
+    "completed_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+ALTER TABLE "daily_summary_runs" ADD COLUMN IF NOT EXISTS "scope" varchar(50) NOT NULL;
+ALTER TABLE "daily_summary_runs" ADD COLUMN IF NOT EXISTS "date" varchar(10) NOT NULL;
+ALTER TABLE "daily_summary_runs" ADD COLUMN IF NOT EXISTS "employees" bigint;
+ALTER TABLE "daily_summary_runs" ADD COLUMN IF NOT EXISTS "completed_at" timestamptz;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_summary_scope_date" ON "daily_summary_runs" ("scope","date");
+
+CREATE TABLE IF NOT EXISTS "leave_types" (
+    "id" bigserial,
+    "code" varchar(50) NOT NULL,
+    "name" text NOT NULL,
+    "annual_allowance" decimal,
+    "max_carry_over" decimal,
+    "requires_attachment" boolean,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+ALTER TABLE "leave_types" ADD COLUMN IF NOT EXISTS "code" varchar(50) NOT NULL;
+ALTER TABLE "leave_types" ADD COLUMN IF NOT EXISTS "name" text NOT NULL;
+ALTER TABLE "leave_types" ADD COLUMN IF NOT EXISTS "annual_allowance" decimal;
+ALTER TABLE "leave_types" ADD COLUMN IF NOT EXISTS "max_carry_over" decimal;
+ALTER TABLE "leave_types" ADD COLUMN IF NOT EXISTS "requires_attachment" boolean;
+ALTER TABLE "leave_types" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "leave_types" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_leave_types_code" ON "leave_types" ("code");
+
+CREATE TABLE IF NOT EXISTS "leave_balances" (
+    "id" bigserial,
+    "user_id" bigint NOT NULL,
+    "leave_type_id" bigint NOT NULL,
+    "year" bigint NOT NULL,
+    "accrued" decimal,
+    "carried_over" decimal,
+    "used" decimal,
+    "pending" decimal,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_leave_balances_leave_type" FOREIGN KEY ("leave_type_id") REFERENCES "leave_types"("id")
+);
+ALTER TABLE "leave_balances" ADD COLUMN IF NOT EXISTS "user_id" bigint NOT NULL;
+ALTER TABLE "leave_balances" ADD COLUMN IF NOT EXISTS "leave_type_id" bigint NOT NULL;
+ALTER TABLE "leave_balances" ADD COLUMN IF NOT EXISTS "year" bigint NOT NULL;
+ALTER TABLE "leave_balances" ADD COLUMN IF NOT EXISTS "accrued" decimal;
+ALTER TABLE "leave_balances" ADD COLUMN IF NOT EXISTS "carried_over" decimal;
+ALTER TABLE "leave_balances" ADD COLUMN IF NOT EXISTS "used" decimal;
+ALTER TABLE "leave_balances" ADD COLUMN IF NOT EXISTS "pending" decimal;
+ALTER TABLE "leave_balances" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "leave_balances" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_leave_balance" ON "leave_balances" ("user_id","leave_type_id","year");
+
+CREATE TABLE IF NOT EXISTS "leave_requests" (
+    "id" bigserial,
+    "user_id" bigint NOT NULL,
+    "leave_type_id" bigint NOT NULL,
+    "start_date" varchar(10) NOT NULL,
+    "end_date" varchar(10) NOT NULL,
+    "half_day" varchar(2),
+    "days" decimal,
+    "reason" text,
+    "attachment_path" text,
+    "status" varchar(20) NOT NULL DEFAULT 'pending',
+    "approver_id" bigint,
+    "decision_note" text,
+    "decided_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_leave_requests_user" FOREIGN KEY ("user_id") REFERENCES "users"("id"),
+    CONSTRAINT "fk_leave_requests_leave_type" FOREIGN KEY ("leave_type_id") REFERENCES "leave_types"("id"),
+    CONSTRAINT "fk_leave_requests_approver" FOREIGN KEY ("approver_id") REFERENCES "users"("id")
+);
+ALTER TABLE "leave_requests" ADD COLUMN IF NOT EXISTS "user_id" bigint NOT NULL;
+ALTER TABLE "leave_requests" ADD COLUMN IF NOT EXISTS "leave_type_id" bigint NOT NULL;
+ALTER TABLE "leave_requests" ADD COLUMN IF NOT EXISTS "start_date" varchar(10) NOT NULL;
+ALTER TABLE "leave_requests" ADD COLUMN IF NOT EXISTS "end_date" varchar(10) NOT NULL;
+ALTER TABLE "leave_requests" ADD COLUMN IF NOT EXISTS "half_day" varchar(2);
+ALTER TABLE "leave_requests" ADD COLUMN IF NOT EXISTS "days" decimal;
+ALTER TABLE "leave_requests" ADD COLUMN IF NOT EXISTS "reason" text;
+ALTER TABLE "leave_requests" ADD COLUMN IF NOT EXISTS "attachment_path" text;
+ALTER TABLE "leave_requests" ADD COLUMN IF NOT EXISTS "status" varchar(20) NOT NULL DEFAULT 'pending';
+ALTER TABLE "leave_requests" ADD COLUMN IF NOT EXISTS "approver_id" bigint;
+ALTER TABLE "leave_requests" ADD COLUMN IF NOT EXISTS "decision_note" text;
+ALTER TABLE "leave_requests" ADD COLUMN IF NOT EXISTS "decided_at" timestamptz;
+ALTER TABLE "leave_requests" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "leave_requests" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_leave_requests_start_date" ON "leave_requests" ("start_date");
+CREATE INDEX IF NOT EXISTS "idx_leave_requests_user_id" ON "leave_requests" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_leave_requests_status" ON "leave_requests" ("status");
+CREATE INDEX IF NOT EXISTS "idx_leave_requests_end_date" ON "leave_requests" ("end_date");
+
+CREATE TABLE IF NOT EXISTS "calendar_days" (
+    "id" bigserial,
+    "date" varchar(10) NOT NULL,
+    "kind" varchar(20) NOT NULL DEFAULT 'holiday',
+    "name" text NOT NULL,
+    "site_id" bigint,
+    "source" varchar(20) NOT NULL DEFAULT 'manual',
+    "external_uid" varchar(255),
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_calendar_days_site" FOREIGN KEY ("site_id") REFERENCES "sites"("id")
+);
+ALTER TABLE "calendar_days" ADD COLUMN IF NOT EXISTS "date" varchar(10) NOT NULL;
+ALTER TABLE "calendar_days" ADD COLUMN IF NOT EXISTS "kind" varchar(20) NOT NULL DEFAULT 'holiday';
+ALTER TABLE "calendar_days" ADD COLUMN IF NOT EXISTS "name" text NOT NULL;
+ALTER TABLE "calendar_days" ADD COLUMN IF NOT EXISTS "site_id" bigint;
+ALTER TABLE "calendar_days" ADD COLUMN IF NOT EXISTS "source" varchar(20) NOT NULL DEFAULT 'manual';
+ALTER TABLE "calendar_days" ADD COLUMN IF NOT EXISTS "external_uid" varchar(255);
+ALTER TABLE "calendar_days" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "calendar_days" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_calendar_days_external_uid" ON "calendar_days" ("external_uid");
+CREATE INDEX IF NOT EXISTS "idx_calendar_days_site_id" ON "calendar_days" ("site_id");
+CREATE INDEX IF NOT EXISTS "idx_calendar_days_date" ON "calendar_days" ("date");
+
+CREATE TABLE IF NOT EXISTS "attendance_corrections" (
+    "id" bigserial,
+    "user_id" bigint NOT NULL,
+    "attendance_id" bigint,
+    "check_in_time" timestamptz NOT NULL,
+    "date" varchar(10) NOT NULL,
+    "check_in_status" varchar(20) NOT NULL,
+    "reason" text NOT NULL,
+    "status" varchar(20) NOT NULL DEFAULT 'pending',
+    "requested_by_id" bigint NOT NULL,
+    "approver_id" bigint,
+    "decision_note" text,
+    "decided_at" timestamptz,
+    "original_time" timestamptz,
+    "original_status" varchar(20),
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_attendance_corrections_user" FOREIGN KEY ("user_id") REFERENCES "users"("id"),
+    CONSTRAINT "fk_attendance_corrections_requested_by" FOREIGN KEY ("requested_by_id") REFERENCES "users"("id"),
+    CONSTRAINT "fk_attendance_corrections_approver" FOREIGN KEY ("approver_id") REFERENCES "users"("id")
+);
+ALTER TABLE "attendance_corrections" ADD COLUMN IF NOT EXISTS "user_id" bigint NOT NULL;
+ALTER TABLE "attendance_corrections" ADD COLUMN IF NOT EXISTS "attendance_id" bigint;
+ALTER TABLE "attendance_corrections" ADD COLUMN IF NOT EXISTS "check_in_time" timestamptz NOT NULL;
+ALTER TABLE "attendance_corrections" ADD COLUMN IF NOT EXISTS "date" varchar(10) NOT NULL;
+ALTER TABLE "attendance_corrections" ADD COLUMN IF NOT EXISTS "check_in_status" varchar(20) NOT NULL;
+ALTER TABLE "attendance_corrections" ADD COLUMN IF NOT EXISTS "reason" text NOT NULL;
+ALTER TABLE "attendance_corrections" ADD COLUMN IF NOT EXISTS "status" varchar(20) NOT NULL DEFAULT 'pending';
+ALTER TABLE "attendance_corrections" ADD COLUMN IF NOT EXISTS "requested_by_id" bigint NOT NULL;
+ALTER TABLE "attendance_corrections" ADD COLUMN IF NOT EXISTS "approver_id" bigint;
+ALTER TABLE "attendance_corrections" ADD COLUMN IF NOT EXISTS "decision_note" text;
+ALTER TABLE "attendance_corrections" ADD COLUMN IF NOT EXISTS "decided_at" timestamptz;
+ALTER TABLE "attendance_corrections" ADD COLUMN IF NOT EXISTS "original_time" timestamptz;
+ALTER TABLE "attendance_corrections" ADD COLUMN IF NOT EXISTS "original_status" varchar(20);
+ALTER TABLE "attendance_corrections" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+ALTER TABLE "attendance_corrections" ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_attendance_corrections_status" ON "attendance_corrections" ("status");
+CREATE INDEX IF NOT EXISTS "idx_attendance_corrections_date" ON "attendance_corrections" ("date");
+CREATE INDEX IF NOT EXISTS "idx_attendance_corrections_attendance_id" ON "attendance_corrections" ("attendance_id");
+CREATE INDEX IF NOT EXISTS "idx_attendance_corrections_user_id" ON "attendance_corrections" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "attendance_correction_events" (
+    "id" bigserial,
+    "correction_id" bigint NOT NULL,
+    "action" varchar(20) NOT NULL,
+    "actor_id" bigint NOT NULL,
+    "note" text,
+    "before" text,
+    "after" text,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_attendance_corrections_events" FOREIGN KEY ("correction_id") REFERENCES "attendance_corrections"("id")
+);
+ALTER TABLE "attendance_correction_events" ADD COLUMN IF NOT EXISTS "correction_id" bigint NOT NULL;
+ALTER TABLE "attendance_correction_events" ADD COLUMN IF NOT EXISTS "action" varchar(20) NOT NULL;
+ALTER TABLE "attendance_correction_events" ADD COLUMN IF NOT EXISTS "actor_id" bigint NOT NULL;
+ALTER TABLE "attendance_correction_events" ADD COLUMN IF NOT EXISTS "note" text;
+ALTER TABLE "attendance_correction_events" ADD COLUMN IF NOT EXISTS "before" text;
+ALTER TABLE "attendance_correction_events" ADD COLUMN IF NOT EXISTS "after" text;
+ALTER TABLE "attendance_correction_events" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_attendance_correction_events_correction_id" ON "attendance_correction_events" ("correction_id");
+
+CREATE TABLE IF NOT EXISTS "audit_logs" (
+    "id" bigserial,
+    "sequence" bigint NOT NULL,
+    "created_at" timestamptz NOT NULL,
+    "actor_id" bigint,
+    "actor_role" varchar(20),
+    "action" varchar(150) NOT NULL,
+    "method" varchar(10) NOT NULL,
+    "path" text NOT NULL,
+    "status_code" bigint,
+    "entity_type" varchar(50),
+    "entity_id" varchar(100),
+    "request_id" varchar(64),
+    "ip" varchar(64),
+    "user_agent" text,
+    "diff" text,
+    "prev_hash" varchar(64) NOT NULL,
+    "hash" varchar(64) NOT NULL,
+    PRIMARY KEY ("id")
+);
+ALTER TABLE "audit_logs" ADD COLUMN IF NOT EXISTS "sequence" bigint NOT NULL;
+ALTER TABLE "audit_logs" ADD COLUMN IF NOT EXISTS "created_at" timestamptz NOT NULL;
+ALTER TABLE "audit_logs" ADD COLUMN IF NOT EXISTS "actor_id" bigint;
+ALTER TABLE "audit_logs" ADD COLUMN IF NOT EXISTS "actor_role" varchar(20);
+ALTER TABLE "audit_logs" ADD COLUMN IF NOT EXISTS "action" varchar(150) NOT NULL;
+ALTER TABLE "audit_logs" ADD COLUMN IF NOT EXISTS "method" varchar(10) NOT NULL;
+ALTER TABLE "audit_logs" ADD COLUMN IF NOT EXISTS "path" text NOT NULL;
+ALTER TABLE "audit_logs" ADD COLUMN IF NOT EXISTS "status_code" bigint;
+ALTER TABLE "audit_logs" ADD COLUMN IF NOT EXISTS "entity_type" varchar(50);
+ALTER TABLE "audit_logs" ADD COLUMN IF NOT EXISTS "entity_id" varchar(100);
+ALTER TABLE "audit_logs" ADD COLUMN IF NOT EXISTS "request_id" varchar(64);
+ALTER TABLE "audit_logs" ADD COLUMN IF NOT EXISTS "ip" varchar(64);
+ALTER TABLE "audit_logs" ADD COLUMN IF NOT EXISTS "user_agent" text;
+ALTER TABLE "audit_logs" ADD COLUMN IF NOT EXISTS "diff" text;
+ALTER TABLE "audit_logs" ADD COLUMN IF NOT EXISTS "prev_hash" varchar(64) NOT NULL;
+ALTER TABLE "audit_logs" ADD COLUMN IF NOT EXISTS "hash" varchar(64) NOT NULL;
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_request_id" ON "audit_logs" ("request_id");
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_entity" ON "audit_logs" ("entity_type","entity_id");
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_action" ON "audit_logs" ("action");
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_actor_id" ON "audit_logs" ("actor_id");
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_created_at" ON "audit_logs" ("created_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_audit_logs_sequence" ON "audit_logs" ("sequence");
+
+CREATE TABLE IF NOT EXISTS "retention_purge_runs" (
+    "id" bigserial,
+    "trigger" varchar(20) NOT NULL,
+    "triggered_by_id" bigint,
+    "dry_run" boolean,
+    "successful_selfies" bigint,
+    "failed_selfies" bigint,
+    "reference_photos" bigint,
+    "held" bigint,
+    "failed" bigint,
+    "errors" text,
+    "started_at" timestamptz,
+    "completed_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+ALTER TABLE "retention_purge_runs" ADD COLUMN IF NOT EXISTS "trigger" varchar(20) NOT NULL;
+ALTER TABLE "retention_purge_runs" ADD COLUMN IF NOT EXISTS "triggered_by_id" bigint;
+ALTER TABLE "retention_purge_runs" ADD COLUMN IF NOT EXISTS "dry_run" boolean;
+ALTER TABLE "retention_purge_runs" ADD COLUMN IF NOT EXISTS "successful_selfies" bigint;
+ALTER TABLE "retention_purge_runs" ADD COLUMN IF NOT EXISTS "failed_selfies" bigint;
+ALTER TABLE "retention_purge_runs" ADD COLUMN IF NOT EXISTS "reference_photos" bigint;
+ALTER TABLE "retention_purge_runs" ADD COLUMN IF NOT EXISTS "held" bigint;
+ALTER TABLE "retention_purge_runs" ADD COLUMN IF NOT EXISTS "failed" bigint;
+ALTER TABLE "retention_purge_runs" ADD COLUMN IF NOT EXISTS "errors" text;
+ALTER TABLE "retention_purge_runs" ADD COLUMN IF NOT EXISTS "started_at" timestamptz;
+ALTER TABLE "retention_purge_runs" ADD COLUMN IF NOT EXISTS "completed_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_retention_purge_runs_started_at" ON "retention_purge_runs" ("started_at");
+
+CREATE TABLE IF NOT EXISTS "retention_purge_items" (
+    "id" bigserial,
+    "run_id" bigint NOT NULL,
+    "category" varchar(30) NOT NULL,
+    "entity_type" varchar(30) NOT NULL,
+    "entity_id" bigint NOT NULL,
+    "user_id" bigint NOT NULL,
+    "recorded_at" timestamptz,
+    "purged_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_retention_purge_runs_items" FOREIGN KEY ("run_id") REFERENCES "retention_purge_runs"("id")
+);
+ALTER TABLE "retention_purge_items" ADD COLUMN IF NOT EXISTS "run_id" bigint NOT NULL;
+ALTER TABLE "retention_purge_items" ADD COLUMN IF NOT EXISTS "category" varchar(30) NOT NULL;
+ALTER TABLE "retention_purge_items" ADD COLUMN IF NOT EXISTS "entity_type" varchar(30) NOT NULL;
+ALTER TABLE "retention_purge_items" ADD COLUMN IF NOT EXISTS "entity_id" bigint NOT NULL;
+ALTER TABLE "retention_purge_items" ADD COLUMN IF NOT EXISTS "user_id" bigint NOT NULL;
+ALTER TABLE "retention_purge_items" ADD COLUMN IF NOT EXISTS "recorded_at" timestamptz;
+ALTER TABLE "retention_purge_items" ADD COLUMN IF NOT EXISTS "purged_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_retention_items_entity" ON "retention_purge_items" ("entity_type","entity_id");
+CREATE INDEX IF NOT EXISTS "idx_retention_purge_items_run_id" ON "retention_purge_items" ("run_id");
+CREATE INDEX IF NOT EXISTS "idx_retention_purge_items_user_id" ON "retention_purge_items" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "biometric_consents" (
+    "id" bigserial,
+    "user_id" bigint NOT NULL,
+    "policy_version" varchar(50) NOT NULL,
+    "method" varchar(20) NOT NULL,
+    "given_at" timestamptz NOT NULL,
+    "recorded_by_id" bigint,
+    "withdrawn_at" timestamptz,
+    "withdrawn_by_id" bigint,
+    "withdraw_reason" text,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+ALTER TABLE "biometric_consents" ADD COLUMN IF NOT EXISTS "user_id" bigint NOT NULL;
+ALTER TABLE "biometric_consents" ADD COLUMN IF NOT EXISTS "policy_version" varchar(50) NOT NULL;
+ALTER TABLE "biometric_consents" ADD COLUMN IF NOT EXISTS "method" varchar(20) NOT NULL;
+ALTER TABLE "biometric_consents" ADD COLUMN IF NOT EXISTS "given_at" timestamptz NOT NULL;
+ALTER TABLE "biometric_consents" ADD COLUMN IF NOT EXISTS "recorded_by_id" bigint;
+ALTER TABLE "biometric_consents" ADD COLUMN IF NOT EXISTS "withdrawn_at" timestamptz;
+ALTER TABLE "biometric_consents" ADD COLUMN IF NOT EXISTS "withdrawn_by_id" bigint;
+ALTER TABLE "biometric_consents" ADD COLUMN IF NOT EXISTS "withdraw_reason" text;
+ALTER TABLE "biometric_consents" ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_biometric_consents_user_id" ON "biometric_consents" ("user_id");
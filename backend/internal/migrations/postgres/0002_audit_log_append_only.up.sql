@@ -0,0 +1,16 @@
+-- Audit log append-only: tolak UPDATE, DELETE dan TRUNCATE di level database.
+-- Hash chain tetap jadi deteksi utama, trigger ini mencegah perubahan lewat aplikasi atau query biasa.
+
+CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_logs_no_modify ON audit_logs;
+CREATE TRIGGER audit_logs_no_modify BEFORE UPDATE OR DELETE ON audit_logs
+    FOR EACH ROW EXECUTE PROCEDURE audit_logs_append_only();
+
+DROP TRIGGER IF EXISTS audit_logs_no_truncate ON audit_logs;
+CREATE TRIGGER audit_logs_no_truncate BEFORE TRUNCATE ON audit_logs
+    FOR EACH STATEMENT EXECUTE PROCEDURE audit_logs_append_only();
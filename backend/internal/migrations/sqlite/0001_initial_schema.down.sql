@@ -0,0 +1,19 @@
+DROP TABLE IF EXISTS `biometric_consents`;
+DROP TABLE IF EXISTS `retention_purge_items`;
+DROP TABLE IF EXISTS `retention_purge_runs`;
+DROP TABLE IF EXISTS `audit_logs`;
+DROP TABLE IF EXISTS `attendance_correction_events`;
+DROP TABLE IF EXISTS `attendance_corrections`;
+DROP TABLE IF EXISTS `calendar_days`;
+DROP TABLE IF EXISTS `leave_requests`;
+DROP TABLE IF EXISTS `leave_balances`;
+DROP TABLE IF EXISTS `leave_types`;
+DROP TABLE IF EXISTS `daily_summary_runs`;
+DROP TABLE IF EXISTS `daily_attendances`;
+DROP TABLE IF EXISTS `employee_assignments`;
+DROP TABLE IF EXISTS `sites`;
+DROP TABLE IF EXISTS `work_schedules`;
+DROP TABLE IF EXISTS `teams`;
+DROP TABLE IF EXISTS `departments`;
+DROP TABLE IF EXISTS `attendances`;
+DROP TABLE IF EXISTS `users`;
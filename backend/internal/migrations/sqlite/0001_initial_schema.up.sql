@@ -0,0 +1,326 @@
+-- Skema awal, sama dengan hasil AutoMigrate sebelum migration berversi dipakai.
+-- IF NOT EXISTS supaya database lama (dibuat AutoMigrate) bisa langsung diadopsi dengan `migrate up`.
+-- SQLite tidak punya ADD COLUMN IF NOT EXISTS; tidak perlu karena driver SQLite ditambahkan bersama skema lengkap ini.
+
+CREATE TABLE IF NOT EXISTS `users` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `name` text NOT NULL,
+    `email` text NOT NULL,
+    `phone` text,
+    `face_image_path` text NOT NULL,
+    `face_descriptor` text,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deactivated_at` datetime,
+    `legal_hold` numeric NOT NULL DEFAULT false,
+    `image_purged_at` datetime,
+    `erased_at` datetime,
+    `role` varchar(20) NOT NULL DEFAULT 'employee',
+    `password_hash` text
+);
+CREATE INDEX IF NOT EXISTS `idx_users_deactivated_at` ON `users` (`deactivated_at`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_users_email` ON `users` (`email`);
+
+CREATE TABLE IF NOT EXISTS `attendances` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `user_id` integer NOT NULL,
+    `check_in_time` datetime NOT NULL,
+    `face_image_path` text,
+    `similarity_score` real,
+    `status` varchar(20) NOT NULL,
+    `site_id` integer,
+    `device_id` varchar(100),
+    `method` varchar(20) NOT NULL DEFAULT 'face',
+    `created_at` datetime,
+    `legal_hold` numeric NOT NULL DEFAULT false,
+    `image_purged_at` datetime,
+    CONSTRAINT `fk_users_attendances` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_attendances_device_id` ON `attendances` (`device_id`);
+CREATE INDEX IF NOT EXISTS `idx_attendances_site_id` ON `attendances` (`site_id`);
+CREATE INDEX IF NOT EXISTS `idx_attendances_user_id` ON `attendances` (`user_id`);
+
+CREATE TABLE IF NOT EXISTS `departments` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `name` text NOT NULL,
+    `code` varchar(50) NOT NULL,
+    `parent_id` integer,
+    `manager_id` integer,
+    `created_at` datetime,
+    `updated_at` datetime,
+    CONSTRAINT `fk_departments_manager` FOREIGN KEY (`manager_id`) REFERENCES `users`(`id`)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_departments_code` ON `departments` (`code`);
+CREATE INDEX IF NOT EXISTS `idx_departments_manager_id` ON `departments` (`manager_id`);
+CREATE INDEX IF NOT EXISTS `idx_departments_parent_id` ON `departments` (`parent_id`);
+
+CREATE TABLE IF NOT EXISTS `teams` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `name` text NOT NULL,
+    `department_id` integer NOT NULL,
+    `manager_id` integer,
+    `created_at` datetime,
+    `updated_at` datetime,
+    CONSTRAINT `fk_teams_manager` FOREIGN KEY (`manager_id`) REFERENCES `users`(`id`),
+    CONSTRAINT `fk_departments_teams` FOREIGN KEY (`department_id`) REFERENCES `departments`(`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_teams_manager_id` ON `teams` (`manager_id`);
+CREATE INDEX IF NOT EXISTS `idx_teams_department_id` ON `teams` (`department_id`);
+
+CREATE TABLE IF NOT EXISTS `work_schedules` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `name` text NOT NULL,
+    `start_time` varchar(5) NOT NULL,
+    `late_grace_minutes` integer NOT NULL DEFAULT 0,
+    `work_days` varchar(20) NOT NULL,
+    `created_at` datetime,
+    `updated_at` datetime
+);
+
+CREATE TABLE IF NOT EXISTS `sites` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `name` text NOT NULL,
+    `code` varchar(50) NOT NULL,
+    `address` text,
+    `timezone` varchar(64),
+    `created_at` datetime,
+    `updated_at` datetime,
+    `work_schedule_id` integer,
+    CONSTRAINT `fk_sites_work_schedule` FOREIGN KEY (`work_schedule_id`) REFERENCES `work_schedules`(`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_sites_work_schedule_id` ON `sites` (`work_schedule_id`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_sites_code` ON `sites` (`code`);
+
+CREATE TABLE IF NOT EXISTS `employee_assignments` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `user_id` integer NOT NULL,
+    `department_id` integer NOT NULL,
+    `team_id` integer,
+    `site_id` integer,
+    `effective_from` datetime NOT NULL,
+    `effective_to` datetime,
+    `created_at` datetime,
+    CONSTRAINT `fk_employee_assignments_department` FOREIGN KEY (`department_id`) REFERENCES `departments`(`id`),
+    CONSTRAINT `fk_employee_assignments_team` FOREIGN KEY (`team_id`) REFERENCES `teams`(`id`),
+    CONSTRAINT `fk_employee_assignments_site` FOREIGN KEY (`site_id`) REFERENCES `sites`(`id`),
+    CONSTRAINT `fk_users_assignments` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_employee_assignments_department_id` ON `employee_assignments` (`department_id`);
+CREATE INDEX IF NOT EXISTS `idx_employee_assignments_user_id` ON `employee_assignments` (`user_id`);
+CREATE INDEX IF NOT EXISTS `idx_employee_assignments_effective_to` ON `employee_assignments` (`effective_to`);
+CREATE INDEX IF NOT EXISTS `idx_employee_assignments_effective_from` ON `employee_assignments` (`effective_from`);
+CREATE INDEX IF NOT EXISTS `idx_employee_assignments_site_id` ON `employee_assignments` (`site_id`);
+CREATE INDEX IF NOT EXISTS `idx_employee_assignments_team_id` ON `employee_assignments` (`team_id`);
+
+CREATE TABLE IF NOT EXISTS `daily_attendances` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `user_id` integer NOT NULL,
+    `date` varchar(10) NOT NULL,
+    `status` varchar(20) NOT NULL,
+    `site_id` integer,
+    `department_id` integer,
+    `team_id` integer,
+    `timezone` varchar(64),
+    `scheduled_start` datetime,
+    `first_check_in` datetime,
+    `attendance_id` integer,
+    `correction_id` integer,
+    `late_minutes` integer,
+    `failed_attempts` integer,
+    `leave_request_id` integer,
+    `computed_at` datetime,
+    CONSTRAINT `fk_daily_attendances_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_daily_attendances_team_id` ON `daily_attendances` (`team_id`);
+CREATE INDEX IF NOT EXISTS `idx_daily_attendances_department_id` ON `daily_attendances` (`department_id`);
+CREATE INDEX IF NOT EXISTS `idx_daily_attendances_site_id` ON `daily_attendances` (`site_id`);
+CREATE INDEX IF NOT EXISTS `idx_daily_attendances_status` ON `daily_attendances` (`status`);
+CREATE INDEX IF NOT EXISTS `idx_daily_attendances_date` ON `daily_attendances` (`date`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_daily_user_date` ON `daily_attendances` (`user_id`,`date`);
+
+CREATE TABLE IF NOT EXISTS `daily_summary_runs` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `scope` varchar(50) NOT NULL,
+    `date` varchar(10) NOT NULL,
+    `employees` integer,
+    `completed_at` datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_summary_scope_date` ON `daily_summary_runs` (`scope`,`date`);
+
+CREATE TABLE IF NOT EXISTS `leave_types` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `code` varchar(50) NOT NULL,
+    `name` text NOT NULL,
+    `annual_allowance` real,
+    `max_carry_over` real,
+    `requires_attachment` numeric,
+    `created_at` datetime,
+    `updated_at` datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_leave_types_code` ON `leave_types` (`code`);
+
+CREATE TABLE IF NOT EXISTS `leave_balances` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `user_id` integer NOT NULL,
+    `leave_type_id` integer NOT NULL,
+    `year` integer NOT NULL,
+    `accrued` real,
+    `carried_over` real,
+    `used` real,
+    `pending` real,
+    `created_at` datetime,
+    `updated_at` datetime,
+    CONSTRAINT `fk_leave_balances_leave_type` FOREIGN KEY (`leave_type_id`) REFERENCES `leave_types`(`id`)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_leave_balance` ON `leave_balances` (`user_id`,`leave_type_id`,`year`);
+
+CREATE TABLE IF NOT EXISTS `leave_requests` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `user_id` integer NOT NULL,
+    `leave_type_id` integer NOT NULL,
+    `start_date` varchar(10) NOT NULL,
+    `end_date` varchar(10) NOT NULL,
+    `half_day` varchar(2),
+    `days` real,
+    `reason` text,
+    `attachment_path` text,
+    `status` varchar(20) NOT NULL DEFAULT 'pending',
+    `approver_id` integer,
+    `decision_note` text,
+    `decided_at` datetime,
+    `created_at` datetime,
+    `updated_at` datetime,
+    CONSTRAINT `fk_leave_requests_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`),
+    CONSTRAINT `fk_leave_requests_leave_type` FOREIGN KEY (`leave_type_id`) REFERENCES `leave_types`(`id`),
+    CONSTRAINT `fk_leave_requests_approver` FOREIGN KEY (`approver_id`) REFERENCES `users`(`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_leave_requests_start_date` ON `leave_requests` (`start_date`);
+CREATE INDEX IF NOT EXISTS `idx_leave_requests_user_id` ON `leave_requests` (`user_id`);
+CREATE INDEX IF NOT EXISTS `idx_leave_requests_status` ON `leave_requests` (`status`);
+CREATE INDEX IF NOT EXISTS `idx_leave_requests_end_date` ON `leave_requests` (`end_date`);
+
+CREATE TABLE IF NOT EXISTS `calendar_days` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `date` varchar(10) NOT NULL,
+    `kind` varchar(20) NOT NULL DEFAULT 'holiday',
+    `name` text NOT NULL,
+    `site_id` integer,
+    `source` varchar(20) NOT NULL DEFAULT 'manual',
+    `external_uid` varchar(255),
+    `created_at` datetime,
+    `updated_at` datetime,
+    CONSTRAINT `fk_calendar_days_site` FOREIGN KEY (`site_id`) REFERENCES `sites`(`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_calendar_days_external_uid` ON `calendar_days` (`external_uid`);
+CREATE INDEX IF NOT EXISTS `idx_calendar_days_site_id` ON `calendar_days` (`site_id`);
+CREATE INDEX IF NOT EXISTS `idx_calendar_days_date` ON `calendar_days` (`date`);
+
+CREATE TABLE IF NOT EXISTS `attendance_corrections` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `user_id` integer NOT NULL,
+    `attendance_id` integer,
+    `check_in_time` datetime NOT NULL,
+    `date` varchar(10) NOT NULL,
+    `check_in_status` varchar(20) NOT NULL,
+    `reason` text NOT NULL,
+    `status` varchar(20) NOT NULL DEFAULT 'pending',
+    `requested_by_id` integer NOT NULL,
+    `approver_id` integer,
+    `decision_note` text,
+    `decided_at` datetime,
+    `original_time` datetime,
+    `original_status` varchar(20),
+    `created_at` datetime,
+    `updated_at` datetime,
+    CONSTRAINT `fk_attendance_corrections_user` FOREIGN KEY (`user_id`) REFERENCES `users`(`id`),
+    CONSTRAINT `fk_attendance_corrections_requested_by` FOREIGN KEY (`requested_by_id`) REFERENCES `users`(`id`),
+    CONSTRAINT `fk_attendance_corrections_approver` FOREIGN KEY (`approver_id`) REFERENCES `users`(`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_attendance_corrections_status` ON `attendance_corrections` (`status`);
+CREATE INDEX IF NOT EXISTS `idx_attendance_corrections_date` ON `attendance_corrections` (`date`);
+CREATE INDEX IF NOT EXISTS `idx_attendance_corrections_attendance_id` ON `attendance_corrections` (`attendance_id`);
+CREATE INDEX IF NOT EXISTS `idx_attendance_corrections_user_id` ON `attendance_corrections` (`user_id`);
+
+CREATE TABLE IF NOT EXISTS `attendance_correction_events` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `correction_id` integer NOT NULL,
+    `action` varchar(20) NOT NULL,
+    `actor_id` integer NOT NULL,
+    `note` text,
+    `before` text,
+    `after` text,
+    `created_at` datetime,
+    CONSTRAINT `fk_attendance_corrections_events` FOREIGN KEY (`correction_id`) REFERENCES `attendance_corrections`(`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_attendance_correction_events_correction_id` ON `attendance_correction_events` (`correction_id`);
+
+CREATE TABLE IF NOT EXISTS `audit_logs` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `sequence` integer NOT NULL,
+    `created_at` datetime NOT NULL,
+    `actor_id` integer,
+    `actor_role` varchar(20),
+    `action` varchar(150) NOT NULL,
+    `method` varchar(10) NOT NULL,
+    `path` text NOT NULL,
+    `status_code` integer,
+    `entity_type` varchar(50),
+    `entity_id` varchar(100),
+    `request_id` varchar(64),
+    `ip` varchar(64),
+    `user_agent` text,
+    `diff` text,
+    `prev_hash` varchar(64) NOT NULL,
+    `hash` varchar(64) NOT NULL
+);
+CREATE INDEX IF NOT EXISTS `idx_audit_logs_action` ON `audit_logs` (`action`);
+CREATE INDEX IF NOT EXISTS `idx_audit_logs_actor_id` ON `audit_logs` (`actor_id`);
+CREATE INDEX IF NOT EXISTS `idx_audit_logs_created_at` ON `audit_logs` (`created_at`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_audit_logs_sequence` ON `audit_logs` (`sequence`);
+CREATE INDEX IF NOT EXISTS `idx_audit_logs_request_id` ON `audit_logs` (`request_id`);
+CREATE INDEX IF NOT EXISTS `idx_audit_logs_entity` ON `audit_logs` (`entity_type`,`entity_id`);
+
+CREATE TABLE IF NOT EXISTS `retention_purge_runs` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `trigger` varchar(20) NOT NULL,
+    `triggered_by_id` integer,
+    `dry_run` numeric,
+    `successful_selfies` integer,
+    `failed_selfies` integer,
+    `reference_photos` integer,
+    `held` integer,
+    `failed` integer,
+    `errors` text,
+    `started_at` datetime,
+    `completed_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_retention_purge_runs_started_at` ON `retention_purge_runs` (`started_at`);
+
+CREATE TABLE IF NOT EXISTS `retention_purge_items` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `run_id` integer NOT NULL,
+    `category` varchar(30) NOT NULL,
+    `entity_type` varchar(30) NOT NULL,
+    `entity_id` integer NOT NULL,
+    `user_id` integer NOT NULL,
+    `recorded_at` datetime,
+    `purged_at` datetime,
+    CONSTRAINT `fk_retention_purge_runs_items` FOREIGN KEY (`run_id`) REFERENCES `retention_purge_runs`(`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_retention_purge_items_user_id` ON `retention_purge_items` (`user_id`);
+CREATE INDEX IF NOT EXISTS `idx_retention_items_entity` ON `retention_purge_items` (`entity_type`,`entity_id`);
+CREATE INDEX IF NOT EXISTS `idx_retention_purge_items_run_id` ON `retention_purge_items` (`run_id`);
+
+CREATE TABLE IF NOT EXISTS `biometric_consents` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `user_id` integer NOT NULL,
+    `policy_version` varchar(50) NOT NULL,
+    `method` varchar(20) NOT NULL,
+    `given_at` datetime NOT NULL,
+    `recorded_by_id` integer,
+    `withdrawn_at` datetime,
+    `withdrawn_by_id` integer,
+    `withdraw_reason` text,
+    `created_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_biometric_consents_user_id` ON `biometric_consents` (`user_id`);
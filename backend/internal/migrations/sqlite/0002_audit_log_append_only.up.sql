@@ -0,0 +1,12 @@
+-- Audit log append-only: tolak UPDATE dan DELETE di level database.
+-- SQLite tidak punya TRUNCATE, DELETE tanpa WHERE juga ditolak trigger ini.
+
+CREATE TRIGGER IF NOT EXISTS audit_logs_no_update BEFORE UPDATE ON audit_logs
+BEGIN
+    SELECT RAISE(ABORT, 'audit_logs is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS audit_logs_no_delete BEFORE DELETE ON audit_logs
+BEGIN
+    SELECT RAISE(ABORT, 'audit_logs is append-only');
+END;
@@ -0,0 +1,10 @@
+-- Token bucket rate limiter untuk RATE_LIMIT_STORE=database, dipakai bersama semua instance backend.
+
+CREATE TABLE IF NOT EXISTS `rate_limit_buckets` (
+    `bucket_key` text NOT NULL,
+    `tokens` real NOT NULL,
+    `refilled_at` datetime NOT NULL,
+    `full_at` datetime NOT NULL,
+    PRIMARY KEY (`bucket_key`)
+);
+CREATE INDEX IF NOT EXISTS `idx_rate_limit_buckets_full_at` ON `rate_limit_buckets` (`full_at`);
@@ -9,11 +9,17 @@ type Attendance struct {
 	ID              uint      `json:"id" gorm:"primaryKey"`
 	UserID          uint      `json:"user_id" gorm:"not null;index"`
 	CheckInTime     time.Time `json:"check_in_time" gorm:"not null"`
-	FaceImagePath   string    `json:"face_image_path"` // Selfie photo saat check-in
-	SimilarityScore float64   `json:"similarity_score"` // Confidence score dari face matching (0.0 - 1.0)
-	Status          string    `json:"status" gorm:"type:varchar(20);not null"` // success/failed
+	FaceImagePath   string    `json:"-"`                                                    // Selfie photo saat check-in, diakses lewat signed URL
+	SimilarityScore float64   `json:"similarity_score"`                                     // Confidence score dari face matching (0.0 - 1.0)
+	Status          string    `json:"status" gorm:"type:varchar(20);not null"`              // success/failed
+	SiteID          *uint     `json:"site_id" gorm:"index"`                                 // Lokasi check-in (optional)
+	DeviceID        string    `json:"device_id" gorm:"type:varchar(100);index"`             // Kiosk/device identifier (optional)
+	Method          string    `json:"method" gorm:"type:varchar(20);not null;default:face"` // face / fallback (tanpa biometrik)
 	CreatedAt       time.Time `json:"created_at"`
-	
+
+	LegalHold     bool       `json:"legal_hold" gorm:"not null;default:false"` // Selfie tidak di-purge selama di-hold
+	ImagePurgedAt *time.Time `json:"image_purged_at,omitempty"`                // Selfie sudah dihapus oleh retention purge
+
 	// Relationship
 	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
 }
@@ -25,14 +31,23 @@ func (Attendance) TableName() string {
 
 // AttendanceResponse is the response struct with user info
 type AttendanceResponse struct {
-	ID              uint         `json:"id"`
-	UserID          uint         `json:"user_id"`
-	UserName        string       `json:"user_name"`
-	CheckInTime     time.Time    `json:"check_in_time"`
-	FaceImagePath   string       `json:"face_image_path"`
-	SimilarityScore float64      `json:"similarity_score"`
-	Status          string       `json:"status"`
-	CreatedAt       time.Time    `json:"created_at"`
+	ID              uint      `json:"id"`
+	UserID          uint      `json:"user_id"`
+	UserName        string    `json:"user_name"`
+	CheckInTime     time.Time `json:"check_in_time"`
+	SimilarityScore float64   `json:"similarity_score"`
+	Status          string    `json:"status"`
+	SiteID          *uint     `json:"site_id"`
+	DeviceID        string    `json:"device_id"`
+	Method          string    `json:"method"`
+	CreatedAt       time.Time `json:"created_at"`
+
+	LegalHold     bool       `json:"legal_hold"`
+	ImagePurgedAt *time.Time `json:"image_purged_at,omitempty"`
+
+	// Signed URL selfie, hanya terisi untuk user yang login
+	FaceImageURL     string `json:"face_image_url,omitempty"`
+	FaceThumbnailURL string `json:"face_thumbnail_url,omitempty"`
 }
 
 // ToResponse converts Attendance to AttendanceResponse
@@ -41,16 +56,20 @@ func (a *Attendance) ToResponse() AttendanceResponse {
 	if a.User.ID != 0 {
 		userName = a.User.Name
 	}
-	
+
 	return AttendanceResponse{
 		ID:              a.ID,
 		UserID:          a.UserID,
 		UserName:        userName,
 		CheckInTime:     a.CheckInTime,
-		FaceImagePath:   a.FaceImagePath,
 		SimilarityScore: a.SimilarityScore,
 		Status:          a.Status,
+		SiteID:          a.SiteID,
+		DeviceID:        a.DeviceID,
+		Method:          a.Method,
 		CreatedAt:       a.CreatedAt,
+		LegalHold:       a.LegalHold,
+		ImagePurgedAt:   a.ImagePurgedAt,
 	}
 }
 
@@ -59,3 +78,9 @@ const (
 	AttendanceStatusSuccess = "success"
 	AttendanceStatusFailed  = "failed"
 )
+
+// Check-in method constants
+const (
+	AttendanceMethodFace     = "face"     // Selfie + face verification
+	AttendanceMethodFallback = "fallback" // Login password, untuk karyawan tanpa consent biometrik
+)
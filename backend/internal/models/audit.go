@@ -0,0 +1,75 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// AuditLog is one append-only entry of the audit trail
+// Setiap entry menyimpan hash entry sebelumnya (PrevHash), jadi perubahan atau
+// penghapusan satu row akan memutus rantai dan terdeteksi saat verifikasi
+type AuditLog struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	Sequence   uint64    `json:"sequence" gorm:"not null;uniqueIndex"` // Urutan rantai, mulai dari 1
+	CreatedAt  time.Time `json:"created_at" gorm:"not null;index"`
+	ActorID    *uint     `json:"actor_id" gorm:"index"` // nil = request tanpa login (kiosk)
+	ActorRole  string    `json:"actor_role,omitempty" gorm:"type:varchar(20)"`
+	Action     string    `json:"action" gorm:"type:varchar(150);not null;index"` // Contoh: "POST /api/employees/register"
+	Method     string    `json:"method" gorm:"type:varchar(10);not null"`
+	Path       string    `json:"path" gorm:"type:text;not null"`
+	StatusCode int       `json:"status_code"`
+	EntityType string    `json:"entity_type,omitempty" gorm:"type:varchar(50);index:idx_audit_logs_entity"`
+	EntityID   string    `json:"entity_id,omitempty" gorm:"type:varchar(100);index:idx_audit_logs_entity"`
+	RequestID  string    `json:"request_id,omitempty" gorm:"type:varchar(64);index"`
+	IP         string    `json:"ip,omitempty" gorm:"type:varchar(64)"`
+	UserAgent  string    `json:"user_agent,omitempty" gorm:"type:text"`
+	Diff       string    `json:"diff,omitempty" gorm:"type:text"` // JSON {field: {old, new}}
+	PrevHash   string    `json:"prev_hash" gorm:"type:varchar(64);not null"`
+	Hash       string    `json:"hash" gorm:"type:varchar(64);not null"`
+}
+
+// TableName specifies the table name for AuditLog model
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// ComputeHash returns SHA-256 of PrevHash and the canonical JSON of the entry fields
+// Urutan field tetap (struct), CreatedAt diformat UTC RFC3339Nano supaya bisa dihitung ulang oleh auditor
+func (l *AuditLog) ComputeHash() string {
+	canonical, _ := json.Marshal(struct {
+		Sequence   uint64 `json:"sequence"`
+		CreatedAt  string `json:"created_at"`
+		ActorID    *uint  `json:"actor_id"`
+		ActorRole  string `json:"actor_role"`
+		Action     string `json:"action"`
+		Method     string `json:"method"`
+		Path       string `json:"path"`
+		StatusCode int    `json:"status_code"`
+		EntityType string `json:"entity_type"`
+		EntityID   string `json:"entity_id"`
+		RequestID  string `json:"request_id"`
+		IP         string `json:"ip"`
+		UserAgent  string `json:"user_agent"`
+		Diff       string `json:"diff"`
+	}{
+		Sequence:   l.Sequence,
+		CreatedAt:  l.CreatedAt.UTC().Format(time.RFC3339Nano),
+		ActorID:    l.ActorID,
+		ActorRole:  l.ActorRole,
+		Action:     l.Action,
+		Method:     l.Method,
+		Path:       l.Path,
+		StatusCode: l.StatusCode,
+		EntityType: l.EntityType,
+		EntityID:   l.EntityID,
+		RequestID:  l.RequestID,
+		IP:         l.IP,
+		UserAgent:  l.UserAgent,
+		Diff:       l.Diff,
+	})
+
+	sum := sha256.Sum256(append([]byte(l.PrevHash+"\n"), canonical...))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+)
+
+// Calendar day kinds
+const (
+	CalendarKindHoliday    = "holiday"     // Libur, walaupun jatuh di hari kerja schedule
+	CalendarKindWorkingDay = "working_day" // Hari kerja pengganti, walaupun jatuh di hari libur schedule
+)
+
+// CalendarDay overrides the weekly work schedule for one local date
+// SiteID nil berlaku untuk seluruh organisasi, entry per site mengalahkan entry organisasi
+type CalendarDay struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Date        string    `json:"date" gorm:"type:varchar(10);not null;index"` // YYYY-MM-DD lokal
+	Kind        string    `json:"kind" gorm:"type:varchar(20);not null;default:holiday"`
+	Name        string    `json:"name" gorm:"not null"`
+	SiteID      *uint     `json:"site_id" gorm:"index"`
+	Source      string    `json:"source" gorm:"type:varchar(20);not null;default:manual"` // manual / ics
+	ExternalUID string    `json:"external_uid,omitempty" gorm:"type:varchar(255);index"`  // UID event iCalendar
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Relationship
+	Site *Site `json:"site,omitempty" gorm:"foreignKey:SiteID"`
+}
+
+// TableName specifies the table name for CalendarDay model
+func (CalendarDay) TableName() string {
+	return "calendar_days"
+}
+
+// IsValidCalendarKind checks if kind is holiday or working_day
+func IsValidCalendarKind(kind string) bool {
+	return kind == CalendarKindHoliday || kind == CalendarKindWorkingDay
+}
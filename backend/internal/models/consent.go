@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+)
+
+// Biometric consent capture methods
+const (
+	ConsentMethodForm        = "form"         // Checkbox saat registrasi
+	ConsentMethodPaper       = "paper"        // Formulir kertas, dicatat oleh HR
+	ConsentMethodSelfService = "self_service" // Karyawan memberi consent sendiri setelah login
+	ConsentMethodImport      = "import"       // Kolom consent di CSV bulk import
+)
+
+// IsValidConsentMethod checks if method is one of the known capture methods
+func IsValidConsentMethod(method string) bool {
+	switch method {
+	case ConsentMethodForm, ConsentMethodPaper, ConsentMethodSelfService, ConsentMethodImport:
+		return true
+	}
+	return false
+}
+
+// Consent status of an employee, diturunkan dari consent record terakhir
+const (
+	ConsentStatusConsented = "consented" // Consent aktif untuk policy version saat ini
+	ConsentStatusOutdated  = "outdated"  // Consent aktif tapi untuk policy version lama
+	ConsentStatusWithdrawn = "withdrawn"
+	ConsentStatusMissing   = "missing" // Belum pernah memberi consent
+)
+
+// BiometricConsent records an employee's consent to face enrollment and verification
+// Setiap consent baru jadi record baru, record terakhir yang menentukan status karyawan
+type BiometricConsent struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	UserID         uint       `json:"user_id" gorm:"not null;index"`
+	PolicyVersion  string     `json:"policy_version" gorm:"type:varchar(50);not null"`
+	Method         string     `json:"method" gorm:"type:varchar(20);not null"`
+	GivenAt        time.Time  `json:"given_at" gorm:"not null"`
+	RecordedByID   *uint      `json:"recorded_by_id"` // HR/admin yang mencatat, nil = karyawan sendiri atau import CLI
+	WithdrawnAt    *time.Time `json:"withdrawn_at"`
+	WithdrawnByID  *uint      `json:"withdrawn_by_id"`
+	WithdrawReason string     `json:"withdraw_reason,omitempty" gorm:"type:text"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// TableName specifies the table name for BiometricConsent model
+func (BiometricConsent) TableName() string {
+	return "biometric_consents"
+}
+
+// Status returns the consent status for the current policy version
+func (c *BiometricConsent) Status(policyVersion string) string {
+	switch {
+	case c == nil:
+		return ConsentStatusMissing
+	case c.WithdrawnAt != nil:
+		return ConsentStatusWithdrawn
+	case c.PolicyVersion != policyVersion:
+		return ConsentStatusOutdated
+	}
+	return ConsentStatusConsented
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"time"
+)
+
+// Correction request status
+const (
+	CorrectionStatusPending   = "pending"
+	CorrectionStatusApproved  = "approved"
+	CorrectionStatusRejected  = "rejected"
+	CorrectionStatusCancelled = "cancelled"
+)
+
+// Correction history actions
+const (
+	CorrectionActionSubmitted = "submitted"
+	CorrectionActionApproved  = "approved"
+	CorrectionActionRejected  = "rejected"
+	CorrectionActionCancelled = "cancelled"
+)
+
+// AttendanceCorrection proposes a corrected check-in for an employee
+// Record Attendance asli tidak pernah diubah, correction yang approved meng-override-nya di summary
+// AttendanceID nil berarti check-in yang hilang (misalnya kamera kiosk mati)
+type AttendanceCorrection struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	UserID         uint       `json:"user_id" gorm:"not null;index"`
+	AttendanceID   *uint      `json:"attendance_id" gorm:"index"`
+	CheckInTime    time.Time  `json:"check_in_time" gorm:"not null"`                    // Waktu yang dikoreksi (UTC)
+	Date           string     `json:"date" gorm:"type:varchar(10);not null;index"`      // Tanggal lokal CheckInTime
+	CheckInStatus  string     `json:"check_in_status" gorm:"type:varchar(20);not null"` // success/failed
+	Reason         string     `json:"reason" gorm:"type:text;not null"`
+	Status         string     `json:"status" gorm:"type:varchar(20);not null;default:pending;index"`
+	RequestedByID  uint       `json:"requested_by_id" gorm:"not null"`
+	ApproverID     *uint      `json:"approver_id"`
+	DecisionNote   string     `json:"decision_note,omitempty" gorm:"type:text"`
+	DecidedAt      *time.Time `json:"decided_at"`
+	OriginalTime   *time.Time `json:"original_time"`                                     // Snapshot record asli saat diajukan
+	OriginalStatus string     `json:"original_status,omitempty" gorm:"type:varchar(20)"` // Kosong kalau check-in hilang
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	// Relationships
+	User        User                        `json:"-" gorm:"foreignKey:UserID"`
+	RequestedBy User                        `json:"-" gorm:"foreignKey:RequestedByID"`
+	Approver    *User                       `json:"-" gorm:"foreignKey:ApproverID"`
+	Events      []AttendanceCorrectionEvent `json:"events,omitempty" gorm:"foreignKey:CorrectionID"`
+}
+
+// TableName specifies the table name for AttendanceCorrection model
+func (AttendanceCorrection) TableName() string {
+	return "attendance_corrections"
+}
+
+// AttendanceCorrectionEvent is one append-only step in a correction's history
+type AttendanceCorrectionEvent struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	CorrectionID uint      `json:"correction_id" gorm:"not null;index"`
+	Action       string    `json:"action" gorm:"type:varchar(20);not null"`
+	ActorID      uint      `json:"actor_id" gorm:"not null"`
+	Note         string    `json:"note,omitempty" gorm:"type:text"`
+	Before       string    `json:"before,omitempty" gorm:"type:text"` // JSON nilai efektif sebelum aksi
+	After        string    `json:"after,omitempty" gorm:"type:text"`  // JSON nilai efektif setelah aksi
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for AttendanceCorrectionEvent model
+func (AttendanceCorrectionEvent) TableName() string {
+	return "attendance_correction_events"
+}
+
+// Apply overrides check-in time and status of the original record
+func (c *AttendanceCorrection) Apply(a *Attendance) {
+	a.CheckInTime = c.CheckInTime
+	a.Status = c.CheckInStatus
+}
+
+// CorrectionResponse adds employee, requester and approver names
+type CorrectionResponse struct {
+	AttendanceCorrection
+	UserName        string `json:"user_name"`
+	RequestedByName string `json:"requested_by_name"`
+	ApproverName    string `json:"approver_name,omitempty"`
+}
+
+// ToResponse converts AttendanceCorrection to CorrectionResponse
+func (c *AttendanceCorrection) ToResponse() CorrectionResponse {
+	response := CorrectionResponse{
+		AttendanceCorrection: *c,
+		UserName:             c.User.Name,
+		RequestedByName:      c.RequestedBy.Name,
+	}
+	if c.Approver != nil {
+		response.ApproverName = c.Approver.Name
+	}
+	return response
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+)
+
+// DailyAttendance is the materialized attendance status of one employee for one local day
+// Dibuat oleh daily summary job setelah hari di site karyawan selesai
+type DailyAttendance struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	UserID         uint       `json:"user_id" gorm:"not null;uniqueIndex:idx_daily_user_date"`
+	Date           string     `json:"date" gorm:"type:varchar(10);not null;uniqueIndex:idx_daily_user_date;index"` // YYYY-MM-DD lokal
+	Status         string     `json:"status" gorm:"type:varchar(20);not null;index"`
+	SiteID         *uint      `json:"site_id" gorm:"index"`
+	DepartmentID   *uint      `json:"department_id" gorm:"index"` // Snapshot assignment pada hari itu
+	TeamID         *uint      `json:"team_id" gorm:"index"`
+	Timezone       string     `json:"timezone" gorm:"type:varchar(64)"`
+	ScheduledStart *time.Time `json:"scheduled_start"`
+	FirstCheckIn   *time.Time `json:"first_check_in"`
+	AttendanceID   *uint      `json:"attendance_id"` // Check-in sukses pertama
+	CorrectionID   *uint      `json:"correction_id"` // Correction approved yang dipakai untuk check-in tersebut
+	LateMinutes    int        `json:"late_minutes"`
+	FailedAttempts int        `json:"failed_attempts"`
+	LeaveRequestID *uint      `json:"leave_request_id"` // Cuti approved yang menutupi hari ini
+	ComputedAt     time.Time  `json:"computed_at"`
+
+	// Relationship
+	User User `json:"-" gorm:"foreignKey:UserID"`
+}
+
+// TableName specifies the table name for DailyAttendance model
+func (DailyAttendance) TableName() string {
+	return "daily_attendances"
+}
+
+// DailyAttendanceResponse is the response struct with user name
+type DailyAttendanceResponse struct {
+	DailyAttendance
+	UserName string `json:"user_name"`
+}
+
+// ToResponse converts DailyAttendance to DailyAttendanceResponse
+func (d *DailyAttendance) ToResponse() DailyAttendanceResponse {
+	return DailyAttendanceResponse{
+		DailyAttendance: *d,
+		UserName:        d.User.Name,
+	}
+}
+
+// DailySummaryRun records that a scope (site or organization default) was materialized for a date
+type DailySummaryRun struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Scope       string    `json:"scope" gorm:"type:varchar(50);not null;uniqueIndex:idx_summary_scope_date"` // "org" atau "site:<id>"
+	Date        string    `json:"date" gorm:"type:varchar(10);not null;uniqueIndex:idx_summary_scope_date"`
+	Employees   int       `json:"employees"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+// TableName specifies the table name for DailySummaryRun model
+func (DailySummaryRun) TableName() string {
+	return "daily_summary_runs"
+}
+
+// Daily status constants
+const (
+	DailyStatusPresent = "present"
+	DailyStatusLate    = "late"
+	DailyStatusAbsent  = "absent"
+	DailyStatusOnLeave = "on_leave"
+	DailyStatusHoliday = "holiday"
+)
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"time"
+)
+
+// Leave request status
+const (
+	LeaveStatusPending   = "pending"
+	LeaveStatusApproved  = "approved"
+	LeaveStatusRejected  = "rejected"
+	LeaveStatusCancelled = "cancelled"
+)
+
+// Half-day leave, kosong berarti full day
+const (
+	HalfDayMorning   = "am"
+	HalfDayAfternoon = "pm"
+)
+
+// LeaveType defines a kind of leave and its yearly allowance (cuti tahunan, sakit, dll)
+type LeaveType struct {
+	ID                 uint      `json:"id" gorm:"primaryKey"`
+	Code               string    `json:"code" gorm:"type:varchar(50);uniqueIndex;not null"`
+	Name               string    `json:"name" gorm:"not null"`
+	AnnualAllowance    float64   `json:"annual_allowance"` // Hari per tahun, di-accrue setiap awal tahun
+	MaxCarryOver       float64   `json:"max_carry_over"`   // Sisa tahun lalu yang boleh dibawa
+	RequiresAttachment bool      `json:"requires_attachment"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for LeaveType model
+func (LeaveType) TableName() string {
+	return "leave_types"
+}
+
+// LeaveBalance tracks one employee's days for one leave type in one year
+type LeaveBalance struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	UserID      uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_leave_balance"`
+	LeaveTypeID uint      `json:"leave_type_id" gorm:"not null;uniqueIndex:idx_leave_balance"`
+	Year        int       `json:"year" gorm:"not null;uniqueIndex:idx_leave_balance"`
+	Accrued     float64   `json:"accrued"`
+	CarriedOver float64   `json:"carried_over"`
+	Used        float64   `json:"used"`
+	Pending     float64   `json:"pending"` // Sudah diajukan, belum diputuskan
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Relationship
+	LeaveType LeaveType `json:"leave_type" gorm:"foreignKey:LeaveTypeID"`
+}
+
+// TableName specifies the table name for LeaveBalance model
+func (LeaveBalance) TableName() string {
+	return "leave_balances"
+}
+
+// Available returns days that can still be requested
+func (b *LeaveBalance) Available() float64 {
+	return b.Accrued + b.CarriedOver - b.Used - b.Pending
+}
+
+// LeaveBalanceResponse adds the computed available days
+type LeaveBalanceResponse struct {
+	LeaveBalance
+	Available float64 `json:"available"`
+}
+
+// ToResponse converts LeaveBalance to LeaveBalanceResponse
+func (b *LeaveBalance) ToResponse() LeaveBalanceResponse {
+	return LeaveBalanceResponse{
+		LeaveBalance: *b,
+		Available:    b.Available(),
+	}
+}
+
+// LeaveRequest is an employee's request for time off
+type LeaveRequest struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	UserID         uint       `json:"user_id" gorm:"not null;index"`
+	LeaveTypeID    uint       `json:"leave_type_id" gorm:"not null"`
+	StartDate      string     `json:"start_date" gorm:"type:varchar(10);not null;index"` // YYYY-MM-DD lokal
+	EndDate        string     `json:"end_date" gorm:"type:varchar(10);not null;index"`
+	HalfDay        string     `json:"half_day" gorm:"type:varchar(2)"` // am / pm, hanya untuk request satu hari
+	Days           float64    `json:"days"`                            // Hari kerja yang terpotong dari balance
+	Reason         string     `json:"reason" gorm:"type:text"`
+	AttachmentPath string     `json:"-"` // Diakses lewat signed URL
+	Status         string     `json:"status" gorm:"type:varchar(20);not null;default:pending;index"`
+	ApproverID     *uint      `json:"approver_id"`
+	DecisionNote   string     `json:"decision_note,omitempty" gorm:"type:text"`
+	DecidedAt      *time.Time `json:"decided_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	// Relationships
+	User      User      `json:"-" gorm:"foreignKey:UserID"`
+	LeaveType LeaveType `json:"leave_type" gorm:"foreignKey:LeaveTypeID"`
+	Approver  *User     `json:"-" gorm:"foreignKey:ApproverID"`
+}
+
+// TableName specifies the table name for LeaveRequest model
+func (LeaveRequest) TableName() string {
+	return "leave_requests"
+}
+
+// Covers checks if the request includes the local date (YYYY-MM-DD)
+func (r *LeaveRequest) Covers(date string) bool {
+	return r.StartDate <= date && date <= r.EndDate
+}
+
+// LeaveRequestResponse adds employee and approver names
+type LeaveRequestResponse struct {
+	LeaveRequest
+	UserName      string `json:"user_name"`
+	ApproverName  string `json:"approver_name,omitempty"`
+	AttachmentURL string `json:"attachment_url,omitempty"` // Signed URL, hanya untuk user yang login
+}
+
+// ToResponse converts LeaveRequest to LeaveRequestResponse
+func (r *LeaveRequest) ToResponse() LeaveRequestResponse {
+	response := LeaveRequestResponse{
+		LeaveRequest: *r,
+		UserName:     r.User.Name,
+	}
+	if r.Approver != nil {
+		response.ApproverName = r.Approver.Name
+	}
+	return response
+}
@@ -0,0 +1,145 @@
+package models
+
+import (
+	"time"
+)
+
+// Department represents an organizational unit
+// ParentID membentuk hierarchy sehingga report bisa di-roll up ke unit di atasnya
+type Department struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"not null"`
+	Code      string    `json:"code" gorm:"type:varchar(50);uniqueIndex;not null"`
+	ParentID  *uint     `json:"parent_id" gorm:"index"`
+	ManagerID *uint     `json:"manager_id" gorm:"index"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Relationship, manager hanya ditampilkan lewat DepartmentResponse (nama saja)
+	Manager *User  `json:"-" gorm:"foreignKey:ManagerID"`
+	Teams   []Team `json:"teams,omitempty" gorm:"foreignKey:DepartmentID"`
+}
+
+// TableName specifies the table name for Department model
+func (Department) TableName() string {
+	return "departments"
+}
+
+// Team represents a group of employees inside a department
+type Team struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	Name         string    `json:"name" gorm:"not null"`
+	DepartmentID uint      `json:"department_id" gorm:"not null;index"`
+	ManagerID    *uint     `json:"manager_id" gorm:"index"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+
+	// Relationship
+	Department Department `json:"-" gorm:"foreignKey:DepartmentID"`
+	Manager    *User      `json:"-" gorm:"foreignKey:ManagerID"`
+}
+
+// TableName specifies the table name for Team model
+func (Team) TableName() string {
+	return "teams"
+}
+
+// DepartmentResponse adds the manager name, tanpa email, phone dan data lain manager
+type DepartmentResponse struct {
+	Department
+	ManagerName string `json:"manager_name,omitempty"`
+}
+
+// ToResponse converts Department to DepartmentResponse
+func (d *Department) ToResponse() DepartmentResponse {
+	response := DepartmentResponse{Department: *d}
+	if d.Manager != nil {
+		response.ManagerName = d.Manager.Name
+	}
+	return response
+}
+
+// TeamResponse adds the manager name
+type TeamResponse struct {
+	Team
+	ManagerName string `json:"manager_name,omitempty"`
+}
+
+// ToResponse converts Team to TeamResponse
+func (t *Team) ToResponse() TeamResponse {
+	response := TeamResponse{Team: *t}
+	if t.Manager != nil {
+		response.ManagerName = t.Manager.Name
+	}
+	return response
+}
+
+// EmployeeAssignment places an employee in a department/team for a period of time
+// EffectiveTo nil berarti assignment masih berlaku
+type EmployeeAssignment struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	UserID        uint       `json:"user_id" gorm:"not null;index"`
+	DepartmentID  uint       `json:"department_id" gorm:"not null;index"`
+	TeamID        *uint      `json:"team_id" gorm:"index"`
+	SiteID        *uint      `json:"site_id" gorm:"index"` // Home site, menentukan timezone karyawan
+	EffectiveFrom time.Time  `json:"effective_from" gorm:"not null;index"`
+	EffectiveTo   *time.Time `json:"effective_to" gorm:"index"`
+	CreatedAt     time.Time  `json:"created_at"`
+
+	// Relationship
+	Department Department `json:"-" gorm:"foreignKey:DepartmentID"`
+	Team       *Team      `json:"-" gorm:"foreignKey:TeamID"`
+	Site       *Site      `json:"-" gorm:"foreignKey:SiteID"`
+}
+
+// TableName specifies the table name for EmployeeAssignment model
+func (EmployeeAssignment) TableName() string {
+	return "employee_assignments"
+}
+
+// IsActiveAt checks if the assignment is effective at the given time
+func (a *EmployeeAssignment) IsActiveAt(t time.Time) bool {
+	if t.Before(a.EffectiveFrom) {
+		return false
+	}
+	return a.EffectiveTo == nil || t.Before(*a.EffectiveTo)
+}
+
+// AssignmentResponse is the response struct with department and team names
+type AssignmentResponse struct {
+	ID             uint       `json:"id"`
+	UserID         uint       `json:"user_id"`
+	DepartmentID   uint       `json:"department_id"`
+	DepartmentName string     `json:"department_name"`
+	TeamID         *uint      `json:"team_id"`
+	TeamName       string     `json:"team_name,omitempty"`
+	SiteID         *uint      `json:"site_id"`
+	SiteName       string     `json:"site_name,omitempty"`
+	EffectiveFrom  time.Time  `json:"effective_from"`
+	EffectiveTo    *time.Time `json:"effective_to"`
+}
+
+// ToResponse converts EmployeeAssignment to AssignmentResponse
+func (a *EmployeeAssignment) ToResponse() AssignmentResponse {
+	teamName := ""
+	if a.Team != nil {
+		teamName = a.Team.Name
+	}
+	siteName := ""
+	if a.Site != nil {
+		siteName = a.Site.Name
+	}
+
+	return AssignmentResponse{
+		ID:             a.ID,
+		UserID:         a.UserID,
+		DepartmentID:   a.DepartmentID,
+		DepartmentName: a.Department.Name,
+		TeamID:         a.TeamID,
+		TeamName:       teamName,
+		SiteID:         a.SiteID,
+		SiteName:       siteName,
+		EffectiveFrom:  a.EffectiveFrom,
+		EffectiveTo:    a.EffectiveTo,
+	}
+}
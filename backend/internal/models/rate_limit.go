@@ -0,0 +1,14 @@
+package models
+
+import (
+	"time"
+)
+
+// RateLimitBucket is the token bucket state of one rate limit key (RATE_LIMIT_STORE=database)
+// Key berisi rule dan IP / device / user ID, contoh "checkin_ip:10.0.0.7"
+type RateLimitBucket struct {
+	Key        string    `gorm:"column:bucket_key;primaryKey;size:255" json:"key"`
+	Tokens     float64   `gorm:"not null" json:"tokens"`
+	RefilledAt time.Time `gorm:"not null" json:"refilled_at"`
+	FullAt     time.Time `gorm:"not null;index" json:"full_at"` // Bucket penuh lagi, baris boleh dihapus
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+)
+
+// Retention purge categories
+const (
+	RetentionSuccessfulSelfie = "successful_selfie" // Selfie check-in yang berhasil
+	RetentionFailedSelfie     = "failed_selfie"     // Selfie check-in yang gagal, disimpan lebih lama untuk investigasi
+	RetentionReferencePhoto   = "reference_photo"   // Foto referensi + face descriptor karyawan yang sudah keluar
+)
+
+// Retention purge triggers
+const (
+	RetentionTriggerJob    = "job"
+	RetentionTriggerManual = "manual"
+	RetentionTriggerCLI    = "cli" // attendancectl purge, tanpa TriggeredByID
+)
+
+// RetentionPolicy is how many days biometric files are kept, 0 = disimpan selamanya
+type RetentionPolicy struct {
+	SuccessfulSelfieDays int `json:"successful_selfie_days"` // Dihitung dari check_in_time
+	FailedSelfieDays     int `json:"failed_selfie_days"`     // Dihitung dari check_in_time
+	ReferencePhotoDays   int `json:"reference_photo_days"`   // Dihitung dari deactivated_at (employment berakhir)
+}
+
+// Cutoff returns the time before which records of a category are purged
+// ok false kalau category disimpan selamanya
+func (p RetentionPolicy) Cutoff(category string, now time.Time) (cutoff time.Time, ok bool) {
+	days := 0
+	switch category {
+	case RetentionSuccessfulSelfie:
+		days = p.SuccessfulSelfieDays
+	case RetentionFailedSelfie:
+		days = p.FailedSelfieDays
+	case RetentionReferencePhoto:
+		days = p.ReferencePhotoDays
+	}
+	if days <= 0 {
+		return time.Time{}, false
+	}
+	return now.AddDate(0, 0, -days), true
+}
+
+// RetentionPurgeRun is the report of one purge run
+type RetentionPurgeRun struct {
+	ID                uint       `json:"id" gorm:"primaryKey"`
+	Trigger           string     `json:"trigger" gorm:"type:varchar(20);not null"` // job / manual / cli
+	TriggeredByID     *uint      `json:"triggered_by_id"`                          // Admin yang menjalankan purge manual
+	DryRun            bool       `json:"dry_run"`
+	SuccessfulSelfies int        `json:"successful_selfies"`
+	FailedSelfies     int        `json:"failed_selfies"`
+	ReferencePhotos   int        `json:"reference_photos"`
+	Held              int64      `json:"held"` // Record yang sudah lewat retention tapi ditahan legal hold
+	Failed            int        `json:"failed"`
+	Errors            string     `json:"errors,omitempty" gorm:"type:text"`
+	StartedAt         time.Time  `json:"started_at" gorm:"index"`
+	CompletedAt       *time.Time `json:"completed_at"`
+
+	Items []RetentionPurgeItem `json:"items,omitempty" gorm:"foreignKey:RunID"`
+}
+
+// TableName specifies the table name for RetentionPurgeRun model
+func (RetentionPurgeRun) TableName() string {
+	return "retention_purge_runs"
+}
+
+// RetentionPurgeItem records one purged (atau, saat dry run, akan di-purge) record
+type RetentionPurgeItem struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	RunID      uint      `json:"run_id" gorm:"not null;index"`
+	Category   string    `json:"category" gorm:"type:varchar(30);not null"`
+	EntityType string    `json:"entity_type" gorm:"type:varchar(30);not null;index:idx_retention_items_entity"` // attendance / employee
+	EntityID   uint      `json:"entity_id" gorm:"not null;index:idx_retention_items_entity"`
+	UserID     uint      `json:"user_id" gorm:"not null;index"`
+	RecordedAt time.Time `json:"recorded_at"` // check_in_time selfie atau deactivated_at karyawan
+	PurgedAt   time.Time `json:"purged_at"`
+}
+
+// TableName specifies the table name for RetentionPurgeItem model
+func (RetentionPurgeItem) TableName() string {
+	return "retention_purge_items"
+}
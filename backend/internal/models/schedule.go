@@ -0,0 +1,77 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WorkSchedule defines expected start time and working weekdays
+// Dipasang per site, site tanpa schedule memakai default dari config
+type WorkSchedule struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	Name             string    `json:"name" gorm:"not null"`
+	StartTime        string    `json:"start_time" gorm:"type:varchar(5);not null"` // HH:MM waktu lokal
+	LateGraceMinutes int       `json:"late_grace_minutes" gorm:"not null;default:0"`
+	WorkDays         string    `json:"work_days" gorm:"type:varchar(20);not null"` // ISO weekday, contoh: "1,2,3,4,5"
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for WorkSchedule model
+func (WorkSchedule) TableName() string {
+	return "work_schedules"
+}
+
+// Validate checks StartTime and WorkDays format
+func (s *WorkSchedule) Validate() error {
+	if _, _, err := s.StartClock(); err != nil {
+		return err
+	}
+	if _, err := ParseWorkDays(s.WorkDays); err != nil {
+		return err
+	}
+	if s.LateGraceMinutes < 0 {
+		return fmt.Errorf("late_grace_minutes must not be negative")
+	}
+	return nil
+}
+
+// StartClock returns hour and minute of StartTime
+func (s *WorkSchedule) StartClock() (int, int, error) {
+	t, err := time.Parse("15:04", s.StartTime)
+	if err != nil {
+		return 0, 0, fmt.Errorf("start_time must be in HH:MM format")
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+// IsWorkDay checks if weekday is part of WorkDays
+func (s *WorkSchedule) IsWorkDay(weekday time.Weekday) bool {
+	days, err := ParseWorkDays(s.WorkDays)
+	if err != nil {
+		return false
+	}
+	return days[weekday]
+}
+
+// ParseWorkDays parses ISO weekdays (1=Monday ... 7=Sunday) separated by comma
+func ParseWorkDays(value string) (map[time.Weekday]bool, error) {
+	days := make(map[time.Weekday]bool)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 1 || n > 7 {
+			return nil, fmt.Errorf("work_days must be ISO weekdays 1-7 separated by comma")
+		}
+		days[time.Weekday(n%7)] = true
+	}
+	if len(days) == 0 {
+		return nil, fmt.Errorf("work_days must contain at least one day")
+	}
+	return days, nil
+}
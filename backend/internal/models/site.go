@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+)
+
+// Site represents a physical office/branch where employees check in
+type Site struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"not null"`
+	Code      string    `json:"code" gorm:"type:varchar(50);uniqueIndex;not null"`
+	Address   string    `json:"address"`
+	Timezone  string    `json:"timezone" gorm:"type:varchar(64)"` // IANA timezone, kosong = ORG_TIMEZONE
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	WorkScheduleID *uint         `json:"work_schedule_id" gorm:"index"` // nil = default schedule dari config
+	WorkSchedule   *WorkSchedule `json:"work_schedule,omitempty" gorm:"foreignKey:WorkScheduleID"`
+}
+
+// TableName specifies the table name for Site model
+func (Site) TableName() string {
+	return "sites"
+}
@@ -6,17 +6,47 @@ import (
 
 // User represents employee data in the system
 type User struct {
-	ID              uint      `json:"id" gorm:"primaryKey"`
-	Name            string    `json:"name" gorm:"not null"`
-	Email           string    `json:"email" gorm:"uniqueIndex;not null"`
-	Phone           string    `json:"phone"`
-	FaceImagePath   string    `json:"face_image_path" gorm:"not null"` // Path to reference face photo
-	FaceDescriptor  string    `json:"-" gorm:"type:text"`              // JSON string storing face embedding/hash
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
-	
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	Name           string    `json:"name" gorm:"not null"`
+	Email          string    `json:"email" gorm:"uniqueIndex;not null"`
+	Phone          string    `json:"phone"`
+	FaceImagePath  string    `json:"-" gorm:"not null"`  // Path to reference face photo, diakses lewat signed URL
+	FaceDescriptor string    `json:"-" gorm:"type:text"` // JSON string storing face embedding/hash
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty" gorm:"index"` // nil = karyawan aktif
+
+	LegalHold     bool       `json:"legal_hold" gorm:"not null;default:false"` // Foto, descriptor dan semua selfie karyawan tidak di-purge
+	ImagePurgedAt *time.Time `json:"image_purged_at,omitempty"`                // Foto referensi dan descriptor sudah dihapus oleh retention purge
+	ErasedAt      *time.Time `json:"erased_at,omitempty"`                      // Data pribadi sudah dihapus atas permintaan karyawan (pseudonymized)
+
+	Role         string `json:"role" gorm:"type:varchar(20);not null;default:employee"`
+	PasswordHash string `json:"-"` // bcrypt, kosong = tidak bisa login dengan password
+
 	// Relationship: One user has many attendance records
 	Attendances []Attendance `json:"attendances,omitempty" gorm:"foreignKey:UserID"`
+
+	// Relationship: Department/team assignment history
+	Assignments []EmployeeAssignment `json:"-" gorm:"foreignKey:UserID"`
+}
+
+// User roles
+const (
+	RoleEmployee = "employee"
+	RoleManager  = "manager"
+	RoleHR       = "hr"
+	RoleAdmin    = "admin"
+	RoleAuditor  = "auditor" // Read-only akses ke audit log
+)
+
+// IsValidRole checks if role is one of the known roles
+func IsValidRole(role string) bool {
+	switch role {
+	case RoleEmployee, RoleManager, RoleHR, RoleAdmin, RoleAuditor:
+		return true
+	}
+	return false
 }
 
 // TableName specifies the table name for User model
@@ -26,22 +56,60 @@ func (User) TableName() string {
 
 // UserResponse is the response struct without sensitive data
 type UserResponse struct {
-	ID            uint      `json:"id"`
-	Name          string    `json:"name"`
-	Email         string    `json:"email"`
-	Phone         string    `json:"phone"`
-	FaceImagePath string    `json:"face_image_path"`
-	CreatedAt     time.Time `json:"created_at"`
+	ID            uint       `json:"id"`
+	Name          string     `json:"name"`
+	Email         string     `json:"email"`
+	Phone         string     `json:"phone"`
+	Role          string     `json:"role"`
+	CreatedAt     time.Time  `json:"created_at"`
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty"`
+	LegalHold     bool       `json:"legal_hold"`
+	ImagePurgedAt *time.Time `json:"image_purged_at,omitempty"`
+	ErasedAt      *time.Time `json:"erased_at,omitempty"`
+
+	// Signed URL foto referensi, hanya terisi untuk user yang login
+	FaceImageURL     string `json:"face_image_url,omitempty"`
+	FaceThumbnailURL string `json:"face_thumbnail_url,omitempty"`
+
+	Assignment *AssignmentResponse `json:"assignment,omitempty"`
 }
 
 // ToResponse converts User to UserResponse
 func (u *User) ToResponse() UserResponse {
-	return UserResponse{
+	response := UserResponse{
 		ID:            u.ID,
 		Name:          u.Name,
 		Email:         u.Email,
 		Phone:         u.Phone,
-		FaceImagePath: u.FaceImagePath,
+		Role:          u.Role,
 		CreatedAt:     u.CreatedAt,
+		DeactivatedAt: u.DeactivatedAt,
+		LegalHold:     u.LegalHold,
+		ImagePurgedAt: u.ImagePurgedAt,
+		ErasedAt:      u.ErasedAt,
+	}
+
+	// Include current assignment jika sudah di-preload
+	if assignment := u.CurrentAssignment(time.Now()); assignment != nil {
+		resp := assignment.ToResponse()
+		response.Assignment = &resp
+	}
+
+	return response
+}
+
+// DirectoryEntry is the kiosk view of an employee, hanya ID dan nama untuk memilih karyawan saat check-in
+type DirectoryEntry struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// CurrentAssignment returns the preloaded assignment effective at the given time
+func (u *User) CurrentAssignment(at time.Time) *EmployeeAssignment {
+	for i := range u.Assignments {
+		if u.Assignments[i].IsActiveAt(at) {
+			return &u.Assignments[i]
+		}
 	}
+	return nil
 }
@@ -0,0 +1,68 @@
+package ratelimit
+
+import (
+	"attendance-system/internal/models"
+	"context"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GormStore keeps buckets in the rate_limit_buckets table, dipakai bersama oleh semua instance
+// Setiap Take satu transaksi kecil; di PostgreSQL baris bucket dikunci (SELECT ... FOR UPDATE)
+// supaya dua instance tidak mengambil token yang sama
+type GormStore struct {
+	db        *gorm.DB
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+// NewGormStore creates a GormStore
+func NewGormStore(db *gorm.DB) *GormStore {
+	return &GormStore{db: db}
+}
+
+// Take takes n tokens from the bucket of key
+func (s *GormStore) Take(ctx context.Context, key string, limit Limit, n int, now time.Time) (Result, error) {
+	s.sweep(ctx, now)
+
+	var result Result
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Baris dibuat dulu (bucket penuh) supaya selalu ada baris yang bisa dikunci
+		row := models.RateLimitBucket{Key: key, Tokens: float64(limit.Requests), RefilledAt: now, FullAt: now}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&row).Error; err != nil {
+			return err
+		}
+
+		query := tx
+		if tx.Dialector.Name() == "postgres" {
+			query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+		}
+		if err := query.Where("bucket_key = ?", key).First(&row).Error; err != nil {
+			return err
+		}
+
+		b := bucket{tokens: row.Tokens, refilledAt: row.RefilledAt}
+		result = b.take(limit, n, now)
+		return tx.Model(&models.RateLimitBucket{}).Where("bucket_key = ?", key).UpdateColumns(map[string]interface{}{
+			"tokens": b.tokens, "refilled_at": b.refilledAt, "full_at": b.fullAt,
+		}).Error
+	})
+	return result, err
+}
+
+// sweep deletes buckets that are full again, paling sering sekali per sweepInterval per instance
+func (s *GormStore) sweep(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	if now.Sub(s.lastSweep) < sweepInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastSweep = now
+	s.mu.Unlock()
+
+	// Gagal hapus tidak masalah, dicoba lagi di sweep berikutnya
+	s.db.WithContext(ctx).Where("full_at <= ?", now).Delete(&models.RateLimitBucket{})
+}
@@ -0,0 +1,46 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often buckets that are full again are removed
+const sweepInterval = time.Minute
+
+// MemoryStore keeps buckets in process memory
+// Setiap instance punya bucket sendiri; untuk beberapa instance di belakang load balancer pakai GormStore
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket)}
+}
+
+// Take takes n tokens from the bucket of key
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit, n int, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Bucket yang sudah penuh sama dengan bucket baru, dihapus supaya map tidak tumbuh terus (IP / device acak)
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		for k, b := range s.buckets {
+			if !b.fullAt.After(now) {
+				delete(s.buckets, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{}
+		s.buckets[key] = b
+	}
+	return b.take(limit, n, now), nil
+}
@@ -0,0 +1,266 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Store drivers for RATE_LIMIT_STORE
+const (
+	StoreMemory   = "memory"   // Per instance, cukup untuk satu backend
+	StoreDatabase = "database" // Tabel rate_limit_buckets, dipakai bersama semua instance
+)
+
+// Rule names, juga dipakai sebagai label metrics dan prefix key bucket
+const (
+	RuleCheckInIP     = "checkin_ip"
+	RuleCheckInDevice = "checkin_device"
+	RuleCheckInUser   = "checkin_user"   // Per karyawan target (user_id di form check-in)
+	RuleCheckInFailed = "checkin_failed" // Verifikasi wajah gagal per karyawan target, lebih ketat
+	RuleRegisterIP    = "register_ip"
+	RuleRegisterUser  = "register_user" // Per user yang login (HR / admin)
+)
+
+// maxIDLength membatasi panjang ID dari input client (device_id) di key bucket, ID lebih panjang di-hash
+const maxIDLength = 64
+
+// Limit is a token bucket: Requests token, terisi penuh kembali dalam Per
+// Requests juga kapasitas bucket, jadi burst sebesar Requests tetap diizinkan
+type Limit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// ParseLimit parses "10/1m" (10 request per menit); "0" atau "off" mematikan rule
+func ParseLimit(raw string) (Limit, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "0" || strings.EqualFold(raw, "off") {
+		return Limit{}, nil
+	}
+	requests, per, ok := strings.Cut(raw, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("%q must be <requests>/<duration>, e.g. 10/1m, or off", raw)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(requests))
+	if err != nil || n < 1 {
+		return Limit{}, fmt.Errorf("%q: requests must be a positive number", raw)
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(per))
+	if err != nil || d < time.Second {
+		return Limit{}, fmt.Errorf("%q: duration must be at least 1s, e.g. 30s, 1m, 1h", raw)
+	}
+	return Limit{Requests: n, Per: d}, nil
+}
+
+// Enabled reports whether the rule limits anything
+func (l Limit) Enabled() bool {
+	return l.Requests > 0 && l.Per > 0
+}
+
+// String formats the limit like ParseLimit input
+func (l Limit) String() string {
+	if !l.Enabled() {
+		return "off"
+	}
+	return fmt.Sprintf("%d/%s", l.Requests, l.Per)
+}
+
+// rate returns the refill rate in tokens per second
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Per.Seconds()
+}
+
+// Result is the outcome of taking tokens from one bucket
+type Result struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration // Kapan token berikutnya tersedia, hanya kalau Allowed false
+}
+
+// Store keeps the bucket state
+type Store interface {
+	// Take refills the bucket up to now lalu mengambil n token kalau cukup
+	// n = 0 hanya memeriksa apakah masih ada token, tanpa mengambilnya
+	Take(ctx context.Context, key string, limit Limit, n int, now time.Time) (Result, error)
+}
+
+// bucket is the state of one key
+type bucket struct {
+	tokens     float64
+	refilledAt time.Time
+	fullAt     time.Time // Setelah waktu ini bucket penuh lagi, jadi boleh dihapus dari store
+}
+
+// take refills the bucket and removes n tokens if available
+func (b *bucket) take(limit Limit, n int, now time.Time) Result {
+	capacity := float64(limit.Requests)
+	if b.refilledAt.IsZero() {
+		b.tokens = capacity
+	} else if elapsed := now.Sub(b.refilledAt); elapsed > 0 {
+		b.tokens = math.Min(capacity, b.tokens+elapsed.Seconds()*limit.rate())
+	}
+	// Rule yang diperketat saat reload: sisa token tidak boleh melebihi kapasitas baru
+	b.tokens = math.Min(b.tokens, capacity)
+	if now.After(b.refilledAt) {
+		b.refilledAt = now
+	}
+
+	var result Result
+	if need := math.Max(float64(n), 1); b.tokens < need {
+		result.RetryAfter = seconds((need - b.tokens) / limit.rate())
+	} else {
+		b.tokens -= float64(n)
+		result = Result{Allowed: true, Remaining: int(b.tokens)}
+	}
+	b.fullAt = b.refilledAt.Add(seconds((capacity - b.tokens) / limit.rate()))
+	return result
+}
+
+// seconds converts fractional seconds to a Duration
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// Rules maps rule names to their limit, rule yang tidak ada atau Limit nol tidak membatasi
+type Rules map[string]Limit
+
+// Key identifies the bucket of a request for one rule
+type Key struct {
+	Rule string
+	ID   string // IP, device ID atau user ID
+	Peek bool   // Hanya cek sisa token; token diambil terpisah lewat Consume (contoh: verifikasi gagal)
+}
+
+// bucketKey returns the store key, ID panjang dari client di-hash supaya ukuran key tetap terbatas
+func (k Key) bucketKey() string {
+	id := k.ID
+	if len(id) > maxIDLength {
+		sum := sha256.Sum256([]byte(id))
+		id = "sha256:" + hex.EncodeToString(sum[:16])
+	}
+	return k.Rule + ":" + id
+}
+
+// Decision is the outcome of Allow
+type Decision struct {
+	Allowed    bool
+	Rule       string        // Rule yang menolak request
+	RetryAfter time.Duration // Untuk header Retry-After
+}
+
+// Limiter applies the configured rules on a store
+// Method pada receiver nil tidak membatasi apa pun (RATE_LIMIT_ENABLED=false)
+type Limiter struct {
+	store    Store
+	mu       sync.RWMutex
+	rules    Rules
+	failOpen bool
+}
+
+// NewLimiter creates a Limiter
+func NewLimiter(store Store, rules Rules) *Limiter {
+	return &Limiter{store: store, rules: rules}
+}
+
+// SetRules replaces the limits (config reload), state bucket yang sudah ada tetap dipakai
+func (l *Limiter) SetRules(rules Rules) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rules = rules
+}
+
+// SetFailOpen chooses what happens when the store errors: true = request dilayani, false = ditolak
+func (l *Limiter) SetFailOpen(failOpen bool) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.failOpen = failOpen
+}
+
+// FailOpen reports whether requests are allowed while the store errors
+func (l *Limiter) FailOpen() bool {
+	if l == nil {
+		return true
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.failOpen
+}
+
+// limit returns the limit of a rule
+func (l *Limiter) limit(rule string) Limit {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.rules[rule]
+}
+
+// Allow takes one token from every key, tapi hanya kalau semua bucket masih punya token
+// Semua bucket dicek dulu (tanpa mengambil token), jadi bucket lain tidak berkurang saat satu key menolak
+func (l *Limiter) Allow(ctx context.Context, keys ...Key) (Decision, error) {
+	if l == nil {
+		return Decision{Allowed: true}, nil
+	}
+	now := time.Now()
+	limits := make([]Limit, len(keys))
+	for i, key := range keys {
+		limits[i] = l.limit(key.Rule)
+		if !limits[i].Enabled() || key.ID == "" {
+			continue
+		}
+		decision, err := l.take(ctx, key, limits[i], 0, now)
+		if err != nil || !decision.Allowed {
+			return decision, err
+		}
+	}
+	for i, key := range keys {
+		if !limits[i].Enabled() || key.ID == "" || key.Peek {
+			continue
+		}
+		// Request lain bisa mengambil token di antara cek dan ambil, jadi hasilnya tetap diperiksa
+		decision, err := l.take(ctx, key, limits[i], 1, now)
+		if err != nil || !decision.Allowed {
+			return decision, err
+		}
+	}
+	return Decision{Allowed: true}, nil
+}
+
+// take takes n tokens from the bucket of key and converts the result to a Decision
+func (l *Limiter) take(ctx context.Context, key Key, limit Limit, n int, now time.Time) (Decision, error) {
+	result, err := l.store.Take(ctx, key.bucketKey(), limit, n, now)
+	if err != nil {
+		return Decision{}, fmt.Errorf("rate limit %s: %w", key.Rule, err)
+	}
+	if !result.Allowed {
+		return Decision{Rule: key.Rule, RetryAfter: result.RetryAfter}, nil
+	}
+	return Decision{Allowed: true}, nil
+}
+
+// Consume takes one token without rejecting anything, dipakai setelah kejadian yang dibatasi (verifikasi gagal)
+// Request berikutnya ditolak oleh Allow dengan Key.Peek selama bucket kosong
+func (l *Limiter) Consume(ctx context.Context, key Key) error {
+	if l == nil {
+		return nil
+	}
+	limit := l.limit(key.Rule)
+	if !limit.Enabled() || key.ID == "" {
+		return nil
+	}
+	if _, err := l.store.Take(ctx, key.bucketKey(), limit, 1, time.Now()); err != nil {
+		return fmt.Errorf("rate limit %s: %w", key.Rule, err)
+	}
+	return nil
+}
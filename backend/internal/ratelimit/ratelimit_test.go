@@ -0,0 +1,134 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBucketTake(t *testing.T) {
+	limit := Limit{Requests: 3, Per: 30 * time.Second} // 1 token per 10 detik
+	start := time.Date(2024, 3, 4, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		after         time.Duration // Sejak start
+		n             int
+		wantAllowed   bool
+		wantRemaining int
+		wantRetry     time.Duration
+	}{
+		{"new bucket starts full", 0, 1, true, 2, 0},
+		{"burst up to capacity", 0, 1, true, 1, 0},
+		{"last token", 0, 1, true, 0, 0},
+		{"empty bucket rejects", 0, 1, false, 0, 10 * time.Second},
+		{"peek on empty bucket rejects", 5 * time.Second, 0, false, 0, 5 * time.Second},
+		{"refilled one token", 10 * time.Second, 1, true, 0, 0},
+		{"peek does not take", 20 * time.Second, 0, true, 1, 0},
+		{"refill stops at capacity", 10 * time.Minute, 1, true, 2, 0},
+	}
+
+	var b bucket
+	for _, tt := range tests {
+		result := b.take(limit, tt.n, start.Add(tt.after))
+		if result.Allowed != tt.wantAllowed || result.Remaining != tt.wantRemaining || result.RetryAfter != tt.wantRetry {
+			t.Fatalf("%s: got %+v, want allowed=%v remaining=%d retry=%s",
+				tt.name, result, tt.wantAllowed, tt.wantRemaining, tt.wantRetry)
+		}
+	}
+}
+
+func TestBucketTakeShrunkLimit(t *testing.T) {
+	now := time.Date(2024, 3, 4, 8, 0, 0, 0, time.UTC)
+	var b bucket
+	b.take(Limit{Requests: 10, Per: time.Minute}, 1, now)
+
+	// Rule diperketat saat reload: sisa 9 token dipotong ke kapasitas baru
+	result := b.take(Limit{Requests: 2, Per: time.Minute}, 1, now)
+	if !result.Allowed || result.Remaining != 1 {
+		t.Fatalf("got %+v, want allowed with 1 remaining", result)
+	}
+}
+
+func TestLimiterAllow(t *testing.T) {
+	ctx := context.Background()
+	rules := Rules{
+		RuleCheckInIP:     {Requests: 5, Per: time.Minute},
+		RuleCheckInUser:   {Requests: 1, Per: time.Minute},
+		RuleCheckInFailed: {Requests: 1, Per: time.Minute},
+	}
+	store := NewMemoryStore()
+	limiter := NewLimiter(store, rules)
+
+	remaining := func(key Key) int {
+		t.Helper()
+		result, err := store.Take(ctx, key.bucketKey(), rules[key.Rule], 0, time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result.Remaining
+	}
+
+	ip := Key{Rule: RuleCheckInIP, ID: "10.0.0.1"}
+	user := Key{Rule: RuleCheckInUser, ID: "2"}
+	failed := Key{Rule: RuleCheckInFailed, ID: "2", Peek: true}
+
+	decision, err := limiter.Allow(ctx, ip, user, failed)
+	if err != nil || !decision.Allowed {
+		t.Fatalf("first request: %+v, %v", decision, err)
+	}
+	if got := remaining(ip); got != 4 {
+		t.Fatalf("ip bucket has %d tokens, want 4", got)
+	}
+	if got := remaining(failed); got != 1 {
+		t.Fatalf("peeked bucket has %d tokens, want 1 (peek must not take)", got)
+	}
+
+	// Bucket user habis: request ditolak dan bucket IP yang dicek lebih dulu tidak ikut berkurang
+	decision, err = limiter.Allow(ctx, ip, user, failed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decision.Allowed || decision.Rule != RuleCheckInUser || decision.RetryAfter <= 0 {
+		t.Fatalf("second request: %+v, want rejected by %s with Retry-After", decision, RuleCheckInUser)
+	}
+	if got := remaining(ip); got != 4 {
+		t.Fatalf("ip bucket has %d tokens after rejection, want 4", got)
+	}
+
+	// Verifikasi gagal menghabiskan bucket peek, request karyawan lain dari IP yang sama tetap lolos
+	if err := limiter.Consume(ctx, failed); err != nil {
+		t.Fatal(err)
+	}
+	decision, err = limiter.Allow(ctx, ip, failed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decision.Allowed || decision.Rule != RuleCheckInFailed {
+		t.Fatalf("after failed verification: %+v, want rejected by %s", decision, RuleCheckInFailed)
+	}
+	other := Key{Rule: RuleCheckInUser, ID: "3"}
+	if decision, err := limiter.Allow(ctx, ip, other); err != nil || !decision.Allowed {
+		t.Fatalf("other employee: %+v, %v", decision, err)
+	}
+	if got := remaining(ip); got != 3 {
+		t.Fatalf("ip bucket has %d tokens, want 3", got)
+	}
+}
+
+func TestLimiterAllowDisabled(t *testing.T) {
+	ctx := context.Background()
+	var disabled *Limiter
+	if decision, err := disabled.Allow(ctx, Key{Rule: RuleCheckInIP, ID: "10.0.0.1"}); err != nil || !decision.Allowed {
+		t.Fatalf("nil limiter: %+v, %v", decision, err)
+	}
+
+	// Rule off dan key tanpa ID tidak membatasi
+	limiter := NewLimiter(NewMemoryStore(), Rules{RuleRegisterIP: {}, RuleRegisterUser: {Requests: 1, Per: time.Minute}})
+	for i := 0; i < 3; i++ {
+		decision, err := limiter.Allow(ctx, Key{Rule: RuleRegisterIP, ID: "10.0.0.1"}, Key{Rule: RuleRegisterUser})
+		if err != nil || !decision.Allowed {
+			t.Fatalf("request %d: %+v, %v", i, decision, err)
+		}
+	}
+}
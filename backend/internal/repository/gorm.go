@@ -0,0 +1,390 @@
+package repository
+
+import (
+	"attendance-system/internal/models"
+	"attendance-system/internal/utils"
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// attendanceBatchSize is the batch size of EachInRange
+const attendanceBatchSize = 500
+
+// GormUserRepository implements UserRepository with GORM
+type GormUserRepository struct {
+	db *gorm.DB
+}
+
+// NewGormUserRepository creates a new GormUserRepository
+func NewGormUserRepository(db *gorm.DB) *GormUserRepository {
+	return &GormUserRepository{db: db}
+}
+
+// FindByID returns an employee with the assignment effective now
+func (r *GormUserRepository) FindByID(ctx context.Context, id uint) (*models.User, error) {
+	now := time.Now()
+	var user models.User
+	err := r.db.WithContext(ctx).Preload("Assignments", "effective_from <= ? AND (effective_to IS NULL OR effective_to > ?)", now, now).
+		Preload("Assignments.Department").
+		Preload("Assignments.Team").
+		Preload("Assignments.Site").
+		First(&user, id).Error
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &user, nil
+}
+
+// Create stores a new employee and their consent in one transaction
+func (r *GormUserRepository) Create(ctx context.Context, user *models.User, consent *models.BiometricConsent) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		if consent == nil {
+			return nil
+		}
+		consent.UserID = user.ID
+		return tx.Create(consent).Error
+	})
+}
+
+// UpdateFace replaces the reference photo and face descriptor
+func (r *GormUserRepository) UpdateFace(ctx context.Context, id uint, imagePath, descriptor string) error {
+	result := r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"face_image_path": imagePath, "face_descriptor": descriptor, "image_purged_at": nil,
+	})
+	return affected(result)
+}
+
+// SetLegalHold sets or releases the legal hold of an employee
+func (r *GormUserRepository) SetLegalHold(ctx context.Context, id uint, hold bool) error {
+	return affected(r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).UpdateColumn("legal_hold", hold))
+}
+
+// List returns employees ordered by ID
+func (r *GormUserRepository) List(ctx context.Context, includeDeactivated bool) ([]models.User, error) {
+	query := r.db.WithContext(ctx).Order("id")
+	if !includeDeactivated {
+		query = query.Where("deactivated_at IS NULL")
+	}
+	var users []models.User
+	if err := query.Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// Deactivate marks an active employee as deactivated
+func (r *GormUserRepository) Deactivate(ctx context.Context, id uint, at time.Time) error {
+	result := r.db.WithContext(ctx).Model(&models.User{}).Where("id = ? AND deactivated_at IS NULL", id).Update("deactivated_at", at)
+	return affected(result)
+}
+
+// UpdateCredentials sets role and/or password hash, nilai kosong tidak diubah
+func (r *GormUserRepository) UpdateCredentials(ctx context.Context, id uint, role, passwordHash string) error {
+	updates := map[string]interface{}{}
+	if role != "" {
+		updates["role"] = role
+	}
+	if passwordHash != "" {
+		updates["password_hash"] = passwordHash
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+	return affected(r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).Updates(updates))
+}
+
+// Page returns one page of employees with the assignment effective now
+func (r *GormUserRepository) Page(ctx context.Context, filter UserFilter, page utils.PageParams) ([]models.User, int64, error) {
+	now := time.Now()
+	query := r.db.WithContext(ctx).Model(&models.User{})
+
+	if filter.Visible != nil {
+		sql, args := visibleCondition("users.id", *filter.Visible, now)
+		query = query.Where(sql, args...)
+	}
+	if filter.Search != "" {
+		pattern := likePattern(filter.Search)
+		query = query.Where("LOWER(users.name) LIKE ? ESCAPE '\\' OR LOWER(users.email) LIKE ? ESCAPE '\\'", pattern, pattern)
+	}
+	if filter.Org != nil {
+		sql, args := currentAssigneesSQL(*filter.Org, now)
+		query = query.Where("users.id IN ("+sql+")", args...)
+	}
+	if filter.ConsentStatus != "" {
+		sql, args, err := ConsentStatusCondition(filter.ConsentStatus, filter.PolicyVersion)
+		if err != nil {
+			return nil, 0, err
+		}
+		query = query.Where(sql, args...)
+	}
+
+	// Total sebelum cursor dan limit
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query, err := page.Apply(query.Preload("Assignments", "effective_from <= ? AND (effective_to IS NULL OR effective_to > ?)", now, now).
+		Preload("Assignments.Department").
+		Preload("Assignments.Team").
+		Preload("Assignments.Site"))
+	if err != nil {
+		return nil, 0, err
+	}
+	var users []models.User
+	if err := query.Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// GormAttendanceRepository implements AttendanceRepository with GORM
+type GormAttendanceRepository struct {
+	db *gorm.DB
+}
+
+// NewGormAttendanceRepository creates a new GormAttendanceRepository
+func NewGormAttendanceRepository(db *gorm.DB) *GormAttendanceRepository {
+	return &GormAttendanceRepository{db: db}
+}
+
+// Create stores a new check-in record
+func (r *GormAttendanceRepository) Create(ctx context.Context, attendance *models.Attendance) error {
+	return r.db.WithContext(ctx).Create(attendance).Error
+}
+
+// FindByID returns a check-in record
+func (r *GormAttendanceRepository) FindByID(ctx context.Context, id uint) (*models.Attendance, error) {
+	var attendance models.Attendance
+	if err := r.db.WithContext(ctx).First(&attendance, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &attendance, nil
+}
+
+// LatestSuccessful returns the latest successful check-in in [from, to) with its employee
+func (r *GormAttendanceRepository) LatestSuccessful(ctx context.Context, userID uint, from, to time.Time) (*models.Attendance, error) {
+	var attendance models.Attendance
+	err := r.db.WithContext(ctx).Preload("User").
+		Where("user_id = ? AND check_in_time >= ? AND check_in_time < ?", userID, from, to).
+		Where("status = ?", models.AttendanceStatusSuccess).
+		Order("check_in_time DESC").
+		First(&attendance).Error
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return &attendance, nil
+}
+
+// SetLegalHold sets or releases the legal hold of a check-in selfie
+func (r *GormAttendanceRepository) SetLegalHold(ctx context.Context, id uint, hold bool) error {
+	return affected(r.db.WithContext(ctx).Model(&models.Attendance{}).Where("id = ?", id).UpdateColumn("legal_hold", hold))
+}
+
+// EachInRange calls fn with batches of check-ins in [from, to)
+func (r *GormAttendanceRepository) EachInRange(ctx context.Context, from, to time.Time, userID uint, fn func([]models.Attendance) error) error {
+	query := r.db.WithContext(ctx).Preload("User").Where("check_in_time >= ? AND check_in_time < ?", from, to)
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	// Session: query dipakai ulang tiap batch tanpa menumpuk kondisi keyset sebelumnya
+	query = query.Session(&gorm.Session{})
+
+	// Keyset per (check_in_time, id) supaya export rentang panjang tidak memuat semua baris sekaligus
+	var last *models.Attendance
+	for {
+		batchQuery := query
+		if last != nil {
+			batchQuery = batchQuery.Where("check_in_time > ? OR (check_in_time = ? AND id > ?)",
+				last.CheckInTime, last.CheckInTime, last.ID)
+		}
+		var batch []models.Attendance
+		if err := batchQuery.Order("check_in_time, id").Limit(attendanceBatchSize).Find(&batch).Error; err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+		if len(batch) < attendanceBatchSize {
+			return nil
+		}
+		last = &batch[len(batch)-1]
+	}
+}
+
+// Page returns one page of check-ins with their employee
+func (r *GormAttendanceRepository) Page(ctx context.Context, filter AttendanceFilter, page utils.PageParams) ([]models.Attendance, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.Attendance{})
+
+	if filter.Visible != nil {
+		sql, args := visibleCondition("attendances.user_id", *filter.Visible, time.Now())
+		query = query.Where(sql, args...)
+	}
+	if filter.UserID != 0 {
+		query = query.Where("attendances.user_id = ?", filter.UserID)
+	}
+	if filter.SiteID != 0 {
+		query = query.Where("attendances.site_id = ?", filter.SiteID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("attendances.check_in_time >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("attendances.check_in_time < ?", filter.To)
+	}
+	if filter.Status != "" {
+		query = query.Where("attendances.status = ?", filter.Status)
+	}
+	if filter.DeviceID != "" {
+		query = query.Where("attendances.device_id = ?", filter.DeviceID)
+	}
+	if filter.Search != "" {
+		pattern := likePattern(filter.Search)
+		query = query.Where("attendances.user_id IN (SELECT id FROM users WHERE LOWER(name) LIKE ? ESCAPE '\\' OR LOWER(email) LIKE ? ESCAPE '\\')", pattern, pattern)
+	}
+	if filter.Org != nil {
+		// Assignment yang berlaku saat check-in, bukan sekarang
+		sql, args := AssignmentConditions(*filter.Org)
+		query = query.Where("EXISTS (SELECT 1 FROM employee_assignments ea LEFT JOIN teams t ON t.id = ea.team_id "+
+			"WHERE ea.user_id = attendances.user_id AND ea.effective_from <= attendances.check_in_time "+
+			"AND (ea.effective_to IS NULL OR ea.effective_to > attendances.check_in_time) AND "+sql+")", args...)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query, err := page.Apply(query.Preload("User"))
+	if err != nil {
+		return nil, 0, err
+	}
+	var attendances []models.Attendance
+	if err := query.Find(&attendances).Error; err != nil {
+		return nil, 0, err
+	}
+	return attendances, total, nil
+}
+
+// GormSiteRepository implements SiteRepository with GORM
+type GormSiteRepository struct {
+	db *gorm.DB
+}
+
+// NewGormSiteRepository creates a new GormSiteRepository
+func NewGormSiteRepository(db *gorm.DB) *GormSiteRepository {
+	return &GormSiteRepository{db: db}
+}
+
+// FindByID returns a site
+func (r *GormSiteRepository) FindByID(ctx context.Context, id uint) (*models.Site, error) {
+	var site models.Site
+	if err := r.db.WithContext(ctx).First(&site, id).Error; err != nil {
+		return nil, notFound(err)
+	}
+	return &site, nil
+}
+
+// latestConsentSQL selects the latest consent record of users.id
+const latestConsentSQL = "SELECT MAX(lc.id) FROM biometric_consents lc WHERE lc.user_id = users.id"
+
+// ConsentStatusCondition builds the SQL condition on users for a consent status
+// Dipakai juga oleh ConsentService untuk statistik coverage
+func ConsentStatusCondition(status, policyVersion string) (string, []interface{}, error) {
+	switch status {
+	case models.ConsentStatusConsented:
+		return "EXISTS (SELECT 1 FROM biometric_consents bc WHERE bc.id = (" + latestConsentSQL + ") AND bc.withdrawn_at IS NULL AND bc.policy_version = ?)",
+			[]interface{}{policyVersion}, nil
+	case models.ConsentStatusOutdated:
+		return "EXISTS (SELECT 1 FROM biometric_consents bc WHERE bc.id = (" + latestConsentSQL + ") AND bc.withdrawn_at IS NULL AND bc.policy_version <> ?)",
+			[]interface{}{policyVersion}, nil
+	case models.ConsentStatusWithdrawn:
+		return "EXISTS (SELECT 1 FROM biometric_consents bc WHERE bc.id = (" + latestConsentSQL + ") AND bc.withdrawn_at IS NOT NULL)", nil, nil
+	case models.ConsentStatusMissing:
+		return "NOT EXISTS (SELECT 1 FROM biometric_consents bc WHERE bc.user_id = users.id)", nil, nil
+	}
+	return "", nil, ErrInvalidConsentStatus
+}
+
+// AssignmentConditions builds SQL conditions on employee_assignments (ea) and teams (t)
+// Dipakai juga oleh OrgService untuk scope dan cek manager
+func AssignmentConditions(filter AssignmentFilter) (string, []interface{}) {
+	var conds []string
+	var args []interface{}
+
+	if filter.DepartmentIDs != nil {
+		conds = append(conds, "ea.department_id IN ?")
+		args = append(args, filter.DepartmentIDs)
+	}
+	if filter.TeamID != 0 {
+		conds = append(conds, "ea.team_id = ?")
+		args = append(args, filter.TeamID)
+	}
+	if filter.ManagerID != 0 {
+		// Manager melihat tim yang dia pimpin dan semua department (beserta sub-department) yang dia pimpin
+		if len(filter.ManagedDepartmentIDs) > 0 {
+			conds = append(conds, "(t.manager_id = ? OR ea.department_id IN ?)")
+			args = append(args, filter.ManagerID, filter.ManagedDepartmentIDs)
+		} else {
+			conds = append(conds, "t.manager_id = ?")
+			args = append(args, filter.ManagerID)
+		}
+	}
+
+	if len(conds) == 0 {
+		conds = append(conds, "1 = 1")
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+// currentAssigneesSQL selects the IDs of employees whose assignment effective at now matches the filter
+func currentAssigneesSQL(filter AssignmentFilter, now time.Time) (string, []interface{}) {
+	conds, args := AssignmentConditions(filter)
+	sql := "SELECT ea.user_id FROM employee_assignments ea LEFT JOIN teams t ON t.id = ea.team_id " +
+		"WHERE ea.effective_from <= ? AND (ea.effective_to IS NULL OR ea.effective_to > ?) AND " + conds
+	return sql, append([]interface{}{now, now}, args...)
+}
+
+// visibleCondition restricts column (ID karyawan) to the visible employees
+func visibleCondition(column string, visible Visibility, now time.Time) (string, []interface{}) {
+	if visible.Reports == nil {
+		return column + " = ?", []interface{}{visible.UserID}
+	}
+	sql, args := currentAssigneesSQL(*visible.Reports, now)
+	return column + " = ? OR " + column + " IN (" + sql + ")", append([]interface{}{visible.UserID}, args...)
+}
+
+// likePattern builds a lowercase LIKE pattern with escaped wildcards
+func likePattern(search string) string {
+	replacer := strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_")
+	return "%" + replacer.Replace(strings.ToLower(strings.TrimSpace(search))) + "%"
+}
+
+// notFound maps gorm.ErrRecordNotFound to ErrNotFound
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// affected returns ErrNotFound kalau update tidak mengenai record apa pun
+func affected(result *gorm.DB) error {
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
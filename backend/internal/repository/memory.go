@@ -0,0 +1,450 @@
+package repository
+
+import (
+	"attendance-system/internal/models"
+	"attendance-system/internal/utils"
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryUserRepository is an in-memory UserRepository for handler tests
+type MemoryUserRepository struct {
+	mu       sync.Mutex
+	nextID   uint
+	users    map[uint]models.User
+	consents []models.BiometricConsent
+}
+
+// NewMemoryUserRepository creates an empty MemoryUserRepository
+func NewMemoryUserRepository(users ...models.User) *MemoryUserRepository {
+	r := &MemoryUserRepository{users: make(map[uint]models.User)}
+	for _, user := range users {
+		u := user
+		if err := r.Create(context.Background(), &u, nil); err != nil {
+			panic(err)
+		}
+	}
+	return r
+}
+
+// FindByID returns a copy of the stored employee
+func (r *MemoryUserRepository) FindByID(ctx context.Context, id uint) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &user, nil
+}
+
+// Create stores a new employee, email harus unik seperti di database
+func (r *MemoryUserRepository) Create(ctx context.Context, user *models.User, consent *models.BiometricConsent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.users {
+		if strings.EqualFold(existing.Email, user.Email) {
+			return fmt.Errorf("duplicate email %s", user.Email)
+		}
+	}
+
+	if user.ID == 0 {
+		r.nextID++
+		user.ID = r.nextID
+	} else if user.ID > r.nextID {
+		r.nextID = user.ID
+	}
+	now := time.Now()
+	user.CreatedAt, user.UpdatedAt = now, now
+	r.users[user.ID] = *user
+
+	if consent != nil {
+		consent.ID = uint(len(r.consents) + 1)
+		consent.UserID = user.ID
+		consent.CreatedAt = now
+		r.consents = append(r.consents, *consent)
+	}
+	return nil
+}
+
+// UpdateFace replaces the reference photo and face descriptor
+func (r *MemoryUserRepository) UpdateFace(ctx context.Context, id uint, imagePath, descriptor string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return ErrNotFound
+	}
+	user.FaceImagePath = imagePath
+	user.FaceDescriptor = descriptor
+	user.ImagePurgedAt = nil
+	r.users[id] = user
+	return nil
+}
+
+// SetLegalHold sets or releases the legal hold of an employee
+func (r *MemoryUserRepository) SetLegalHold(ctx context.Context, id uint, hold bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return ErrNotFound
+	}
+	user.LegalHold = hold
+	r.users[id] = user
+	return nil
+}
+
+// List returns employees ordered by ID
+func (r *MemoryUserRepository) List(ctx context.Context, includeDeactivated bool) ([]models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	users := make([]models.User, 0, len(r.users))
+	for _, user := range r.users {
+		if includeDeactivated || user.DeactivatedAt == nil {
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+// Deactivate marks an active employee as deactivated
+func (r *MemoryUserRepository) Deactivate(ctx context.Context, id uint, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok || user.DeactivatedAt != nil {
+		return ErrNotFound
+	}
+	user.DeactivatedAt = &at
+	r.users[id] = user
+	return nil
+}
+
+// UpdateCredentials sets role and/or password hash, nilai kosong tidak diubah
+func (r *MemoryUserRepository) UpdateCredentials(ctx context.Context, id uint, role, passwordHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if role != "" {
+		user.Role = role
+	}
+	if passwordHash != "" {
+		user.PasswordHash = passwordHash
+	}
+	r.users[id] = user
+	return nil
+}
+
+// Page returns one page of employees, hanya assignment yang berlaku sekarang yang ikut
+// Assignment disimpan di User.Assignments (beserta Team untuk filter manager)
+func (r *MemoryUserRepository) Page(ctx context.Context, filter UserFilter, page utils.PageParams) ([]models.User, int64, error) {
+	if filter.ConsentStatus != "" {
+		if _, _, err := ConsentStatusCondition(filter.ConsentStatus, filter.PolicyVersion); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	r.mu.Lock()
+	now := time.Now()
+	var matches []models.User
+	for _, user := range r.users {
+		if filter.Visible != nil && !isVisible(&user, *filter.Visible, now) {
+			continue
+		}
+		if filter.Search != "" && !matchesSearch(&user, filter.Search) {
+			continue
+		}
+		if filter.Org != nil && !hasAssignment(&user, *filter.Org, now) {
+			continue
+		}
+		if filter.ConsentStatus != "" && r.latestConsent(user.ID).Status(filter.PolicyVersion) != filter.ConsentStatus {
+			continue
+		}
+		user.Assignments = slices.DeleteFunc(slices.Clone(user.Assignments), func(a models.EmployeeAssignment) bool {
+			return !a.IsActiveAt(now)
+		})
+		matches = append(matches, user)
+	}
+	r.mu.Unlock()
+
+	users, err := utils.PageSlice(page, matches, func(u models.User) interface{} {
+		switch page.SortKey {
+		case "email":
+			return u.Email
+		case "created_at":
+			return u.CreatedAt
+		}
+		return u.Name
+	}, func(u models.User) uint { return u.ID })
+	if err != nil {
+		return nil, 0, err
+	}
+	return users, int64(len(matches)), nil
+}
+
+// latestConsent returns the latest consent of an employee, nil kalau belum ada (caller memegang mu)
+func (r *MemoryUserRepository) latestConsent(userID uint) *models.BiometricConsent {
+	var latest *models.BiometricConsent
+	for i := range r.consents {
+		if r.consents[i].UserID == userID && (latest == nil || r.consents[i].ID > latest.ID) {
+			latest = &r.consents[i]
+		}
+	}
+	return latest
+}
+
+// Consents returns the consent records stored with Create
+func (r *MemoryUserRepository) Consents(userID uint) []models.BiometricConsent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var consents []models.BiometricConsent
+	for _, consent := range r.consents {
+		if consent.UserID == userID {
+			consents = append(consents, consent)
+		}
+	}
+	return consents
+}
+
+// MemoryAttendanceRepository is an in-memory AttendanceRepository for handler tests
+// users dipakai untuk mengisi Attendance.User seperti Preload di implementasi GORM
+type MemoryAttendanceRepository struct {
+	mu          sync.Mutex
+	users       UserRepository
+	attendances []models.Attendance
+}
+
+// NewMemoryAttendanceRepository creates an empty MemoryAttendanceRepository
+func NewMemoryAttendanceRepository(users UserRepository) *MemoryAttendanceRepository {
+	return &MemoryAttendanceRepository{users: users}
+}
+
+// Create stores a new check-in record
+func (r *MemoryAttendanceRepository) Create(ctx context.Context, attendance *models.Attendance) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attendance.ID = uint(len(r.attendances) + 1)
+	now := time.Now()
+	attendance.CreatedAt = now
+	if attendance.CheckInTime.IsZero() {
+		attendance.CheckInTime = now
+	}
+	stored := *attendance
+	stored.User = models.User{}
+	r.attendances = append(r.attendances, stored)
+	return nil
+}
+
+// FindByID returns a copy of the stored check-in record
+func (r *MemoryAttendanceRepository) FindByID(ctx context.Context, id uint) (*models.Attendance, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id == 0 || int(id) > len(r.attendances) {
+		return nil, ErrNotFound
+	}
+	attendance := r.attendances[id-1]
+	return &attendance, nil
+}
+
+// LatestSuccessful returns the latest successful check-in in [from, to) with its employee
+func (r *MemoryAttendanceRepository) LatestSuccessful(ctx context.Context, userID uint, from, to time.Time) (*models.Attendance, error) {
+	r.mu.Lock()
+	var matches []models.Attendance
+	for _, attendance := range r.attendances {
+		if attendance.UserID == userID && attendance.Status == models.AttendanceStatusSuccess &&
+			!attendance.CheckInTime.Before(from) && attendance.CheckInTime.Before(to) {
+			matches = append(matches, attendance)
+		}
+	}
+	r.mu.Unlock()
+
+	if len(matches) == 0 {
+		return nil, ErrNotFound
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].CheckInTime.After(matches[j].CheckInTime)
+	})
+
+	latest := matches[0]
+	if r.users != nil {
+		user, err := r.users.FindByID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		latest.User = *user
+	}
+	return &latest, nil
+}
+
+// SetLegalHold sets or releases the legal hold of a check-in selfie
+func (r *MemoryAttendanceRepository) SetLegalHold(ctx context.Context, id uint, hold bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id == 0 || int(id) > len(r.attendances) {
+		return ErrNotFound
+	}
+	r.attendances[id-1].LegalHold = hold
+	return nil
+}
+
+// EachInRange calls fn once with every check-in in [from, to)
+func (r *MemoryAttendanceRepository) EachInRange(ctx context.Context, from, to time.Time, userID uint, fn func([]models.Attendance) error) error {
+	r.mu.Lock()
+	var matches []models.Attendance
+	for _, attendance := range r.attendances {
+		if (userID == 0 || attendance.UserID == userID) &&
+			!attendance.CheckInTime.Before(from) && attendance.CheckInTime.Before(to) {
+			matches = append(matches, attendance)
+		}
+	}
+	r.mu.Unlock()
+
+	if len(matches) == 0 {
+		return nil
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].CheckInTime.Equal(matches[j].CheckInTime) {
+			return matches[i].ID < matches[j].ID
+		}
+		return matches[i].CheckInTime.Before(matches[j].CheckInTime)
+	})
+	if r.users != nil {
+		for i := range matches {
+			if user, err := r.users.FindByID(ctx, matches[i].UserID); err == nil {
+				matches[i].User = *user
+			}
+		}
+	}
+	return fn(matches)
+}
+
+// Page returns one page of check-ins with their employee
+// Visibility, search dan filter org dinilai dari karyawan di users, jadi users wajib diisi untuk filter itu
+func (r *MemoryAttendanceRepository) Page(ctx context.Context, filter AttendanceFilter, page utils.PageParams) ([]models.Attendance, int64, error) {
+	r.mu.Lock()
+	candidates := slices.Clone(r.attendances)
+	r.mu.Unlock()
+
+	var matches []models.Attendance
+	for _, attendance := range candidates {
+		if (filter.UserID != 0 && attendance.UserID != filter.UserID) ||
+			(filter.SiteID != 0 && (attendance.SiteID == nil || *attendance.SiteID != filter.SiteID)) ||
+			(!filter.From.IsZero() && attendance.CheckInTime.Before(filter.From)) ||
+			(!filter.To.IsZero() && !attendance.CheckInTime.Before(filter.To)) ||
+			(filter.Status != "" && attendance.Status != filter.Status) ||
+			(filter.DeviceID != "" && attendance.DeviceID != filter.DeviceID) {
+			continue
+		}
+		if r.users != nil {
+			if user, err := r.users.FindByID(ctx, attendance.UserID); err == nil {
+				attendance.User = *user
+			}
+		}
+		if filter.Visible != nil && !isVisible(&attendance.User, *filter.Visible, time.Now()) {
+			continue
+		}
+		if filter.Search != "" && !matchesSearch(&attendance.User, filter.Search) {
+			continue
+		}
+		if filter.Org != nil && !hasAssignment(&attendance.User, *filter.Org, attendance.CheckInTime) {
+			continue
+		}
+		matches = append(matches, attendance)
+	}
+
+	attendances, err := utils.PageSlice(page, matches, func(a models.Attendance) interface{} {
+		switch page.SortKey {
+		case "similarity_score":
+			return a.SimilarityScore
+		case "created_at":
+			return a.CreatedAt
+		}
+		return a.CheckInTime
+	}, func(a models.Attendance) uint { return a.ID })
+	if err != nil {
+		return nil, 0, err
+	}
+	return attendances, int64(len(matches)), nil
+}
+
+// MemorySiteRepository is an in-memory SiteRepository for handler tests
+type MemorySiteRepository struct {
+	sites map[uint]models.Site
+}
+
+// NewMemorySiteRepository creates a MemorySiteRepository with the given sites
+func NewMemorySiteRepository(sites ...models.Site) *MemorySiteRepository {
+	r := &MemorySiteRepository{sites: make(map[uint]models.Site)}
+	for _, site := range sites {
+		r.sites[site.ID] = site
+	}
+	return r
+}
+
+// FindByID returns a copy of the stored site
+func (r *MemorySiteRepository) FindByID(ctx context.Context, id uint) (*models.Site, error) {
+	site, ok := r.sites[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &site, nil
+}
+
+// isVisible checks an employee against a Visibility, laporan manager dari assignment yang berlaku di now
+func isVisible(user *models.User, visible Visibility, now time.Time) bool {
+	if user.ID == visible.UserID {
+		return true
+	}
+	return visible.Reports != nil && hasAssignment(user, *visible.Reports, now)
+}
+
+// matchesSearch checks if the name or email contains search, case-insensitive seperti LIKE di GORM
+func matchesSearch(user *models.User, search string) bool {
+	search = strings.ToLower(strings.TrimSpace(search))
+	return strings.Contains(strings.ToLower(user.Name), search) || strings.Contains(strings.ToLower(user.Email), search)
+}
+
+// hasAssignment checks if the employee had an assignment at t matching the filter, sama dengan AssignmentConditions
+func hasAssignment(user *models.User, filter AssignmentFilter, t time.Time) bool {
+	for i := range user.Assignments {
+		a := &user.Assignments[i]
+		if !a.IsActiveAt(t) {
+			continue
+		}
+		if filter.DepartmentIDs != nil && !slices.Contains(filter.DepartmentIDs, a.DepartmentID) {
+			continue
+		}
+		if filter.TeamID != 0 && (a.TeamID == nil || *a.TeamID != filter.TeamID) {
+			continue
+		}
+		if filter.ManagerID != 0 {
+			leadsTeam := a.Team != nil && a.Team.ManagerID != nil && *a.Team.ManagerID == filter.ManagerID
+			if !leadsTeam && !slices.Contains(filter.ManagedDepartmentIDs, a.DepartmentID) {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
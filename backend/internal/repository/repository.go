@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"attendance-system/internal/models"
+	"attendance-system/internal/utils"
+	"context"
+	"errors"
+	"time"
+)
+
+// Repository errors
+var (
+	ErrNotFound             = errors.New("record not found")
+	ErrInvalidConsentStatus = errors.New("invalid consent status")
+)
+
+// AssignmentFilter matches employee assignments by org unit, semua kondisi digabung dengan AND
+// Department sudah diperluas ke sub-department oleh OrgService, repository tidak membaca pohon department
+type AssignmentFilter struct {
+	DepartmentIDs []uint // nil = tidak difilter
+	TeamID        uint
+	// ManagerID matches teams led by the manager or assignments in ManagedDepartmentIDs
+	ManagerID            uint
+	ManagedDepartmentIDs []uint
+}
+
+// Visibility restricts a list to one employee and, for managers, the employees they manage now
+type Visibility struct {
+	UserID  uint
+	Reports *AssignmentFilter // nil = hanya UserID sendiri
+}
+
+// UserFilter selects employees for UserRepository.Page, field kosong = tidak difilter
+type UserFilter struct {
+	Visible *Visibility // nil = semua karyawan (HR/admin)
+	Search  string      // Bagian nama atau email, case-insensitive
+	Org     *AssignmentFilter
+	// ConsentStatus is one of models.ConsentStatus*, consented/outdated dinilai terhadap PolicyVersion
+	ConsentStatus string
+	PolicyVersion string
+}
+
+// AttendanceFilter selects check-ins for AttendanceRepository.Page, field kosong = tidak difilter
+type AttendanceFilter struct {
+	Visible  *Visibility
+	UserID   uint
+	SiteID   uint
+	From     time.Time // Inklusif
+	To       time.Time // Eksklusif
+	Status   string
+	DeviceID string
+	Search   string // Bagian nama atau email karyawan
+	// Org matches the assignment effective at check-in time, jadi riwayat tetap ikut org unit lama
+	Org *AssignmentFilter
+}
+
+// UserRepository loads and stores employees
+type UserRepository interface {
+	// FindByID returns an employee with the assignment effective now (department, team, site)
+	FindByID(ctx context.Context, id uint) (*models.User, error)
+	// Create stores a new employee together with their initial biometric consent
+	Create(ctx context.Context, user *models.User, consent *models.BiometricConsent) error
+	// UpdateFace replaces the reference photo and face descriptor
+	UpdateFace(ctx context.Context, id uint, imagePath, descriptor string) error
+	SetLegalHold(ctx context.Context, id uint, hold bool) error
+	// List returns employees ordered by ID, includeDeactivated=false hanya yang masih aktif
+	List(ctx context.Context, includeDeactivated bool) ([]models.User, error)
+	// Deactivate marks an active employee as deactivated, ErrNotFound kalau tidak ada atau sudah nonaktif
+	Deactivate(ctx context.Context, id uint, at time.Time) error
+	// UpdateCredentials sets role and/or password hash, nilai kosong tidak diubah
+	UpdateCredentials(ctx context.Context, id uint, role, passwordHash string) error
+	// Page returns one page of employees (limit+1 untuk deteksi halaman berikutnya) and the total before paging
+	// Assignment yang dimuat hanya yang berlaku sekarang; cursor invalid menghasilkan utils.ErrInvalidCursor
+	Page(ctx context.Context, filter UserFilter, page utils.PageParams) ([]models.User, int64, error)
+}
+
+// AttendanceRepository loads and stores check-in records
+type AttendanceRepository interface {
+	Create(ctx context.Context, attendance *models.Attendance) error
+	FindByID(ctx context.Context, id uint) (*models.Attendance, error)
+	// LatestSuccessful returns the latest successful check-in in [from, to) with its employee
+	LatestSuccessful(ctx context.Context, userID uint, from, to time.Time) (*models.Attendance, error)
+	SetLegalHold(ctx context.Context, id uint, hold bool) error
+	// EachInRange calls fn with batches of check-ins in [from, to) with their employee, urut check_in_time
+	// userID 0 berarti semua karyawan
+	EachInRange(ctx context.Context, from, to time.Time, userID uint, fn func([]models.Attendance) error) error
+	// Page returns one page of check-ins with their employee (limit+1) and the total before paging
+	Page(ctx context.Context, filter AttendanceFilter, page utils.PageParams) ([]models.Attendance, int64, error)
+}
+
+// SiteRepository loads sites
+type SiteRepository interface {
+	FindByID(ctx context.Context, id uint) (*models.Site, error)
+}
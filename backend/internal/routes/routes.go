@@ -2,53 +2,193 @@ package routes
 
 import (
 	"attendance-system/internal/handlers"
+	"attendance-system/internal/metrics"
+	"attendance-system/internal/middleware"
+	"attendance-system/internal/models"
+	"attendance-system/internal/ratelimit"
+	"attendance-system/internal/services"
+	"attendance-system/internal/utils"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/fiber/v2/middleware/logger"
 )
 
+// Handlers groups the handlers mounted by SetupRoutes, dibuat di main dengan dependency eksplisit
+type Handlers struct {
+	Health     *handlers.HealthHandler
+	Auth       *handlers.AuthHandler
+	User       *handlers.UserHandler
+	Attendance *handlers.AttendanceHandler
+	Org        *handlers.OrgHandler
+	Daily      *handlers.DailyAttendanceHandler
+	Leave      *handlers.LeaveHandler
+	Calendar   *handlers.CalendarHandler
+	Correction *handlers.CorrectionHandler
+	Audit      *handlers.AuditHandler
+	Image      *handlers.ImageHandler
+	Retention  *handlers.RetentionHandler
+	Privacy    *handlers.PrivacyHandler
+	Consent    *handlers.ConsentHandler
+	Metrics    *handlers.MetricsHandler // nil = METRICS_ENABLED=false
+}
+
 // SetupRoutes configures all application routes
-func SetupRoutes(app *fiber.App) {
+func SetupRoutes(app *fiber.App, h Handlers, authService *services.AuthService, auditService *services.AuditService,
+	m *metrics.Metrics, limiter *ratelimit.Limiter) {
 	// Middleware
-	app.Use(logger.New())
+	// Request ID paling awal supaya log request, audit log dan response memakai ID yang sama
+	app.Use(middleware.RequestID())
+	// Probe dipanggil tiap beberapa detik oleh orchestrator / load balancer, di-mount sebelum log request,
+	// tracing dan metrics supaya tidak membanjiri log; check yang gagal tetap dicatat oleh handler
+	app.Get("/livez", h.Health.Liveness)
+	app.Get("/readyz", h.Health.Readiness)
+	// Server span sebelum logger supaya log request ikut memuat trace_id
+	app.Use(middleware.Tracing())
+	app.Use(middleware.RequestLogger())
+	if h.Metrics != nil {
+		app.Use(middleware.Metrics(m))
+		// Di luar /api, path default yang di-scrape Prometheus
+		app.Get("/metrics", h.Metrics.Metrics)
+	}
 	app.Use(cors.New(cors.Config{
-		AllowOrigins: "*",
-		AllowHeaders: "Origin, Content-Type, Accept",
-		AllowMethods: "GET, POST, PUT, DELETE, OPTIONS",
+		AllowOrigins:  "*",
+		AllowHeaders:  "Origin, Content-Type, Accept, Authorization, X-Request-ID",
+		AllowMethods:  "GET, POST, PUT, DELETE, OPTIONS",
+		ExposeHeaders: "X-Request-ID",
 	}))
 
-	// Initialize handlers
-	healthHandler := handlers.NewHealthHandler()
-	userHandler := handlers.NewUserHandler()
-	attendanceHandler := handlers.NewAttendanceHandler()
+	// Identitas user (kalau ada token) dan audit log untuk semua request yang mengubah data
+	app.Use(middleware.OptionalAuth(authService))
+	app.Use(middleware.Audit(auditService))
+
+	requireAuth := middleware.RequireAuth(authService)
+	requireHR := middleware.RequireRole(models.RoleHR, models.RoleAdmin)
 
 	// API routes
 	api := app.Group("/api")
 
 	// Health check
-	api.Get("/health", healthHandler.HealthCheck)
+	api.Get("/health", h.Health.HealthCheck)
+
+	// Auth routes
+	auth := api.Group("/auth")
+	auth.Post("/login", h.Auth.Login)
+	auth.Get("/me", requireAuth, h.Auth.Me)
 
 	// Employee routes
 	employees := api.Group("/employees")
-	employees.Post("/register", userHandler.RegisterEmployee)
-	employees.Get("/", userHandler.GetEmployees)
-	employees.Get("/:id", userHandler.GetEmployee)
+	// Registrasi meng-enroll wajah dan mencatat consent atas nama HR/admin yang login
+	employees.Post("/register", requireAuth, requireHR, middleware.RateLimit(limiter, m, middleware.RegistrationKeys), h.User.RegisterEmployee)
+	employees.Post("/import", requireAuth, requireHR, h.User.ImportEmployees)
+	// Daftar nama untuk kiosk check-in (tanpa login, hanya ID dan nama), didaftarkan sebelum /:id
+	employees.Get("/directory", h.User.GetDirectory)
+	// Daftar dan detail karyawan dibatasi scope caller (sendiri, bawahan, atau semua untuk HR/admin)
+	employees.Get("/", requireAuth, h.User.GetEmployees)
+	employees.Get("/:id", requireAuth, h.User.GetEmployee)
+	employees.Get("/:id/assignments", requireAuth, h.Org.GetAssignments)
+	// Assignment menentukan scope manager, jadi hanya HR/admin yang boleh mengubah
+	employees.Post("/:id/assignments", requireAuth, requireHR, h.Org.AssignEmployee)
+	employees.Put("/:id/credentials", requireAuth, middleware.RequireRole(models.RoleAdmin), h.Auth.SetCredentials)
+	employees.Put("/:id/legal-hold", requireAuth, requireHR, h.Retention.SetEmployeeLegalHold)
+	employees.Put("/:id/face", requireAuth, requireHR, h.User.UpdateFaceImage)
+
+	// Biometric consent (karyawan sendiri atau HR/admin)
+	employees.Get("/:id/consent", requireAuth, h.Consent.GetConsent)
+	employees.Post("/:id/consent", requireAuth, h.Consent.GiveConsent)
+	employees.Post("/:id/consent/withdraw", requireAuth, h.Consent.WithdrawConsent)
+
+	// Data subject requests: export (karyawan sendiri atau HR/admin) dan erasure (admin)
+	employees.Get("/:id/export", requireAuth, middleware.AuditRead(auditService, "employee"), h.Privacy.ExportEmployeeData)
+	employees.Post("/:id/erase", requireAuth, middleware.RequireRole(models.RoleAdmin), h.Privacy.EraseEmployeeData)
+
+	// Organization routes (perubahan hanya HR/admin: manager_id department / team menentukan scope manager)
+	// Daftar department / team butuh login, response hanya memuat nama manager
+	departments := api.Group("/departments")
+	departments.Post("/", requireAuth, requireHR, h.Org.CreateDepartment)
+	departments.Get("/", requireAuth, h.Org.GetDepartments)
+	departments.Get("/:id", requireAuth, h.Org.GetDepartment)
+	departments.Put("/:id", requireAuth, requireHR, h.Org.UpdateDepartment)
+
+	teams := api.Group("/teams")
+	teams.Post("/", requireAuth, requireHR, h.Org.CreateTeam)
+	teams.Get("/", requireAuth, h.Org.GetTeams)
+	teams.Put("/:id", requireAuth, requireHR, h.Org.UpdateTeam)
+
+	sites := api.Group("/sites")
+	sites.Post("/", requireAuth, requireHR, h.Org.CreateSite)
+	sites.Get("/", h.Org.GetSites)
+	sites.Put("/:id", requireAuth, requireHR, h.Org.UpdateSite)
+
+	schedules := api.Group("/schedules")
+	schedules.Post("/", requireAuth, requireHR, h.Org.CreateWorkSchedule)
+	schedules.Get("/", h.Org.GetWorkSchedules)
+	schedules.Put("/:id", requireAuth, requireHR, h.Org.UpdateWorkSchedule)
+
+	// Calendar routes
+	calendar := api.Group("/calendar")
+	calendar.Get("/", h.Calendar.GetCalendar)
+	calendar.Get("/entries", h.Calendar.GetCalendarEntries)
+	calendar.Post("/entries", requireAuth, requireHR, h.Calendar.CreateCalendarEntry)
+	calendar.Delete("/entries/:id", requireAuth, requireHR, h.Calendar.DeleteCalendarEntry)
+	calendar.Post("/import", requireAuth, requireHR, h.Calendar.ImportCalendar)
 
 	// Attendance routes
 	attendance := api.Group("/attendance")
-	attendance.Post("/checkin", attendanceHandler.CheckIn)
-	attendance.Get("/", attendanceHandler.GetAttendances)
-	attendance.Get("/today/:user_id", attendanceHandler.GetTodayAttendance)
+	// Rate limit per IP, device dan karyawan target, mencegah brute-force foto sampai lolos threshold
+	attendance.Post("/checkin", middleware.RateLimit(limiter, m, middleware.CheckInKeys), h.Attendance.CheckIn)
+	attendance.Post("/checkin/fallback", requireAuth, h.Attendance.FallbackCheckIn)
+	attendance.Get("/", requireAuth, h.Attendance.GetAttendances)
+	attendance.Get("/today/:user_id", requireAuth, h.Attendance.GetTodayAttendance)
+	attendance.Get("/daily", requireAuth, h.Daily.GetDailyAttendances)
+	attendance.Post("/daily/recompute", requireAuth, requireHR, h.Daily.RecomputeDailyAttendances)
+
+	// Attendance correction routes (butuh login)
+	corrections := attendance.Group("/corrections", requireAuth)
+	corrections.Post("/", h.Correction.SubmitCorrection)
+	corrections.Get("/", h.Correction.GetCorrections)
+	corrections.Get("/:id", h.Correction.GetCorrection)
+	corrections.Post("/:id/approve", h.Correction.ApproveCorrection)
+	corrections.Post("/:id/reject", h.Correction.RejectCorrection)
+	corrections.Post("/:id/cancel", h.Correction.CancelCorrection)
+	attendance.Get("/:id/history", requireAuth, h.Correction.GetAttendanceHistory)
+	attendance.Put("/:id/legal-hold", requireAuth, requireHR, h.Retention.SetAttendanceLegalHold)
+
+	// Leave routes (butuh login)
+	leave := api.Group("/leave", requireAuth)
+	leave.Get("/types", h.Leave.GetLeaveTypes)
+	leave.Post("/types", requireHR, h.Leave.CreateLeaveType)
+	leave.Put("/types/:id", requireHR, h.Leave.UpdateLeaveType)
+	leave.Post("/requests", h.Leave.SubmitLeaveRequest)
+	leave.Get("/requests", h.Leave.GetLeaveRequests)
+	leave.Get("/requests/:id", h.Leave.GetLeaveRequest)
+	leave.Post("/requests/:id/approve", h.Leave.ApproveLeaveRequest)
+	leave.Post("/requests/:id/reject", h.Leave.RejectLeaveRequest)
+	leave.Post("/requests/:id/cancel", h.Leave.CancelLeaveRequest)
+	leave.Get("/balances", h.Leave.GetLeaveBalances)
+	leave.Post("/accrue", requireHR, h.Leave.AccrueLeaveBalances)
+
+	// Audit log routes (admin/auditor)
+	audit := api.Group("/audit", requireAuth, middleware.RequireRole(models.RoleAdmin, models.RoleAuditor))
+	audit.Get("/", h.Audit.GetAuditLogs)
+	audit.Get("/export", middleware.AuditRead(auditService, "audit_log"), h.Audit.ExportAuditLogs)
+	audit.Get("/verify", h.Audit.VerifyAuditLogs)
+
+	// Biometric retention routes (HR/admin/auditor, purge manual hanya admin)
+	retention := api.Group("/retention", requireAuth, middleware.RequireRole(models.RoleHR, models.RoleAdmin, models.RoleAuditor))
+	retention.Get("/policy", h.Retention.GetPolicy)
+	retention.Post("/purge", middleware.RequireRole(models.RoleAdmin), h.Retention.Purge)
+	retention.Get("/runs", h.Retention.GetPurgeRuns)
+	retention.Get("/runs/:id/items", h.Retention.GetPurgeItems)
+
+	// Consent coverage report (HR/admin/auditor)
+	api.Get("/consent/coverage", requireAuth, middleware.RequireRole(models.RoleHR, models.RoleAdmin, models.RoleAuditor), h.Consent.GetConsentCoverage)
 
-	// Serve static files (uploaded images)
-	app.Static("/uploads", "./uploads")
+	// Protected images: foto karyawan, selfie dan lampiran cuti
+	// Bisa diakses dengan signed URL (untuk <img src>) atau header Authorization, setiap akses tercatat di audit log
+	api.Get("/images/:kind/:id", middleware.AuditRead(auditService, "image"), h.Image.GetImage)
 
 	// 404 handler
 	app.Use(func(c *fiber.Ctx) error {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"status":  "error",
-			"message": "Route not found",
-		})
+		return utils.NotFoundResponse(c, "Route not found")
 	})
 }
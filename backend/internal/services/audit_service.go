@@ -0,0 +1,214 @@
+package services
+
+import (
+	"attendance-system/internal/logging"
+	"attendance-system/internal/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// auditRecordAttempts is how many times Record retries when another instance wins the same sequence
+const auditRecordAttempts = 5
+
+// AuditFilter limits audit log queries and exports
+type AuditFilter struct {
+	ActorID    uint
+	Action     string
+	EntityType string
+	EntityID   string
+	RequestID  string
+	From       *time.Time
+	To         *time.Time // Exclusive
+}
+
+// AuditVerifyResult is the outcome of walking the hash chain
+type AuditVerifyResult struct {
+	Valid        bool   `json:"valid"`
+	Checked      int64  `json:"checked"`
+	LastSequence uint64 `json:"last_sequence"`
+	BrokenAt     uint64 `json:"broken_at,omitempty"` // Sequence pertama yang tidak cocok
+	Reason       string `json:"reason,omitempty"`
+}
+
+// AuditService appends entries to the hash-chained audit log
+type AuditService struct {
+	db *gorm.DB
+	mu sync.Mutex // Serialisasi append di instance ini, antar instance dijaga unique index sequence
+}
+
+// NewAuditService creates a new AuditService instance
+func NewAuditService(db *gorm.DB) *AuditService {
+	return &AuditService{db: db}
+}
+
+// Record appends an entry, mengisi Sequence, CreatedAt, PrevHash dan Hash
+func (s *AuditService) Record(entry *models.AuditLog) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var err error
+	for attempt := 0; attempt < auditRecordAttempts; attempt++ {
+		entry.ID = 0
+		err = s.db.Transaction(func(tx *gorm.DB) error {
+			var last models.AuditLog
+			err := tx.Select("sequence", "hash").Order("sequence DESC").Take(&last).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+
+			entry.Sequence = last.Sequence + 1
+			entry.PrevHash = last.Hash
+			// Presisi mikrodetik sesuai kolom timestamp, supaya hash bisa dihitung ulang dari data tersimpan
+			entry.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+			entry.Hash = entry.ComputeHash()
+			return tx.Create(entry).Error
+		})
+		if err == nil {
+			return nil
+		}
+
+		// Sequence sudah dipakai instance lain: baca ulang ujung rantai dan coba lagi
+		var exists int64
+		if s.db.Model(&models.AuditLog{}).Where("sequence = ?", entry.Sequence).Count(&exists).Error != nil || exists == 0 {
+			break
+		}
+	}
+	return fmt.Errorf("failed to append audit log: %w", err)
+}
+
+// Query returns a filtered query on audit_logs
+func (s *AuditService) Query(filter AuditFilter) *gorm.DB {
+	query := s.db.Model(&models.AuditLog{})
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.RequestID != "" {
+		query = query.Where("request_id = ?", filter.RequestID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	return query
+}
+
+// Each streams matching entries ordered by sequence in batches
+func (s *AuditService) Each(filter AuditFilter, batchSize int, fn func(*models.AuditLog) error) error {
+	var batch []models.AuditLog
+	var fnErr error
+	result := s.Query(filter).Order("sequence ASC").FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+		for i := range batch {
+			if fnErr = fn(&batch[i]); fnErr != nil {
+				return fnErr
+			}
+		}
+		return nil
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	return result.Error
+}
+
+// Verify walks the whole chain and recomputes every hash
+func (s *AuditService) Verify() (*AuditVerifyResult, error) {
+	result := &AuditVerifyResult{Valid: true}
+	prevHash := ""
+
+	err := s.Each(AuditFilter{}, 1000, func(entry *models.AuditLog) error {
+		switch {
+		case entry.Sequence != result.LastSequence+1:
+			result.Reason = fmt.Sprintf("missing entries before sequence %d", entry.Sequence)
+		case entry.PrevHash != prevHash:
+			result.Reason = "prev_hash does not match previous entry"
+		case entry.ComputeHash() != entry.Hash:
+			result.Reason = "entry content does not match its hash"
+		}
+		if result.Reason != "" {
+			result.Valid = false
+			result.BrokenAt = entry.Sequence
+			return errChainBroken
+		}
+
+		result.Checked++
+		result.LastSequence = entry.Sequence
+		prevHash = entry.Hash
+		return nil
+	})
+	if err != nil && !errors.Is(err, errChainBroken) {
+		return nil, err
+	}
+	return result, nil
+}
+
+// errChainBroken stops Each once verification fails
+var errChainBroken = errors.New("audit chain broken")
+
+// AuditDiff returns JSON of changed fields of entityType as {field: {"old": .., "new": ..}}
+// before atau after boleh nil (create/delete). Field dengan tag json:"-" tidak ikut
+// Audit log append-only dan tidak bisa dihapus (juga saat erasure), jadi nama orang, email, telepon dan secret
+// hanya dicatat sebagai field yang berubah dengan nilai [redacted], termasuk di object nested (contoh: user).
+// Nama department, team, site, hari libur dan jenis cuti tetap tercatat (lihat logging.RedactValue)
+func AuditDiff(entityType string, before, after interface{}) string {
+	old, cur := auditFields(before), auditFields(after)
+
+	changes := make(map[string]map[string]interface{})
+	for key, value := range cur {
+		if prev, ok := old[key]; !ok || !reflect.DeepEqual(prev, value) {
+			change := map[string]interface{}{"new": logging.RedactValue(entityType, key, value)}
+			if ok {
+				change["old"] = logging.RedactValue(entityType, key, prev)
+			}
+			changes[key] = change
+		}
+	}
+	for key, prev := range old {
+		if _, ok := cur[key]; !ok {
+			changes[key] = map[string]interface{}{"old": logging.RedactValue(entityType, key, prev)}
+		}
+	}
+	// Timestamp otomatis bukan perubahan yang berarti
+	delete(changes, "updated_at")
+
+	if len(changes) == 0 {
+		return ""
+	}
+	raw, err := json.Marshal(changes)
+	if err != nil {
+		return ""
+	}
+	return string(raw)
+}
+
+// auditFields flattens a value to its top-level JSON fields
+func auditFields(value interface{}) map[string]interface{} {
+	fields := map[string]interface{}{}
+	if value == nil || (reflect.ValueOf(value).Kind() == reflect.Ptr && reflect.ValueOf(value).IsNil()) {
+		return fields
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return fields
+	}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return map[string]interface{}{"value": string(raw)}
+	}
+	return fields
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"attendance-system/internal/models"
+	"encoding/json"
+	"testing"
+)
+
+func TestAuditDiffRedactsPersonNames(t *testing.T) {
+	managerID := uint(2)
+	tests := []struct {
+		name       string
+		entityType string
+		before     interface{}
+		after      interface{}
+		want       map[string]interface{} // field -> nilai "new" yang diharapkan
+	}{
+		{
+			"department name stays readable", "department",
+			nil, models.Department{ID: 1, Name: "Finance", ManagerID: &managerID},
+			map[string]interface{}{"name": "Finance", "manager_id": float64(2)},
+		},
+		{
+			"holiday name stays readable", "calendar_day",
+			nil, map[string]interface{}{"name": "Hari Raya", "kind": "holiday"},
+			map[string]interface{}{"name": "Hari Raya", "kind": "holiday"},
+		},
+		{
+			"employee name, email and phone are redacted", "employee",
+			nil, map[string]interface{}{"name": "Budi", "email": "budi@example.com", "phone": "081234567890", "role": "employee"},
+			map[string]interface{}{"name": "[redacted]", "email": "[redacted]", "phone": "[redacted]", "role": "employee"},
+		},
+		{
+			"person name fields are redacted on any entity", "attendance",
+			nil, map[string]interface{}{"user_name": "Budi", "site_name": "Jakarta", "user": map[string]interface{}{"name": "Budi"}},
+			map[string]interface{}{"user_name": "[redacted]", "site_name": "Jakarta", "user": map[string]interface{}{"name": "[redacted]"}},
+		},
+		{
+			"leave approver is redacted, leave type is not", "leave_request",
+			nil, map[string]interface{}{"approver_name": "Hana", "leave_type_name": "Cuti Tahunan"},
+			map[string]interface{}{"approver_name": "[redacted]", "leave_type_name": "Cuti Tahunan"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var changes map[string]map[string]interface{}
+			if err := json.Unmarshal([]byte(AuditDiff(tt.entityType, tt.before, tt.after)), &changes); err != nil {
+				t.Fatal(err)
+			}
+			for field, want := range tt.want {
+				got, _ := json.Marshal(changes[field]["new"])
+				expected, _ := json.Marshal(want)
+				if string(got) != string(expected) {
+					t.Errorf("%s: new %s, want %s", field, got, expected)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"attendance-system/internal/models"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidCredentials is returned when login fails
+var ErrInvalidCredentials = errors.New("invalid email or password")
+
+// ErrInvalidToken is returned when a bearer token is malformed, tampered or expired
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// MinPasswordLength is the minimum accepted password length
+const MinPasswordLength = 8
+
+// TokenClaims is the payload of an access token
+type TokenClaims struct {
+	UserID    uint   `json:"uid"`
+	Role      string `json:"role"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// AuthService issues and verifies HMAC-signed access tokens
+// Format token: base64url(payload JSON).base64url(HMAC-SHA256(payload))
+type AuthService struct {
+	db     *gorm.DB
+	secret []byte
+	ttl    time.Duration
+}
+
+// NewAuthService creates a new AuthService instance
+func NewAuthService(db *gorm.DB, secret []byte, ttl time.Duration) *AuthService {
+	return &AuthService{
+		db:     db,
+		secret: secret,
+		ttl:    ttl,
+	}
+}
+
+// HashPassword hashes a plaintext password with bcrypt
+func HashPassword(password string) (string, error) {
+	if len(password) < MinPasswordLength {
+		return "", fmt.Errorf("password must be at least %d characters", MinPasswordLength)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// TTL returns how long issued tokens stay valid
+func (s *AuthService) TTL() time.Duration {
+	return s.ttl
+}
+
+// Login checks email and password and returns a token for the user
+func (s *AuthService) Login(email, password string) (string, *models.User, error) {
+	var user models.User
+	if err := s.db.Where("LOWER(email) = ?", strings.ToLower(strings.TrimSpace(email))).First(&user).Error; err != nil {
+		return "", nil, ErrInvalidCredentials
+	}
+	if user.PasswordHash == "" || user.DeactivatedAt != nil {
+		return "", nil, ErrInvalidCredentials
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return "", nil, ErrInvalidCredentials
+	}
+
+	token, err := s.IssueToken(&user)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, &user, nil
+}
+
+// IssueToken creates a signed token for the user
+func (s *AuthService) IssueToken(user *models.User) (string, error) {
+	claims := TokenClaims{
+		UserID:    user.ID,
+		Role:      user.Role,
+		ExpiresAt: time.Now().Add(s.ttl).Unix(),
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode token: %w", err)
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.sign(encoded)), nil
+}
+
+// VerifyToken validates signature and expiry, lalu cek user masih aktif
+// Role diambil ulang dari database supaya perubahan role langsung berlaku
+func (s *AuthService) VerifyToken(token string) (*TokenClaims, error) {
+	encoded, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	expected, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(expected, s.sign(encoded)) {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var claims TokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if time.Now().Unix() >= claims.ExpiresAt {
+		return nil, ErrInvalidToken
+	}
+
+	var user models.User
+	if err := s.db.Select("id", "role", "deactivated_at").First(&user, claims.UserID).Error; err != nil {
+		return nil, ErrInvalidToken
+	}
+	if user.DeactivatedAt != nil {
+		return nil, ErrInvalidToken
+	}
+	claims.Role = user.Role
+
+	return &claims, nil
+}
+
+// EnsureAdmin creates or promotes the bootstrap admin account
+// Dipanggil saat startup kalau ADMIN_EMAIL dan ADMIN_PASSWORD di-set
+func (s *AuthService) EnsureAdmin(name, email, password string) (*models.User, error) {
+	hash, err := HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	var user models.User
+	err = s.db.Where("LOWER(email) = ?", strings.ToLower(email)).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		user = models.User{
+			Name:         name,
+			Email:        email,
+			Role:         models.RoleAdmin,
+			PasswordHash: hash,
+		}
+		if err := s.db.Create(&user).Error; err != nil {
+			return nil, fmt.Errorf("failed to create admin: %w", err)
+		}
+		return &user, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load admin: %w", err)
+	}
+
+	// Akun sudah ada: pastikan role admin, password tidak ditimpa
+	updates := map[string]interface{}{"role": models.RoleAdmin}
+	if user.PasswordHash == "" {
+		updates["password_hash"] = hash
+	}
+	if err := s.db.Model(&user).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("failed to update admin: %w", err)
+	}
+	return &user, nil
+}
+
+// sign computes the HMAC of the encoded payload
+func (s *AuthService) sign(encoded string) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"attendance-system/internal/models"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testAuthSecret = []byte("test-secret-that-is-at-least-32-bytes")
+
+func TestAuthServiceLogin(t *testing.T) {
+	db := newTestDB(t)
+	auth := NewAuthService(db, testAuthSecret, time.Hour)
+
+	hash, err := HashPassword("correct horse battery")
+	if err != nil {
+		t.Fatal(err)
+	}
+	active := createTestUser(t, db, "budi", models.RoleEmployee)
+	deactivated := createTestUser(t, db, "citra", models.RoleEmployee)
+	createTestUser(t, db, "dewi", models.RoleEmployee) // Tanpa password
+	now := time.Now()
+	db.Model(&models.User{}).Where("id IN ?", []uint{active.ID, deactivated.ID}).Update("password_hash", hash)
+	db.Model(deactivated).Update("deactivated_at", &now)
+
+	tests := []struct {
+		name     string
+		email    string
+		password string
+		wantErr  error
+	}{
+		{"valid", "budi@example.com", "correct horse battery", nil},
+		{"email is case insensitive", " BUDI@example.com ", "correct horse battery", nil},
+		{"wrong password", "budi@example.com", "wrong horse battery", ErrInvalidCredentials},
+		{"unknown email", "eka@example.com", "correct horse battery", ErrInvalidCredentials},
+		{"deactivated", "citra@example.com", "correct horse battery", ErrInvalidCredentials},
+		{"no password set", "dewi@example.com", "", ErrInvalidCredentials},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, user, err := auth.Login(tt.email, tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			claims, err := auth.VerifyToken(token)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if claims.UserID != active.ID || user.ID != active.ID {
+				t.Fatalf("token for user %d, want %d", claims.UserID, active.ID)
+			}
+		})
+	}
+
+	if _, err := HashPassword("short"); err == nil {
+		t.Fatal("short password was accepted")
+	}
+}
+
+func TestAuthServiceVerifyToken(t *testing.T) {
+	db := newTestDB(t)
+	auth := NewAuthService(db, testAuthSecret, time.Hour)
+	user := createTestUser(t, db, "budi", models.RoleEmployee)
+	token, err := auth.IssueToken(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, sig, _ := strings.Cut(token, ".")
+
+	// Payload diubah jadi admin tapi signature lama dipakai ulang
+	var claims TokenClaims
+	raw, _ := base64.RawURLEncoding.DecodeString(payload)
+	json.Unmarshal(raw, &claims)
+	claims.Role = models.RoleAdmin
+	forged, _ := json.Marshal(claims)
+	escalated := base64.RawURLEncoding.EncodeToString(forged) + "." + sig
+
+	sigBytes, _ := base64.RawURLEncoding.DecodeString(sig)
+	sigBytes[0] ^= 0xff
+	badSignature := payload + "." + base64.RawURLEncoding.EncodeToString(sigBytes)
+
+	expired, err := NewAuthService(db, testAuthSecret, -time.Minute).IssueToken(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherSecret, err := NewAuthService(db, []byte("another-secret-that-is-32-bytes-long"), time.Hour).IssueToken(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{"valid", token, nil},
+		{"tampered payload", escalated, ErrInvalidToken},
+		{"tampered signature", badSignature, ErrInvalidToken},
+		{"signed with another secret", otherSecret, ErrInvalidToken},
+		{"expired", expired, ErrInvalidToken},
+		{"no signature", payload, ErrInvalidToken},
+		{"garbage", "not.a-token!", ErrInvalidToken},
+		{"empty", "", ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := auth.VerifyToken(tt.token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (claims.UserID != user.ID || claims.Role != models.RoleEmployee) {
+				t.Fatalf("claims %+v, want employee %d", claims, user.ID)
+			}
+		})
+	}
+}
+
+func TestAuthServiceVerifyTokenReloadsUser(t *testing.T) {
+	db := newTestDB(t)
+	auth := NewAuthService(db, testAuthSecret, time.Hour)
+	user := createTestUser(t, db, "budi", models.RoleManager)
+	token, err := auth.IssueToken(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Role diturunkan setelah token dibuat: token lama langsung memakai role baru
+	db.Model(user).Update("role", models.RoleEmployee)
+	claims, err := auth.VerifyToken(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Role != models.RoleEmployee {
+		t.Fatalf("role %q, want %q from the database", claims.Role, models.RoleEmployee)
+	}
+
+	// Karyawan dinonaktifkan: token yang masih berlaku ditolak
+	now := time.Now()
+	db.Model(user).Update("deactivated_at", &now)
+	if _, err := auth.VerifyToken(token); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("deactivated user: err %v, want %v", err, ErrInvalidToken)
+	}
+
+	// Karyawan dihapus
+	deleted := createTestUser(t, db, "citra", models.RoleEmployee)
+	token, _ = auth.IssueToken(deleted)
+	db.Delete(deleted)
+	if _, err := auth.VerifyToken(token); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("deleted user: err %v, want %v", err, ErrInvalidToken)
+	}
+}
+
+func TestAuthServiceEnsureAdmin(t *testing.T) {
+	db := newTestDB(t)
+	auth := NewAuthService(db, testAuthSecret, time.Hour)
+
+	admin, err := auth.EnsureAdmin("Administrator", "admin@example.com", "correct horse battery")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if admin.Role != models.RoleAdmin {
+		t.Fatalf("role %q, want admin", admin.Role)
+	}
+
+	// Startup berikutnya dengan password lain tidak menimpa password yang sudah ada
+	if _, err := auth.EnsureAdmin("Administrator", "ADMIN@example.com", "another horse battery"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := auth.Login("admin@example.com", "correct horse battery"); err != nil {
+		t.Fatalf("original password no longer works: %v", err)
+	}
+	var count int64
+	db.Model(&models.User{}).Where("role = ?", models.RoleAdmin).Count(&count)
+	if count != 1 {
+		t.Fatalf("%d admins, want 1", count)
+	}
+}
@@ -0,0 +1,499 @@
+package services
+
+import (
+	"attendance-system/internal/models"
+	"attendance-system/internal/utils"
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidCalendar is returned for malformed calendar input
+var ErrInvalidCalendar = errors.New("invalid calendar")
+
+// maxEventDays limits expansion of one multi-day or recurring iCalendar event
+const maxEventDays = 366
+
+// icsRecurrenceYears limits RRULE expansion, termasuk rule tanpa COUNT/UNTIL
+const icsRecurrenceYears = 10
+
+// Calendar holds calendar overrides for a date range, hasil CalendarService.Load
+type Calendar struct {
+	entries map[string][]models.CalendarDay // date -> entries (organisasi dan site)
+}
+
+// Lookup returns the entry effective for a site on the date
+// Entry site mengalahkan entry organisasi, nil kalau tidak ada
+func (c *Calendar) Lookup(date string, siteID *uint) *models.CalendarDay {
+	var orgEntry *models.CalendarDay
+	for i := range c.entries[date] {
+		entry := &c.entries[date][i]
+		if entry.SiteID == nil {
+			orgEntry = entry
+			continue
+		}
+		if siteID != nil && *entry.SiteID == *siteID {
+			return entry
+		}
+	}
+	return orgEntry
+}
+
+// IsWorkingDay combines the weekly schedule with calendar overrides
+func (c *Calendar) IsWorkingDay(date string, siteID *uint, schedule models.WorkSchedule) (bool, *models.CalendarDay) {
+	entry := c.Lookup(date, siteID)
+	if entry != nil {
+		return entry.Kind == models.CalendarKindWorkingDay, entry
+	}
+	day, err := time.Parse(utils.DateLayout, date)
+	if err != nil {
+		return false, nil
+	}
+	// Weekday tanggal kalender sama di semua timezone
+	return schedule.IsWorkDay(day.Weekday()), nil
+}
+
+// CalendarDayInfo is one resolved day returned by the calendar query API
+type CalendarDayInfo struct {
+	Date       string `json:"date"`
+	Weekday    string `json:"weekday"`
+	WorkingDay bool   `json:"working_day"`
+	Kind       string `json:"kind,omitempty"` // holiday / working_day kalau ada override
+	Name       string `json:"name,omitempty"`
+	EntryID    *uint  `json:"entry_id,omitempty"`
+}
+
+// ICSImportResult summarizes an iCalendar import
+type ICSImportResult struct {
+	Events  int `json:"events"`
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Skipped int `json:"skipped"` // Event tanpa DTSTART yang valid
+}
+
+// CalendarService manages holidays and working-day overrides
+type CalendarService struct {
+	db              *gorm.DB
+	defaultSchedule models.WorkSchedule
+}
+
+// NewCalendarService creates a new CalendarService instance
+func NewCalendarService(db *gorm.DB, defaultSchedule models.WorkSchedule) *CalendarService {
+	return &CalendarService{
+		db:              db,
+		defaultSchedule: defaultSchedule,
+	}
+}
+
+// Load reads all calendar entries in [from, to] (YYYY-MM-DD)
+func (s *CalendarService) Load(from, to string) (*Calendar, error) {
+	var entries []models.CalendarDay
+	if err := s.db.Where("date >= ? AND date <= ?", from, to).Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to load calendar: %w", err)
+	}
+
+	calendar := &Calendar{entries: make(map[string][]models.CalendarDay)}
+	for _, e := range entries {
+		calendar.entries[e.Date] = append(calendar.entries[e.Date], e)
+	}
+	return calendar, nil
+}
+
+// ScheduleForSite returns the site's work schedule, atau default schedule
+func (s *CalendarService) ScheduleForSite(siteID *uint) (models.WorkSchedule, error) {
+	if siteID == nil {
+		return s.defaultSchedule, nil
+	}
+	var site models.Site
+	if err := s.db.Preload("WorkSchedule").First(&site, *siteID).Error; err != nil {
+		return models.WorkSchedule{}, err
+	}
+	if site.WorkSchedule != nil {
+		return *site.WorkSchedule, nil
+	}
+	return s.defaultSchedule, nil
+}
+
+// Days resolves every date in [from, to] for a site (nil = organisasi)
+func (s *CalendarService) Days(from, to string, siteID *uint) ([]CalendarDayInfo, error) {
+	start, end, err := parseCalendarRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule, err := s.ScheduleForSite(siteID)
+	if err != nil {
+		return nil, err
+	}
+	calendar, err := s.Load(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var days []CalendarDayInfo
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		date := d.Format(utils.DateLayout)
+		working, entry := calendar.IsWorkingDay(date, siteID, schedule)
+		info := CalendarDayInfo{
+			Date:       date,
+			Weekday:    d.Weekday().String(),
+			WorkingDay: working,
+		}
+		if entry != nil {
+			info.Kind = entry.Kind
+			info.Name = entry.Name
+			info.EntryID = &entry.ID
+		}
+		days = append(days, info)
+	}
+	return days, nil
+}
+
+// Save creates or replaces the entry for the same date and site
+func (s *CalendarService) Save(entry *models.CalendarDay) error {
+	if _, err := time.Parse(utils.DateLayout, entry.Date); err != nil {
+		return fmt.Errorf("%w: date must be YYYY-MM-DD", ErrInvalidCalendar)
+	}
+	if !models.IsValidCalendarKind(entry.Kind) {
+		return fmt.Errorf("%w: kind must be holiday or working_day", ErrInvalidCalendar)
+	}
+	if strings.TrimSpace(entry.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidCalendar)
+	}
+
+	existing, err := s.find(s.db, entry.Date, entry.SiteID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		entry.ID = existing.ID
+		entry.CreatedAt = existing.CreatedAt
+	}
+	return s.db.Save(entry).Error
+}
+
+// ImportICS imports all-day events from an iCalendar file
+// Event yang sudah ada di tanggal & site yang sama di-update (import ulang aman)
+func (s *CalendarService) ImportICS(r io.Reader, siteID *uint, kind string) (*ICSImportResult, error) {
+	if !models.IsValidCalendarKind(kind) {
+		return nil, fmt.Errorf("%w: kind must be holiday or working_day", ErrInvalidCalendar)
+	}
+
+	events, err := parseICS(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ICSImportResult{Events: len(events)}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		for _, event := range events {
+			if len(event.dates) == 0 {
+				result.Skipped++
+				continue
+			}
+			for _, date := range event.dates {
+				existing, err := s.find(tx, date, siteID)
+				if err != nil {
+					return err
+				}
+				entry := models.CalendarDay{
+					Date:        date,
+					Kind:        kind,
+					Name:        event.summary,
+					SiteID:      siteID,
+					Source:      "ics",
+					ExternalUID: event.uid,
+				}
+				if existing != nil {
+					entry.ID = existing.ID
+					entry.CreatedAt = existing.CreatedAt
+					result.Updated++
+				} else {
+					result.Created++
+				}
+				if err := tx.Save(&entry).Error; err != nil {
+					return fmt.Errorf("failed to save calendar day %s: %w", date, err)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// find returns the entry for a date and site, nil kalau belum ada
+func (s *CalendarService) find(tx *gorm.DB, date string, siteID *uint) (*models.CalendarDay, error) {
+	query := tx.Where("date = ?", date)
+	if siteID == nil {
+		query = query.Where("site_id IS NULL")
+	} else {
+		query = query.Where("site_id = ?", *siteID)
+	}
+
+	var entry models.CalendarDay
+	err := query.First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load calendar day: %w", err)
+	}
+	return &entry, nil
+}
+
+// parseCalendarRange validates a YYYY-MM-DD range for the query API
+func parseCalendarRange(from, to string) (time.Time, time.Time, error) {
+	start, err := time.Parse(utils.DateLayout, from)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: from must be YYYY-MM-DD", ErrInvalidDateRange)
+	}
+	end, err := time.Parse(utils.DateLayout, to)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: to must be YYYY-MM-DD", ErrInvalidDateRange)
+	}
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: to must not be before from", ErrInvalidDateRange)
+	}
+	if end.Sub(start) >= maxEventDays*24*time.Hour {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: range must not exceed %d days", ErrInvalidDateRange, maxEventDays)
+	}
+	return start, end, nil
+}
+
+// icsEvent is one VEVENT expanded to local dates
+type icsEvent struct {
+	uid     string
+	summary string
+	dates   []string
+}
+
+// parseICS reads VEVENT blocks (RFC 5545), hanya tanggal yang dipakai
+func parseICS(r io.Reader) ([]icsEvent, error) {
+	lines, err := unfoldICSLines(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 || !strings.EqualFold(lines[0], "BEGIN:VCALENDAR") {
+		return nil, fmt.Errorf("%w: not an iCalendar file", ErrInvalidCalendar)
+	}
+
+	var events []icsEvent
+	var current map[string]icsProperty
+	for _, line := range lines {
+		switch {
+		case strings.EqualFold(line, "BEGIN:VEVENT"):
+			current = make(map[string]icsProperty)
+		case strings.EqualFold(line, "END:VEVENT"):
+			if current != nil {
+				events = append(events, buildICSEvent(current))
+			}
+			current = nil
+		case current != nil:
+			prop := parseICSProperty(line)
+			if _, exists := current[prop.name]; !exists {
+				current[prop.name] = prop
+			}
+		}
+	}
+	return events, nil
+}
+
+// icsProperty is one content line: NAME;PARAM=VALUE:value
+type icsProperty struct {
+	name   string
+	params string
+	value  string
+}
+
+// unfoldICSLines joins continuation lines (diawali spasi atau tab)
+func unfoldICSLines(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, strings.TrimPrefix(line, "\ufeff"))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCalendar, err)
+	}
+	return lines, nil
+}
+
+// parseICSProperty splits a content line into name, params and value
+func parseICSProperty(line string) icsProperty {
+	head, value, _ := strings.Cut(line, ":")
+	name, params, _ := strings.Cut(head, ";")
+	return icsProperty{name: strings.ToUpper(name), params: strings.ToUpper(params), value: value}
+}
+
+// buildICSEvent expands DTSTART/DTEND into local dates
+// DTEND untuk event all-day (VALUE=DATE) bersifat eksklusif
+func buildICSEvent(props map[string]icsProperty) icsEvent {
+	event := icsEvent{
+		uid:     props["UID"].value,
+		summary: unescapeICSText(props["SUMMARY"].value),
+	}
+	if event.summary == "" {
+		event.summary = "Holiday"
+	}
+
+	start, ok := parseICSDate(props["DTSTART"].value)
+	if !ok {
+		return event
+	}
+	end := start
+	if dtend, exists := props["DTEND"]; exists {
+		if parsed, ok := parseICSDate(dtend.value); ok {
+			end = parsed
+			allDay := strings.Contains(dtend.params, "VALUE=DATE") || len(dtend.value) == 8
+			if allDay && end.After(start) {
+				end = end.AddDate(0, 0, -1)
+			}
+		}
+	}
+	if end.Before(start) {
+		end = start
+	}
+
+	occurrences := []time.Time{start}
+	if rule, ok := parseICSRule(props["RRULE"].value, start); ok {
+		occurrences = rule.occurrences(start)
+	}
+	span := int(end.Sub(start).Hours() / 24)
+	seen := make(map[string]bool)
+	for _, occurrence := range occurrences {
+		for i := 0; i <= span && len(event.dates) < maxEventDays; i++ {
+			date := occurrence.AddDate(0, 0, i).Format(utils.DateLayout)
+			if !seen[date] {
+				seen[date] = true
+				event.dates = append(event.dates, date)
+			}
+		}
+	}
+	return event
+}
+
+// icsRule is the supported subset of RRULE: FREQ, INTERVAL, COUNT, UNTIL
+type icsRule struct {
+	freq     string
+	interval int
+	count    int
+	until    time.Time
+}
+
+// parseICSRule parses RRULE, false kalau tidak ada atau memakai bagian yang tidak didukung
+// BYMONTH/BYMONTHDAY yang sama dengan DTSTART (format umum feed libur) dianggap redundant
+func parseICSRule(value string, start time.Time) (icsRule, bool) {
+	rule := icsRule{interval: 1}
+	if value == "" {
+		return rule, false
+	}
+	byMonth := false
+	for _, part := range strings.Split(strings.ToUpper(value), ";") {
+		key, val, _ := strings.Cut(part, "=")
+		switch key {
+		case "FREQ":
+			rule.freq = val
+		case "INTERVAL", "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				return rule, false
+			}
+			if key == "INTERVAL" {
+				rule.interval = n
+			} else {
+				rule.count = n
+			}
+		case "UNTIL":
+			until, ok := parseICSDate(val)
+			if !ok {
+				return rule, false
+			}
+			rule.until = until
+		case "BYMONTH":
+			if val != strconv.Itoa(int(start.Month())) {
+				return rule, false
+			}
+			byMonth = true
+		case "BYMONTHDAY":
+			if val != strconv.Itoa(start.Day()) {
+				return rule, false
+			}
+		case "WKST":
+			// Tidak berpengaruh tanpa BYDAY
+		default:
+			return rule, false
+		}
+	}
+	switch rule.freq {
+	case "YEARLY":
+		return rule, true
+	case "DAILY", "WEEKLY", "MONTHLY":
+		return rule, !byMonth
+	}
+	return rule, false
+}
+
+// occurrences returns the start date of each occurrence, maks icsRecurrenceYears setelah start
+func (r icsRule) occurrences(start time.Time) []time.Time {
+	horizon := start.AddDate(icsRecurrenceYears, 0, 0)
+	var dates []time.Time
+	for n := 0; r.count == 0 || len(dates) < r.count; n++ {
+		var next time.Time
+		switch r.freq {
+		case "DAILY":
+			next = start.AddDate(0, 0, n*r.interval)
+		case "WEEKLY":
+			next = start.AddDate(0, 0, 7*n*r.interval)
+		case "MONTHLY":
+			next = start.AddDate(0, n*r.interval, 0)
+		default:
+			next = start.AddDate(n*r.interval, 0, 0)
+		}
+		if !next.Before(horizon) || (!r.until.IsZero() && next.After(r.until)) {
+			break
+		}
+		// Tanggal yang tidak ada (31 April, 29 Februari) dilewati dan tidak dihitung, sesuai RFC 5545
+		if (r.freq == "MONTHLY" || r.freq == "YEARLY") && next.Day() != start.Day() {
+			continue
+		}
+		dates = append(dates, next)
+	}
+	return dates
+}
+
+// parseICSDate reads the YYYYMMDD part of a DATE or DATE-TIME value
+func parseICSDate(value string) (time.Time, bool) {
+	if len(value) < 8 {
+		return time.Time{}, false
+	}
+	t, err := time.Parse("20060102", value[:8])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// unescapeICSText decodes TEXT escapes (\, \; \n \\)
+func unescapeICSText(value string) string {
+	replacer := strings.NewReplacer(`\,`, ",", `\;`, ";", `\n`, " ", `\N`, " ", `\\`, `\`)
+	return strings.TrimSpace(replacer.Replace(value))
+}
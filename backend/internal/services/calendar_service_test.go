@@ -0,0 +1,192 @@
+package services
+
+import (
+	"attendance-system/internal/models"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// icsFixture joins content lines with CRLF seperti file .ics asli
+func icsFixture(lines ...string) string {
+	return strings.Join(append(append([]string{"BEGIN:VCALENDAR", "VERSION:2.0"}, lines...), "END:VCALENDAR", ""), "\r\n")
+}
+
+func TestParseICS(t *testing.T) {
+	tests := []struct {
+		name        string
+		event       []string // Isi satu VEVENT
+		wantSummary string
+		wantDates   []string
+	}{
+		{
+			"all-day event, DTEND is exclusive",
+			[]string{"UID:nyepi", "DTSTART;VALUE=DATE:20240311", "DTEND;VALUE=DATE:20240312", "SUMMARY:Hari Suci Nyepi"},
+			"Hari Suci Nyepi", []string{"2024-03-11"},
+		},
+		{
+			"all-day event without DTEND",
+			[]string{"UID:a", "DTSTART;VALUE=DATE:20240101", "SUMMARY:Tahun Baru"},
+			"Tahun Baru", []string{"2024-01-01"},
+		},
+		{
+			"multi-day all-day event",
+			[]string{"UID:lebaran", "DTSTART;VALUE=DATE:20240410", "DTEND;VALUE=DATE:20240412", "SUMMARY:Idul Fitri"},
+			"Idul Fitri", []string{"2024-04-10", "2024-04-11"},
+		},
+		{
+			"multi-day event across a month",
+			[]string{"UID:b", "DTSTART;VALUE=DATE:20240130", "DTEND;VALUE=DATE:20240202", "SUMMARY:Cuti Bersama"},
+			"Cuti Bersama", []string{"2024-01-30", "2024-01-31", "2024-02-01"},
+		},
+		{
+			"date-time event keeps DTEND inclusive",
+			[]string{"UID:c", "DTSTART;TZID=Asia/Jakarta:20240517T090000", "DTEND;TZID=Asia/Jakarta:20240517T170000", "SUMMARY:Outing"},
+			"Outing", []string{"2024-05-17"},
+		},
+		{
+			"folded summary and escapes",
+			[]string{"UID:d", "DTSTART;VALUE=DATE:20240817", "SUMMARY:Hari Ulang Tahun Kemerdekaan\\, Republik", "  Indonesia \\; upacara"},
+			"Hari Ulang Tahun Kemerdekaan, Republik Indonesia ; upacara", []string{"2024-08-17"},
+		},
+		{
+			"folded property name and tab continuation",
+			[]string{"UID:e", "DTST", " ART;VALUE=DATE:20241225", "SUMMARY:Hari", "\t Natal"},
+			"Hari Natal", []string{"2024-12-25"},
+		},
+		{
+			"yearly rule with COUNT",
+			[]string{"UID:f", "DTSTART;VALUE=DATE:20240501", "RRULE:FREQ=YEARLY;COUNT=3", "SUMMARY:Hari Buruh"},
+			"Hari Buruh", []string{"2024-05-01", "2025-05-01", "2026-05-01"},
+		},
+		{
+			"yearly rule with redundant BYMONTH and UNTIL",
+			[]string{"UID:g", "DTSTART;VALUE=DATE:20240601", "RRULE:FREQ=YEARLY;BYMONTH=6;BYMONTHDAY=1;UNTIL=20260601T000000Z", "SUMMARY:Pancasila"},
+			"Pancasila", []string{"2024-06-01", "2025-06-01", "2026-06-01"},
+		},
+		{
+			"unbounded yearly rule stops after ten years",
+			[]string{"UID:h", "DTSTART;VALUE=DATE:20240101", "RRULE:FREQ=YEARLY", "SUMMARY:Tahun Baru"},
+			"Tahun Baru", []string{
+				"2024-01-01", "2025-01-01", "2026-01-01", "2027-01-01", "2028-01-01",
+				"2029-01-01", "2030-01-01", "2031-01-01", "2032-01-01", "2033-01-01",
+			},
+		},
+		{
+			"yearly rule skips years without february 29",
+			[]string{"UID:i", "DTSTART;VALUE=DATE:20240229", "RRULE:FREQ=YEARLY;COUNT=2", "SUMMARY:Kabisat"},
+			"Kabisat", []string{"2024-02-29", "2028-02-29"},
+		},
+		{
+			"weekly multi-day rule with INTERVAL",
+			[]string{"UID:j", "DTSTART;VALUE=DATE:20240706", "DTEND;VALUE=DATE:20240708", "RRULE:FREQ=WEEKLY;INTERVAL=2;COUNT=2", "SUMMARY:Shutdown"},
+			"Shutdown", []string{"2024-07-06", "2024-07-07", "2024-07-20", "2024-07-21"},
+		},
+		{
+			"overlapping daily occurrences are not duplicated",
+			[]string{"UID:k", "DTSTART;VALUE=DATE:20240902", "DTEND;VALUE=DATE:20240904", "RRULE:FREQ=DAILY;COUNT=2", "SUMMARY:Audit"},
+			"Audit", []string{"2024-09-02", "2024-09-03", "2024-09-04"},
+		},
+		{
+			"unsupported rule keeps the first occurrence",
+			[]string{"UID:l", "DTSTART;VALUE=DATE:20241111", "RRULE:FREQ=MONTHLY;BYDAY=2MO", "SUMMARY:Rapat"},
+			"Rapat", []string{"2024-11-11"},
+		},
+		{
+			"missing summary",
+			[]string{"UID:m", "DTSTART;VALUE=DATE:20241001"},
+			"Holiday", []string{"2024-10-01"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := append(append([]string{"BEGIN:VEVENT"}, tt.event...), "END:VEVENT")
+			events, err := parseICS(strings.NewReader(icsFixture(lines...)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(events) != 1 {
+				t.Fatalf("%d events, want 1", len(events))
+			}
+			if events[0].summary != tt.wantSummary {
+				t.Fatalf("summary %q, want %q", events[0].summary, tt.wantSummary)
+			}
+			if !reflect.DeepEqual(events[0].dates, tt.wantDates) {
+				t.Fatalf("dates %v, want %v", events[0].dates, tt.wantDates)
+			}
+		})
+	}
+
+	// Event multi-hari dibatasi maxEventDays
+	events, err := parseICS(strings.NewReader(icsFixture(
+		"BEGIN:VEVENT", "DTSTART;VALUE=DATE:20240101", "DTEND;VALUE=DATE:20260101", "END:VEVENT",
+		"BEGIN:VEVENT", "DTSTART;VALUE=DATE:20240101", "RRULE:FREQ=DAILY", "END:VEVENT",
+	)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, event := range events {
+		if len(event.dates) != maxEventDays {
+			t.Fatalf("event %d expanded to %d dates, want %d", i, len(event.dates), maxEventDays)
+		}
+	}
+
+	if _, err := parseICS(strings.NewReader("BEGIN:VEVENT\r\nEND:VEVENT\r\n")); !errors.Is(err, ErrInvalidCalendar) {
+		t.Fatalf("not an iCalendar file: err %v, want %v", err, ErrInvalidCalendar)
+	}
+}
+
+func TestCalendarImportICS(t *testing.T) {
+	db := newTestDB(t)
+	service := NewCalendarService(db, testSchedule)
+
+	// BOM + folded line (satu spasi pertama dibuang saat unfold) + event tanpa DTSTART
+	fixture := "\ufeff" + icsFixture(
+		"BEGIN:VEVENT", "UID:lebaran", "DTSTART;VALUE=DATE:20240410", "DTEND;VALUE=DATE:20240412",
+		"SUMMARY:Hari Raya", "  Idul Fitri", "END:VEVENT",
+		"BEGIN:VEVENT", "UID:buruh", "DTSTART;VALUE=DATE:20240501", "RRULE:FREQ=YEARLY;COUNT=2", "SUMMARY:Hari Buruh", "END:VEVENT",
+		"BEGIN:VEVENT", "UID:broken", "SUMMARY:Tanpa tanggal", "END:VEVENT",
+	)
+	result, err := service.ImportICS(strings.NewReader(fixture), nil, models.CalendarKindHoliday)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *result != (ICSImportResult{Events: 3, Created: 4, Skipped: 1}) {
+		t.Fatalf("result %+v, want 3 events, 4 created, 1 skipped", *result)
+	}
+
+	var days []models.CalendarDay
+	if err := db.Order("date ASC").Find(&days).Error; err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, day := range days {
+		got = append(got, day.Date+" "+day.Name)
+		if day.Kind != models.CalendarKindHoliday || day.Source != "ics" || day.SiteID != nil {
+			t.Fatalf("entry %+v, want an organisation holiday from ics", day)
+		}
+	}
+	want := []string{"2024-04-10 Hari Raya Idul Fitri", "2024-04-11 Hari Raya Idul Fitri", "2024-05-01 Hari Buruh", "2025-05-01 Hari Buruh"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("entries %v, want %v", got, want)
+	}
+
+	// Import ulang meng-update entry yang sama
+	result, err = service.ImportICS(strings.NewReader(fixture), nil, models.CalendarKindHoliday)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Created != 0 || result.Updated != 4 {
+		t.Fatalf("reimport %+v, want 4 updated", *result)
+	}
+	var count int64
+	db.Model(&models.CalendarDay{}).Count(&count)
+	if count != 4 {
+		t.Fatalf("%d entries after reimport, want 4", count)
+	}
+
+	if _, err := service.ImportICS(strings.NewReader(fixture), nil, "vacation"); !errors.Is(err, ErrInvalidCalendar) {
+		t.Fatalf("invalid kind: err %v, want %v", err, ErrInvalidCalendar)
+	}
+}
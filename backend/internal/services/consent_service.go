@@ -0,0 +1,217 @@
+package services
+
+import (
+	"attendance-system/internal/models"
+	"attendance-system/internal/repository"
+	"attendance-system/internal/storage"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Consent errors, handler memetakan ke status HTTP
+var (
+	ErrConsentRequired      = errors.New("biometric consent is missing, withdrawn or for an outdated policy version")
+	ErrConsentAlreadyGiven  = errors.New("employee already consented to the current policy version")
+	ErrConsentNotActive     = errors.New("employee has no active consent to withdraw")
+	ErrInvalidConsentMethod = errors.New("invalid consent method")
+	ErrInvalidConsentStatus = repository.ErrInvalidConsentStatus
+)
+
+// ConsentCoverage summarizes consent status of active employees
+type ConsentCoverage struct {
+	PolicyVersion          string  `json:"policy_version"`
+	Employees              int64   `json:"employees"` // Karyawan aktif yang cocok dengan filter
+	Consented              int64   `json:"consented"`
+	Outdated               int64   `json:"outdated"`
+	Withdrawn              int64   `json:"withdrawn"`
+	Missing                int64   `json:"missing"`
+	EnrolledWithoutConsent int64   `json:"enrolled_without_consent"` // Masih punya face descriptor tanpa consent aktif
+	CoveragePercent        float64 `json:"coverage_percent"`
+}
+
+// ConsentService records biometric consent and enforces it before face enrollment and verification
+type ConsentService struct {
+	db            *gorm.DB
+	storage       storage.Storage
+	policyVersion string
+}
+
+// NewConsentService creates a new ConsentService instance
+func NewConsentService(db *gorm.DB, store storage.Storage, policyVersion string) *ConsentService {
+	return &ConsentService{db: db, storage: store, policyVersion: policyVersion}
+}
+
+// PolicyVersion returns the biometric policy version employees must consent to
+func (s *ConsentService) PolicyVersion() string {
+	return s.policyVersion
+}
+
+// Latest returns the latest consent record of an employee, nil kalau belum pernah ada
+func (s *ConsentService) Latest(userID uint) (*models.BiometricConsent, error) {
+	// Find + Limit supaya karyawan tanpa consent tidak memunculkan log "record not found"
+	var consents []models.BiometricConsent
+	if err := s.db.Where("user_id = ?", userID).Order("id DESC").Limit(1).Find(&consents).Error; err != nil {
+		return nil, err
+	}
+	if len(consents) == 0 {
+		return nil, nil
+	}
+	return &consents[0], nil
+}
+
+// Status returns the consent status of an employee
+func (s *ConsentService) Status(userID uint) (string, error) {
+	consent, err := s.Latest(userID)
+	if err != nil {
+		return "", err
+	}
+	return consent.Status(s.policyVersion), nil
+}
+
+// Require returns ErrConsentRequired unless the employee consented to the current policy version
+func (s *ConsentService) Require(userID uint) error {
+	status, err := s.Status(userID)
+	if err != nil {
+		return err
+	}
+	if status != models.ConsentStatusConsented {
+		return ErrConsentRequired
+	}
+	return nil
+}
+
+// History returns all consent records of an employee, terbaru dulu
+func (s *ConsentService) History(userID uint) ([]models.BiometricConsent, error) {
+	var consents []models.BiometricConsent
+	err := s.db.Where("user_id = ?", userID).Order("id DESC").Find(&consents).Error
+	return consents, err
+}
+
+// Give records consent to the current policy version
+func (s *ConsentService) Give(userID uint, method string, recordedByID *uint) (*models.BiometricConsent, error) {
+	if !models.IsValidConsentMethod(method) {
+		return nil, ErrInvalidConsentMethod
+	}
+	status, err := s.Status(userID)
+	if err != nil {
+		return nil, err
+	}
+	if status == models.ConsentStatusConsented {
+		return nil, ErrConsentAlreadyGiven
+	}
+
+	consent := models.BiometricConsent{
+		UserID:        userID,
+		PolicyVersion: s.policyVersion,
+		Method:        method,
+		GivenAt:       time.Now().UTC(),
+		RecordedByID:  recordedByID,
+	}
+	if err := s.db.Create(&consent).Error; err != nil {
+		return nil, err
+	}
+	return &consent, nil
+}
+
+// Withdraw marks the active consent as withdrawn and deletes the reference photo and face descriptor
+// Karyawan dengan legal hold tetap diblokir dari face check-in, tapi datanya tidak dihapus
+// Selfie lama mengikuti retention policy biasa
+func (s *ConsentService) Withdraw(ctx context.Context, userID uint, reason string, withdrawnByID *uint) (*models.BiometricConsent, error) {
+	consent, err := s.Latest(userID)
+	if err != nil {
+		return nil, err
+	}
+	if consent == nil || consent.WithdrawnAt != nil {
+		return nil, ErrConsentNotActive
+	}
+
+	var user models.User
+	if err := s.db.Select("id", "face_image_path", "legal_hold").First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if !user.LegalHold && user.FaceImagePath != "" {
+		if err := s.storage.Delete(ctx, user.FaceImagePath); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return nil, fmt.Errorf("failed to delete reference photo: %w", err)
+		}
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(consent).Updates(map[string]interface{}{
+			"withdrawn_at": now, "withdrawn_by_id": withdrawnByID, "withdraw_reason": reason,
+		}).Error
+		if err != nil || user.LegalHold {
+			return err
+		}
+		return tx.Model(&models.User{}).Where("id = ?", userID).UpdateColumns(map[string]interface{}{
+			"face_image_path": "", "face_descriptor": "", "image_purged_at": now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	consent.WithdrawnAt = &now
+	consent.WithdrawnByID = withdrawnByID
+	consent.WithdrawReason = reason
+	return consent, nil
+}
+
+// StatusScope restricts a users query to employees with the given consent status
+func (s *ConsentService) StatusScope(status string) (func(*gorm.DB) *gorm.DB, error) {
+	sql, args, err := repository.ConsentStatusCondition(status, s.policyVersion)
+	if err != nil {
+		return nil, err
+	}
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(sql, args...)
+	}, nil
+}
+
+// Coverage counts active employees per consent status
+// scopes dipakai untuk filter org unit (OrgService.UsersScope)
+func (s *ConsentService) Coverage(scopes ...func(*gorm.DB) *gorm.DB) (*ConsentCoverage, error) {
+	active := func() *gorm.DB {
+		return s.db.Model(&models.User{}).Where("users.deactivated_at IS NULL").Scopes(scopes...)
+	}
+
+	coverage := &ConsentCoverage{PolicyVersion: s.policyVersion}
+	if err := active().Count(&coverage.Employees).Error; err != nil {
+		return nil, err
+	}
+
+	counts := map[string]*int64{
+		models.ConsentStatusConsented: &coverage.Consented,
+		models.ConsentStatusOutdated:  &coverage.Outdated,
+		models.ConsentStatusWithdrawn: &coverage.Withdrawn,
+		models.ConsentStatusMissing:   &coverage.Missing,
+	}
+	for status, count := range counts {
+		scope, err := s.StatusScope(status)
+		if err != nil {
+			return nil, err
+		}
+		if err := active().Scopes(scope).Count(count).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	consented, args, err := repository.ConsentStatusCondition(models.ConsentStatusConsented, s.policyVersion)
+	if err != nil {
+		return nil, err
+	}
+	err = active().Where("users.face_descriptor <> '' AND NOT "+consented, args...).Count(&coverage.EnrolledWithoutConsent).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if coverage.Employees > 0 {
+		coverage.CoveragePercent = float64(coverage.Consented) * 100 / float64(coverage.Employees)
+	}
+	return coverage, nil
+}
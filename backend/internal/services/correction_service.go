@@ -0,0 +1,376 @@
+package services
+
+import (
+	"attendance-system/internal/logging"
+	"attendance-system/internal/models"
+	"attendance-system/internal/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Correction workflow errors, handler memetakan ke status HTTP
+var (
+	ErrInvalidCorrection    = errors.New("invalid correction request")
+	ErrCorrectionForbidden  = errors.New("not allowed to act on this correction")
+	ErrCorrectionNotPending = errors.New("correction is no longer pending")
+	ErrCorrectionDuplicate  = errors.New("a pending correction already exists for this attendance")
+)
+
+// CorrectionSubmission is the input for a new correction request
+type CorrectionSubmission struct {
+	UserID       uint
+	AttendanceID *uint // nil = check-in yang hilang
+	CheckInTime  time.Time
+	Status       string
+	Reason       string
+}
+
+// correctionState is the effective check-in recorded in history events
+type correctionState struct {
+	CheckInTime  *time.Time `json:"check_in_time"`
+	Status       string     `json:"status,omitempty"`
+	CorrectionID *uint      `json:"correction_id,omitempty"`
+}
+
+// AttendanceHistory is an original record together with every correction on it
+type AttendanceHistory struct {
+	Attendance  models.AttendanceResponse   `json:"attendance"`
+	Effective   correctionState             `json:"effective"`
+	Corrections []models.CorrectionResponse `json:"corrections"`
+}
+
+// CorrectionService handles manual attendance corrections and their approval
+type CorrectionService struct {
+	db             *gorm.DB
+	orgService     *OrgService
+	tzService      *TimezoneService
+	summaryService *DailySummaryService
+}
+
+// NewCorrectionService creates a new CorrectionService instance
+func NewCorrectionService(db *gorm.DB, orgService *OrgService, tzService *TimezoneService, summaryService *DailySummaryService) *CorrectionService {
+	return &CorrectionService{
+		db:             db,
+		orgService:     orgService,
+		tzService:      tzService,
+		summaryService: summaryService,
+	}
+}
+
+// CanView checks if the actor may see corrections of the employee
+func (s *CorrectionService) CanView(actorID uint, actorRole string, userID uint) (bool, error) {
+	if actorID == userID {
+		return true, nil
+	}
+	return s.orgService.CanManageEmployee(actorID, actorRole, userID)
+}
+
+// Submit creates a pending correction, oleh karyawan sendiri atau atasannya
+func (s *CorrectionService) Submit(actorID uint, actorRole string, sub CorrectionSubmission) (*models.AttendanceCorrection, error) {
+	allowed, err := s.CanView(actorID, actorRole, sub.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrCorrectionForbidden
+	}
+
+	sub.Reason = strings.TrimSpace(sub.Reason)
+	if sub.Reason == "" {
+		return nil, fmt.Errorf("%w: reason is required", ErrInvalidCorrection)
+	}
+	if sub.Status == "" {
+		sub.Status = models.AttendanceStatusSuccess
+	}
+	if sub.Status != models.AttendanceStatusSuccess && sub.Status != models.AttendanceStatusFailed {
+		return nil, fmt.Errorf("%w: status must be success or failed", ErrInvalidCorrection)
+	}
+	if sub.CheckInTime.IsZero() {
+		return nil, fmt.Errorf("%w: check_in_time is required", ErrInvalidCorrection)
+	}
+	if sub.CheckInTime.After(time.Now()) {
+		return nil, fmt.Errorf("%w: check_in_time must not be in the future", ErrInvalidCorrection)
+	}
+
+	checkIn := sub.CheckInTime.UTC()
+	correction := models.AttendanceCorrection{
+		UserID:        sub.UserID,
+		AttendanceID:  sub.AttendanceID,
+		CheckInTime:   checkIn,
+		Date:          utils.LocalDate(checkIn, s.tzService.LocationForUser(sub.UserID, checkIn)),
+		CheckInStatus: sub.Status,
+		Reason:        sub.Reason,
+		Status:        models.CorrectionStatusPending,
+		RequestedByID: actorID,
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var before correctionState
+		if sub.AttendanceID != nil {
+			var attendance models.Attendance
+			if err := tx.First(&attendance, *sub.AttendanceID).Error; err != nil {
+				return fmt.Errorf("%w: attendance not found", ErrInvalidCorrection)
+			}
+			if attendance.UserID != sub.UserID {
+				return fmt.Errorf("%w: attendance belongs to another employee", ErrInvalidCorrection)
+			}
+
+			var pending int64
+			err := tx.Model(&models.AttendanceCorrection{}).
+				Where("attendance_id = ? AND status = ?", attendance.ID, models.CorrectionStatusPending).
+				Count(&pending).Error
+			if err != nil {
+				return err
+			}
+			if pending > 0 {
+				return ErrCorrectionDuplicate
+			}
+
+			original := attendance.CheckInTime.UTC()
+			correction.OriginalTime = &original
+			correction.OriginalStatus = attendance.Status
+
+			before, err = s.effectiveState(tx, &attendance)
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Create(&correction).Error; err != nil {
+			return err
+		}
+		return s.recordEvent(tx, &correction, models.CorrectionActionSubmitted, actorID, "", before, proposedState(&correction))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Get(correction.ID)
+}
+
+// Decide approves or rejects a pending correction
+// Approver tidak boleh karyawan yang bersangkutan atau yang mengajukan
+func (s *CorrectionService) Decide(id, approverID uint, approverRole string, approve bool, note string) (*models.AttendanceCorrection, error) {
+	var correction models.AttendanceCorrection
+	if err := s.db.First(&correction, id).Error; err != nil {
+		return nil, err
+	}
+	if correction.UserID == approverID || correction.RequestedByID == approverID {
+		return nil, fmt.Errorf("%w: cannot decide a correction you are part of", ErrCorrectionForbidden)
+	}
+	allowed, err := s.orgService.CanManageEmployee(approverID, approverRole, correction.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrCorrectionForbidden
+	}
+
+	status, action := models.CorrectionStatusRejected, models.CorrectionActionRejected
+	if approve {
+		status, action = models.CorrectionStatusApproved, models.CorrectionActionApproved
+	}
+	now := time.Now().UTC()
+	note = strings.TrimSpace(note)
+
+	// Tanggal yang perlu di-recompute: tanggal check-in efektif lama dan baru
+	affected := []string{correction.Date}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		before := correctionState{}
+		if correction.AttendanceID != nil {
+			var attendance models.Attendance
+			if err := tx.First(&attendance, *correction.AttendanceID).Error; err != nil {
+				return err
+			}
+			var err error
+			before, err = s.effectiveState(tx, &attendance)
+			if err != nil {
+				return err
+			}
+			if before.CheckInTime != nil {
+				loc := s.tzService.LocationForUser(correction.UserID, *before.CheckInTime)
+				affected = append(affected, utils.LocalDate(*before.CheckInTime, loc))
+			}
+		}
+
+		// Guard status di WHERE supaya dua approver tidak memproses bersamaan
+		result := tx.Model(&models.AttendanceCorrection{}).
+			Where("id = ? AND status = ?", correction.ID, models.CorrectionStatusPending).
+			Updates(map[string]interface{}{
+				"status":        status,
+				"approver_id":   approverID,
+				"decision_note": note,
+				"decided_at":    now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrCorrectionNotPending
+		}
+
+		after := before
+		if approve {
+			after = proposedState(&correction)
+		}
+		return s.recordEvent(tx, &correction, action, approverID, note, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if approve {
+		s.recomputeDates(correction.UserID, affected)
+	}
+
+	return s.Get(correction.ID)
+}
+
+// Cancel withdraws a pending correction, oleh pengaju atau karyawan yang bersangkutan
+func (s *CorrectionService) Cancel(id, actorID uint) (*models.AttendanceCorrection, error) {
+	var correction models.AttendanceCorrection
+	if err := s.db.First(&correction, id).Error; err != nil {
+		return nil, err
+	}
+	if correction.UserID != actorID && correction.RequestedByID != actorID {
+		return nil, ErrCorrectionForbidden
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.AttendanceCorrection{}).
+			Where("id = ? AND status = ?", correction.ID, models.CorrectionStatusPending).
+			Update("status", models.CorrectionStatusCancelled)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrCorrectionNotPending
+		}
+		return s.recordEvent(tx, &correction, models.CorrectionActionCancelled, actorID, "", correctionState{}, correctionState{})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Get(correction.ID)
+}
+
+// Get loads a correction with relations and history events
+func (s *CorrectionService) Get(id uint) (*models.AttendanceCorrection, error) {
+	var correction models.AttendanceCorrection
+	err := s.db.Preload("User").Preload("RequestedBy").Preload("Approver").
+		Preload("Events", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		First(&correction, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &correction, nil
+}
+
+// History returns the original attendance, its effective value and all corrections
+func (s *CorrectionService) History(attendanceID uint) (*AttendanceHistory, error) {
+	var attendance models.Attendance
+	if err := s.db.Preload("User").First(&attendance, attendanceID).Error; err != nil {
+		return nil, err
+	}
+
+	effective, err := s.effectiveState(s.db, &attendance)
+	if err != nil {
+		return nil, err
+	}
+
+	var corrections []models.AttendanceCorrection
+	err = s.db.Preload("User").Preload("RequestedBy").Preload("Approver").
+		Preload("Events", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Where("attendance_id = ?", attendanceID).
+		Order("created_at ASC").
+		Find(&corrections).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load corrections: %w", err)
+	}
+
+	history := &AttendanceHistory{
+		Attendance:  attendance.ToResponse(),
+		Effective:   effective,
+		Corrections: make([]models.CorrectionResponse, len(corrections)),
+	}
+	for i := range corrections {
+		history.Corrections[i] = corrections[i].ToResponse()
+	}
+	return history, nil
+}
+
+// effectiveState returns the attendance value after the latest approved correction
+func (s *CorrectionService) effectiveState(tx *gorm.DB, attendance *models.Attendance) (correctionState, error) {
+	checkIn := attendance.CheckInTime.UTC()
+	state := correctionState{CheckInTime: &checkIn, Status: attendance.Status}
+
+	var latest models.AttendanceCorrection
+	err := tx.Where("attendance_id = ? AND status = ?", attendance.ID, models.CorrectionStatusApproved).
+		Order("decided_at DESC, id DESC").
+		First(&latest).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return state, nil
+	}
+	if err != nil {
+		return state, fmt.Errorf("failed to load corrections: %w", err)
+	}
+	return proposedState(&latest), nil
+}
+
+// recordEvent appends a history event with before/after snapshots
+func (s *CorrectionService) recordEvent(tx *gorm.DB, correction *models.AttendanceCorrection, action string, actorID uint, note string, before, after correctionState) error {
+	event := models.AttendanceCorrectionEvent{
+		CorrectionID: correction.ID,
+		Action:       action,
+		ActorID:      actorID,
+		Note:         note,
+		Before:       encodeState(before),
+		After:        encodeState(after),
+	}
+	if err := tx.Create(&event).Error; err != nil {
+		return fmt.Errorf("failed to record correction history: %w", err)
+	}
+	return nil
+}
+
+// recomputeDates refreshes daily rows for dates that have already closed
+func (s *CorrectionService) recomputeDates(userID uint, dates []string) {
+	closed := s.summaryService.ClosedUntil(time.Now())
+	seen := make(map[string]bool)
+	for _, date := range dates {
+		if seen[date] || date > closed {
+			continue
+		}
+		seen[date] = true
+		if _, err := s.summaryService.MaterializeDate(date, []uint{userID}, ""); err != nil {
+			slog.Error("Error recomputing daily attendance", "date", date, "user_id", userID, logging.Err(err))
+		}
+	}
+}
+
+// proposedState is the check-in value a correction sets
+func proposedState(correction *models.AttendanceCorrection) correctionState {
+	checkIn := correction.CheckInTime.UTC()
+	state := correctionState{CheckInTime: &checkIn, Status: correction.CheckInStatus}
+	if correction.ID != 0 {
+		state.CorrectionID = &correction.ID
+	}
+	return state
+}
+
+// encodeState serializes a state, kosong kalau tidak ada nilai
+func encodeState(state correctionState) string {
+	if state.CheckInTime == nil && state.Status == "" {
+		return ""
+	}
+	data, _ := json.Marshal(state)
+	return string(data)
+}
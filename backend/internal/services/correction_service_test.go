@@ -0,0 +1,285 @@
+package services
+
+import (
+	"attendance-system/internal/models"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// newTestCorrectionService builds a CorrectionService on the default summary setup
+func newTestCorrectionService(t *testing.T, db *gorm.DB) *CorrectionService {
+	t.Helper()
+	summaryService := newTestSummaryService(t, db)
+	return NewCorrectionService(db, NewOrgService(db), summaryService.tzService, summaryService)
+}
+
+// jakartaTime parses "YYYY-MM-DD HH:MM" waktu Jakarta
+func jakartaTime(t *testing.T, value string) time.Time {
+	t.Helper()
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := time.ParseInLocation("2006-01-02 15:04", value, jakarta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed.UTC()
+}
+
+// assignToDepartment creates a department led by manager with the employee in it
+func assignToDepartment(t *testing.T, db *gorm.DB, code string, manager, employee *models.User) *models.Department {
+	t.Helper()
+	department := &models.Department{Name: code, Code: code, ManagerID: &manager.ID}
+	if err := db.Create(department).Error; err != nil {
+		t.Fatal(err)
+	}
+	assignment := &models.EmployeeAssignment{
+		UserID: employee.ID, DepartmentID: department.ID, EffectiveFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if err := db.Create(assignment).Error; err != nil {
+		t.Fatal(err)
+	}
+	return department
+}
+
+func TestCorrectionDecidePermissions(t *testing.T) {
+	db := newTestDB(t)
+	service := newTestCorrectionService(t, db)
+
+	budi := createSummaryUser(t, db, "budi")
+	citra := createSummaryUser(t, db, "citra")
+	manager := createTestUser(t, db, "maya", models.RoleManager)
+	otherManager := createTestUser(t, db, "oscar", models.RoleManager)
+	hr := createTestUser(t, db, "hana", models.RoleHR)
+	admin := createTestUser(t, db, "adi", models.RoleAdmin)
+	assignToDepartment(t, db, "FIN", manager, budi)
+	assignToDepartment(t, db, "OPS", otherManager, citra)
+
+	attendance := &models.Attendance{UserID: budi.ID, CheckInTime: jakartaTime(t, "2024-03-05 09:40"), Status: models.AttendanceStatusSuccess}
+	if err := db.Create(attendance).Error; err != nil {
+		t.Fatal(err)
+	}
+	submit := func(actor *models.User, attendanceID *uint) (*models.AttendanceCorrection, error) {
+		return service.Submit(actor.ID, actor.Role, CorrectionSubmission{
+			UserID: budi.ID, AttendanceID: attendanceID, CheckInTime: jakartaTime(t, "2024-03-05 08:55"), Reason: "Kamera kiosk error",
+		})
+	}
+
+	// Pengajuan: karyawan sendiri, atasannya, HR; bukan karyawan lain atau manager department lain
+	for _, actor := range []*models.User{citra, otherManager} {
+		if _, err := submit(actor, &attendance.ID); !errors.Is(err, ErrCorrectionForbidden) {
+			t.Fatalf("submit by %s: err %v, want %v", actor.Name, err, ErrCorrectionForbidden)
+		}
+	}
+	own, err := submit(budi, &attendance.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := submit(manager, &attendance.ID); !errors.Is(err, ErrCorrectionDuplicate) {
+		t.Fatalf("second pending correction: err %v, want %v", err, ErrCorrectionDuplicate)
+	}
+
+	tests := []struct {
+		name    string
+		actor   *models.User
+		wantErr error
+	}{
+		{"employee approving their own correction", budi, ErrCorrectionForbidden},
+		{"another employee", citra, ErrCorrectionForbidden},
+		{"manager of another department", otherManager, ErrCorrectionForbidden},
+	}
+	for _, tt := range tests {
+		if _, err := service.Decide(own.ID, tt.actor.ID, tt.actor.Role, true, ""); !errors.Is(err, tt.wantErr) {
+			t.Fatalf("%s: err %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+	decided, err := service.Decide(own.ID, manager.ID, manager.Role, true, "ok")
+	if err != nil {
+		t.Fatalf("manager of the employee: %v", err)
+	}
+	if decided.Status != models.CorrectionStatusApproved || decided.ApproverID == nil || *decided.ApproverID != manager.ID {
+		t.Fatalf("correction %+v, want approved by the manager", decided)
+	}
+	if _, err := service.Decide(own.ID, hr.ID, hr.Role, false, ""); !errors.Is(err, ErrCorrectionNotPending) {
+		t.Fatalf("decision after approval: err %v, want %v", err, ErrCorrectionNotPending)
+	}
+
+	// Yang mengajukan atas nama karyawan tidak boleh meng-approve, termasuk HR dan admin
+	for _, requester := range []*models.User{hr, admin, manager} {
+		correction, err := submit(requester, nil)
+		if err != nil {
+			t.Fatalf("submit by %s: %v", requester.Name, err)
+		}
+		if _, err := service.Decide(correction.ID, requester.ID, requester.Role, true, ""); !errors.Is(err, ErrCorrectionForbidden) {
+			t.Fatalf("%s approving their own request: err %v, want %v", requester.Name, err, ErrCorrectionForbidden)
+		}
+		if _, err := service.Cancel(correction.ID, requester.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Manager kehilangan scope setelah karyawan pindah department
+	pending, err := submit(budi, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	moved := time.Now().UTC().Add(-time.Hour)
+	db.Model(&models.EmployeeAssignment{}).Where("user_id = ?", budi.ID).Update("effective_to", moved)
+	var ops models.Department
+	db.Where("code = ?", "OPS").First(&ops)
+	db.Create(&models.EmployeeAssignment{UserID: budi.ID, DepartmentID: ops.ID, EffectiveFrom: moved})
+	if _, err := service.Decide(pending.ID, manager.ID, manager.Role, true, ""); !errors.Is(err, ErrCorrectionForbidden) {
+		t.Fatalf("former manager: err %v, want %v", err, ErrCorrectionForbidden)
+	}
+	if _, err := service.Decide(pending.ID, otherManager.ID, otherManager.Role, true, ""); err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+}
+
+func TestCorrectionLastDecisionWins(t *testing.T) {
+	db := newTestDB(t)
+	service := newTestCorrectionService(t, db)
+	budi := createSummaryUser(t, db, "budi")
+	hr := createTestUser(t, db, "hana", models.RoleHR)
+
+	const date = "2024-03-05"
+	attendance := &models.Attendance{UserID: budi.ID, CheckInTime: jakartaTime(t, date+" 09:40"), Status: models.AttendanceStatusSuccess}
+	if err := db.Create(attendance).Error; err != nil {
+		t.Fatal(err)
+	}
+	correct := func(clock string) *models.AttendanceCorrection {
+		t.Helper()
+		correction, err := service.Submit(budi.ID, budi.Role, CorrectionSubmission{
+			UserID: budi.ID, AttendanceID: &attendance.ID, CheckInTime: jakartaTime(t, date+" "+clock), Reason: "Salah jam",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return correction
+	}
+	// decide memakai Decide, lalu menyetel decided_at supaya urutan keputusan deterministik
+	decide := func(correction *models.AttendanceCorrection, approve bool, decidedAt string) {
+		t.Helper()
+		if _, err := service.Decide(correction.ID, hr.ID, hr.Role, approve, ""); err != nil {
+			t.Fatal(err)
+		}
+		at, _ := time.Parse(time.RFC3339, decidedAt)
+		db.Model(&models.AttendanceCorrection{}).Where("id = ?", correction.ID).Update("decided_at", at)
+	}
+	assertEffective := func(step string, wantLate int, want *models.AttendanceCorrection) {
+		t.Helper()
+		if _, err := service.summaryService.MaterializeDate(date, []uint{budi.ID}, ""); err != nil {
+			t.Fatal(err)
+		}
+		row := summaryRow(t, db, budi.ID, date)
+		if row == nil || row.LateMinutes != wantLate || row.CorrectionID == nil || *row.CorrectionID != want.ID {
+			t.Fatalf("%s: daily row %+v, want late %d from correction %d", step, row, wantLate, want.ID)
+		}
+		history, err := service.History(attendance.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if history.Effective.CorrectionID == nil || *history.Effective.CorrectionID != want.ID {
+			t.Fatalf("%s: history effective %+v, want correction %d", step, history.Effective, want.ID)
+		}
+		// Record asli tidak pernah diubah
+		if !history.Attendance.CheckInTime.Equal(attendance.CheckInTime) {
+			t.Fatalf("%s: original check-in changed to %s", step, history.Attendance.CheckInTime)
+		}
+	}
+
+	first := correct("09:30")
+	decide(first, true, "2024-03-06T03:00:00Z")
+	assertEffective("first approval", 30, first)
+
+	second := correct("08:50")
+	decide(second, true, "2024-03-06T04:00:00Z")
+	assertEffective("later approval", 0, second)
+
+	// Correction yang ditolak tidak meng-override
+	rejected := correct("11:00")
+	decide(rejected, false, "2024-03-06T05:00:00Z")
+	assertEffective("rejection", 0, second)
+
+	// Urutan keputusan, bukan urutan pengajuan, yang menentukan
+	third := correct("09:20")
+	decide(third, true, "2024-03-06T03:30:00Z")
+	assertEffective("approval backdated before the latest", 0, second)
+
+	// decided_at sama: id terbesar yang menang
+	fourth := correct("09:25")
+	decide(fourth, true, "2024-03-06T04:00:00Z")
+	assertEffective("tie on decided_at", 25, fourth)
+}
+
+func TestCorrectionMissingAndMovedCheckIns(t *testing.T) {
+	db := newTestDB(t)
+	service := newTestCorrectionService(t, db)
+	budi := createSummaryUser(t, db, "budi")
+	hr := createTestUser(t, db, "hana", models.RoleHR)
+
+	// Check-in tercatat di hari Senin, padahal sebenarnya Selasa pagi
+	attendance := &models.Attendance{UserID: budi.ID, CheckInTime: jakartaTime(t, "2024-03-04 23:50"), Status: models.AttendanceStatusSuccess}
+	if err := db.Create(attendance).Error; err != nil {
+		t.Fatal(err)
+	}
+	moved, err := service.Submit(hr.ID, hr.Role, CorrectionSubmission{
+		UserID: budi.ID, AttendanceID: &attendance.ID, CheckInTime: jakartaTime(t, "2024-03-05 09:05"), Reason: "Jam kiosk salah",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Check-in hari Rabu hilang
+	missing, err := service.Submit(budi.ID, budi.Role, CorrectionSubmission{
+		UserID: budi.ID, CheckInTime: jakartaTime(t, "2024-03-06 09:45"), Reason: "Kamera mati",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved.Date != "2024-03-05" || missing.Date != "2024-03-06" {
+		t.Fatalf("correction dates %s and %s, want local dates 2024-03-05 and 2024-03-06", moved.Date, missing.Date)
+	}
+
+	if _, err := service.summaryService.MaterializeDate(testMonday, []uint{budi.ID}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if row := summaryRow(t, db, budi.ID, testMonday); row.Status != models.DailyStatusLate {
+		t.Fatalf("monday before approval %+v, want late", row)
+	}
+
+	// Approve me-recompute tanggal lama dan baru (sudah lewat)
+	admin := createTestUser(t, db, "adi", models.RoleAdmin)
+	for _, correction := range []*models.AttendanceCorrection{moved, missing} {
+		if _, err := service.Decide(correction.ID, admin.ID, admin.Role, true, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []struct {
+		date       string
+		status     string
+		late       int
+		correction *models.AttendanceCorrection
+	}{
+		{testMonday, models.DailyStatusAbsent, 0, nil},
+		{"2024-03-05", models.DailyStatusPresent, 0, moved},
+		{"2024-03-06", models.DailyStatusLate, 45, missing},
+	}
+	for _, w := range want {
+		if w.date != testMonday {
+			if _, err := service.summaryService.MaterializeDate(w.date, []uint{budi.ID}, ""); err != nil {
+				t.Fatal(err)
+			}
+		}
+		row := summaryRow(t, db, budi.ID, w.date)
+		if row == nil || row.Status != w.status || row.LateMinutes != w.late {
+			t.Fatalf("%s: row %+v, want %s late %d", w.date, row, w.status, w.late)
+		}
+		if (w.correction == nil) != (row.CorrectionID == nil) || (w.correction != nil && *row.CorrectionID != w.correction.ID) {
+			t.Fatalf("%s: correction %v, want %v", w.date, row.CorrectionID, w.correction)
+		}
+	}
+}
@@ -0,0 +1,558 @@
+package services
+
+import (
+	"attendance-system/internal/logging"
+	"attendance-system/internal/models"
+	"attendance-system/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Summary scope for employees without a home site
+const SummaryScopeOrg = "org"
+
+// MaxRecomputeDays limits a single recompute request
+const MaxRecomputeDays = 93
+
+// ErrInvalidDateRange is returned when a recompute range is malformed
+var ErrInvalidDateRange = errors.New("invalid date range")
+
+// SiteSummaryScope returns the summary scope key for a site
+func SiteSummaryScope(siteID uint) string {
+	return fmt.Sprintf("site:%d", siteID)
+}
+
+// employeeDay holds everything needed to classify one employee on one local date
+type employeeDay struct {
+	user       models.User
+	assignment *models.EmployeeAssignment
+	loc        *time.Location
+	schedule   models.WorkSchedule
+	leaves     []*models.LeaveRequest // Cuti approved pada hari ini, bisa dua cuti setengah hari (am + pm)
+	workDay    bool                   // Schedule mingguan + override calendar
+	holiday    *models.CalendarDay    // Libur dari calendar
+	start      time.Time              // Tengah malam lokal (UTC instant)
+	end        time.Time
+}
+
+// DailySummaryService materializes daily attendance status (present, late, absent, ...)
+type DailySummaryService struct {
+	db              *gorm.DB
+	tzService       *TimezoneService
+	calendar        *CalendarService
+	defaultSchedule models.WorkSchedule
+}
+
+// NewDailySummaryService creates a new DailySummaryService instance
+func NewDailySummaryService(db *gorm.DB, tzService *TimezoneService, calendar *CalendarService, defaultSchedule models.WorkSchedule) *DailySummaryService {
+	return &DailySummaryService{
+		db:              db,
+		tzService:       tzService,
+		calendar:        calendar,
+		defaultSchedule: defaultSchedule,
+	}
+}
+
+// Recompute rebuilds daily rows for every date in [from, to]
+// userIDs kosong berarti semua karyawan
+func (s *DailySummaryService) Recompute(from, to string, userIDs []uint) (int, error) {
+	start, err := time.Parse(utils.DateLayout, from)
+	if err != nil {
+		return 0, fmt.Errorf("%w: from must be YYYY-MM-DD", ErrInvalidDateRange)
+	}
+	end, err := time.Parse(utils.DateLayout, to)
+	if err != nil {
+		return 0, fmt.Errorf("%w: to must be YYYY-MM-DD", ErrInvalidDateRange)
+	}
+	if end.Before(start) {
+		return 0, fmt.Errorf("%w: to must not be before from", ErrInvalidDateRange)
+	}
+	if end.Sub(start) >= MaxRecomputeDays*24*time.Hour {
+		return 0, fmt.Errorf("%w: range must not exceed %d days", ErrInvalidDateRange, MaxRecomputeDays)
+	}
+
+	total := 0
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		count, err := s.MaterializeDate(d.Format(utils.DateLayout), userIDs, "")
+		if err != nil {
+			return total, err
+		}
+		total += count
+	}
+	return total, nil
+}
+
+// MaterializeDate computes and upserts daily rows for one local date
+// scope membatasi ke karyawan dengan home site tertentu ("site:<id>") atau tanpa site ("org");
+// scope kosong berarti semua karyawan
+func (s *DailySummaryService) MaterializeDate(date string, userIDs []uint, scope string) (int, error) {
+	day, err := time.Parse(utils.DateLayout, date)
+	if err != nil {
+		return 0, fmt.Errorf("invalid date: %w", err)
+	}
+
+	// Window kasar dalam UTC yang pasti mencakup hari lokal di semua timezone (UTC-12 s/d UTC+14)
+	windowStart := day.Add(-14 * time.Hour)
+	windowEnd := day.Add(36 * time.Hour)
+
+	// Load karyawan yang mungkin aktif pada tanggal tersebut
+	var users []models.User
+	query := s.db.Select("id", "name", "created_at", "deactivated_at").
+		Where("created_at < ?", windowEnd).
+		Where("deactivated_at IS NULL OR deactivated_at > ?", windowStart)
+	if len(userIDs) > 0 {
+		query = query.Where("id IN ?", userIDs)
+	}
+	if err := query.Find(&users).Error; err != nil {
+		return 0, fmt.Errorf("failed to load employees: %w", err)
+	}
+	if len(users) == 0 {
+		return 0, nil
+	}
+
+	days, err := s.employeeDays(date, day, users, scope)
+	if err != nil {
+		return 0, err
+	}
+	if len(days) == 0 {
+		return 0, nil
+	}
+
+	// Load semua check-in dalam window sekali saja, lalu bucket per karyawan
+	ids := make([]uint, 0, len(days))
+	for _, d := range days {
+		ids = append(ids, d.user.ID)
+	}
+
+	// Cuti approved yang menutupi tanggal ini
+	var leaves []models.LeaveRequest
+	err = s.db.Where("user_id IN ? AND status = ? AND start_date <= ? AND end_date >= ?",
+		ids, models.LeaveStatusApproved, date, date).
+		Find(&leaves).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to load leave requests: %w", err)
+	}
+	leavesByUser := make(map[uint][]*models.LeaveRequest)
+	for i := range leaves {
+		leavesByUser[leaves[i].UserID] = append(leavesByUser[leaves[i].UserID], &leaves[i])
+	}
+
+	// Hari kerja = schedule site + override calendar (libur nasional, hari kerja pengganti)
+	calendar, err := s.calendar.Load(date, date)
+	if err != nil {
+		return 0, err
+	}
+	for _, d := range days {
+		d.leaves = leavesByUser[d.user.ID]
+
+		var siteID *uint
+		if d.assignment != nil {
+			siteID = d.assignment.SiteID
+		}
+		var entry *models.CalendarDay
+		d.workDay, entry = calendar.IsWorkingDay(date, siteID, d.schedule)
+		if entry != nil && entry.Kind == models.CalendarKindHoliday {
+			d.holiday = entry
+		}
+	}
+
+	var attendances []models.Attendance
+	err = s.db.Where("user_id IN ? AND check_in_time >= ? AND check_in_time < ?", ids, windowStart, windowEnd).
+		Order("check_in_time ASC").
+		Find(&attendances).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to load attendances: %w", err)
+	}
+
+	records, err := s.applyCorrections(date, ids, attendances)
+	if err != nil {
+		return 0, err
+	}
+	byUser := make(map[uint][]effectiveAttendance)
+	for _, a := range records {
+		byUser[a.UserID] = append(byUser[a.UserID], a)
+	}
+
+	now := time.Now().UTC()
+	count := 0
+	for _, d := range days {
+		row := s.classify(date, d, byUser[d.user.ID])
+		if row == nil {
+			// Hari libur mingguan tanpa check-in: hapus row lama jika ada (hasil recompute)
+			if err := s.db.Where("user_id = ? AND date = ?", d.user.ID, date).Delete(&models.DailyAttendance{}).Error; err != nil {
+				return count, fmt.Errorf("failed to delete daily attendance: %w", err)
+			}
+			continue
+		}
+
+		row.ComputedAt = now
+		err := s.db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "date"}},
+			UpdateAll: true,
+		}).Create(row).Error
+		if err != nil {
+			return count, fmt.Errorf("failed to save daily attendance: %w", err)
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// WorkDates returns the employee's working dates (YYYY-MM-DD) in [from, to]
+// Schedule dan calendar diambil dari home site karyawan pada tanggal from
+func (s *DailySummaryService) WorkDates(userID uint, from, to string) ([]string, error) {
+	start, err := time.Parse(utils.DateLayout, from)
+	if err != nil {
+		return nil, fmt.Errorf("%w: from must be YYYY-MM-DD", ErrInvalidDateRange)
+	}
+	end, err := time.Parse(utils.DateLayout, to)
+	if err != nil {
+		return nil, fmt.Errorf("%w: to must be YYYY-MM-DD", ErrInvalidDateRange)
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("%w: to must not be before from", ErrInvalidDateRange)
+	}
+
+	schedule := s.defaultSchedule
+	var siteID *uint
+	var assignment models.EmployeeAssignment
+	noon := start.Add(12 * time.Hour)
+	err = s.db.Preload("Site.WorkSchedule").
+		Where("user_id = ? AND effective_from <= ?", userID, noon).
+		Where("effective_to IS NULL OR effective_to > ?", noon).
+		First(&assignment).Error
+	if err == nil && assignment.Site != nil {
+		siteID = assignment.SiteID
+		if assignment.Site.WorkSchedule != nil {
+			schedule = *assignment.Site.WorkSchedule
+		}
+	}
+
+	calendar, err := s.calendar.Load(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var dates []string
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		date := d.Format(utils.DateLayout)
+		if working, _ := calendar.IsWorkingDay(date, siteID, schedule); working {
+			dates = append(dates, date)
+		}
+	}
+	return dates, nil
+}
+
+// ClosedUntil returns the last local date (YYYY-MM-DD) that has already ended in the organization timezone
+func (s *DailySummaryService) ClosedUntil(now time.Time) string {
+	return now.In(s.tzService.DefaultLocation()).AddDate(0, 0, -1).Format(utils.DateLayout)
+}
+
+// effectiveAttendance is a check-in after approved corrections are applied
+type effectiveAttendance struct {
+	models.Attendance
+	correctionID *uint // Correction yang meng-override record ini
+}
+
+// applyCorrections overlays approved corrections on the raw check-ins
+// Correction bisa memindahkan check-in ke tanggal lain, jadi dicari berdasarkan tanggal
+// koreksi maupun attendance yang ada di window. Correction tanpa attendance menjadi check-in baru.
+func (s *DailySummaryService) applyCorrections(date string, userIDs []uint, attendances []models.Attendance) ([]effectiveAttendance, error) {
+	records := make([]effectiveAttendance, len(attendances))
+	index := make(map[uint]int, len(attendances))
+	attendanceIDs := make([]uint, len(attendances))
+	for i, a := range attendances {
+		records[i] = effectiveAttendance{Attendance: a}
+		index[a.ID] = i
+		attendanceIDs[i] = a.ID
+	}
+
+	query := s.db.Where("user_id IN ? AND status = ?", userIDs, models.CorrectionStatusApproved)
+	if len(attendanceIDs) > 0 {
+		query = query.Where("date = ? OR attendance_id IN ?", date, attendanceIDs)
+	} else {
+		query = query.Where("date = ?", date)
+	}
+	var corrections []models.AttendanceCorrection
+	if err := query.Order("decided_at ASC, id ASC").Find(&corrections).Error; err != nil {
+		return nil, fmt.Errorf("failed to load corrections: %w", err)
+	}
+
+	// Urut decided_at (lalu id kalau sama): correction terakhir yang menang
+	for i := range corrections {
+		c := &corrections[i]
+		if c.AttendanceID != nil {
+			if idx, ok := index[*c.AttendanceID]; ok {
+				c.Apply(&records[idx].Attendance)
+				records[idx].correctionID = &c.ID
+				continue
+			}
+			// Record asli di luar window, tapi waktu koreksinya jatuh di tanggal ini
+			a := models.Attendance{ID: *c.AttendanceID, UserID: c.UserID}
+			c.Apply(&a)
+			index[a.ID] = len(records)
+			records = append(records, effectiveAttendance{Attendance: a, correctionID: &c.ID})
+			continue
+		}
+		a := models.Attendance{UserID: c.UserID}
+		c.Apply(&a)
+		records = append(records, effectiveAttendance{Attendance: a, correctionID: &c.ID})
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].CheckInTime.Before(records[j].CheckInTime)
+	})
+	return records, nil
+}
+
+// employeeDays resolves site, timezone and schedule for each employee on the date
+func (s *DailySummaryService) employeeDays(date string, day time.Time, users []models.User, scope string) ([]*employeeDay, error) {
+	ids := make([]uint, len(users))
+	for i, u := range users {
+		ids[i] = u.ID
+	}
+
+	// Assignment dipilih berdasarkan siang hari UTC pada tanggal tersebut
+	noon := day.Add(12 * time.Hour)
+	var assignments []models.EmployeeAssignment
+	err := s.db.Preload("Site.WorkSchedule").
+		Where("user_id IN ? AND effective_from <= ?", ids, noon).
+		Where("effective_to IS NULL OR effective_to > ?", noon).
+		Find(&assignments).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load assignments: %w", err)
+	}
+	byUser := make(map[uint]*models.EmployeeAssignment)
+	for i := range assignments {
+		byUser[assignments[i].UserID] = &assignments[i]
+	}
+
+	var days []*employeeDay
+	for _, user := range users {
+		assignment := byUser[user.ID]
+
+		// Filter scope job
+		switch {
+		case scope == SummaryScopeOrg && assignment != nil && assignment.SiteID != nil:
+			continue
+		case scope != "" && scope != SummaryScopeOrg &&
+			(assignment == nil || assignment.SiteID == nil || SiteSummaryScope(*assignment.SiteID) != scope):
+			continue
+		}
+
+		d := &employeeDay{
+			user:       user,
+			assignment: assignment,
+			loc:        s.tzService.DefaultLocation(),
+			schedule:   s.defaultSchedule,
+		}
+		if assignment != nil && assignment.Site != nil {
+			d.loc = s.tzService.siteLocation(assignment.Site)
+			if assignment.Site.WorkSchedule != nil {
+				d.schedule = *assignment.Site.WorkSchedule
+			}
+		}
+
+		start, err := utils.StartOfDate(date, d.loc)
+		if err != nil {
+			return nil, err
+		}
+		d.start, d.end = utils.DayBounds(start, d.loc)
+
+		// Karyawan belum terdaftar atau sudah nonaktif pada hari itu
+		if !user.CreatedAt.Before(d.end) {
+			continue
+		}
+		if user.DeactivatedAt != nil && !user.DeactivatedAt.After(d.start) {
+			continue
+		}
+
+		days = append(days, d)
+	}
+
+	return days, nil
+}
+
+// classify determines the daily status, nil berarti tidak perlu row (hari libur tanpa check-in)
+func (s *DailySummaryService) classify(date string, d *employeeDay, records []effectiveAttendance) *models.DailyAttendance {
+	row := &models.DailyAttendance{
+		UserID:   d.user.ID,
+		Date:     date,
+		Timezone: d.loc.String(),
+	}
+	if d.assignment != nil {
+		row.SiteID = d.assignment.SiteID
+		row.DepartmentID = &d.assignment.DepartmentID
+		row.TeamID = d.assignment.TeamID
+	}
+
+	// Cari check-in sukses pertama dalam hari lokal
+	var first *effectiveAttendance
+	for i := range records {
+		a := &records[i]
+		if a.CheckInTime.Before(d.start) || !a.CheckInTime.Before(d.end) {
+			continue
+		}
+		if a.Status != models.AttendanceStatusSuccess {
+			row.FailedAttempts++
+			continue
+		}
+		if first == nil {
+			first = a
+		}
+	}
+
+	localDay := d.start.In(d.loc)
+	isWorkDay := d.workDay
+
+	if first == nil {
+		// Libur calendar di hari kerja schedule dicatat sebagai holiday, bukan absent
+		if d.holiday != nil && d.schedule.IsWorkDay(localDay.Weekday()) {
+			row.Status = models.DailyStatusHoliday
+			return row
+		}
+		if !isWorkDay {
+			return nil
+		}
+		row.Status = models.DailyStatusAbsent
+		if leave, _ := d.dayLeave(); leave != nil {
+			row.Status = models.DailyStatusOnLeave
+			row.LeaveRequestID = &leave.ID
+		}
+		return row
+	}
+
+	checkIn := first.CheckInTime.UTC()
+	row.FirstCheckIn = &checkIn
+	if first.ID != 0 {
+		row.AttendanceID = &first.ID
+	}
+	row.CorrectionID = first.correctionID
+	row.Status = models.DailyStatusPresent
+
+	// Cuti full day atau setengah hari pagi: tidak dihitung late
+	leave, skipLate := d.dayLeave()
+	if leave != nil {
+		row.LeaveRequestID = &leave.ID
+	}
+
+	// Late hanya dihitung di hari kerja
+	if isWorkDay && !skipLate {
+		hour, minute, err := d.schedule.StartClock()
+		if err == nil {
+			scheduled := time.Date(localDay.Year(), localDay.Month(), localDay.Day(), hour, minute, 0, 0, d.loc).UTC()
+			row.ScheduledStart = &scheduled
+			deadline := scheduled.Add(time.Duration(d.schedule.LateGraceMinutes) * time.Minute)
+			if checkIn.After(deadline) {
+				row.Status = models.DailyStatusLate
+				row.LateMinutes = int(checkIn.Sub(scheduled).Minutes())
+			}
+		}
+	}
+
+	return row
+}
+
+// dayLeave merges the approved leave of the day, coversMorning true kalau ada cuti full day atau setengah hari pagi
+// Cuti yang menutupi pagi didahulukan karena itu yang menentukan late, jadi am + pm tidak saling menimpa
+func (d *employeeDay) dayLeave() (leave *models.LeaveRequest, coversMorning bool) {
+	for _, l := range d.leaves {
+		if l.HalfDay != models.HalfDayAfternoon {
+			return l, true
+		}
+		if leave == nil {
+			leave = l
+		}
+	}
+	return leave, false
+}
+
+// DailySummaryJob periodically materializes days that have closed in each site
+type DailySummaryJob struct {
+	service      *DailySummaryService
+	db           *gorm.DB
+	interval     time.Duration
+	closeAfter   time.Duration
+	backfillDays int
+}
+
+// NewDailySummaryJob creates a new DailySummaryJob
+func NewDailySummaryJob(service *DailySummaryService, interval, closeAfter time.Duration, backfillDays int) *DailySummaryJob {
+	return &DailySummaryJob{
+		service:      service,
+		db:           service.db,
+		interval:     interval,
+		closeAfter:   closeAfter,
+		backfillDays: backfillDays,
+	}
+}
+
+// Run checks for closed days on every tick until ctx is cancelled
+func (j *DailySummaryJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := j.RunOnce(time.Now()); err != nil {
+			slog.Error("Error running daily summary job", logging.Err(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce materializes every closed day (within backfill window) not yet processed per scope
+func (j *DailySummaryJob) RunOnce(now time.Time) error {
+	var sites []models.Site
+	if err := j.db.Select("id", "timezone").Find(&sites).Error; err != nil {
+		return fmt.Errorf("failed to load sites: %w", err)
+	}
+
+	scopes := map[string]*time.Location{SummaryScopeOrg: j.service.tzService.DefaultLocation()}
+	for i := range sites {
+		scopes[SiteSummaryScope(sites[i].ID)] = j.service.tzService.siteLocation(&sites[i])
+	}
+
+	for scope, loc := range scopes {
+		// Hari terakhir yang sudah selesai = kemarin relatif terhadap (now - closeAfter)
+		lastClosed := now.Add(-j.closeAfter).In(loc).AddDate(0, 0, -1)
+
+		for i := j.backfillDays - 1; i >= 0; i-- {
+			date := utils.LocalDate(lastClosed.AddDate(0, 0, -i), loc)
+
+			var existing int64
+			if err := j.db.Model(&models.DailySummaryRun{}).Where("scope = ? AND date = ?", scope, date).Count(&existing).Error; err != nil {
+				return fmt.Errorf("failed to check summary runs: %w", err)
+			}
+			if existing > 0 {
+				continue
+			}
+
+			count, err := j.service.MaterializeDate(date, nil, scope)
+			if err != nil {
+				return fmt.Errorf("failed to materialize %s for %s: %w", date, scope, err)
+			}
+
+			run := models.DailySummaryRun{Scope: scope, Date: date, Employees: count, CompletedAt: time.Now().UTC()}
+			if err := j.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&run).Error; err != nil {
+				return fmt.Errorf("failed to record summary run: %w", err)
+			}
+
+			slog.Info("Daily summary materialized", "scope", scope, "date", date, "employees", count)
+		}
+	}
+
+	return nil
+}
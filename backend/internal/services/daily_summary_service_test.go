@@ -0,0 +1,243 @@
+package services
+
+import (
+	"attendance-system/internal/models"
+	"fmt"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"gorm.io/gorm"
+)
+
+// Senin 4 Maret 2024, Sabtu 9 Maret 2024
+const (
+	testMonday   = "2024-03-04"
+	testSaturday = "2024-03-09"
+)
+
+var testSchedule = models.WorkSchedule{Name: "default", StartTime: "09:00", LateGraceMinutes: 15, WorkDays: "1,2,3,4,5"}
+
+// newTestSummaryService builds a DailySummaryService with the default schedule, ORG_TIMEZONE Asia/Jakarta
+func newTestSummaryService(t *testing.T, db *gorm.DB) *DailySummaryService {
+	t.Helper()
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tzService := NewTimezoneService(db, NewOrgService(db), jakarta)
+	return NewDailySummaryService(db, tzService, NewCalendarService(db, testSchedule), testSchedule)
+}
+
+// createSummaryUser inserts an employee registered long before the test dates
+func createSummaryUser(t *testing.T, db *gorm.DB, name string) *models.User {
+	t.Helper()
+	user := createTestUser(t, db, name, models.RoleEmployee)
+	registered := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := db.Model(user).Update("created_at", registered).Error; err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+// summaryRow returns the materialized row, nil kalau tidak ada
+func summaryRow(t *testing.T, db *gorm.DB, userID uint, date string) *models.DailyAttendance {
+	t.Helper()
+	var rows []models.DailyAttendance
+	if err := db.Where("user_id = ? AND date = ?", userID, date).Find(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return &rows[0]
+}
+
+func TestDailySummaryClassify(t *testing.T) {
+	db := newTestDB(t)
+	service := newTestSummaryService(t, db)
+	jakarta := service.tzService.DefaultLocation()
+
+	leaveType := &models.LeaveType{Code: "ANNUAL", Name: "Cuti Tahunan", AnnualAllowance: 12}
+	if err := db.Create(leaveType).Error; err != nil {
+		t.Fatal(err)
+	}
+	// Libur nasional hari Senin dan Sabtu
+	for _, date := range []string{testMonday, testSaturday} {
+		if err := db.Create(&models.CalendarDay{Date: date, Kind: models.CalendarKindHoliday, Name: "Libur"}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	type checkIn struct {
+		clock  string // HH:MM waktu Jakarta
+		status string
+	}
+	tests := []struct {
+		name       string
+		date       string
+		checkIns   []checkIn
+		halfDays   []string // Cuti approved pada tanggal itu, "" = full day
+		wantStatus string   // "" = tidak ada row
+		wantLate   int
+		wantFailed int
+		wantLeave  int // Index di halfDays cuti yang tercatat, -1 = tanpa cuti
+	}{
+		{"on time", "2024-03-05", []checkIn{{"08:55", models.AttendanceStatusSuccess}}, nil, models.DailyStatusPresent, 0, 0, -1},
+		{"last minute of grace", "2024-03-05", []checkIn{{"09:15", models.AttendanceStatusSuccess}}, nil, models.DailyStatusPresent, 0, 0, -1},
+		{"after grace counts from schedule start", "2024-03-05", []checkIn{{"09:16", models.AttendanceStatusSuccess}}, nil, models.DailyStatusLate, 16, 0, -1},
+		{"failed attempts before a late success", "2024-03-05",
+			[]checkIn{{"08:50", models.AttendanceStatusFailed}, {"09:30", models.AttendanceStatusSuccess}}, nil, models.DailyStatusLate, 30, 1, -1},
+		{"no check-in on a work day", "2024-03-05", nil, nil, models.DailyStatusAbsent, 0, 0, -1},
+		{"weekly off without check-in", "2024-03-10", nil, nil, "", 0, 0, -1},
+		{"weekly off with check-in is never late", "2024-03-10", []checkIn{{"11:00", models.AttendanceStatusSuccess}}, nil, models.DailyStatusPresent, 0, 0, -1},
+		{"holiday on a work day", testMonday, nil, nil, models.DailyStatusHoliday, 0, 0, -1},
+		{"holiday on a weekly off", testSaturday, nil, nil, "", 0, 0, -1},
+		{"check-in on a holiday is never late", testMonday, []checkIn{{"11:00", models.AttendanceStatusSuccess}}, nil, models.DailyStatusPresent, 0, 0, -1},
+		{"full day leave", "2024-03-05", nil, []string{""}, models.DailyStatusOnLeave, 0, 0, 0},
+		{"morning leave skips the late check", "2024-03-05", []checkIn{{"13:00", models.AttendanceStatusSuccess}},
+			[]string{models.HalfDayMorning}, models.DailyStatusPresent, 0, 0, 0},
+		{"afternoon leave is still late", "2024-03-05", []checkIn{{"09:30", models.AttendanceStatusSuccess}},
+			[]string{models.HalfDayAfternoon}, models.DailyStatusLate, 30, 0, 0},
+		{"morning and afternoon halves", "2024-03-05", nil,
+			[]string{models.HalfDayAfternoon, models.HalfDayMorning}, models.DailyStatusOnLeave, 0, 0, 1},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := createSummaryUser(t, db, fmt.Sprintf("employee%d", i))
+
+			day, _ := time.ParseInLocation("2006-01-02", tt.date, jakarta)
+			for _, c := range tt.checkIns {
+				clock, _ := time.Parse("15:04", c.clock)
+				at := day.Add(time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute)
+				if err := db.Create(&models.Attendance{UserID: user.ID, CheckInTime: at.UTC(), Status: c.status}).Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+			var leaveIDs []uint
+			for _, half := range tt.halfDays {
+				leave := &models.LeaveRequest{
+					UserID: user.ID, LeaveTypeID: leaveType.ID, StartDate: tt.date, EndDate: tt.date,
+					HalfDay: half, Days: 1, Status: models.LeaveStatusApproved,
+				}
+				if err := db.Create(leave).Error; err != nil {
+					t.Fatal(err)
+				}
+				leaveIDs = append(leaveIDs, leave.ID)
+			}
+
+			if _, err := service.MaterializeDate(tt.date, []uint{user.ID}, ""); err != nil {
+				t.Fatal(err)
+			}
+			row := summaryRow(t, db, user.ID, tt.date)
+			if tt.wantStatus == "" {
+				if row != nil {
+					t.Fatalf("row %+v, want none", row)
+				}
+				return
+			}
+			if row == nil {
+				t.Fatalf("no row, want %s", tt.wantStatus)
+			}
+			if row.Status != tt.wantStatus || row.LateMinutes != tt.wantLate || row.FailedAttempts != tt.wantFailed {
+				t.Fatalf("status %s late %d failed %d, want %s late %d failed %d",
+					row.Status, row.LateMinutes, row.FailedAttempts, tt.wantStatus, tt.wantLate, tt.wantFailed)
+			}
+			if tt.wantLeave < 0 && row.LeaveRequestID != nil {
+				t.Fatalf("leave %d recorded, want none", *row.LeaveRequestID)
+			}
+			if tt.wantLeave >= 0 && (row.LeaveRequestID == nil || *row.LeaveRequestID != leaveIDs[tt.wantLeave]) {
+				t.Fatalf("leave %v, want %d", row.LeaveRequestID, leaveIDs[tt.wantLeave])
+			}
+			if row.Timezone != "Asia/Jakarta" {
+				t.Fatalf("timezone %s, want Asia/Jakarta", row.Timezone)
+			}
+		})
+	}
+}
+
+func TestDailySummaryJobClosesEachSiteInItsTimezone(t *testing.T) {
+	db := newTestDB(t)
+	service := newTestSummaryService(t, db)
+	job := NewDailySummaryJob(service, time.Minute, time.Hour, 1)
+
+	department := &models.Department{Name: "Operations", Code: "OPS"}
+	if err := db.Create(department).Error; err != nil {
+		t.Fatal(err)
+	}
+	jakartaSite := &models.Site{Name: "Jakarta", Code: "JKT", Timezone: "Asia/Jakarta"}
+	newYorkSite := &models.Site{Name: "New York", Code: "NYC", Timezone: "America/New_York"}
+	for _, site := range []*models.Site{jakartaSite, newYorkSite} {
+		if err := db.Create(site).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	budi := createSummaryUser(t, db, "budi")
+	citra := createSummaryUser(t, db, "citra")
+	for user, site := range map[uint]*models.Site{budi.ID: jakartaSite, citra.ID: newYorkSite} {
+		assignment := &models.EmployeeAssignment{
+			UserID: user, DepartmentID: department.ID, SiteID: &site.ID,
+			EffectiveFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		}
+		if err := db.Create(assignment).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	runs := func() map[string]bool {
+		var all []models.DailySummaryRun
+		if err := db.Where("date = ?", testMonday).Find(&all).Error; err != nil {
+			t.Fatal(err)
+		}
+		done := make(map[string]bool)
+		for _, run := range all {
+			done[run.Scope] = true
+		}
+		return done
+	}
+	jakartaScope, newYorkScope := SiteSummaryScope(jakartaSite.ID), SiteSummaryScope(newYorkSite.ID)
+
+	steps := []struct {
+		name        string
+		now         string // UTC
+		wantJakarta bool   // Senin sudah di-materialize untuk site tersebut
+		wantNewYork bool
+	}{
+		// Jakarta (UTC+7) Selasa 00:30, belum lewat DAILY_SUMMARY_CLOSE_AFTER
+		{"jakarta midnight within close delay", "2024-03-04T17:30:00Z", false, false},
+		// Jakarta Selasa 01:00 = tutup + 1 jam; New York (UTC-5) masih Senin siang
+		{"jakarta closed, new york still open", "2024-03-04T18:00:00Z", true, false},
+		// New York Selasa 01:00
+		{"new york closed", "2024-03-05T06:00:00Z", true, true},
+	}
+	for _, step := range steps {
+		now, _ := time.Parse(time.RFC3339, step.now)
+		if err := job.RunOnce(now); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		done := runs()
+		if done[jakartaScope] != step.wantJakarta || done[newYorkScope] != step.wantNewYork {
+			t.Fatalf("%s: jakarta %v, new york %v, want %v and %v",
+				step.name, done[jakartaScope], done[newYorkScope], step.wantJakarta, step.wantNewYork)
+		}
+		if (summaryRow(t, db, budi.ID, testMonday) != nil) != step.wantJakarta {
+			t.Fatalf("%s: jakarta employee row does not match the site run", step.name)
+		}
+		if (summaryRow(t, db, citra.ID, testMonday) != nil) != step.wantNewYork {
+			t.Fatalf("%s: new york employee row does not match the site run", step.name)
+		}
+	}
+
+	// Hari yang sudah di-run tidak diproses ulang, row yang dihapus tidak muncul lagi
+	db.Where("user_id = ?", budi.ID).Delete(&models.DailyAttendance{})
+	now, _ := time.Parse(time.RFC3339, "2024-03-05T06:30:00Z")
+	if err := job.RunOnce(now); err != nil {
+		t.Fatal(err)
+	}
+	if row := summaryRow(t, db, budi.ID, testMonday); row != nil {
+		t.Fatalf("closed day was materialized again: %+v", row)
+	}
+	if row := summaryRow(t, db, citra.ID, testMonday); row.Status != models.DailyStatusAbsent || row.Timezone != "America/New_York" {
+		t.Fatalf("new york row %+v, want absent in America/New_York", row)
+	}
+}
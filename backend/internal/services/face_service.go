@@ -1,23 +1,58 @@
 package services
 
 import (
+	"attendance-system/internal/metrics"
+	"attendance-system/internal/storage"
+	"attendance-system/internal/tracing"
+	"attendance-system/internal/utils"
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"image"
+	"image/color"
 	_ "image/jpeg"
 	_ "image/png"
+	"io"
 	"math"
-	"os"
+	"mime/multipart"
+	"time"
 
 	"github.com/corona10/goimagehash"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // FaceService handles face verification operations
-type FaceService struct{}
+// Foto wajah/selfie dan descriptor disimpan terenkripsi (envelope), decrypt dilakukan di sini
+type FaceService struct {
+	envelope *utils.Envelope
+	storage  storage.Storage
+	metrics  *metrics.Metrics // nil = tidak dicatat (CLI)
+}
 
 // NewFaceService creates a new FaceService instance
-func NewFaceService() *FaceService {
-	return &FaceService{}
+func NewFaceService(envelope *utils.Envelope, store storage.Storage, m *metrics.Metrics) *FaceService {
+	return &FaceService{envelope: envelope, storage: store, metrics: m}
+}
+
+// SaveUploadedImage stores an uploaded face image or selfie encrypted, return storage key
+func (fs *FaceService) SaveUploadedImage(ctx context.Context, file *multipart.FileHeader) (string, error) {
+	return utils.SaveUploadedFile(ctx, fs.storage, file, "", fs.envelope)
+}
+
+// SaveImage stores a face image from a reader encrypted, return storage key
+func (fs *FaceService) SaveImage(ctx context.Context, src io.Reader, originalName string) (string, error) {
+	return utils.SaveFile(ctx, fs.storage, src, originalName, "", fs.envelope)
+}
+
+// ReadImage returns the decrypted content of a stored image
+func (fs *FaceService) ReadImage(ctx context.Context, imagePath string) ([]byte, error) {
+	return utils.ReadFile(ctx, fs.storage, imagePath, fs.envelope)
+}
+
+// DeleteImage removes a stored image, dipakai untuk rollback kalau proses setelah upload gagal
+func (fs *FaceService) DeleteImage(ctx context.Context, imagePath string) error {
+	return utils.DeleteFile(ctx, fs.storage, imagePath)
 }
 
 // FaceDescriptor represents face embedding data
@@ -28,62 +63,133 @@ type FaceDescriptor struct {
 	DHash uint64 `json:"dhash"` // Difference hash
 }
 
-// ExtractFaceDescriptor extracts face features from image
+// ExtractFaceDescriptor extracts face features from image, hasilnya terenkripsi dan siap disimpan
 // CATATAN: Ini adalah implementasi SEDERHANA menggunakan image hashing
 // Untuk production, gunakan face recognition API seperti:
 // - AWS Rekognition
 // - Face++ API
 // - Azure Face API
 // - Atau microservice Python dengan face_recognition library
-func (fs *FaceService) ExtractFaceDescriptor(imagePath string) (string, error) {
-	// Buka file gambar
-	file, err := os.Open(imagePath)
+func (fs *FaceService) ExtractFaceDescriptor(ctx context.Context, imagePath string) (_ string, err error) {
+	ctx, span := tracing.Start(ctx, "face.ExtractFaceDescriptor")
+	defer func() { tracing.End(span, err) }()
+
+	descriptor, err := fs.extractDescriptor(ctx, imagePath)
+	if err != nil {
+		return "", err
+	}
+	return fs.envelope.SealString(descriptor)
+}
+
+// extractDescriptor returns the plaintext descriptor JSON of an image
+func (fs *FaceService) extractDescriptor(ctx context.Context, imagePath string) (string, error) {
+	// Baca dan decrypt file gambar
+	content, err := fs.ReadImage(ctx, imagePath)
 	if err != nil {
 		return "", fmt.Errorf("failed to open image: %w", err)
 	}
-	defer file.Close()
+	return fs.DescribeImage(ctx, content)
+}
 
-	// Decode image
-	img, _, err := image.Decode(file)
+// DescribeImage returns the plaintext descriptor JSON of image bytes that are not in storage
+// Dipakai attendancectl verify-photo: foto dari disk dibandingkan tanpa disimpan
+func (fs *FaceService) DescribeImage(ctx context.Context, content []byte) (_ string, err error) {
+	started := time.Now()
+	ctx, span := tracing.Start(ctx, "face.DescribeImage", attribute.Int("image.size", len(content)))
+	defer func() {
+		fs.metrics.ObserveExtraction(time.Since(started))
+		tracing.End(span, err)
+	}()
+
+	// Decode image, span terpisah dari hashing supaya terlihat mana yang lambat
+	_, decodeSpan := tracing.Start(ctx, "image.decode")
+	img, format, err := image.Decode(bytes.NewReader(content))
 	if err != nil {
+		tracing.End(decodeSpan, err)
 		return "", fmt.Errorf("failed to decode image: %w", err)
 	}
+	bounds := img.Bounds()
+	decodeSpan.SetAttributes(
+		attribute.String("image.format", format),
+		attribute.Int("image.width", bounds.Dx()),
+		attribute.Int("image.height", bounds.Dy()),
+	)
+	decodeSpan.End()
+
+	_, hashSpan := tracing.Start(ctx, "face.hash")
+	descriptor, err := hashImage(img)
+	tracing.End(hashSpan, err)
+	if err != nil {
+		return "", err
+	}
+
+	// Convert ke JSON string
+	descriptorJSON, err := json.Marshal(descriptor)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal descriptor: %w", err)
+	}
+
+	return string(descriptorJSON), nil
+}
 
+// hashImage builds the descriptor of a decoded image
+func hashImage(img image.Image) (FaceDescriptor, error) {
 	// Generate multiple hash untuk akurasi lebih baik
 	pHash, err := goimagehash.PerceptionHash(img)
 	if err != nil {
-		return "", fmt.Errorf("failed to generate perceptual hash: %w", err)
+		return FaceDescriptor{}, fmt.Errorf("failed to generate perceptual hash: %w", err)
 	}
 
 	aHash, err := goimagehash.AverageHash(img)
 	if err != nil {
-		return "", fmt.Errorf("failed to generate average hash: %w", err)
+		return FaceDescriptor{}, fmt.Errorf("failed to generate average hash: %w", err)
 	}
 
 	dHash, err := goimagehash.DifferenceHash(img)
 	if err != nil {
-		return "", fmt.Errorf("failed to generate difference hash: %w", err)
+		return FaceDescriptor{}, fmt.Errorf("failed to generate difference hash: %w", err)
 	}
 
-	// Buat descriptor object
-	descriptor := FaceDescriptor{
+	return FaceDescriptor{
 		PHash: pHash.GetHash(),
 		AHash: aHash.GetHash(),
 		DHash: dHash.GetHash(),
-	}
+	}, nil
+}
 
-	// Convert ke JSON string
-	descriptorJSON, err := json.Marshal(descriptor)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal descriptor: %w", err)
+// SelfTest hashes a generated image, dipakai readiness probe untuk memastikan engine wajah siap dipakai
+// Engine sekarang lokal (perceptual hash) tanpa koneksi keluar; kalau diganti API / microservice eksternal,
+// cek koneksi ke engine itu di sini. Tidak dicatat di metrics ekstraksi
+func (fs *FaceService) SelfTest(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
 	}
-
-	return string(descriptorJSON), nil
+	img := image.NewGray(image.Rect(0, 0, 32, 32))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			img.SetGray(x, y, color.Gray{Y: uint8((x ^ y) * 8)})
+		}
+	}
+	_, err := hashImage(img)
+	return err
 }
 
 // CompareFaces membandingkan dua face descriptor dan return similarity score
+// Descriptor boleh terenkripsi atau plaintext
 // Score: 0.0 (completely different) - 1.0 (identical)
-func (fs *FaceService) CompareFaces(descriptor1JSON, descriptor2JSON string) (float64, error) {
+func (fs *FaceService) CompareFaces(ctx context.Context, descriptor1JSON, descriptor2JSON string) (_ float64, err error) {
+	_, span := tracing.Start(ctx, "face.CompareFaces")
+	defer func() { tracing.End(span, err) }()
+
+	descriptor1JSON, err = fs.envelope.OpenString(descriptor1JSON)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decrypt descriptor 1: %w", err)
+	}
+	descriptor2JSON, err = fs.envelope.OpenString(descriptor2JSON)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decrypt descriptor 2: %w", err)
+	}
+
 	// Parse descriptor 1
 	var desc1 FaceDescriptor
 	if err := json.Unmarshal([]byte(descriptor1JSON), &desc1); err != nil {
@@ -111,26 +217,31 @@ func (fs *FaceService) CompareFaces(descriptor1JSON, descriptor2JSON string) (fl
 
 	// Ensure similarity dalam range [0, 1]
 	similarity = math.Max(0, math.Min(1, similarity))
+	span.SetAttributes(attribute.Float64("face.similarity", similarity))
 
 	return similarity, nil
 }
 
 // VerifyFace verifies if uploaded face matches reference descriptor
-func (fs *FaceService) VerifyFace(uploadedImagePath, referenceDescriptorJSON string, threshold float64) (bool, float64, error) {
+func (fs *FaceService) VerifyFace(ctx context.Context, uploadedImagePath, referenceDescriptorJSON string, threshold float64) (_ bool, _ float64, err error) {
+	ctx, span := tracing.Start(ctx, "face.VerifyFace", attribute.Float64("face.threshold", threshold))
+	defer func() { tracing.End(span, err) }()
+
 	// Extract descriptor dari uploaded image
-	uploadedDescriptor, err := fs.ExtractFaceDescriptor(uploadedImagePath)
+	uploadedDescriptor, err := fs.extractDescriptor(ctx, uploadedImagePath)
 	if err != nil {
 		return false, 0, fmt.Errorf("failed to extract face descriptor: %w", err)
 	}
 
 	// Compare dengan reference descriptor
-	similarity, err := fs.CompareFaces(uploadedDescriptor, referenceDescriptorJSON)
+	similarity, err := fs.CompareFaces(ctx, uploadedDescriptor, referenceDescriptorJSON)
 	if err != nil {
 		return false, 0, fmt.Errorf("failed to compare faces: %w", err)
 	}
 
 	// Check if similarity meets threshold
 	isMatch := similarity >= threshold
+	span.SetAttributes(attribute.Bool("face.match", isMatch))
 
 	return isMatch, similarity, nil
 }
@@ -0,0 +1,282 @@
+package services
+
+import (
+	"attendance-system/internal/models"
+	"attendance-system/internal/storage"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"mime"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nfnt/resize"
+	"gorm.io/gorm"
+)
+
+// Image kinds, dipakai sebagai segment URL /api/images/:kind/:id
+const (
+	ImageKindEmployee        = "employees"         // Foto referensi karyawan
+	ImageKindAttendance      = "attendances"       // Selfie check-in
+	ImageKindLeaveAttachment = "leave-attachments" // Lampiran cuti (gambar/PDF)
+)
+
+// Image sizes, kosong = file asli
+const (
+	ImageSizeThumb  = "thumb"
+	ImageSizeMedium = "medium"
+)
+
+// imageSizePixels is the longest side of each thumbnail size
+var imageSizePixels = map[string]uint{
+	ImageSizeThumb:  160,
+	ImageSizeMedium: 480,
+}
+
+// ErrImageNotFound is returned when the record has no file
+var ErrImageNotFound = errors.New("image not found")
+
+// ErrImageForbidden is returned when the viewer may not see the image
+var ErrImageForbidden = errors.New("not allowed to view this image")
+
+// ErrInvalidImageURL is returned for tampered or expired signed URLs
+var ErrInvalidImageURL = errors.New("invalid or expired image URL")
+
+// ImageContent is a decrypted (and optionally resized) image ready to send
+// RedirectURL di-set kalau file bisa diunduh langsung dari storage (presigned URL)
+type ImageContent struct {
+	Data        []byte
+	ContentType string
+	RedirectURL string
+}
+
+// ImageService signs image URLs and serves protected images
+// URL ditandatangani HMAC untuk viewer tertentu, izin dicek lagi saat gambar diambil
+type ImageService struct {
+	db          *gorm.DB
+	orgService  *OrgService
+	faceService *FaceService
+	storage     storage.Storage
+	key         []byte
+	mu          sync.RWMutex
+	ttl         time.Duration // Bisa diganti saat config reload
+}
+
+// NewImageService creates a new ImageService instance
+// Key HMAC diturunkan dari secret, jadi URL gambar tidak bisa dipakai sebagai access token
+func NewImageService(db *gorm.DB, orgService *OrgService, faceService *FaceService, store storage.Storage, secret []byte, ttl time.Duration) *ImageService {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("image-url-v1"))
+	return &ImageService{
+		db:          db,
+		orgService:  orgService,
+		faceService: faceService,
+		storage:     store,
+		key:         mac.Sum(nil),
+		ttl:         ttl,
+	}
+}
+
+// TTL returns how long signed URLs stay valid
+func (s *ImageService) TTL() time.Duration {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ttl
+}
+
+// SetTTL replaces the signed URL lifetime (config reload)
+func (s *ImageService) SetTTL(ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ttl = ttl
+}
+
+// IsValidImageSize checks the size query param
+func IsValidImageSize(size string) bool {
+	_, ok := imageSizePixels[size]
+	return ok || size == ""
+}
+
+// URL returns a signed URL for the viewer, kosong kalau viewer belum login
+func (s *ImageService) URL(kind string, id, viewerID uint, size string) string {
+	if viewerID == 0 || id == 0 {
+		return ""
+	}
+
+	expires := time.Now().Add(s.TTL()).Unix()
+	query := url.Values{}
+	if size != "" {
+		query.Set("size", size)
+	}
+	query.Set("viewer", strconv.FormatUint(uint64(viewerID), 10))
+	query.Set("exp", strconv.FormatInt(expires, 10))
+	query.Set("sig", s.sign(kind, id, size, viewerID, expires))
+	return fmt.Sprintf("/api/images/%s/%d?%s", kind, id, query.Encode())
+}
+
+// VerifyURL checks signature and expiry and returns the viewer the URL was issued to
+func (s *ImageService) VerifyURL(kind string, id uint, size, viewer, exp, sig string) (uint, error) {
+	viewerID, err := strconv.ParseUint(viewer, 10, 32)
+	if err != nil || viewerID == 0 {
+		return 0, ErrInvalidImageURL
+	}
+	expires, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return 0, ErrInvalidImageURL
+	}
+	expected := s.sign(kind, id, size, uint(viewerID), expires)
+	if !hmac.Equal([]byte(expected), []byte(sig)) {
+		return 0, ErrInvalidImageURL
+	}
+	return uint(viewerID), nil
+}
+
+// SignUser fills photo URLs of an employee response
+func (s *ImageService) SignUser(response *models.UserResponse, user *models.User, viewerID uint) {
+	if user.FaceImagePath == "" {
+		return
+	}
+	response.FaceImageURL = s.URL(ImageKindEmployee, user.ID, viewerID, "")
+	response.FaceThumbnailURL = s.URL(ImageKindEmployee, user.ID, viewerID, ImageSizeThumb)
+}
+
+// SignAttendance fills selfie URLs of an attendance response
+func (s *ImageService) SignAttendance(response *models.AttendanceResponse, attendance *models.Attendance, viewerID uint) {
+	if attendance.FaceImagePath == "" {
+		return
+	}
+	response.FaceImageURL = s.URL(ImageKindAttendance, attendance.ID, viewerID, "")
+	response.FaceThumbnailURL = s.URL(ImageKindAttendance, attendance.ID, viewerID, ImageSizeThumb)
+}
+
+// SignLeave fills the attachment URL of a leave request response
+func (s *ImageService) SignLeave(response *models.LeaveRequestResponse, viewerID uint) {
+	if response.AttachmentPath == "" {
+		return
+	}
+	response.AttachmentURL = s.URL(ImageKindLeaveAttachment, response.ID, viewerID, "")
+}
+
+// Fetch loads, authorizes, decrypts and optionally resizes an image
+// Role viewer dibaca ulang dari database, jadi URL yang sudah terbit ikut tercabut kalau akses berubah
+func (s *ImageService) Fetch(ctx context.Context, kind string, id, viewerID uint, size string) (*ImageContent, error) {
+	ownerID, path, err := s.locate(kind, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if viewerID != ownerID {
+		var viewer models.User
+		if err := s.db.Select("id", "role", "deactivated_at").First(&viewer, viewerID).Error; err != nil || viewer.DeactivatedAt != nil {
+			return nil, ErrImageForbidden
+		}
+		allowed, err := s.orgService.CanManageEmployee(viewer.ID, viewer.Role, ownerID)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, ErrImageForbidden
+		}
+	}
+
+	// Lampiran cuti tidak dienkripsi, jadi bisa diunduh langsung dari S3 tanpa lewat backend
+	if kind == ImageKindLeaveAttachment && size == "" {
+		redirect, err := s.storage.SignedURL(ctx, path, s.TTL())
+		if err == nil {
+			return &ImageContent{RedirectURL: redirect}, nil
+		}
+		if !errors.Is(err, storage.ErrSignedURLNotSupported) {
+			return nil, err
+		}
+	}
+
+	data, err := s.faceService.ReadImage(ctx, path)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, ErrImageNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	content := &ImageContent{Data: data, ContentType: mime.TypeByExtension(strings.ToLower(filepath.Ext(path)))}
+	if content.ContentType == "" {
+		content.ContentType = "application/octet-stream"
+	}
+	if size != "" {
+		return thumbnail(content, imageSizePixels[size])
+	}
+	return content, nil
+}
+
+// locate returns the owner and stored path of an image
+func (s *ImageService) locate(kind string, id uint) (uint, string, error) {
+	var ownerID uint
+	var path string
+	var err error
+
+	switch kind {
+	case ImageKindEmployee:
+		var user models.User
+		err = s.db.Select("id", "face_image_path").First(&user, id).Error
+		ownerID, path = user.ID, user.FaceImagePath
+	case ImageKindAttendance:
+		var attendance models.Attendance
+		err = s.db.Select("id", "user_id", "face_image_path").First(&attendance, id).Error
+		ownerID, path = attendance.UserID, attendance.FaceImagePath
+	case ImageKindLeaveAttachment:
+		var request models.LeaveRequest
+		err = s.db.Select("id", "user_id", "attachment_path").First(&request, id).Error
+		ownerID, path = request.UserID, request.AttachmentPath
+	default:
+		return 0, "", ErrImageNotFound
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && path == "") {
+		return 0, "", ErrImageNotFound
+	}
+	return ownerID, path, err
+}
+
+// sign returns base64url HMAC of the URL parameters
+func (s *ImageService) sign(kind string, id uint, size string, viewerID uint, expires int64) string {
+	mac := hmac.New(sha256.New, s.key)
+	fmt.Fprintf(mac, "%s|%d|%s|%d|%d", kind, id, size, viewerID, expires)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// thumbnail scales an image so its longest side is at most maxSide, hasilnya JPEG
+func thumbnail(content *ImageContent, maxSide uint) (*ImageContent, error) {
+	if !strings.HasPrefix(content.ContentType, "image/") {
+		return nil, fmt.Errorf("%w: thumbnails are only available for images", ErrImageNotFound)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(content.Data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	bounds := img.Bounds()
+	if uint(bounds.Dx()) > maxSide || uint(bounds.Dy()) > maxSide {
+		if bounds.Dx() >= bounds.Dy() {
+			img = resize.Resize(maxSide, 0, img, resize.Lanczos3)
+		} else {
+			img = resize.Resize(0, maxSide, img, resize.Lanczos3)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+	return &ImageContent{Data: buf.Bytes(), ContentType: "image/jpeg"}, nil
+}
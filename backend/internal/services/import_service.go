@@ -0,0 +1,382 @@
+package services
+
+import (
+	"archive/zip"
+	"attendance-system/internal/models"
+	"attendance-system/internal/utils"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/mail"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// maxImportPhotoSize limits a single photo extracted from the ZIP (proteksi zip bomb)
+const maxImportPhotoSize = 10 << 20
+
+// Import row status constants
+const (
+	ImportStatusValid   = "valid" // Lolos validasi (dry run)
+	ImportStatusCreated = "created"
+	ImportStatusSkipped = "skipped"
+	ImportStatusFailed  = "failed"
+)
+
+// ImportRow is a single employee row from the CSV file
+type ImportRow struct {
+	Line       int
+	Name       string
+	Email      string
+	Phone      string
+	Department string // Department code atau name
+	Photo      string // Nama file foto di dalam ZIP
+	Consent    bool   // Karyawan sudah memberi consent biometrik (kolom consent: yes/true/1)
+}
+
+// ImportRowResult is the outcome of importing one row
+type ImportRowResult struct {
+	Line    int    `json:"line"`
+	Email   string `json:"email"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+	UserID  uint   `json:"user_id,omitempty"`
+}
+
+// ImportReport summarizes an import run
+type ImportReport struct {
+	DryRun  bool              `json:"dry_run"`
+	Total   int               `json:"total"`
+	Valid   int               `json:"valid"`
+	Created int               `json:"created"`
+	Skipped int               `json:"skipped"`
+	Failed  int               `json:"failed"`
+	Rows    []ImportRowResult `json:"rows"`
+}
+
+// ImportService handles bulk employee import from CSV plus ZIP of photos
+type ImportService struct {
+	db            *gorm.DB
+	faceService   *FaceService
+	policyVersion string // Versi kebijakan biometrik untuk consent yang dicatat
+	workers       int
+}
+
+// NewImportService creates a new ImportService instance
+func NewImportService(db *gorm.DB, faceService *FaceService, policyVersion string, workers int) *ImportService {
+	if workers < 1 {
+		workers = 1
+	}
+	return &ImportService{
+		db:            db,
+		faceService:   faceService,
+		policyVersion: policyVersion,
+		workers:       workers,
+	}
+}
+
+// ParseCSV reads employee rows from CSV
+// Header wajib: name, email, photo, consent; optional: phone, department
+func ParseCSV(r io.Reader) ([]ImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if name == "photo_filename" {
+			name = "photo"
+		}
+		columns[name] = i
+	}
+	for _, required := range []string{"name", "email", "photo", "consent"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV header is missing required column %q", required)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var rows []ImportRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, ImportRow{
+			Line:       line,
+			Name:       field(record, "name"),
+			Email:      strings.ToLower(field(record, "email")),
+			Phone:      field(record, "phone"),
+			Department: field(record, "department"),
+			Photo:      field(record, "photo"),
+			Consent:    isConsentValue(field(record, "consent")),
+		})
+	}
+
+	return rows, nil
+}
+
+// isConsentValue reports whether a CSV consent cell means consent was given
+func isConsentValue(value string) bool {
+	switch strings.ToLower(value) {
+	case "yes", "y", "true", "1":
+		return true
+	}
+	return false
+}
+
+// importJob is a validated row ready to be created
+type importJob struct {
+	index        int
+	row          ImportRow
+	photo        *zip.File
+	departmentID uint
+}
+
+// Import validates every row first, then creates employees using a bounded worker pool
+// Jika dryRun true, hanya validasi yang dijalankan dan tidak ada data yang disimpan
+// recordedByID dicatat di consent setiap karyawan, nil kalau import dari CLI
+func (s *ImportService) Import(ctx context.Context, rows []ImportRow, photos *zip.Reader, dryRun bool, recordedByID *uint) (*ImportReport, error) {
+	report := &ImportReport{
+		DryRun: dryRun,
+		Total:  len(rows),
+		Rows:   make([]ImportRowResult, len(rows)),
+	}
+
+	jobs, err := s.validate(rows, photos, report)
+	if err != nil {
+		return nil, err
+	}
+
+	if !dryRun {
+		s.process(ctx, jobs, report, recordedByID)
+	}
+
+	for _, result := range report.Rows {
+		switch result.Status {
+		case ImportStatusValid:
+			report.Valid++
+		case ImportStatusCreated:
+			report.Created++
+		case ImportStatusSkipped:
+			report.Skipped++
+		case ImportStatusFailed:
+			report.Failed++
+		}
+	}
+
+	return report, nil
+}
+
+// validate checks all rows and returns jobs for rows that passed
+func (s *ImportService) validate(rows []ImportRow, photos *zip.Reader, report *ImportReport) ([]importJob, error) {
+	// Index foto berdasarkan nama file (case-insensitive, tanpa folder)
+	photoIndex := make(map[string]*zip.File)
+	if photos != nil {
+		for _, f := range photos.File {
+			if f.FileInfo().IsDir() {
+				continue
+			}
+			photoIndex[strings.ToLower(path.Base(f.Name))] = f
+		}
+	}
+
+	// Load existing emails dan departments sekali saja
+	emails := make([]string, 0, len(rows))
+	for _, row := range rows {
+		emails = append(emails, row.Email)
+	}
+	existing := make(map[string]bool)
+	if len(emails) > 0 {
+		var found []string
+		if err := s.db.Model(&models.User{}).Where("LOWER(email) IN ?", emails).Pluck("email", &found).Error; err != nil {
+			return nil, fmt.Errorf("failed to check existing employees: %w", err)
+		}
+		for _, email := range found {
+			existing[strings.ToLower(email)] = true
+		}
+	}
+
+	var departments []models.Department
+	if err := s.db.Find(&departments).Error; err != nil {
+		return nil, fmt.Errorf("failed to load departments: %w", err)
+	}
+	departmentIndex := make(map[string]uint)
+	for _, d := range departments {
+		departmentIndex[strings.ToLower(d.Name)] = d.ID
+		departmentIndex[strings.ToLower(d.Code)] = d.ID
+	}
+
+	seen := make(map[string]int)
+	var jobs []importJob
+
+	for i, row := range rows {
+		result := ImportRowResult{Line: row.Line, Email: row.Email, Status: ImportStatusFailed}
+		fail := func(message string) {
+			result.Message = message
+			report.Rows[i] = result
+		}
+
+		if row.Name == "" || row.Email == "" || row.Photo == "" {
+			fail("name, email and photo are required")
+			continue
+		}
+		if !row.Consent {
+			fail("biometric consent is required")
+			continue
+		}
+		if _, err := mail.ParseAddress(row.Email); err != nil {
+			fail("invalid email address")
+			continue
+		}
+		if line, dup := seen[row.Email]; dup {
+			fail(fmt.Sprintf("duplicate email, already used on line %d", line))
+			continue
+		}
+		seen[row.Email] = row.Line
+
+		if existing[row.Email] {
+			result.Status = ImportStatusSkipped
+			fail("employee with this email already exists")
+			continue
+		}
+
+		var departmentID uint
+		if row.Department != "" {
+			id, ok := departmentIndex[strings.ToLower(row.Department)]
+			if !ok {
+				fail(fmt.Sprintf("department %q not found", row.Department))
+				continue
+			}
+			departmentID = id
+		}
+
+		if !utils.IsAllowedImageExt(filepath.Ext(row.Photo)) {
+			fail("photo must be a JPG, JPEG or PNG file")
+			continue
+		}
+		photo, ok := photoIndex[strings.ToLower(path.Base(row.Photo))]
+		if !ok {
+			fail(fmt.Sprintf("photo %q not found in archive", row.Photo))
+			continue
+		}
+		if photo.UncompressedSize64 > maxImportPhotoSize {
+			fail("photo exceeds 10MB limit")
+			continue
+		}
+
+		result.Status = ImportStatusValid
+		report.Rows[i] = result
+		jobs = append(jobs, importJob{index: i, row: row, photo: photo, departmentID: departmentID})
+	}
+
+	return jobs, nil
+}
+
+// process creates employees for validated jobs dengan worker pool
+func (s *ImportService) process(ctx context.Context, jobs []importJob, report *ImportReport, recordedByID *uint) {
+	queue := make(chan importJob)
+	var wg sync.WaitGroup
+	// Row yang sudah diambil worker tetap diselesaikan walau import dibatalkan
+	rowCtx := context.WithoutCancel(ctx)
+
+	for w := 0; w < s.workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				// Setiap worker menulis ke index berbeda, jadi aman tanpa lock
+				report.Rows[job.index] = s.createEmployee(rowCtx, job, recordedByID)
+			}
+		}()
+	}
+
+	for _, job := range jobs {
+		if ctx.Err() != nil {
+			report.Rows[job.index].Status = ImportStatusFailed
+			report.Rows[job.index].Message = "import cancelled"
+			continue
+		}
+		queue <- job
+	}
+	close(queue)
+	wg.Wait()
+}
+
+// createEmployee extracts the photo, computes the descriptor and saves the employee
+func (s *ImportService) createEmployee(ctx context.Context, job importJob, recordedByID *uint) ImportRowResult {
+	result := ImportRowResult{Line: job.row.Line, Email: job.row.Email, Status: ImportStatusFailed}
+
+	src, err := job.photo.Open()
+	if err != nil {
+		result.Message = "failed to read photo from archive"
+		return result
+	}
+	imagePath, err := s.faceService.SaveImage(ctx, io.LimitReader(src, maxImportPhotoSize), job.row.Photo)
+	src.Close()
+	if err != nil {
+		result.Message = "failed to save photo"
+		return result
+	}
+
+	faceDescriptor, err := s.faceService.ExtractFaceDescriptor(ctx, imagePath)
+	if err != nil {
+		s.faceService.DeleteImage(ctx, imagePath)
+		result.Message = "failed to process face image"
+		return result
+	}
+
+	user := models.User{
+		Name:           job.row.Name,
+		Email:          job.row.Email,
+		Phone:          job.row.Phone,
+		FaceImagePath:  imagePath,
+		FaceDescriptor: faceDescriptor,
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		if _, err := NewConsentService(tx, nil, s.policyVersion).Give(user.ID, models.ConsentMethodImport, recordedByID); err != nil {
+			return err
+		}
+		if job.departmentID != 0 {
+			_, err := NewOrgService(tx).AssignEmployee(user.ID, job.departmentID, nil, nil, time.Now())
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		s.faceService.DeleteImage(ctx, imagePath)
+		result.Message = fmt.Sprintf("failed to create employee: %v", err)
+		return result
+	}
+
+	result.Status = ImportStatusCreated
+	result.UserID = user.ID
+	return result
+}
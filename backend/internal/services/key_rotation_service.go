@@ -0,0 +1,142 @@
+package services
+
+import (
+	"attendance-system/internal/models"
+	"attendance-system/internal/storage"
+	"attendance-system/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// keyRotationBatchSize is how many users are re-encrypted per query
+const keyRotationBatchSize = 200
+
+// KeyRotationCount counts processed items of one kind
+type KeyRotationCount struct {
+	Checked int `json:"checked"`
+	Rotated int `json:"rotated"` // Di-encrypt ulang dengan key aktif (termasuk data plaintext lama)
+	Missing int `json:"missing,omitempty"`
+	Failed  int `json:"failed"`
+}
+
+// KeyRotationReport summarizes a key rotation run
+type KeyRotationReport struct {
+	ActiveKeyID string           `json:"active_key_id"`
+	DryRun      bool             `json:"dry_run"`
+	Descriptors KeyRotationCount `json:"descriptors"`
+	Files       KeyRotationCount `json:"files"`
+	Errors      []string         `json:"errors,omitempty"`
+}
+
+// KeyRotationService re-encrypts biometric data with the active master key
+type KeyRotationService struct {
+	db       *gorm.DB
+	envelope *utils.Envelope
+	storage  storage.Storage
+}
+
+// NewKeyRotationService creates a new KeyRotationService instance
+func NewKeyRotationService(db *gorm.DB, envelope *utils.Envelope, store storage.Storage) *KeyRotationService {
+	return &KeyRotationService{db: db, envelope: envelope, storage: store}
+}
+
+// Rotate re-encrypts every face descriptor and face/selfie image not yet sealed with the active key
+// Aman dijalankan ulang: data yang sudah memakai key aktif dilewati
+func (s *KeyRotationService) Rotate(ctx context.Context, dryRun bool) (*KeyRotationReport, error) {
+	report := &KeyRotationReport{ActiveKeyID: s.envelope.ActiveKeyID(), DryRun: dryRun}
+
+	if err := s.rotateDescriptors(ctx, report); err != nil {
+		return report, err
+	}
+
+	// Foto referensi karyawan dan selfie check-in
+	for _, table := range []interface{}{&models.User{}, &models.Attendance{}} {
+		var paths []string
+		if err := s.db.Model(table).Where("face_image_path <> ''").Distinct().Pluck("face_image_path", &paths).Error; err != nil {
+			return report, fmt.Errorf("failed to list images: %w", err)
+		}
+		for _, path := range paths {
+			if err := ctx.Err(); err != nil {
+				return report, err
+			}
+			s.rotateFile(ctx, path, report)
+		}
+	}
+
+	return report, nil
+}
+
+// Pending counts descriptors and images not sealed with the active key, tanpa mengubah data
+// Dipakai sebelum -prune: server yang belum di-reload sejak -generate masih men-seal data baru dengan key lama
+func (s *KeyRotationService) Pending(ctx context.Context) (int, error) {
+	report, err := s.Rotate(ctx, true)
+	if err != nil {
+		return 0, err
+	}
+	return report.Descriptors.Rotated + report.Descriptors.Failed + report.Files.Rotated + report.Files.Failed, nil
+}
+
+// rotateDescriptors re-encrypts users.face_descriptor in batches
+func (s *KeyRotationService) rotateDescriptors(ctx context.Context, report *KeyRotationReport) error {
+	var users []models.User
+	result := s.db.Select("id", "face_descriptor").Where("face_descriptor <> ''").
+		FindInBatches(&users, keyRotationBatchSize, func(tx *gorm.DB, _ int) error {
+			for i := range users {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				user := &users[i]
+				report.Descriptors.Checked++
+				if !s.envelope.NeedsRotationString(user.FaceDescriptor) {
+					continue
+				}
+
+				plaintext, err := s.envelope.OpenString(user.FaceDescriptor)
+				if err == nil && !report.DryRun {
+					var sealed string
+					if sealed, err = s.envelope.SealString(plaintext); err == nil {
+						// UpdateColumn supaya updated_at tidak berubah
+						err = s.db.Model(&models.User{}).Where("id = ?", user.ID).UpdateColumn("face_descriptor", sealed).Error
+					}
+				}
+				if err != nil {
+					report.Descriptors.Failed++
+					report.Errors = append(report.Errors, fmt.Sprintf("user %d descriptor: %v", user.ID, err))
+					continue
+				}
+				report.Descriptors.Rotated++
+			}
+			return nil
+		})
+	return result.Error
+}
+
+// rotateFile re-encrypts one image file in place
+func (s *KeyRotationService) rotateFile(ctx context.Context, path string, report *KeyRotationReport) {
+	report.Files.Checked++
+
+	data, err := storage.ReadAll(ctx, s.storage, path)
+	if errors.Is(err, storage.ErrNotFound) {
+		report.Files.Missing++
+		return
+	}
+	if err == nil && !s.envelope.NeedsRotation(data) {
+		return
+	}
+
+	if err == nil {
+		var plaintext []byte
+		if plaintext, err = s.envelope.Open(data); err == nil && !report.DryRun {
+			err = utils.WriteFile(ctx, s.storage, path, plaintext, s.envelope)
+		}
+	}
+	if err != nil {
+		report.Files.Failed++
+		report.Errors = append(report.Errors, fmt.Sprintf("file %s: %v", path, err))
+		return
+	}
+	report.Files.Rotated++
+}
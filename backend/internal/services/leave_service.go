@@ -0,0 +1,405 @@
+package services
+
+import (
+	"attendance-system/internal/logging"
+	"attendance-system/internal/models"
+	"attendance-system/internal/utils"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Leave workflow errors, handler memetakan ke status HTTP
+var (
+	ErrInvalidLeave        = errors.New("invalid leave request")
+	ErrLeaveOverlap        = errors.New("leave request overlaps an existing request")
+	ErrInsufficientBalance = errors.New("insufficient leave balance")
+	ErrLeaveForbidden      = errors.New("not allowed to act on this leave request")
+	ErrLeaveNotPending     = errors.New("leave request is no longer pending")
+)
+
+// LeaveSubmission is the input for a new leave request
+type LeaveSubmission struct {
+	UserID         uint
+	LeaveTypeID    uint
+	StartDate      string // YYYY-MM-DD
+	EndDate        string
+	HalfDay        string // "", am, pm
+	Reason         string
+	AttachmentPath string
+}
+
+// LeaveService handles leave requests, approvals and balances
+type LeaveService struct {
+	db             *gorm.DB
+	orgService     *OrgService
+	summaryService *DailySummaryService
+}
+
+// NewLeaveService creates a new LeaveService instance
+func NewLeaveService(db *gorm.DB, orgService *OrgService, summaryService *DailySummaryService) *LeaveService {
+	return &LeaveService{
+		db:             db,
+		orgService:     orgService,
+		summaryService: summaryService,
+	}
+}
+
+// CanManage checks if the actor may approve or view leave of the employee
+func (s *LeaveService) CanManage(actorID uint, actorRole string, userID uint) (bool, error) {
+	return s.orgService.CanManageEmployee(actorID, actorRole, userID)
+}
+
+// Submit validates and creates a pending leave request, days langsung di-hold di balance
+func (s *LeaveService) Submit(sub LeaveSubmission) (*models.LeaveRequest, error) {
+	if sub.HalfDay != "" && sub.HalfDay != models.HalfDayMorning && sub.HalfDay != models.HalfDayAfternoon {
+		return nil, fmt.Errorf("%w: half_day must be am or pm", ErrInvalidLeave)
+	}
+	if sub.EndDate == "" {
+		sub.EndDate = sub.StartDate
+	}
+	if sub.HalfDay != "" && sub.StartDate != sub.EndDate {
+		return nil, fmt.Errorf("%w: half-day leave must be a single date", ErrInvalidLeave)
+	}
+	start, err := time.Parse(utils.DateLayout, sub.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: start_date must be YYYY-MM-DD", ErrInvalidLeave)
+	}
+	end, err := time.Parse(utils.DateLayout, sub.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: end_date must be YYYY-MM-DD", ErrInvalidLeave)
+	}
+	if start.Year() != end.Year() {
+		return nil, fmt.Errorf("%w: leave must not span calendar years, submit one request per year", ErrInvalidLeave)
+	}
+
+	var leaveType models.LeaveType
+	if err := s.db.First(&leaveType, sub.LeaveTypeID).Error; err != nil {
+		return nil, fmt.Errorf("%w: leave type not found", ErrInvalidLeave)
+	}
+	if leaveType.RequiresAttachment && sub.AttachmentPath == "" {
+		return nil, fmt.Errorf("%w: %s requires an attachment", ErrInvalidLeave, leaveType.Name)
+	}
+
+	// Hanya hari kerja yang memotong balance
+	dates, err := s.summaryService.WorkDates(sub.UserID, sub.StartDate, sub.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidLeave, err)
+	}
+	if len(dates) == 0 {
+		return nil, fmt.Errorf("%w: no working days in the requested range", ErrInvalidLeave)
+	}
+	days := float64(len(dates))
+	if sub.HalfDay != "" {
+		days = 0.5
+	}
+
+	request := models.LeaveRequest{
+		UserID:         sub.UserID,
+		LeaveTypeID:    sub.LeaveTypeID,
+		StartDate:      sub.StartDate,
+		EndDate:        sub.EndDate,
+		HalfDay:        sub.HalfDay,
+		Days:           days,
+		Reason:         strings.TrimSpace(sub.Reason),
+		AttachmentPath: sub.AttachmentPath,
+		Status:         models.LeaveStatusPending,
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var overlap int64
+		query := tx.Model(&models.LeaveRequest{}).
+			Where("user_id = ? AND status IN ?", sub.UserID, []string{models.LeaveStatusPending, models.LeaveStatusApproved}).
+			Where("start_date <= ? AND end_date >= ?", sub.EndDate, sub.StartDate)
+		// Setengah hari pagi dan sore di tanggal yang sama tidak bentrok (am + pm = satu hari penuh)
+		if opposite := oppositeHalfDay(sub.HalfDay); opposite != "" {
+			query = query.Where("NOT (start_date = ? AND end_date = ? AND COALESCE(half_day, '') = ?)", sub.StartDate, sub.EndDate, opposite)
+		}
+		if err := query.Count(&overlap).Error; err != nil {
+			return err
+		}
+		if overlap > 0 {
+			return ErrLeaveOverlap
+		}
+
+		balance, err := s.ensureBalance(tx, sub.UserID, &leaveType, start.Year())
+		if err != nil {
+			return err
+		}
+
+		// Update atomic dengan syarat saldo cukup, aman untuk request paralel
+		result := tx.Model(&models.LeaveBalance{}).
+			Where("id = ? AND accrued + carried_over - used - pending >= ?", balance.ID, days).
+			Update("pending", gorm.Expr("pending + ?", days))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w: %.1f days requested, %.1f available", ErrInsufficientBalance, days, balance.Available())
+		}
+
+		return tx.Create(&request).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &request, nil
+}
+
+// Decide approves or rejects a pending request
+func (s *LeaveService) Decide(requestID, approverID uint, approverRole string, approve bool, note string) (*models.LeaveRequest, error) {
+	var request models.LeaveRequest
+	if err := s.db.First(&request, requestID).Error; err != nil {
+		return nil, err
+	}
+	if request.UserID == approverID {
+		return nil, fmt.Errorf("%w: cannot decide your own request", ErrLeaveForbidden)
+	}
+	allowed, err := s.CanManage(approverID, approverRole, request.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrLeaveForbidden
+	}
+
+	status := models.LeaveStatusRejected
+	if approve {
+		status = models.LeaveStatusApproved
+	}
+	now := time.Now().UTC()
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// Guard status di WHERE supaya dua approver tidak memproses bersamaan
+		result := tx.Model(&models.LeaveRequest{}).
+			Where("id = ? AND status = ?", request.ID, models.LeaveStatusPending).
+			Updates(map[string]interface{}{
+				"status":        status,
+				"approver_id":   approverID,
+				"decision_note": strings.TrimSpace(note),
+				"decided_at":    now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrLeaveNotPending
+		}
+
+		updates := map[string]interface{}{"pending": gorm.Expr("pending - ?", request.Days)}
+		if approve {
+			updates["used"] = gorm.Expr("used + ?", request.Days)
+		}
+		return s.balanceQuery(tx, &request).Updates(updates).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if approve {
+		s.recomputeClosedDays(&request)
+	}
+
+	return s.Get(request.ID)
+}
+
+// Cancel withdraws a pending request or an approved one that has not started yet
+// Karyawan untuk request sendiri, HR/admin untuk semua (termasuk yang sudah berjalan)
+func (s *LeaveService) Cancel(requestID, actorID uint, actorRole string) (*models.LeaveRequest, error) {
+	var request models.LeaveRequest
+	if err := s.db.First(&request, requestID).Error; err != nil {
+		return nil, err
+	}
+
+	privileged := actorRole == models.RoleHR || actorRole == models.RoleAdmin
+	if request.UserID != actorID && !privileged {
+		return nil, ErrLeaveForbidden
+	}
+	if request.Status != models.LeaveStatusPending && request.Status != models.LeaveStatusApproved {
+		return nil, ErrLeaveNotPending
+	}
+	today := time.Now().UTC().Format(utils.DateLayout)
+	if request.Status == models.LeaveStatusApproved && !privileged && request.StartDate <= today {
+		return nil, fmt.Errorf("%w: leave has already started, contact HR", ErrLeaveForbidden)
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.LeaveRequest{}).
+			Where("id = ? AND status = ?", request.ID, request.Status).
+			Update("status", models.LeaveStatusCancelled)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrLeaveNotPending
+		}
+
+		column := "pending"
+		if request.Status == models.LeaveStatusApproved {
+			column = "used"
+		}
+		return s.balanceQuery(tx, &request).Update(column, gorm.Expr(column+" - ?", request.Days)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Status == models.LeaveStatusApproved {
+		s.recomputeClosedDays(&request)
+	}
+
+	return s.Get(request.ID)
+}
+
+// Get loads a leave request with relations
+func (s *LeaveService) Get(id uint) (*models.LeaveRequest, error) {
+	var request models.LeaveRequest
+	if err := s.db.Preload("User").Preload("LeaveType").Preload("Approver").First(&request, id).Error; err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// Balances returns (and lazily accrues) all leave balances of an employee for a year
+func (s *LeaveService) Balances(userID uint, year int) ([]models.LeaveBalance, error) {
+	var types []models.LeaveType
+	if err := s.db.Order("name ASC").Find(&types).Error; err != nil {
+		return nil, fmt.Errorf("failed to load leave types: %w", err)
+	}
+
+	balances := make([]models.LeaveBalance, 0, len(types))
+	for i := range types {
+		balance, err := s.ensureBalance(s.db, userID, &types[i], year)
+		if err != nil {
+			return nil, err
+		}
+		balance.LeaveType = types[i]
+		balances = append(balances, *balance)
+	}
+	return balances, nil
+}
+
+// AccrueYear creates balances for every active employee and leave type in the year
+// Idempotent: balance yang sudah ada tidak diubah
+func (s *LeaveService) AccrueYear(year int) (int, error) {
+	var types []models.LeaveType
+	if err := s.db.Find(&types).Error; err != nil {
+		return 0, fmt.Errorf("failed to load leave types: %w", err)
+	}
+
+	var userIDs []uint
+	if err := s.db.Model(&models.User{}).Where("deactivated_at IS NULL").Pluck("id", &userIDs).Error; err != nil {
+		return 0, fmt.Errorf("failed to load employees: %w", err)
+	}
+
+	count := 0
+	for _, userID := range userIDs {
+		for i := range types {
+			if _, err := s.ensureBalance(s.db, userID, &types[i], year); err != nil {
+				return count, err
+			}
+			count++
+		}
+	}
+	return count, nil
+}
+
+// ensureBalance returns the balance row, membuatnya dengan accrual tahunan kalau belum ada
+func (s *LeaveService) ensureBalance(tx *gorm.DB, userID uint, leaveType *models.LeaveType, year int) (*models.LeaveBalance, error) {
+	var balance models.LeaveBalance
+	err := tx.Where("user_id = ? AND leave_type_id = ? AND year = ?", userID, leaveType.ID, year).First(&balance).Error
+	if err == nil {
+		return &balance, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to load leave balance: %w", err)
+	}
+
+	var user models.User
+	if err := tx.Select("id", "created_at").First(&user, userID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load employee: %w", err)
+	}
+
+	balance = models.LeaveBalance{
+		UserID:      userID,
+		LeaveTypeID: leaveType.ID,
+		Year:        year,
+		Accrued:     accrualForYear(leaveType.AnnualAllowance, user.CreatedAt, year),
+	}
+
+	// Carry over sisa tahun lalu, dibatasi MaxCarryOver
+	var previous models.LeaveBalance
+	err = tx.Where("user_id = ? AND leave_type_id = ? AND year = ?", userID, leaveType.ID, year-1).First(&previous).Error
+	if err == nil {
+		remaining := previous.Accrued + previous.CarriedOver - previous.Used
+		balance.CarriedOver = math.Max(0, math.Min(remaining, leaveType.MaxCarryOver))
+	}
+
+	// DoNothing kalau request lain sudah membuat row yang sama
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&balance).Error; err != nil {
+		return nil, fmt.Errorf("failed to create leave balance: %w", err)
+	}
+	if err := tx.Where("user_id = ? AND leave_type_id = ? AND year = ?", userID, leaveType.ID, year).First(&balance).Error; err != nil {
+		return nil, fmt.Errorf("failed to load leave balance: %w", err)
+	}
+	return &balance, nil
+}
+
+// balanceQuery targets the balance row a request was charged against
+func (s *LeaveService) balanceQuery(tx *gorm.DB, request *models.LeaveRequest) *gorm.DB {
+	start, _ := time.Parse(utils.DateLayout, request.StartDate)
+	return tx.Model(&models.LeaveBalance{}).
+		Where("user_id = ? AND leave_type_id = ? AND year = ?", request.UserID, request.LeaveTypeID, start.Year())
+}
+
+// recomputeClosedDays refreshes daily rows for leave dates that have already closed
+// Hari yang belum selesai akan diproses daily summary job seperti biasa
+func (s *LeaveService) recomputeClosedDays(request *models.LeaveRequest) {
+	end := request.EndDate
+	if closed := s.summaryService.ClosedUntil(time.Now()); closed < end {
+		end = closed
+	}
+	if end < request.StartDate {
+		return
+	}
+
+	start, _ := time.Parse(utils.DateLayout, request.StartDate)
+	last, _ := time.Parse(utils.DateLayout, end)
+	for d := start; !d.After(last); d = d.AddDate(0, 0, 1) {
+		if _, err := s.summaryService.MaterializeDate(d.Format(utils.DateLayout), []uint{request.UserID}, ""); err != nil {
+			slog.Error("Error recomputing daily attendance", "leave_request_id", request.ID, logging.Err(err))
+			return
+		}
+	}
+}
+
+// oppositeHalfDay returns pm for am and am for pm, kosong untuk cuti full day
+func oppositeHalfDay(halfDay string) string {
+	switch halfDay {
+	case models.HalfDayMorning:
+		return models.HalfDayAfternoon
+	case models.HalfDayAfternoon:
+		return models.HalfDayMorning
+	}
+	return ""
+}
+
+// accrualForYear prorates the annual allowance for employees who joined during the year
+// Dibulatkan ke bawah per setengah hari
+func accrualForYear(allowance float64, joinedAt time.Time, year int) float64 {
+	if joinedAt.Year() > year {
+		return 0
+	}
+	if joinedAt.Year() < year {
+		return allowance
+	}
+	months := float64(12 - int(joinedAt.Month()) + 1)
+	return math.Floor(allowance*months/12*2) / 2
+}
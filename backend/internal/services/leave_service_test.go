@@ -0,0 +1,166 @@
+package services
+
+import (
+	"attendance-system/internal/models"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// newTestLeaveService builds a LeaveService with one leave type of the given allowance
+func newTestLeaveService(t *testing.T, db *gorm.DB, allowance float64) (*LeaveService, *models.LeaveType) {
+	t.Helper()
+	leaveType := &models.LeaveType{Code: "ANNUAL", Name: "Cuti Tahunan", AnnualAllowance: allowance, MaxCarryOver: 5}
+	if err := db.Create(leaveType).Error; err != nil {
+		t.Fatal(err)
+	}
+	return NewLeaveService(db, NewOrgService(db), newTestSummaryService(t, db)), leaveType
+}
+
+// testBalance reads the 2024 balance row
+func testBalance(t *testing.T, db *gorm.DB, userID, leaveTypeID uint) models.LeaveBalance {
+	t.Helper()
+	var balance models.LeaveBalance
+	if err := db.Where("user_id = ? AND leave_type_id = ? AND year = ?", userID, leaveTypeID, 2024).First(&balance).Error; err != nil {
+		t.Fatal(err)
+	}
+	return balance
+}
+
+func TestLeaveSubmitOverlap(t *testing.T) {
+	db := newTestDB(t)
+	service, leaveType := newTestLeaveService(t, db, 12)
+	user := createSummaryUser(t, db, "budi")
+
+	// Dijalankan berurutan, setiap pengajuan yang berhasil ikut dicek pengajuan berikutnya
+	steps := []struct {
+		name    string
+		start   string
+		end     string
+		halfDay string
+		wantErr error
+	}{
+		{"full day", "2024-03-05", "", "", nil},
+		{"same day again", "2024-03-05", "", "", ErrLeaveOverlap},
+		{"half day inside a full day", "2024-03-05", "", models.HalfDayAfternoon, ErrLeaveOverlap},
+		{"range covering the full day", "2024-03-04", "2024-03-06", "", ErrLeaveOverlap},
+		{"morning half", "2024-03-06", "", models.HalfDayMorning, nil},
+		{"afternoon half on the same day", "2024-03-06", "", models.HalfDayAfternoon, nil},
+		{"third half on the same day", "2024-03-06", "", models.HalfDayMorning, ErrLeaveOverlap},
+		{"afternoon half", "2024-03-07", "", models.HalfDayAfternoon, nil},
+		{"same half twice", "2024-03-07", "", models.HalfDayAfternoon, ErrLeaveOverlap},
+		{"full day over a half day", "2024-03-07", "", "", ErrLeaveOverlap},
+		{"half day across dates", "2024-03-07", "2024-03-08", models.HalfDayMorning, ErrInvalidLeave},
+	}
+	for _, step := range steps {
+		_, err := service.Submit(LeaveSubmission{
+			UserID: user.ID, LeaveTypeID: leaveType.ID, StartDate: step.start, EndDate: step.end, HalfDay: step.halfDay,
+		})
+		if !errors.Is(err, step.wantErr) {
+			t.Fatalf("%s: err %v, want %v", step.name, err, step.wantErr)
+		}
+	}
+
+	// 1 hari + 0.5 + 0.5 + 0.5
+	if balance := testBalance(t, db, user.ID, leaveType.ID); balance.Pending != 2.5 {
+		t.Fatalf("pending %.1f, want 2.5", balance.Pending)
+	}
+
+	// Cuti yang dibatalkan tidak lagi bentrok
+	var cancelled models.LeaveRequest
+	db.Where("user_id = ? AND start_date = ? AND half_day = ?", user.ID, "2024-03-07", models.HalfDayAfternoon).First(&cancelled)
+	if _, err := service.Cancel(cancelled.ID, user.ID, models.RoleEmployee); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Submit(LeaveSubmission{UserID: user.ID, LeaveTypeID: leaveType.ID, StartDate: "2024-03-07"}); err != nil {
+		t.Fatalf("full day after cancelling the half day: %v", err)
+	}
+}
+
+func TestLeaveBalanceDeduction(t *testing.T) {
+	db := newTestDB(t)
+	service, leaveType := newTestLeaveService(t, db, 3)
+	user := createSummaryUser(t, db, "budi")
+	hr := createTestUser(t, db, "hana", models.RoleHR)
+
+	// Lima pengajuan paralel untuk tanggal berbeda, saldo hanya cukup untuk tiga
+	dates := []string{"2024-03-04", "2024-03-05", "2024-03-06", "2024-03-07", "2024-03-08"}
+	errs := make([]error, len(dates))
+	var wg sync.WaitGroup
+	for i, date := range dates {
+		wg.Add(1)
+		go func(i int, date string) {
+			defer wg.Done()
+			_, errs[i] = service.Submit(LeaveSubmission{UserID: user.ID, LeaveTypeID: leaveType.ID, StartDate: date})
+		}(i, date)
+	}
+	wg.Wait()
+
+	accepted := 0
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			accepted++
+		case !errors.Is(err, ErrInsufficientBalance):
+			t.Fatalf("%s: err %v, want nil or %v", dates[i], err, ErrInsufficientBalance)
+		}
+	}
+	balance := testBalance(t, db, user.ID, leaveType.ID)
+	if accepted != 3 || balance.Pending != 3 || balance.Available() != 0 {
+		t.Fatalf("%d accepted, pending %.1f, available %.1f; want 3, 3 and 0", accepted, balance.Pending, balance.Available())
+	}
+
+	// Approve memindahkan pending ke used, cancel oleh HR mengembalikan saldo
+	var requests []models.LeaveRequest
+	db.Where("user_id = ?", user.ID).Order("start_date ASC").Find(&requests)
+	if _, err := service.Decide(requests[0].ID, hr.ID, models.RoleHR, true, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Decide(requests[1].ID, hr.ID, models.RoleHR, false, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Decide(requests[0].ID, hr.ID, models.RoleHR, true, ""); !errors.Is(err, ErrLeaveNotPending) {
+		t.Fatalf("second decision: err %v, want %v", err, ErrLeaveNotPending)
+	}
+	balance = testBalance(t, db, user.ID, leaveType.ID)
+	if balance.Used != 1 || balance.Pending != 1 || balance.Available() != 1 {
+		t.Fatalf("after decisions used %.1f pending %.1f available %.1f, want 1, 1 and 1",
+			balance.Used, balance.Pending, balance.Available())
+	}
+	if _, err := service.Cancel(requests[0].ID, hr.ID, models.RoleHR); err != nil {
+		t.Fatal(err)
+	}
+	if balance = testBalance(t, db, user.ID, leaveType.ID); balance.Used != 0 || balance.Available() != 2 {
+		t.Fatalf("after cancel used %.1f available %.1f, want 0 and 2", balance.Used, balance.Available())
+	}
+}
+
+func TestAccrualForYear(t *testing.T) {
+	tests := []struct {
+		name      string
+		allowance float64
+		joined    string
+		year      int
+		want      float64
+	}{
+		{"joined in an earlier year", 12, "2022-07-15", 2024, 12},
+		{"joined in january", 12, "2024-01-20", 2024, 12},
+		{"joined in july gets six months", 12, "2024-07-01", 2024, 6},
+		{"joined in december gets one month", 12, "2024-12-31", 2024, 1},
+		{"prorated down to half a day", 14, "2024-04-10", 2024, 10.5}, // 14 * 9/12 = 10.5
+		{"rounded down, not to nearest", 10, "2024-03-01", 2024, 8.0}, // 10 * 10/12 = 8.33
+		{"joined after the year", 12, "2025-01-01", 2024, 0},
+		{"no allowance", 0, "2020-01-01", 2024, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			joined, _ := time.Parse("2006-01-02", tt.joined)
+			if got := accrualForYear(tt.allowance, joined, tt.year); got != tt.want {
+				t.Fatalf("accrual %.2f, want %.2f", got, tt.want)
+			}
+		})
+	}
+}
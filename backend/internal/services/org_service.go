@@ -0,0 +1,269 @@
+package services
+
+import (
+	"attendance-system/internal/models"
+	"attendance-system/internal/repository"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidAssignment is returned when an assignment request is inconsistent
+var ErrInvalidAssignment = errors.New("invalid assignment")
+
+// OrgFilter narrows employees or attendance records by org unit
+// DepartmentID sudah termasuk semua sub-department (roll up)
+type OrgFilter struct {
+	DepartmentID uint
+	TeamID       uint
+	ManagerID    uint
+}
+
+// IsEmpty checks if no org filter is set
+func (f OrgFilter) IsEmpty() bool {
+	return f.DepartmentID == 0 && f.TeamID == 0 && f.ManagerID == 0
+}
+
+// OrgService handles department, team and assignment logic
+type OrgService struct {
+	db *gorm.DB
+}
+
+// NewOrgService creates a new OrgService instance
+func NewOrgService(db *gorm.DB) *OrgService {
+	return &OrgService{db: db}
+}
+
+// DescendantDepartmentIDs returns the given departments plus all departments below them
+func (s *OrgService) DescendantDepartmentIDs(rootIDs ...uint) ([]uint, error) {
+	var departments []models.Department
+	if err := s.db.Select("id", "parent_id").Find(&departments).Error; err != nil {
+		return nil, fmt.Errorf("failed to load departments: %w", err)
+	}
+
+	children := make(map[uint][]uint)
+	for _, d := range departments {
+		if d.ParentID != nil {
+			children[*d.ParentID] = append(children[*d.ParentID], d.ID)
+		}
+	}
+
+	// Breadth-first walk, visited map mencegah infinite loop kalau data cyclic
+	visited := make(map[uint]bool)
+	queue := append([]uint{}, rootIDs...)
+	result := make([]uint, 0, len(rootIDs))
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+		result = append(result, id)
+		queue = append(queue, children[id]...)
+	}
+
+	return result, nil
+}
+
+// ValidateParent ensures setting parentID on department does not create a cycle
+func (s *OrgService) ValidateParent(departmentID, parentID uint) error {
+	if departmentID == parentID {
+		return fmt.Errorf("%w: department cannot be its own parent", ErrInvalidAssignment)
+	}
+
+	descendants, err := s.DescendantDepartmentIDs(departmentID)
+	if err != nil {
+		return err
+	}
+	for _, id := range descendants {
+		if id == parentID {
+			return fmt.Errorf("%w: parent department is a descendant of this department", ErrInvalidAssignment)
+		}
+	}
+	return nil
+}
+
+// CurrentAssignment returns the assignment effective for user at the given time
+func (s *OrgService) CurrentAssignment(userID uint, at time.Time) (*models.EmployeeAssignment, error) {
+	var assignment models.EmployeeAssignment
+	err := s.db.Preload("Department").Preload("Team").Preload("Site").
+		Where("user_id = ? AND effective_from <= ?", userID, at).
+		Where("effective_to IS NULL OR effective_to > ?", at).
+		Order("effective_from DESC").
+		First(&assignment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &assignment, nil
+}
+
+// AssignEmployee closes the open assignment and starts a new one from effectiveFrom
+func (s *OrgService) AssignEmployee(userID, departmentID uint, teamID, siteID *uint, effectiveFrom time.Time) (*models.EmployeeAssignment, error) {
+	var assignment models.EmployeeAssignment
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var department models.Department
+		if err := tx.First(&department, departmentID).Error; err != nil {
+			return fmt.Errorf("%w: department not found", ErrInvalidAssignment)
+		}
+
+		// Team harus berada di department yang sama
+		if teamID != nil {
+			var team models.Team
+			if err := tx.First(&team, *teamID).Error; err != nil {
+				return fmt.Errorf("%w: team not found", ErrInvalidAssignment)
+			}
+			if team.DepartmentID != departmentID {
+				return fmt.Errorf("%w: team does not belong to department", ErrInvalidAssignment)
+			}
+		}
+
+		if siteID != nil {
+			if err := tx.First(&models.Site{}, *siteID).Error; err != nil {
+				return fmt.Errorf("%w: site not found", ErrInvalidAssignment)
+			}
+		}
+
+		// Assignment baru tidak boleh mundur sebelum assignment terakhir dimulai
+		var latest models.EmployeeAssignment
+		err := tx.Where("user_id = ?", userID).Order("effective_from DESC").First(&latest).Error
+		if err == nil {
+			if !effectiveFrom.After(latest.EffectiveFrom) {
+				return fmt.Errorf("%w: effective_from must be after %s", ErrInvalidAssignment, latest.EffectiveFrom.Format(time.RFC3339))
+			}
+			if latest.EffectiveTo == nil || latest.EffectiveTo.After(effectiveFrom) {
+				if err := tx.Model(&latest).Update("effective_to", effectiveFrom).Error; err != nil {
+					return err
+				}
+			}
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		assignment = models.EmployeeAssignment{
+			UserID:        userID,
+			DepartmentID:  departmentID,
+			TeamID:        teamID,
+			SiteID:        siteID,
+			EffectiveFrom: effectiveFrom,
+		}
+		if err := tx.Create(&assignment).Error; err != nil {
+			return err
+		}
+
+		return tx.Preload("Department").Preload("Team").Preload("Site").First(&assignment, assignment.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &assignment, nil
+}
+
+// UsersScope restricts a users query to employees currently matching the filter
+func (s *OrgService) UsersScope(filter OrgFilter) (func(*gorm.DB) *gorm.DB, error) {
+	conds, args, err := s.assignmentConditions(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	sql := "users.id IN (SELECT ea.user_id FROM employee_assignments ea LEFT JOIN teams t ON t.id = ea.team_id " +
+		"WHERE ea.effective_from <= ? AND (ea.effective_to IS NULL OR ea.effective_to > ?) AND " + conds + ")"
+	args = append([]interface{}{now, now}, args...)
+
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(sql, args...)
+	}, nil
+}
+
+// AttendancesScope restricts an attendances query to records whose employee
+// matched the filter at check-in time, so history stays with the old org unit
+func (s *OrgService) AttendancesScope(filter OrgFilter) (func(*gorm.DB) *gorm.DB, error) {
+	conds, args, err := s.assignmentConditions(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	sql := "EXISTS (SELECT 1 FROM employee_assignments ea LEFT JOIN teams t ON t.id = ea.team_id " +
+		"WHERE ea.user_id = attendances.user_id AND ea.effective_from <= attendances.check_in_time " +
+		"AND (ea.effective_to IS NULL OR ea.effective_to > attendances.check_in_time) AND " + conds + ")"
+
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(sql, args...)
+	}, nil
+}
+
+// CanManageEmployee checks if the actor may act on an employee's records
+// HR dan admin untuk semua karyawan, manager hanya untuk bawahannya
+func (s *OrgService) CanManageEmployee(actorID uint, actorRole string, userID uint) (bool, error) {
+	switch actorRole {
+	case models.RoleHR, models.RoleAdmin:
+		return true, nil
+	case models.RoleManager:
+		return s.IsManagerOf(actorID, userID, time.Now())
+	}
+	return false, nil
+}
+
+// IsManagerOf checks if managerID manages the employee (team atau department beserta sub-department)
+func (s *OrgService) IsManagerOf(managerID, userID uint, at time.Time) (bool, error) {
+	conds, args, err := s.assignmentConditions(OrgFilter{ManagerID: managerID})
+	if err != nil {
+		return false, err
+	}
+
+	var count int64
+	err = s.db.Table("employee_assignments ea").
+		Joins("LEFT JOIN teams t ON t.id = ea.team_id").
+		Where("ea.user_id = ? AND ea.effective_from <= ?", userID, at).
+		Where("ea.effective_to IS NULL OR ea.effective_to > ?", at).
+		Where(conds, args...).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check manager: %w", err)
+	}
+	return count > 0, nil
+}
+
+// AssignmentFilter resolves an org filter for the repositories
+// Department diperluas ke sub-department, filter manager ditambah department yang dia pimpin
+func (s *OrgService) AssignmentFilter(filter OrgFilter) (*repository.AssignmentFilter, error) {
+	resolved := &repository.AssignmentFilter{TeamID: filter.TeamID, ManagerID: filter.ManagerID}
+
+	if filter.DepartmentID != 0 {
+		ids, err := s.DescendantDepartmentIDs(filter.DepartmentID)
+		if err != nil {
+			return nil, err
+		}
+		resolved.DepartmentIDs = ids
+	}
+
+	if filter.ManagerID != 0 {
+		var managed []uint
+		if err := s.db.Model(&models.Department{}).Where("manager_id = ?", filter.ManagerID).Pluck("id", &managed).Error; err != nil {
+			return nil, fmt.Errorf("failed to load managed departments: %w", err)
+		}
+		if len(managed) > 0 {
+			ids, err := s.DescendantDepartmentIDs(managed...)
+			if err != nil {
+				return nil, err
+			}
+			resolved.ManagedDepartmentIDs = ids
+		}
+	}
+	return resolved, nil
+}
+
+// assignmentConditions builds SQL conditions on employee_assignments (ea) and teams (t)
+func (s *OrgService) assignmentConditions(filter OrgFilter) (string, []interface{}, error) {
+	resolved, err := s.AssignmentFilter(filter)
+	if err != nil {
+		return "", nil, err
+	}
+	conds, args := repository.AssignmentConditions(*resolved)
+	return conds, args, nil
+}
@@ -0,0 +1,424 @@
+package services
+
+import (
+	"archive/zip"
+	"attendance-system/internal/models"
+	"attendance-system/internal/storage"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Data subject request errors, handler memetakan ke status HTTP
+var (
+	ErrEmployeeErased      = errors.New("employee data has already been erased")
+	ErrErasureLegalHold    = errors.New("employee or one of their check-ins is under legal hold")
+	ErrErasureNotConfirmed = errors.New("confirm_email does not match the employee email")
+)
+
+// ExportManifest describes the content of an employee data export
+type ExportManifest struct {
+	EmployeeID    uint      `json:"employee_id"`
+	ExportedAt    time.Time `json:"exported_at"`
+	Attendances   int       `json:"attendances"`
+	DailyRecords  int       `json:"daily_records"`
+	LeaveRequests int       `json:"leave_requests"`
+	Corrections   int       `json:"corrections"`
+	Images        int       `json:"images"`
+	MissingImages []string  `json:"missing_images,omitempty"` // File yang tercatat di database tapi sudah tidak ada di storage
+}
+
+// ErasureReport summarizes what an erasure removed
+type ErasureReport struct {
+	EmployeeID    uint      `json:"employee_id"`
+	ErasedAt      time.Time `json:"erased_at"`
+	FilesDeleted  int       `json:"files_deleted"`
+	Attendances   int64     `json:"attendances"`
+	LeaveRequests int64     `json:"leave_requests"`
+	Corrections   int64     `json:"corrections"`
+	// Data yang sengaja tidak dihapus, erasure tidak berarti semua jejak karyawan hilang
+	Retained []ErasureRetention `json:"retained"`
+}
+
+// ErasureRetention is a category of records kept after erasure and why
+type ErasureRetention struct {
+	Category     string `json:"category"`
+	Records      int64  `json:"records"`
+	PersonalData bool   `json:"personal_data"` // true = masih ada nama/email/telepon karyawan di record ini
+	Basis        string `json:"basis"`
+}
+
+// auditRetentionBasis is the legal basis for keeping audit log entries after erasure
+const auditRetentionBasis = "legal obligation and accountability (GDPR art. 17(3)(b) and (e)): " +
+	"audit log is append-only and hash-chained, entries cannot be altered without breaking verification"
+
+// exportFile is a stored file and its name inside the export, ekstensi diambil dari key
+type exportFile struct {
+	key  string
+	name string
+}
+
+// PrivacyService handles data subject access (export) and erasure requests
+type PrivacyService struct {
+	db          *gorm.DB
+	faceService *FaceService
+	storage     storage.Storage
+}
+
+// NewPrivacyService creates a new PrivacyService instance
+func NewPrivacyService(db *gorm.DB, faceService *FaceService, store storage.Storage) *PrivacyService {
+	return &PrivacyService{db: db, faceService: faceService, storage: store}
+}
+
+// Export writes a ZIP with the employee's profile, attendance history, leave, corrections and images
+// Foto di-decrypt supaya bisa dibuka langsung oleh karyawan
+func (s *PrivacyService) Export(ctx context.Context, userID uint, w io.Writer) error {
+	var user models.User
+	if err := s.db.Preload("Assignments", func(db *gorm.DB) *gorm.DB { return db.Order("effective_from") }).
+		Preload("Assignments.Department").Preload("Assignments.Team").Preload("Assignments.Site").
+		First(&user, userID).Error; err != nil {
+		return err
+	}
+
+	var attendances []models.Attendance
+	if err := s.db.Where("user_id = ?", userID).Order("check_in_time").Find(&attendances).Error; err != nil {
+		return fmt.Errorf("failed to load attendances: %w", err)
+	}
+	var daily []models.DailyAttendance
+	if err := s.db.Where("user_id = ?", userID).Order("date").Find(&daily).Error; err != nil {
+		return fmt.Errorf("failed to load daily attendance: %w", err)
+	}
+	var leaveRequests []models.LeaveRequest
+	if err := s.db.Preload("LeaveType").Where("user_id = ?", userID).Order("start_date").Find(&leaveRequests).Error; err != nil {
+		return fmt.Errorf("failed to load leave requests: %w", err)
+	}
+	var balances []models.LeaveBalance
+	if err := s.db.Preload("LeaveType").Where("user_id = ?", userID).Order("year, leave_type_id").Find(&balances).Error; err != nil {
+		return fmt.Errorf("failed to load leave balances: %w", err)
+	}
+	var corrections []models.AttendanceCorrection
+	if err := s.db.Preload("Events").Where("user_id = ?", userID).Order("check_in_time").Find(&corrections).Error; err != nil {
+		return fmt.Errorf("failed to load corrections: %w", err)
+	}
+
+	manifest := ExportManifest{
+		EmployeeID:    user.ID,
+		ExportedAt:    time.Now().UTC(),
+		Attendances:   len(attendances),
+		DailyRecords:  len(daily),
+		LeaveRequests: len(leaveRequests),
+		Corrections:   len(corrections),
+	}
+
+	archive := zip.NewWriter(w)
+
+	assignments := make([]models.AssignmentResponse, 0, len(user.Assignments))
+	for i := range user.Assignments {
+		assignments = append(assignments, user.Assignments[i].ToResponse())
+	}
+	profile := user.ToResponse()
+	profile.Assignment = nil
+	if err := writeZipJSON(archive, "profile.json", map[string]interface{}{"employee": profile, "assignments": assignments}); err != nil {
+		return err
+	}
+
+	attendanceResponses := make([]models.AttendanceResponse, 0, len(attendances))
+	for i := range attendances {
+		attendanceResponses = append(attendanceResponses, attendances[i].ToResponse())
+	}
+	if err := writeZipJSON(archive, "attendance.json", attendanceResponses); err != nil {
+		return err
+	}
+	if err := writeAttendanceCSV(archive, attendances); err != nil {
+		return err
+	}
+
+	dailyResponses := make([]models.DailyAttendanceResponse, 0, len(daily))
+	for i := range daily {
+		dailyResponses = append(dailyResponses, daily[i].ToResponse())
+	}
+	if err := writeZipJSON(archive, "daily_attendance.json", dailyResponses); err != nil {
+		return err
+	}
+
+	leaveResponses := make([]models.LeaveRequestResponse, 0, len(leaveRequests))
+	for i := range leaveRequests {
+		leaveResponses = append(leaveResponses, leaveRequests[i].ToResponse())
+	}
+	if err := writeZipJSON(archive, "leave_requests.json", leaveResponses); err != nil {
+		return err
+	}
+	balanceResponses := make([]models.LeaveBalanceResponse, 0, len(balances))
+	for i := range balances {
+		balanceResponses = append(balanceResponses, balances[i].ToResponse())
+	}
+	if err := writeZipJSON(archive, "leave_balances.json", balanceResponses); err != nil {
+		return err
+	}
+	if err := writeZipJSON(archive, "corrections.json", corrections); err != nil {
+		return err
+	}
+
+	// Foto referensi, selfie check-in dan lampiran cuti
+	images := []exportFile{{key: user.FaceImagePath, name: "images/reference"}}
+	for _, attendance := range attendances {
+		images = append(images, exportFile{key: attendance.FaceImagePath, name: fmt.Sprintf("images/checkins/%d", attendance.ID)})
+	}
+	for _, request := range leaveRequests {
+		images = append(images, exportFile{key: request.AttachmentPath, name: fmt.Sprintf("attachments/leave/%d", request.ID)})
+	}
+	for _, image := range images {
+		if image.key == "" {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		data, err := s.faceService.ReadImage(ctx, image.key)
+		if errors.Is(err, storage.ErrNotFound) {
+			manifest.MissingImages = append(manifest.MissingImages, image.name)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", image.name, err)
+		}
+		file, err := archive.Create(image.name + path.Ext(image.key))
+		if err != nil {
+			return err
+		}
+		if _, err := file.Write(data); err != nil {
+			return err
+		}
+		manifest.Images++
+	}
+
+	if err := writeZipJSON(archive, "manifest.json", manifest); err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+// Erase removes an employee's biometric data and personal details
+// Record attendance, daily attendance dan cuti tetap ada dengan user_id yang sama supaya laporan agregat tetap jalan,
+// hanya identitas (nama, email, telepon), foto, descriptor dan teks bebas yang dihapus
+// confirmEmail harus sama dengan email karyawan untuk mencegah salah ID
+func (s *PrivacyService) Erase(ctx context.Context, userID uint, confirmEmail string) (*ErasureReport, error) {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	if user.ErasedAt != nil {
+		return nil, ErrEmployeeErased
+	}
+	if confirmEmail != user.Email {
+		return nil, ErrErasureNotConfirmed
+	}
+
+	var held int64
+	if err := s.db.Model(&models.Attendance{}).Where("user_id = ? AND legal_hold = ?", userID, true).Count(&held).Error; err != nil {
+		return nil, fmt.Errorf("failed to check legal hold: %w", err)
+	}
+	if user.LegalHold || held > 0 {
+		return nil, ErrErasureLegalHold
+	}
+
+	// Hapus file dulu, database baru diubah setelah semua file terhapus
+	// Kalau gagal di tengah, erase bisa diulang: file yang sudah hilang dianggap berhasil
+	var keys []string
+	if user.FaceImagePath != "" {
+		keys = append(keys, user.FaceImagePath)
+	}
+	var selfies []string
+	if err := s.db.Model(&models.Attendance{}).Where("user_id = ? AND face_image_path <> ''", userID).Pluck("face_image_path", &selfies).Error; err != nil {
+		return nil, fmt.Errorf("failed to load selfies: %w", err)
+	}
+	var attachments []string
+	if err := s.db.Model(&models.LeaveRequest{}).Where("user_id = ? AND attachment_path <> ''", userID).Pluck("attachment_path", &attachments).Error; err != nil {
+		return nil, fmt.Errorf("failed to load leave attachments: %w", err)
+	}
+	keys = append(append(keys, selfies...), attachments...)
+
+	// Dihitung sebelum pseudonymize, setelahnya nama/email asli sudah tidak ada untuk dicari
+	retained, err := s.retainedAuditLogs(&user)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	report := &ErasureReport{EmployeeID: user.ID, ErasedAt: now, Retained: retained}
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := s.storage.Delete(ctx, key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return nil, fmt.Errorf("failed to delete %s: %w", key, err)
+		}
+		report.FilesDeleted++
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		deactivatedAt := now
+		if user.DeactivatedAt != nil {
+			deactivatedAt = *user.DeactivatedAt
+		}
+		// Menonaktifkan akun juga mencabut token yang masih berlaku
+		err := tx.Model(&user).Updates(map[string]interface{}{
+			"name":            fmt.Sprintf("Erased employee #%d", user.ID),
+			"email":           fmt.Sprintf("erased-%d@erased.invalid", user.ID),
+			"phone":           "",
+			"face_image_path": "",
+			"face_descriptor": "",
+			"password_hash":   "",
+			"deactivated_at":  deactivatedAt,
+			"image_purged_at": now,
+			"erased_at":       now,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		result := tx.Model(&models.Attendance{}).Where("user_id = ?", userID).UpdateColumns(map[string]interface{}{
+			"face_image_path": "",
+			"image_purged_at": gorm.Expr("COALESCE(image_purged_at, ?)", now),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		report.Attendances = result.RowsAffected
+
+		result = tx.Model(&models.LeaveRequest{}).Where("user_id = ?", userID).UpdateColumns(map[string]interface{}{
+			"reason": "", "decision_note": "", "attachment_path": "",
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		report.LeaveRequests = result.RowsAffected
+
+		// Reason correction wajib diisi, jadi diganti penanda saja
+		var correctionIDs []uint
+		if err := tx.Model(&models.AttendanceCorrection{}).Where("user_id = ?", userID).Pluck("id", &correctionIDs).Error; err != nil {
+			return err
+		}
+		if len(correctionIDs) > 0 {
+			result = tx.Model(&models.AttendanceCorrection{}).Where("id IN ?", correctionIDs).
+				UpdateColumns(map[string]interface{}{"reason": "[erased]", "decision_note": ""})
+			if result.Error != nil {
+				return result.Error
+			}
+			report.Corrections = result.RowsAffected
+			if err := tx.Model(&models.AttendanceCorrectionEvent{}).Where("correction_id IN ?", correctionIDs).UpdateColumn("note", "").Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to pseudonymize employee: %w", err)
+	}
+
+	return report, nil
+}
+
+// retainedAuditLogs counts audit log entries about the employee that erasure keeps
+// Entry sejak redaksi diff (nama, email, telepon disamarkan) hanya menyimpan ID; entry lama bisa
+// masih berisi data pribadi di diff atau path, dilaporkan terpisah supaya tidak diklaim terhapus
+func (s *PrivacyService) retainedAuditLogs(user *models.User) ([]ErasureRetention, error) {
+	about := "(entity_type = ? AND entity_id = ?) OR actor_id = ?"
+	aboutArgs := []interface{}{"employee", strconv.FormatUint(uint64(user.ID), 10), user.ID}
+
+	var conditions []string
+	var personalArgs []interface{}
+	for _, value := range []string{user.Email, user.Name, user.Phone} {
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+		pattern := auditLikePattern(value)
+		conditions = append(conditions, "diff LIKE ? ESCAPE '\\' OR path LIKE ? ESCAPE '\\'")
+		personalArgs = append(personalArgs, pattern, pattern)
+	}
+
+	var personal int64
+	if len(conditions) > 0 {
+		containsPersonal := strings.Join(conditions, " OR ")
+		if err := s.db.Model(&models.AuditLog{}).Where(containsPersonal, personalArgs...).Count(&personal).Error; err != nil {
+			return nil, fmt.Errorf("failed to count audit logs: %w", err)
+		}
+		about += " OR " + containsPersonal
+		aboutArgs = append(aboutArgs, personalArgs...)
+	}
+
+	var total int64
+	if err := s.db.Model(&models.AuditLog{}).Where(about, aboutArgs...).Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count audit logs: %w", err)
+	}
+
+	retained := []ErasureRetention{{Category: "audit_logs", Records: total - personal, Basis: auditRetentionBasis}}
+	if personal > 0 {
+		retained = append(retained, ErasureRetention{
+			Category: "audit_logs", Records: personal, PersonalData: true, Basis: auditRetentionBasis,
+		})
+	}
+	return retained, nil
+}
+
+// auditLikePattern builds a LIKE pattern matching value anywhere, wildcard di value di-escape
+func auditLikePattern(value string) string {
+	replacer := strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_")
+	return "%" + replacer.Replace(value) + "%"
+}
+
+// writeZipJSON adds an indented JSON file to the archive
+func writeZipJSON(archive *zip.Writer, name string, value interface{}) error {
+	file, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// writeAttendanceCSV adds the check-in history as CSV, untuk dibuka di spreadsheet
+func writeAttendanceCSV(archive *zip.Writer, attendances []models.Attendance) error {
+	file, err := archive.Create("attendance.csv")
+	if err != nil {
+		return err
+	}
+	writer := csv.NewWriter(file)
+	if err := writer.Write([]string{"id", "check_in_time", "status", "similarity_score", "site_id", "device_id", "image_purged_at"}); err != nil {
+		return err
+	}
+	for _, attendance := range attendances {
+		siteID, purgedAt := "", ""
+		if attendance.SiteID != nil {
+			siteID = strconv.FormatUint(uint64(*attendance.SiteID), 10)
+		}
+		if attendance.ImagePurgedAt != nil {
+			purgedAt = attendance.ImagePurgedAt.UTC().Format(time.RFC3339)
+		}
+		err := writer.Write([]string{
+			strconv.FormatUint(uint64(attendance.ID), 10),
+			attendance.CheckInTime.UTC().Format(time.RFC3339),
+			attendance.Status,
+			strconv.FormatFloat(attendance.SimilarityScore, 'f', 4, 64),
+			siteID,
+			attendance.DeviceID,
+			purgedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,326 @@
+package services
+
+import (
+	"attendance-system/internal/logging"
+	"attendance-system/internal/models"
+	"attendance-system/internal/storage"
+	"attendance-system/internal/tracing"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// retentionBatchSize is how many records are purged per query
+const retentionBatchSize = 200
+
+// retentionMaxErrors limits error messages stored in a purge run
+const retentionMaxErrors = 50
+
+// ErrPurgeRunning is returned when another purge is still running, di proses ini atau di instance lain
+var ErrPurgeRunning = errors.New("a retention purge is already running")
+
+// retentionPurgeMu serializes purges dari job dan endpoint manual di satu proses
+var retentionPurgeMu sync.Mutex
+
+// retentionLockKey is the PostgreSQL advisory lock held during a purge, supaya job dan endpoint di
+// semua replica tidak purge bersamaan. Beda dengan lock migration ("retent" dalam hex)
+const retentionLockKey int64 = 0x726574656e74
+
+// RetentionService deletes selfies, reference photos and face descriptors past their retention period
+type RetentionService struct {
+	db      *gorm.DB
+	storage storage.Storage
+	mu      sync.RWMutex
+	policy  models.RetentionPolicy // Bisa diganti saat config reload
+}
+
+// NewRetentionService creates a new RetentionService instance
+func NewRetentionService(db *gorm.DB, store storage.Storage, policy models.RetentionPolicy) *RetentionService {
+	return &RetentionService{db: db, storage: store, policy: policy}
+}
+
+// Policy returns the active retention policy
+func (s *RetentionService) Policy() models.RetentionPolicy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.policy
+}
+
+// SetPolicy replaces the retention policy (config reload), berlaku mulai purge berikutnya
+func (s *RetentionService) SetPolicy(policy models.RetentionPolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.policy = policy
+}
+
+// Purge deletes expired biometric files and records the run
+// Record dengan legal hold (atau milik karyawan dengan legal hold) dilewati dan hanya dihitung
+// Dry run hanya mencatat apa yang akan di-purge
+func (s *RetentionService) Purge(ctx context.Context, now time.Time, trigger string, triggeredByID *uint, dryRun bool) (*models.RetentionPurgeRun, error) {
+	if !retentionPurgeMu.TryLock() {
+		return nil, ErrPurgeRunning
+	}
+	defer retentionPurgeMu.Unlock()
+
+	// SQLite hanya untuk satu instance, lock proses sudah cukup
+	if s.db.Dialector.Name() != "postgres" {
+		return s.purge(ctx, now, trigger, triggeredByID, dryRun)
+	}
+
+	// Advisory lock milik session, jadi lock dan unlock di koneksi yang sama; query purge memakai koneksi lain dari pool
+	var run *models.RetentionPurgeRun
+	var purgeErr error
+	err := s.db.Connection(func(conn *gorm.DB) error {
+		var locked bool
+		if err := conn.Raw("SELECT pg_try_advisory_lock(?)", retentionLockKey).Scan(&locked).Error; err != nil {
+			return fmt.Errorf("failed to acquire purge lock: %w", err)
+		}
+		if !locked {
+			return ErrPurgeRunning
+		}
+		defer conn.Exec("SELECT pg_advisory_unlock(?)", retentionLockKey)
+		run, purgeErr = s.purge(ctx, now, trigger, triggeredByID, dryRun)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return run, purgeErr
+}
+
+// purge runs one purge while the caller holds the purge lock
+func (s *RetentionService) purge(ctx context.Context, now time.Time, trigger string, triggeredByID *uint, dryRun bool) (*models.RetentionPurgeRun, error) {
+	run := &models.RetentionPurgeRun{Trigger: trigger, TriggeredByID: triggeredByID, DryRun: dryRun, StartedAt: now.UTC()}
+	if err := s.db.Create(run).Error; err != nil {
+		return nil, fmt.Errorf("failed to record purge run: %w", err)
+	}
+
+	var errs []string
+	purgeErr := s.purgeSelfies(ctx, run, now, models.RetentionSuccessfulSelfie, models.AttendanceStatusSuccess, &run.SuccessfulSelfies, &errs)
+	if purgeErr == nil {
+		purgeErr = s.purgeSelfies(ctx, run, now, models.RetentionFailedSelfie, models.AttendanceStatusFailed, &run.FailedSelfies, &errs)
+	}
+	if purgeErr == nil {
+		purgeErr = s.purgeReferencePhotos(ctx, run, now, &errs)
+	}
+	if purgeErr != nil {
+		errs = append(errs, purgeErr.Error())
+	}
+
+	completedAt := time.Now().UTC()
+	run.CompletedAt = &completedAt
+	run.Errors = strings.Join(errs, "\n")
+	if err := s.db.Save(run).Error; err != nil {
+		return run, fmt.Errorf("failed to update purge run: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Retention purge finished", "run_id", run.ID, "trigger", trigger, "dry_run", dryRun,
+		"successful_selfies", run.SuccessfulSelfies, "failed_selfies", run.FailedSelfies, "reference_photos", run.ReferencePhotos,
+		"held", run.Held, "failed", run.Failed)
+	return run, purgeErr
+}
+
+// purgeSelfies purges check-in selfies of one status
+func (s *RetentionService) purgeSelfies(ctx context.Context, run *models.RetentionPurgeRun, now time.Time, category, status string, purged *int, errs *[]string) error {
+	cutoff, ok := s.Policy().Cutoff(category, now)
+	if !ok {
+		return nil
+	}
+
+	expired := func() *gorm.DB {
+		return s.db.Model(&models.Attendance{}).
+			Where("status = ? AND face_image_path <> '' AND check_in_time < ?", status, cutoff)
+	}
+	heldUsers := s.db.Model(&models.User{}).Select("id").Where("legal_hold = ?", true)
+
+	var held int64
+	if err := expired().Where("legal_hold = ? OR user_id IN (?)", true, heldUsers).Count(&held).Error; err != nil {
+		return fmt.Errorf("failed to count held selfies: %w", err)
+	}
+	run.Held += held
+
+	var lastID uint
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var attendances []models.Attendance
+		err := expired().Where("legal_hold = ? AND user_id NOT IN (?)", false, heldUsers).
+			Where("id > ?", lastID).Order("id").Limit(retentionBatchSize).
+			Select("id", "user_id", "check_in_time", "face_image_path").Find(&attendances).Error
+		if err != nil {
+			return fmt.Errorf("failed to load expired selfies: %w", err)
+		}
+		if len(attendances) == 0 {
+			return nil
+		}
+
+		items := make([]models.RetentionPurgeItem, 0, len(attendances))
+		for i := range attendances {
+			attendance := &attendances[i]
+			lastID = attendance.ID
+
+			if !run.DryRun {
+				err := s.deleteFile(ctx, attendance.FaceImagePath)
+				if err == nil {
+					err = s.db.Model(&models.Attendance{}).Where("id = ?", attendance.ID).
+						UpdateColumns(map[string]interface{}{"face_image_path": "", "image_purged_at": now}).Error
+				}
+				if err != nil {
+					s.fail(run, errs, fmt.Sprintf("attendance %d: %v", attendance.ID, err))
+					continue
+				}
+			}
+
+			*purged++
+			items = append(items, models.RetentionPurgeItem{
+				RunID: run.ID, Category: category, EntityType: "attendance", EntityID: attendance.ID,
+				UserID: attendance.UserID, RecordedAt: attendance.CheckInTime, PurgedAt: now,
+			})
+		}
+		if err := s.recordItems(items); err != nil {
+			return err
+		}
+	}
+}
+
+// purgeReferencePhotos purges reference photos and face descriptors of employees who left
+func (s *RetentionService) purgeReferencePhotos(ctx context.Context, run *models.RetentionPurgeRun, now time.Time, errs *[]string) error {
+	cutoff, ok := s.Policy().Cutoff(models.RetentionReferencePhoto, now)
+	if !ok {
+		return nil
+	}
+
+	expired := func() *gorm.DB {
+		return s.db.Model(&models.User{}).
+			Where("deactivated_at IS NOT NULL AND deactivated_at < ?", cutoff).
+			Where("face_image_path <> '' OR face_descriptor <> ''")
+	}
+
+	var held int64
+	if err := expired().Where("legal_hold = ?", true).Count(&held).Error; err != nil {
+		return fmt.Errorf("failed to count held employees: %w", err)
+	}
+	run.Held += held
+
+	var lastID uint
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var users []models.User
+		err := expired().Where("legal_hold = ?", false).
+			Where("id > ?", lastID).Order("id").Limit(retentionBatchSize).
+			Select("id", "face_image_path", "deactivated_at").Find(&users).Error
+		if err != nil {
+			return fmt.Errorf("failed to load expired employees: %w", err)
+		}
+		if len(users) == 0 {
+			return nil
+		}
+
+		items := make([]models.RetentionPurgeItem, 0, len(users))
+		for i := range users {
+			user := &users[i]
+			lastID = user.ID
+
+			if !run.DryRun {
+				err := s.deleteFile(ctx, user.FaceImagePath)
+				if err == nil {
+					// UpdateColumns supaya updated_at tidak berubah
+					err = s.db.Model(&models.User{}).Where("id = ?", user.ID).UpdateColumns(map[string]interface{}{
+						"face_image_path": "", "face_descriptor": "", "image_purged_at": now,
+					}).Error
+				}
+				if err != nil {
+					s.fail(run, errs, fmt.Sprintf("employee %d: %v", user.ID, err))
+					continue
+				}
+			}
+
+			run.ReferencePhotos++
+			items = append(items, models.RetentionPurgeItem{
+				RunID: run.ID, Category: models.RetentionReferencePhoto, EntityType: "employee", EntityID: user.ID,
+				UserID: user.ID, RecordedAt: *user.DeactivatedAt, PurgedAt: now,
+			})
+		}
+		if err := s.recordItems(items); err != nil {
+			return err
+		}
+	}
+}
+
+// deleteFile removes a file from storage, file yang sudah tidak ada dianggap berhasil
+func (s *RetentionService) deleteFile(ctx context.Context, key string) error {
+	if key == "" {
+		return nil
+	}
+	if err := s.storage.Delete(ctx, key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// recordItems stores the purged records of one batch
+func (s *RetentionService) recordItems(items []models.RetentionPurgeItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	if err := s.db.CreateInBatches(items, retentionBatchSize).Error; err != nil {
+		return fmt.Errorf("failed to record purged items: %w", err)
+	}
+	return nil
+}
+
+// fail counts a failed record, pesan error dibatasi supaya report tidak membengkak
+func (s *RetentionService) fail(run *models.RetentionPurgeRun, errs *[]string, message string) {
+	run.Failed++
+	if len(*errs) < retentionMaxErrors {
+		*errs = append(*errs, message)
+	}
+}
+
+// RetentionJob periodically purges expired biometric data
+type RetentionJob struct {
+	service  *RetentionService
+	interval time.Duration
+}
+
+// NewRetentionJob creates a new RetentionJob
+func NewRetentionJob(service *RetentionService, interval time.Duration) *RetentionJob {
+	return &RetentionJob{service: service, interval: interval}
+}
+
+// Run purges on every tick until ctx is cancelled
+func (j *RetentionJob) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		// Root span per run supaya durasi purge (dan request delete ke S3) terlihat di trace
+		runCtx, span := tracing.Start(ctx, "job.retention_purge")
+		_, err := j.service.Purge(runCtx, time.Now(), models.RetentionTriggerJob, nil, false)
+		tracing.End(span, err)
+		switch {
+		case errors.Is(err, ErrPurgeRunning):
+			// Replica lain (atau purge manual) sedang jalan, giliran berikutnya dicoba lagi
+			slog.Info("Retention purge skipped, another purge is running")
+		case err != nil && !errors.Is(err, context.Canceled):
+			slog.Error("Error running retention purge job", logging.Err(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
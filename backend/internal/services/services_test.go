@@ -0,0 +1,41 @@
+package services
+
+import (
+	"attendance-system/internal/config"
+	"attendance-system/internal/models"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB opens a migrated SQLite database in a temp dir, sama seperti DB_DRIVER=sqlite di integration suite
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := config.InitDatabase(&config.DatabaseConfig{
+		Driver:      config.DriverSQLite,
+		Path:        filepath.Join(t.TempDir(), "test.db"),
+		AutoMigrate: true,
+		LogLevel:    logger.Silent,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// createTestUser inserts an active employee with the given role
+func createTestUser(t *testing.T, db *gorm.DB, name, role string) *models.User {
+	t.Helper()
+	user := &models.User{Name: name, Email: name + "@example.com", Role: role}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"attendance-system/internal/models"
+	"attendance-system/internal/storage"
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// storageReference is a table column that stores a storage key
+type storageReference struct {
+	model  interface{}
+	column string
+}
+
+// storageReferences lists every column pointing at an uploaded file
+var storageReferences = []storageReference{
+	{&models.User{}, "face_image_path"},
+	{&models.Attendance{}, "face_image_path"},
+	{&models.LeaveRequest{}, "attachment_path"},
+}
+
+// StorageMigrationReport summarizes a storage migration run
+type StorageMigrationReport struct {
+	From           string   `json:"from"`
+	To             string   `json:"to"`
+	DryRun         bool     `json:"dry_run"`
+	Checked        int      `json:"checked"`
+	Copied         int      `json:"copied"`
+	Skipped        int      `json:"skipped"` // Sudah ada di tujuan dengan ukuran sama
+	Missing        int      `json:"missing"` // Direferensikan database tapi tidak ada di sumber
+	Failed         int      `json:"failed"`
+	Deleted        int      `json:"deleted"`
+	PathsRewritten int64    `json:"paths_rewritten"`
+	Errors         []string `json:"errors,omitempty"`
+}
+
+// StorageMigrationService copies uploaded files between storage backends
+type StorageMigrationService struct {
+	db        *gorm.DB
+	from      storage.Storage
+	to        storage.Storage
+	uploadDir string
+}
+
+// NewStorageMigrationService creates a new StorageMigrationService instance
+// uploadDir dipakai untuk mengubah path lama ("uploads/abc.jpg") menjadi key ("abc.jpg")
+func NewStorageMigrationService(db *gorm.DB, from, to storage.Storage, uploadDir string) *StorageMigrationService {
+	return &StorageMigrationService{db: db, from: from, to: to, uploadDir: uploadDir}
+}
+
+// Migrate copies every file referenced by the database from the source to the destination
+// Aman dijalankan ulang: file yang sudah ada di tujuan dengan ukuran sama dilewati
+// deleteSource menghapus file dari sumber setelah berhasil disalin
+func (s *StorageMigrationService) Migrate(ctx context.Context, dryRun, deleteSource bool) (*StorageMigrationReport, error) {
+	report := &StorageMigrationReport{From: s.from.Driver(), To: s.to.Driver(), DryRun: dryRun}
+
+	for _, ref := range storageReferences {
+		var paths []string
+		if err := s.db.Model(ref.model).Where(ref.column+" <> ''").Distinct().Pluck(ref.column, &paths).Error; err != nil {
+			return report, fmt.Errorf("failed to list %s: %w", ref.column, err)
+		}
+
+		for _, stored := range paths {
+			if err := ctx.Err(); err != nil {
+				return report, err
+			}
+			if !s.migrateFile(ctx, ref, stored, report) || dryRun {
+				continue
+			}
+
+			if deleteSource {
+				if err := s.from.Delete(ctx, stored); err != nil {
+					report.Errors = append(report.Errors, fmt.Sprintf("delete %s: %v", stored, err))
+				} else {
+					report.Deleted++
+				}
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// migrateFile copies one file and rewrites legacy paths to keys, return true kalau file ada di tujuan
+func (s *StorageMigrationService) migrateFile(ctx context.Context, ref storageReference, stored string, report *StorageMigrationReport) bool {
+	report.Checked++
+	key := storage.NormalizeKey(s.uploadDir, stored)
+
+	source, err := s.from.Stat(ctx, stored)
+	if errors.Is(err, storage.ErrNotFound) {
+		report.Missing++
+		return false
+	}
+	if err == nil {
+		err = s.copyFile(ctx, stored, key, source, report)
+	}
+	if err != nil {
+		report.Failed++
+		report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", stored, err))
+		return false
+	}
+
+	// Path lama yang masih berisi UPLOAD_PATH diganti key supaya bisa dibaca driver manapun
+	if key != stored && !report.DryRun {
+		// UpdateColumn supaya updated_at tidak berubah
+		result := s.db.Model(ref.model).Where(ref.column+" = ?", stored).UpdateColumn(ref.column, key)
+		if result.Error != nil {
+			report.Failed++
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: failed to update %s: %v", stored, ref.column, result.Error))
+			return false
+		}
+		report.PathsRewritten += result.RowsAffected
+	}
+	return true
+}
+
+// copyFile streams a file to the destination unless it is already there
+func (s *StorageMigrationService) copyFile(ctx context.Context, stored, key string, source *storage.ObjectInfo, report *StorageMigrationReport) error {
+	if existing, err := s.to.Stat(ctx, key); err == nil && existing.Size == source.Size {
+		report.Skipped++
+		return nil
+	} else if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return err
+	}
+
+	if report.DryRun {
+		report.Copied++
+		return nil
+	}
+
+	reader, err := s.from.Get(ctx, stored)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	if err := s.to.Put(ctx, key, reader, source.Size, source.ContentType); err != nil {
+		return err
+	}
+	copied, err := s.to.Stat(ctx, key)
+	if err != nil {
+		return err
+	}
+	if copied.Size != source.Size {
+		return fmt.Errorf("size mismatch after copy (%d != %d bytes)", copied.Size, source.Size)
+	}
+	report.Copied++
+	return nil
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"attendance-system/internal/logging"
+	"attendance-system/internal/models"
+	"attendance-system/internal/utils"
+	"log/slog"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TimezoneService resolves the local calendar used for an employee or site
+// Urutan: timezone site karyawan -> ORG_TIMEZONE
+type TimezoneService struct {
+	db          *gorm.DB
+	orgService  *OrgService
+	defaultLoc  *time.Location
+	locationMap sync.Map // cache IANA name -> *time.Location
+}
+
+// NewTimezoneService creates a new TimezoneService instance
+func NewTimezoneService(db *gorm.DB, orgService *OrgService, defaultLoc *time.Location) *TimezoneService {
+	if defaultLoc == nil {
+		defaultLoc = time.UTC
+	}
+	return &TimezoneService{
+		db:         db,
+		orgService: orgService,
+		defaultLoc: defaultLoc,
+	}
+}
+
+// DefaultLocation returns the organization timezone
+func (s *TimezoneService) DefaultLocation() *time.Location {
+	return s.defaultLoc
+}
+
+// LoadLocation parses an IANA timezone name with caching
+// Nama kosong menghasilkan organization timezone
+func (s *TimezoneService) LoadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return s.defaultLoc, nil
+	}
+	if cached, ok := s.locationMap.Load(name); ok {
+		return cached.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	s.locationMap.Store(name, loc)
+	return loc, nil
+}
+
+// LocationForSite returns the site timezone, falling back to the organization timezone
+func (s *TimezoneService) LocationForSite(siteID *uint) *time.Location {
+	if siteID == nil {
+		return s.defaultLoc
+	}
+
+	var site models.Site
+	if err := s.db.Select("id", "timezone").First(&site, *siteID).Error; err != nil {
+		return s.defaultLoc
+	}
+	return s.siteLocation(&site)
+}
+
+// LocationForUser returns the timezone of the employee's home site at the given time
+func (s *TimezoneService) LocationForUser(userID uint, at time.Time) *time.Location {
+	assignment, err := s.orgService.CurrentAssignment(userID, at)
+	if err != nil || assignment.Site == nil {
+		return s.defaultLoc
+	}
+	return s.siteLocation(assignment.Site)
+}
+
+// UserDayBounds returns the UTC range of the employee's local calendar day containing t
+func (s *TimezoneService) UserDayBounds(userID uint, t time.Time) (time.Time, time.Time, *time.Location) {
+	loc := s.LocationForUser(userID, t)
+	start, end := utils.DayBounds(t, loc)
+	return start.UTC(), end.UTC(), loc
+}
+
+// siteLocation parses the site timezone, invalid value jatuh ke default
+func (s *TimezoneService) siteLocation(site *models.Site) *time.Location {
+	loc, err := s.LoadLocation(site.Timezone)
+	if err != nil {
+		slog.Warn("Invalid site timezone, using organization timezone", "site_id", site.ID, "timezone", site.Timezone, logging.Err(err))
+		return s.defaultLoc
+	}
+	return loc
+}
@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// Local stores objects as files under a root directory (UPLOAD_PATH)
+// Hanya cocok untuk satu instance backend, atau kalau root ada di shared volume
+type Local struct {
+	root string
+}
+
+// NewLocal creates a local-disk storage rooted at dir
+func NewLocal(dir string) *Local {
+	return &Local{root: dir}
+}
+
+// Driver returns the driver name
+func (s *Local) Driver() string {
+	return DriverLocal
+}
+
+// Init creates the root directory
+func (s *Local) Init(ctx context.Context) error {
+	if err := os.MkdirAll(s.root, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create upload directory: %w", err)
+	}
+	return nil
+}
+
+// Put writes an object via temp file + rename, jadi reader tidak pernah melihat file setengah jadi
+func (s *Local) Put(ctx context.Context, key string, src io.Reader, size int64, contentType string) error {
+	target, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, src); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+// Get opens an object for reading
+func (s *Local) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	target, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(target)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+// Delete removes an object
+func (s *Local) Delete(ctx context.Context, key string) error {
+	target, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	return nil
+}
+
+// Stat returns object metadata
+func (s *Local) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	target, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(target)
+	if errors.Is(err, fs.ErrNotExist) || (err == nil && info.IsDir()) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := NormalizeKey(s.root, key)
+	return &ObjectInfo{
+		Key:          normalized,
+		Size:         info.Size(),
+		ContentType:  mime.TypeByExtension(path.Ext(normalized)),
+		LastModified: info.ModTime(),
+	}, nil
+}
+
+// SignedURL is not supported, file lokal selalu disajikan lewat backend
+func (s *Local) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	return "", ErrSignedURLNotSupported
+}
+
+// path maps a key (atau path lama yang masih berisi UPLOAD_PATH) to a file under root
+func (s *Local) path(key string) (string, error) {
+	normalized := NormalizeKey(s.root, key)
+	if normalized == "" {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(s.root, filepath.FromSlash(normalized)), nil
+}
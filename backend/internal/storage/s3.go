@@ -0,0 +1,179 @@
+package storage
+
+import (
+	"attendance-system/internal/tracing"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Options configures the S3-compatible driver (AWS S3, MinIO, dll)
+type S3Options struct {
+	Endpoint  string // host:port tanpa scheme, contoh "localhost:9000" atau "s3.ap-southeast-1.amazonaws.com"
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+	Prefix    string // Optional, semua key disimpan di bawah prefix ini
+}
+
+// S3 stores objects in an S3-compatible bucket
+type S3 struct {
+	client *minio.Client
+	bucket string
+	region string
+	prefix string
+}
+
+// NewS3 creates an S3-compatible storage, belum ada koneksi sampai request pertama
+func NewS3(opts S3Options) (*S3, error) {
+	if opts.Endpoint == "" || opts.Bucket == "" {
+		return nil, fmt.Errorf("S3 endpoint and bucket are required")
+	}
+
+	// Transport bawaan minio dibungkus supaya setiap request ke S3 jadi span di trace request
+	transport, err := minio.DefaultTransport(opts.UseSSL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 transport: %w", err)
+	}
+	client, err := minio.New(opts.Endpoint, &minio.Options{
+		Creds:     credentials.NewStaticV4(opts.AccessKey, opts.SecretKey, ""),
+		Secure:    opts.UseSSL,
+		Region:    opts.Region,
+		Transport: tracing.Transport(transport),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client: %w", err)
+	}
+
+	return &S3{
+		client: client,
+		bucket: opts.Bucket,
+		region: opts.Region,
+		prefix: strings.Trim(opts.Prefix, "/"),
+	}, nil
+}
+
+// Driver returns the driver name
+func (s *S3) Driver() string {
+	return DriverS3
+}
+
+// Init creates the bucket if it does not exist (berguna untuk MinIO lokal)
+func (s *S3) Init(ctx context.Context) error {
+	exists, err := s.client.BucketExists(ctx, s.bucket)
+	if err != nil {
+		return fmt.Errorf("failed to check bucket %s: %w", s.bucket, err)
+	}
+	if exists {
+		return nil
+	}
+	if err := s.client.MakeBucket(ctx, s.bucket, minio.MakeBucketOptions{Region: s.region}); err != nil {
+		return fmt.Errorf("failed to create bucket %s: %w", s.bucket, err)
+	}
+	return nil
+}
+
+// Put uploads an object, size -1 kalau tidak diketahui
+func (s *S3) Put(ctx context.Context, key string, src io.Reader, size int64, contentType string) error {
+	objectName, err := s.objectName(key)
+	if err != nil {
+		return err
+	}
+	_, err = s.client.PutObject(ctx, s.bucket, objectName, src, size, minio.PutObjectOptions{ContentType: contentType})
+	if err != nil {
+		return fmt.Errorf("failed to upload object: %w", err)
+	}
+	return nil
+}
+
+// Get opens an object for reading
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	objectName, err := s.objectName(key)
+	if err != nil {
+		return nil, err
+	}
+	object, err := s.client.GetObject(ctx, s.bucket, objectName, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, mapS3Error(err)
+	}
+	// GetObject lazy, Stat dipanggil supaya object yang tidak ada langsung jadi ErrNotFound
+	if _, err := object.Stat(); err != nil {
+		object.Close()
+		return nil, mapS3Error(err)
+	}
+	return object, nil
+}
+
+// Delete removes an object
+func (s *S3) Delete(ctx context.Context, key string) error {
+	objectName, err := s.objectName(key)
+	if err != nil {
+		return err
+	}
+	if err := s.client.RemoveObject(ctx, s.bucket, objectName, minio.RemoveObjectOptions{}); err != nil {
+		if err = mapS3Error(err); !errors.Is(err, ErrNotFound) {
+			return fmt.Errorf("failed to delete object: %w", err)
+		}
+	}
+	return nil
+}
+
+// Stat returns object metadata
+func (s *S3) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	objectName, err := s.objectName(key)
+	if err != nil {
+		return nil, err
+	}
+	info, err := s.client.StatObject(ctx, s.bucket, objectName, minio.StatObjectOptions{})
+	if err != nil {
+		return nil, mapS3Error(err)
+	}
+	return &ObjectInfo{
+		Key:          NormalizeKey("", key),
+		Size:         info.Size,
+		ContentType:  info.ContentType,
+		LastModified: info.LastModified,
+	}, nil
+}
+
+// SignedURL returns a presigned GET URL
+func (s *S3) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	objectName, err := s.objectName(key)
+	if err != nil {
+		return "", err
+	}
+	signed, err := s.client.PresignedGetObject(ctx, s.bucket, objectName, ttl, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign URL: %w", err)
+	}
+	return signed.String(), nil
+}
+
+// objectName returns the bucket object name of a key
+func (s *S3) objectName(key string) (string, error) {
+	normalized := NormalizeKey("", key)
+	if normalized == "" {
+		return "", ErrInvalidKey
+	}
+	if s.prefix == "" {
+		return normalized, nil
+	}
+	return path.Join(s.prefix, normalized), nil
+}
+
+// mapS3Error converts "no such key" responses to ErrNotFound
+func mapS3Error(err error) error {
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return ErrNotFound
+	}
+	return err
+}
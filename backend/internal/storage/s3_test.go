@@ -0,0 +1,269 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testAccessKey = "test-access-key"
+	testSecretKey = "test-secret-key"
+)
+
+// fakeObject is one object stored by fakeS3
+type fakeObject struct {
+	data        []byte
+	contentType string
+	modified    time.Time
+}
+
+// fakeS3 is a minimal path-style S3 API (bucket dan object) untuk test driver tanpa MinIO
+// Signature tidak diverifikasi, hanya access key di header Authorization / query presigned URL
+type fakeS3 struct {
+	mu      sync.Mutex
+	buckets map[string]map[string]fakeObject
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	credential := r.Header.Get("Authorization")
+	if credential == "" {
+		credential = "Credential=" + r.URL.Query().Get("X-Amz-Credential")
+	}
+	if !strings.Contains(credential, "Credential="+testAccessKey+"/") {
+		writeS3Error(w, http.StatusForbidden, "AccessDenied")
+		return
+	}
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	objects, exists := f.buckets[bucket]
+
+	switch {
+	case key == "" && r.Method == http.MethodHead:
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case key == "" && r.Method == http.MethodPut:
+		f.buckets[bucket] = make(map[string]fakeObject)
+	case !exists:
+		writeS3Error(w, http.StatusNotFound, "NoSuchBucket")
+	case r.Method == http.MethodPut:
+		data, err := readS3Body(r)
+		if err != nil {
+			writeS3Error(w, http.StatusBadRequest, "IncompleteBody")
+			return
+		}
+		objects[key] = fakeObject{data: data, contentType: r.Header.Get("Content-Type"), modified: time.Now().UTC()}
+		sum := md5.Sum(data)
+		w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:])+`"`)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		object, ok := objects[key]
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		sum := md5.Sum(object.data)
+		w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:])+`"`)
+		w.Header().Set("Content-Type", object.contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(object.data)))
+		w.Header().Set("Last-Modified", object.modified.Format(http.TimeFormat))
+		if r.Method == http.MethodGet {
+			w.Write(object.data)
+		}
+	case r.Method == http.MethodDelete:
+		// Seperti S3, menghapus object yang tidak ada tetap 204
+		delete(objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeS3Error(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+// readS3Body decodes a PUT body, minio-go memakai aws-chunked (streaming signature) di koneksi tanpa TLS
+func readS3Body(r *http.Request) ([]byte, error) {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return io.ReadAll(r.Body)
+	}
+	var data bytes.Buffer
+	reader := bufio.NewReader(r.Body)
+	for {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		sizeHex, _, _ := strings.Cut(strings.TrimSpace(header), ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return data.Bytes(), nil
+		}
+		if _, err := io.CopyN(&data, reader, size); err != nil {
+			return nil, err
+		}
+		if _, err := reader.Discard(2); err != nil { // \r\n setelah data chunk
+			return nil, err
+		}
+	}
+}
+
+// writeS3Error writes an S3 XML error, body kosong untuk HEAD seperti S3 asli
+func writeS3Error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>%s</Code><Message>%s</Message><RequestId>fake</RequestId></Error>`, code, code)
+}
+
+// newTestS3 starts a fake S3 server and returns a driver pointing at it
+func newTestS3(t *testing.T, accessKey string) (*S3, *fakeS3, *httptest.Server) {
+	t.Helper()
+	fake := &fakeS3{buckets: make(map[string]map[string]fakeObject)}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	store, err := NewS3(S3Options{
+		Endpoint:  strings.TrimPrefix(server.URL, "http://"),
+		Region:    "us-east-1",
+		Bucket:    "attendance",
+		AccessKey: accessKey,
+		SecretKey: testSecretKey,
+		Prefix:    "/tenant-a/",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store, fake, server
+}
+
+func TestS3PutGetDelete(t *testing.T) {
+	ctx := context.Background()
+	store, fake, _ := newTestS3(t, testAccessKey)
+
+	// Bucket belum ada: Init membuatnya, Init kedua tidak error
+	for i := 0; i < 2; i++ {
+		if err := Init(ctx, store); err != nil {
+			t.Fatalf("init %d: %v", i, err)
+		}
+	}
+
+	content := []byte("selfie bytes")
+	if err := store.Put(ctx, "selfies/a.jpg", bytes.NewReader(content), int64(len(content)), "image/jpeg"); err != nil {
+		t.Fatal(err)
+	}
+	// Key disimpan di bawah S3_PREFIX
+	if _, ok := fake.buckets["attendance"]["tenant-a/selfies/a.jpg"]; !ok {
+		t.Fatalf("object not stored under the prefix: %v", fake.buckets["attendance"])
+	}
+
+	got, err := ReadAll(ctx, store, "selfies/a.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Fatalf("content %q, want %q", got, content)
+	}
+
+	info, err := store.Stat(ctx, "selfies/a.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Key != "selfies/a.jpg" || info.Size != int64(len(content)) || info.ContentType != "image/jpeg" {
+		t.Fatalf("stat %+v", info)
+	}
+
+	if err := store.Delete(ctx, "selfies/a.jpg"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(ctx, "selfies/a.jpg"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("get after delete: err %v, want %v", err, ErrNotFound)
+	}
+	// Object yang sudah tidak ada bukan error
+	if err := store.Delete(ctx, "selfies/a.jpg"); err != nil {
+		t.Fatalf("second delete: %v", err)
+	}
+}
+
+func TestS3ErrorMapping(t *testing.T) {
+	ctx := context.Background()
+	store, _, _ := newTestS3(t, testAccessKey)
+	if err := Init(ctx, store); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Get(ctx, "missing.jpg"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("get: err %v, want %v", err, ErrNotFound)
+	}
+	if _, err := store.Stat(ctx, "missing.jpg"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("stat: err %v, want %v", err, ErrNotFound)
+	}
+	for _, key := range []string{"", "/", ".."} {
+		if err := store.Put(ctx, key, strings.NewReader("x"), 1, "text/plain"); !errors.Is(err, ErrInvalidKey) {
+			t.Fatalf("put %q: err %v, want %v", key, err, ErrInvalidKey)
+		}
+	}
+
+	// Error lain (credential salah) tidak boleh dianggap object tidak ada
+	denied, _, _ := newTestS3(t, "wrong-access-key")
+	if _, err := denied.Get(ctx, "missing.jpg"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Fatalf("access denied: err %v, want a non-not-found error", err)
+	}
+	if err := denied.Delete(ctx, "missing.jpg"); err == nil {
+		t.Fatal("access denied: delete succeeded")
+	}
+}
+
+func TestS3SignedURL(t *testing.T) {
+	ctx := context.Background()
+	store, _, server := newTestS3(t, testAccessKey)
+	if err := Init(ctx, store); err != nil {
+		t.Fatal(err)
+	}
+	content := []byte("leave attachment")
+	if err := store.Put(ctx, "leave/doc.pdf", bytes.NewReader(content), int64(len(content)), "application/pdf"); err != nil {
+		t.Fatal(err)
+	}
+
+	signed, err := store.SignedURL(ctx, "leave/doc.pdf", 5*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := url.Parse(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := parsed.Query()
+	if parsed.Host != strings.TrimPrefix(server.URL, "http://") || parsed.Path != "/attendance/tenant-a/leave/doc.pdf" {
+		t.Fatalf("signed URL %s points at the wrong object", signed)
+	}
+	if query.Get("X-Amz-Expires") != "300" || query.Get("X-Amz-Signature") == "" ||
+		!strings.HasPrefix(query.Get("X-Amz-Credential"), testAccessKey+"/") {
+		t.Fatalf("signed URL %s is missing presign parameters", signed)
+	}
+
+	// URL bisa dipakai langsung tanpa header Authorization
+	resp, err := http.Get(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	got, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || !bytes.Equal(got, content) {
+		t.Fatalf("signed URL: status %d, body %q", resp.StatusCode, got)
+	}
+}
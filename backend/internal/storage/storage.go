@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Storage driver names, dipilih lewat STORAGE_DRIVER
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+// ErrNotFound is returned when an object does not exist
+var ErrNotFound = errors.New("object not found")
+
+// ErrInvalidKey is returned for empty keys or keys escaping the storage root
+var ErrInvalidKey = errors.New("invalid object key")
+
+// ErrSignedURLNotSupported is returned by drivers that cannot issue direct download URLs
+var ErrSignedURLNotSupported = errors.New("signed URLs are not supported by this storage driver")
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	LastModified time.Time
+}
+
+// Storage stores uploaded files (foto wajah, selfie, lampiran cuti)
+// Key selalu memakai "/" sebagai separator, misalnya "leave/20240101_120000_ab12cd34.pdf"
+type Storage interface {
+	// Driver returns the driver name (local / s3)
+	Driver() string
+
+	// Put writes an object, object lama dengan key yang sama ditimpa
+	Put(ctx context.Context, key string, src io.Reader, size int64, contentType string) error
+
+	// Get opens an object for reading, caller wajib Close
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Delete removes an object, object yang tidak ada bukan error
+	Delete(ctx context.Context, key string) error
+
+	// Stat returns object metadata or ErrNotFound
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+
+	// SignedURL returns a time-limited direct download URL
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
+
+// Initializer is implemented by drivers that need setup before first use
+type Initializer interface {
+	Init(ctx context.Context) error
+}
+
+// Init prepares a storage backend (buat directory / bucket) kalau driver membutuhkannya
+func Init(ctx context.Context, store Storage) error {
+	if initializer, ok := store.(Initializer); ok {
+		return initializer.Init(ctx)
+	}
+	return nil
+}
+
+// ReadAll reads a whole object into memory
+func ReadAll(ctx context.Context, store Storage, key string) ([]byte, error) {
+	reader, err := store.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// NormalizeKey converts a stored path into an object key
+// Record lama menyimpan path lengkap termasuk UPLOAD_PATH (misalnya "uploads/abc.jpg"), prefix itu dibuang
+func NormalizeKey(uploadDir, stored string) string {
+	stored = filepath.ToSlash(stored)
+	root := path.Clean(filepath.ToSlash(uploadDir))
+	if root != "." && root != "/" {
+		if rest, ok := strings.CutPrefix(path.Clean(stored), root+"/"); ok {
+			return rest
+		}
+	}
+	// Clean dengan root "/" membuang segment ".." supaya key tidak keluar dari storage root
+	return strings.TrimPrefix(path.Clean("/"+stored), "/")
+}
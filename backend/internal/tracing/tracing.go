@@ -0,0 +1,114 @@
+package tracing
+
+import (
+	"attendance-system/internal/logging"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Exporters for TRACING_EXPORTER
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"   // OTLP/HTTP ke collector (Jaeger, Tempo, vendor APM)
+	ExporterStdout = "stdout" // Span ditulis ke stdout, untuk debugging lokal
+)
+
+// tracerName is the instrumentation scope of every span
+const tracerName = "attendance-system"
+
+// Options configures the tracer provider
+type Options struct {
+	Exporter     string
+	OTLPEndpoint string            // URL collector, contoh http://localhost:4318 (http = tanpa TLS)
+	OTLPHeaders  map[string]string // Header tambahan, misalnya API key vendor
+	ServiceName  string
+	SampleRatio  float64 // 0-1, request dengan traceparent mengikuti keputusan parent
+}
+
+// Setup installs the global tracer provider and W3C trace context propagation
+// Exporter none tidak memasang provider, span jadi no-op tanpa overhead berarti
+// out adalah tujuan exporter stdout; shutdown wajib dipanggil saat server berhenti supaya span terakhir ikut terkirim
+func Setup(ctx context.Context, opts Options, out io.Writer) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if opts.Exporter == ExporterNone || opts.Exporter == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	var exporter sdktrace.SpanExporter
+	switch opts.Exporter {
+	case ExporterOTLP:
+		endpoint, err := url.Parse(opts.OTLPEndpoint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OTLP endpoint: %w", err)
+		}
+		options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(endpoint.Host), otlptracehttp.WithHeaders(opts.OTLPHeaders)}
+		if endpoint.Scheme == "http" {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		if endpoint.Path != "" && endpoint.Path != "/" {
+			options = append(options, otlptracehttp.WithURLPath(endpoint.Path))
+		}
+		exporter, err = otlptracehttp.New(ctx, options...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+		}
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(out), stdouttrace.WithPrettyPrint())
+		if err != nil {
+			return nil, fmt.Errorf("failed to create stdout exporter: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", opts.Exporter)
+	}
+
+	res, err := resource.Merge(resource.Default(),
+		resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(opts.ServiceName)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build tracing resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Tracer returns the tracer of the global provider, diambil setiap kali karena Setup bisa dipanggil belakangan
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// Start starts a span as child of the span in ctx
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End marks the span failed when err is set and ends it
+// Pesan error disamarkan sama seperti di log, error database bisa memuat email atau nomor telepon
+func End(span trace.Span, err error) {
+	if err != nil {
+		message := logging.Redact(err.Error())
+		span.AddEvent("exception", trace.WithAttributes(
+			semconv.ExceptionType(fmt.Sprintf("%T", err)),
+			semconv.ExceptionMessage(message),
+		))
+		span.SetStatus(codes.Error, message)
+	}
+	span.End()
+}
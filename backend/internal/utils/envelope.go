@@ -0,0 +1,281 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Envelope format
+// Binary: magic | keyIDLen(1) | keyID | wrappedKeyLen(2) | wrappedKey | nonce(12) | ciphertext
+// String (kolom database): "enc:v1:" + base64(binary)
+const (
+	envelopeMagic        = "AENV1"
+	envelopeStringPrefix = "enc:v1:"
+	envelopeKeySize      = 32 // AES-256
+)
+
+// ErrUnknownKey is returned when data was sealed with a master key that is not loaded
+var ErrUnknownKey = errors.New("data encrypted with unknown master key")
+
+// Envelope encrypts data with a fresh AES-256-GCM data key per record,
+// data key di-wrap dengan master key aktif. Master key lama hanya dipakai untuk decrypt
+// Key bisa diganti saat jalan (Replace, SIGHUP setelah rotate-keys -generate)
+type Envelope struct {
+	mu       sync.RWMutex
+	activeID string
+	keys     map[string]cipher.AEAD
+}
+
+// NewEnvelope creates an Envelope, keys[0] adalah master key aktif dan sisanya key lama
+func NewEnvelope(keys [][]byte) (*Envelope, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("at least one master key is required")
+	}
+
+	e := &Envelope{keys: make(map[string]cipher.AEAD, len(keys))}
+	for i, key := range keys {
+		if len(key) != envelopeKeySize {
+			return nil, fmt.Errorf("master key %d must be %d bytes, got %d", i+1, envelopeKeySize, len(key))
+		}
+		aead, err := newGCM(key)
+		if err != nil {
+			return nil, err
+		}
+		id := KeyID(key)
+		if i == 0 {
+			e.activeID = id
+		}
+		e.keys[id] = aead
+	}
+	return e, nil
+}
+
+// KeyID returns the fingerprint of a master key (16 hex karakter pertama SHA-256)
+func KeyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// ActiveKeyID returns the fingerprint of the key used for new data
+func (e *Envelope) ActiveKeyID() string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.activeID
+}
+
+// KeyIDs returns the fingerprints of all loaded keys, urut alfabet
+func (e *Envelope) KeyIDs() []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	ids := make([]string, 0, len(e.keys))
+	for id := range e.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Replace switches to the keys of next in place, semua service yang memegang Envelope ini ikut memakai key baru
+func (e *Envelope) Replace(next *Envelope) {
+	if next == nil || next == e {
+		return
+	}
+	next.mu.RLock()
+	activeID, keys := next.activeID, next.keys
+	next.mu.RUnlock()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.activeID, e.keys = activeID, keys
+}
+
+// active returns the active key ID and its cipher
+func (e *Envelope) active() (string, cipher.AEAD) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.activeID, e.keys[e.activeID]
+}
+
+// key returns the cipher of a loaded key
+func (e *Envelope) key(id string) (cipher.AEAD, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	aead, ok := e.keys[id]
+	return aead, ok
+}
+
+// Seal encrypts plaintext with a new data key
+func (e *Envelope) Seal(plaintext []byte) ([]byte, error) {
+	dataKey := make([]byte, envelopeKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	activeID, master := e.active()
+	header := envelopeHeader(activeID)
+	wrappedKey, err := sealGCM(master, dataKey, header)
+	if err != nil {
+		return nil, err
+	}
+	dataAEAD, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := sealGCM(dataAEAD, plaintext, header)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(header)+2+len(wrappedKey)+len(ciphertext))
+	out = append(out, header...)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(wrappedKey)))
+	out = append(out, wrappedKey...)
+	return append(out, ciphertext...), nil
+}
+
+// Open decrypts sealed data, data plaintext lama (belum terenkripsi) dikembalikan apa adanya
+func (e *Envelope) Open(data []byte) ([]byte, error) {
+	if !IsSealed(data) {
+		return data, nil
+	}
+
+	keyID, rest, err := parseEnvelopeHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	master, ok := e.key(keyID)
+	if !ok {
+		return nil, fmt.Errorf("%w %s", ErrUnknownKey, keyID)
+	}
+	header := data[:len(data)-len(rest)]
+
+	if len(rest) < 2 {
+		return nil, fmt.Errorf("corrupted envelope")
+	}
+	wrappedLen := int(binary.BigEndian.Uint16(rest))
+	rest = rest[2:]
+	if len(rest) < wrappedLen {
+		return nil, fmt.Errorf("corrupted envelope")
+	}
+
+	dataKey, err := openGCM(master, rest[:wrappedLen], header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	dataAEAD, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := openGCM(dataAEAD, rest[wrappedLen:], header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data: %w", err)
+	}
+	return plaintext, nil
+}
+
+// SealString encrypts a string for storage in a text column
+func (e *Envelope) SealString(plaintext string) (string, error) {
+	sealed, err := e.Seal([]byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	return envelopeStringPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// OpenString decrypts a value from SealString, nilai plaintext lama dikembalikan apa adanya
+func (e *Envelope) OpenString(value string) (string, error) {
+	encoded, ok := strings.CutPrefix(value, envelopeStringPrefix)
+	if !ok {
+		return value, nil
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("corrupted envelope: %w", err)
+	}
+	// Prefix enc:v1: tanpa magic bukan data lama, jangan kembalikan hasil decode base64 sebagai plaintext
+	if !IsSealed(sealed) {
+		return "", fmt.Errorf("corrupted envelope: missing header")
+	}
+	plaintext, err := e.Open(sealed)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// NeedsRotation reports whether data is plaintext or sealed with a non-active key
+func (e *Envelope) NeedsRotation(data []byte) bool {
+	if !IsSealed(data) {
+		return true
+	}
+	keyID, _, err := parseEnvelopeHeader(data)
+	return err != nil || keyID != e.ActiveKeyID()
+}
+
+// NeedsRotationString is NeedsRotation for values from SealString
+func (e *Envelope) NeedsRotationString(value string) bool {
+	encoded, ok := strings.CutPrefix(value, envelopeStringPrefix)
+	if !ok {
+		return true
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	return err != nil || e.NeedsRotation(sealed)
+}
+
+// IsSealed reports whether data starts with the envelope magic
+func IsSealed(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(envelopeMagic))
+}
+
+// envelopeHeader returns magic and the key ID, juga dipakai sebagai AAD
+func envelopeHeader(keyID string) []byte {
+	header := append([]byte(envelopeMagic), byte(len(keyID)))
+	return append(header, keyID...)
+}
+
+// parseEnvelopeHeader returns key ID and the bytes after the header
+func parseEnvelopeHeader(data []byte) (string, []byte, error) {
+	rest := data[len(envelopeMagic):]
+	if len(rest) < 1 || len(rest) < 1+int(rest[0]) {
+		return "", nil, fmt.Errorf("corrupted envelope")
+	}
+	idLen := int(rest[0])
+	return string(rest[1 : 1+idLen]), rest[1+idLen:], nil
+}
+
+// newGCM creates an AES-GCM cipher for key
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealGCM encrypts with a random nonce prepended to the ciphertext
+func sealGCM(aead cipher.AEAD, plaintext, aad []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+// openGCM decrypts output of sealGCM
+func openGCM(aead cipher.AEAD, data, aad []byte) ([]byte, error) {
+	if len(data) < aead.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	return aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], aad)
+}
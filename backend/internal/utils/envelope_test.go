@@ -0,0 +1,176 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// testMasterKey returns a deterministic 32-byte master key
+func testMasterKey(seed byte) []byte {
+	return bytes.Repeat([]byte{seed}, envelopeKeySize)
+}
+
+// newTestEnvelope creates an Envelope, keys[0] aktif
+func newTestEnvelope(t *testing.T, keys ...[]byte) *Envelope {
+	t.Helper()
+	envelope, err := NewEnvelope(keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return envelope
+}
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+	envelope := newTestEnvelope(t, testMasterKey(1))
+
+	for _, plaintext := range []string{"", "[0.12,-0.5,0.33]", "enc:v1:looks like an envelope", strings.Repeat("x", 70000)} {
+		sealed, err := envelope.Seal([]byte(plaintext))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !IsSealed(sealed) || (plaintext != "" && bytes.Contains(sealed, []byte(plaintext))) {
+			t.Fatalf("sealed data is not an envelope or leaks the plaintext")
+		}
+		opened, err := envelope.Open(sealed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(opened) != plaintext {
+			t.Fatalf("open %q, want %q", opened, plaintext)
+		}
+
+		value, err := envelope.SealString(plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(value, envelopeStringPrefix) {
+			t.Fatalf("sealed string %q is missing the %s prefix", value, envelopeStringPrefix)
+		}
+		got, err := envelope.OpenString(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != plaintext {
+			t.Fatalf("open string %q, want %q", got, plaintext)
+		}
+	}
+
+	// Data key dan nonce baru setiap Seal
+	first, _ := envelope.SealString("same")
+	second, _ := envelope.SealString("same")
+	if first == second {
+		t.Fatal("sealing the same plaintext twice produced identical output")
+	}
+}
+
+func TestEnvelopeTamper(t *testing.T) {
+	envelope := newTestEnvelope(t, testMasterKey(1))
+	sealed, err := envelope.Seal([]byte("face descriptor"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := len(envelopeHeader(envelope.ActiveKeyID()))
+
+	tests := []struct {
+		name   string
+		mutate func(data []byte) []byte
+	}{
+		{"flipped GCM tag", func(data []byte) []byte { data[len(data)-1] ^= 0x01; return data }},
+		{"flipped ciphertext", func(data []byte) []byte { data[len(data)-20] ^= 0x80; return data }},
+		{"flipped wrapped key", func(data []byte) []byte { data[header+2+15] ^= 0x01; return data }},
+		{"truncated tag", func(data []byte) []byte { return data[:len(data)-4] }},
+		{"truncated after header", func(data []byte) []byte { return data[:header+1] }},
+		{"wrapped key length past the end", func(data []byte) []byte { data[header] = 0xff; return data }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := tt.mutate(append([]byte(nil), sealed...))
+			if plaintext, err := envelope.Open(data); err == nil {
+				t.Fatalf("tampered envelope opened as %q", plaintext)
+			}
+			value := envelopeStringPrefix + base64.StdEncoding.EncodeToString(data)
+			if _, err := envelope.OpenString(value); err == nil {
+				t.Fatal("tampered sealed string opened")
+			}
+		})
+	}
+}
+
+func TestEnvelopeWrongKey(t *testing.T) {
+	oldKey, newKey, otherKey := testMasterKey(1), testMasterKey(2), testMasterKey(3)
+	old := newTestEnvelope(t, oldKey)
+	value, err := old.SealString("descriptor")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := newTestEnvelope(t, otherKey).OpenString(value); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("unknown key: err %v, want %v", err, ErrUnknownKey)
+	}
+
+	// Key dengan ID sama tapi isi berbeda: header cocok, unwrap gagal
+	imposter := newTestEnvelope(t, otherKey)
+	imposter.keys[old.ActiveKeyID()] = imposter.keys[imposter.ActiveKeyID()]
+	if _, err := imposter.OpenString(value); err == nil || errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("wrong key material: err %v, want an unwrap error", err)
+	}
+
+	// Setelah rotasi key lama tetap bisa decrypt, data baru pakai key aktif
+	rotated := newTestEnvelope(t, newKey, oldKey)
+	if got, err := rotated.OpenString(value); err != nil || got != "descriptor" {
+		t.Fatalf("old data after rotation: %q, %v", got, err)
+	}
+	if !rotated.NeedsRotationString(value) {
+		t.Fatal("data sealed with the old key does not need rotation")
+	}
+	resealed, _ := rotated.SealString("descriptor")
+	if rotated.NeedsRotationString(resealed) {
+		t.Fatal("data sealed with the active key needs rotation")
+	}
+	if _, err := old.OpenString(resealed); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("new data with the old key only: err %v, want %v", err, ErrUnknownKey)
+	}
+
+	if _, err := NewEnvelope([][]byte{[]byte("short")}); err == nil {
+		t.Fatal("short master key accepted")
+	}
+	if _, err := NewEnvelope(nil); err == nil {
+		t.Fatal("no master key accepted")
+	}
+}
+
+func TestEnvelopePlaintextValues(t *testing.T) {
+	envelope := newTestEnvelope(t, testMasterKey(1))
+
+	// Data lama yang belum terenkripsi dikembalikan apa adanya dan perlu dirotasi
+	for _, legacy := range []string{"", "[0.12,-0.5,0.33]", "ENC:V1:not the prefix", "enc:v2:" + base64.StdEncoding.EncodeToString([]byte("x"))} {
+		got, err := envelope.OpenString(legacy)
+		if err != nil || got != legacy {
+			t.Fatalf("legacy %q: got %q, %v", legacy, got, err)
+		}
+		if !envelope.NeedsRotationString(legacy) {
+			t.Fatalf("legacy %q does not need rotation", legacy)
+		}
+	}
+	if got, err := envelope.Open([]byte("raw bytes")); err != nil || string(got) != "raw bytes" {
+		t.Fatalf("legacy bytes: got %q, %v", got, err)
+	}
+
+	// Nilai ber-prefix enc:v1: yang bukan envelope tidak boleh dikembalikan sebagai hasil decode base64
+	for _, value := range []string{
+		envelopeStringPrefix,
+		envelopeStringPrefix + "not base64!",
+		envelopeStringPrefix + base64.StdEncoding.EncodeToString([]byte("plain text")),
+		envelopeStringPrefix + base64.StdEncoding.EncodeToString([]byte(envelopeMagic)),
+	} {
+		if got, err := envelope.OpenString(value); err == nil {
+			t.Fatalf("%q opened as %q, want a corrupted envelope error", value, got)
+		}
+		if !envelope.NeedsRotationString(value) {
+			t.Fatalf("%q does not need rotation", value)
+		}
+	}
+}
@@ -1,68 +1,145 @@
 package utils
 
 import (
+	"attendance-system/internal/storage"
+	"attendance-system/internal/tracing"
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"mime"
 	"mime/multipart"
-	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-// SaveUploadedFile saves uploaded file to disk dengan unique filename
-func SaveUploadedFile(file *multipart.FileHeader, uploadDir string) (string, error) {
-	// Validate file extension
+// SaveUploadedFile saves uploaded file to storage dengan unique filename dan return key-nya
+// envelope nil = disimpan plaintext
+func SaveUploadedFile(ctx context.Context, store storage.Storage, file *multipart.FileHeader, prefix string, envelope *Envelope) (string, error) {
+	// Open uploaded file
+	src, err := file.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to open uploaded file: %w", err)
+	}
+	defer src.Close()
+
+	return SaveFile(ctx, store, src, file.Filename, prefix, envelope)
+}
+
+// SaveAttachment saves a supporting document (surat dokter, dll): image atau PDF
+func SaveAttachment(ctx context.Context, store storage.Storage, file *multipart.FileHeader, prefix string) (string, error) {
 	ext := strings.ToLower(filepath.Ext(file.Filename))
-	if ext != ".jpg" && ext != ".jpeg" && ext != ".png" {
-		return "", fmt.Errorf("invalid file type. Only JPG, JPEG, and PNG are allowed")
+	if !IsAllowedImageExt(ext) && ext != ".pdf" {
+		return "", fmt.Errorf("invalid file type. Only JPG, JPEG, PNG and PDF are allowed")
 	}
 
-	// Create upload directory if not exists
-	if err := os.MkdirAll(uploadDir, os.ModePerm); err != nil {
-		return "", fmt.Errorf("failed to create upload directory: %w", err)
+	src, err := file.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to open uploaded file: %w", err)
+	}
+	defer src.Close()
+
+	return writeUniqueFile(ctx, store, src, ext, prefix, nil)
+}
+
+// SaveFile writes content from reader to storage dengan unique filename
+// originalName hanya dipakai untuk validasi dan extension
+func SaveFile(ctx context.Context, store storage.Storage, src io.Reader, originalName, prefix string, envelope *Envelope) (string, error) {
+	// Validate file extension
+	ext := strings.ToLower(filepath.Ext(originalName))
+	if !IsAllowedImageExt(ext) {
+		return "", fmt.Errorf("invalid file type. Only JPG, JPEG, and PNG are allowed")
 	}
 
+	return writeUniqueFile(ctx, store, src, ext, prefix, envelope)
+}
+
+// writeUniqueFile stores src as prefix/timestamp_uuid.ext, terenkripsi kalau envelope di-set
+func writeUniqueFile(ctx context.Context, store storage.Storage, src io.Reader, ext, prefix string, envelope *Envelope) (string, error) {
 	// Generate unique filename: timestamp_uuid.ext
 	timestamp := time.Now().Format("20060102_150405")
 	uniqueID := uuid.New().String()[:8]
-	filename := fmt.Sprintf("%s_%s%s", timestamp, uniqueID, ext)
-	
-	filepath := filepath.Join(uploadDir, filename)
+	key := path.Join(prefix, fmt.Sprintf("%s_%s%s", timestamp, uniqueID, ext))
 
-	// Open uploaded file
-	src, err := file.Open()
+	content, err := io.ReadAll(src)
 	if err != nil {
-		return "", fmt.Errorf("failed to open uploaded file: %w", err)
+		return "", fmt.Errorf("failed to read file: %w", err)
 	}
-	defer src.Close()
+	if err := WriteFile(ctx, store, key, content, envelope); err != nil {
+		return "", err
+	}
+	return key, nil
+}
 
-	// Create destination file
-	dst, err := os.Create(filepath)
-	if err != nil {
-		return "", fmt.Errorf("failed to create destination file: %w", err)
+// WriteFile stores content under key, terenkripsi kalau envelope di-set
+func WriteFile(ctx context.Context, store storage.Storage, key string, content []byte, envelope *Envelope) (err error) {
+	ctx, span := tracing.Start(ctx, "storage.write",
+		attribute.String("storage.driver", store.Driver()),
+		attribute.String("storage.key", key),
+		attribute.Int("storage.size", len(content)),
+		attribute.Bool("storage.encrypted", envelope != nil),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if envelope != nil {
+		sealed, err := envelope.Seal(content)
+		if err != nil {
+			return err
+		}
+		// Isi terenkripsi, content type asli tidak ikut disimpan di storage
+		content, contentType = sealed, "application/octet-stream"
 	}
-	defer dst.Close()
 
-	// Copy file content
-	if _, err := dst.ReadFrom(src); err != nil {
-		return "", fmt.Errorf("failed to save file: %w", err)
+	if err := store.Put(ctx, key, bytes.NewReader(content), int64(len(content)), contentType); err != nil {
+		return fmt.Errorf("failed to save file: %w", err)
 	}
+	return nil
+}
+
+// ReadFile reads a file from storage dan decrypt kalau terenkripsi, file plaintext lama dibaca apa adanya
+func ReadFile(ctx context.Context, store storage.Storage, key string, envelope *Envelope) (data []byte, err error) {
+	ctx, span := tracing.Start(ctx, "storage.read",
+		attribute.String("storage.driver", store.Driver()),
+		attribute.String("storage.key", key),
+	)
+	defer func() { tracing.End(span, err) }()
 
-	return filepath, nil
+	data, err = storage.ReadAll(ctx, store, key)
+	if err != nil || envelope == nil {
+		return data, err
+	}
+	return envelope.Open(data)
+}
+
+// IsAllowedImageExt checks if extension (with dot) is a supported image type
+func IsAllowedImageExt(ext string) bool {
+	ext = strings.ToLower(ext)
+	return ext == ".jpg" || ext == ".jpeg" || ext == ".png"
 }
 
-// DeleteFile deletes file from disk
-func DeleteFile(filepath string) error {
-	if err := os.Remove(filepath); err != nil && !os.IsNotExist(err) {
+// DeleteFile deletes file from storage
+func DeleteFile(ctx context.Context, store storage.Storage, key string) (err error) {
+	ctx, span := tracing.Start(ctx, "storage.delete",
+		attribute.String("storage.driver", store.Driver()),
+		attribute.String("storage.key", key),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	if err := store.Delete(ctx, key); err != nil {
 		return fmt.Errorf("failed to delete file: %w", err)
 	}
 	return nil
 }
 
-// FileExists checks if file exists
-func FileExists(filepath string) bool {
-	_, err := os.Stat(filepath)
-	return !os.IsNotExist(err)
+// FileExists checks if file exists in storage
+func FileExists(ctx context.Context, store storage.Storage, key string) bool {
+	_, err := store.Stat(ctx, key)
+	return !errors.Is(err, storage.ErrNotFound)
 }
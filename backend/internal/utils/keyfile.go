@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GenerateMasterKey returns a new random AES-256 master key
+func GenerateMasterKey() ([]byte, error) {
+	key := make([]byte, envelopeKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate master key: %w", err)
+	}
+	return key, nil
+}
+
+// DecodeMasterKey decodes a base64 master key
+func DecodeMasterKey(value string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil {
+		return nil, fmt.Errorf("master key is not valid base64: %w", err)
+	}
+	if len(key) != envelopeKeySize {
+		return nil, fmt.Errorf("master key must be %d bytes, got %d", envelopeKeySize, len(key))
+	}
+	return key, nil
+}
+
+// ReadKeyFile reads master keys, satu key base64 per baris, baris pertama = key aktif
+// Baris kosong dan baris diawali "#" diabaikan
+func ReadKeyFile(path string) ([][]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys [][]byte
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		key, err := DecodeMasterKey(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", path, line, err)
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%s contains no master key", path)
+	}
+	return keys, nil
+}
+
+// WriteKeyFile writes master keys with owner-only permissions, keys[0] = key aktif
+func WriteKeyFile(path string, keys [][]byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create key directory: %w", err)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("# Master key biometrik, baris pertama = key aktif, sisanya key lama untuk decrypt\n")
+	for _, key := range keys {
+		buf.WriteString(base64.StdEncoding.EncodeToString(key))
+		buf.WriteByte('\n')
+	}
+
+	if err := writeFileAtomic(path, buf.Bytes()); err != nil {
+		return err
+	}
+	return os.Chmod(path, 0o600)
+}
+
+// writeFileAtomic writes to a temp file in the same directory then renames it over path
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-"+filepath.Base(path)+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close file: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,272 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// Pagination limits
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 200
+)
+
+// ErrInvalidCursor is returned for a cursor that cannot be decoded, handler menjawab 400
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Sort field value kinds, dipakai untuk decode nilai cursor dengan tipe yang benar
+const (
+	SortKindTime   = "time"
+	SortKindString = "string"
+	SortKindNumber = "number"
+)
+
+// SortField maps a public sort key to a database column
+type SortField struct {
+	Column string // Qualified column, contoh: attendances.check_in_time
+	Kind   string
+}
+
+// PageMeta is the pagination metadata returned in APIResponse
+type PageMeta struct {
+	Total      int64  `json:"total"`
+	Limit      int    `json:"limit"`
+	Sort       string `json:"sort"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// PageParams holds parsed cursor pagination and sorting parameters
+type PageParams struct {
+	Limit    int
+	SortKey  string
+	SortDesc bool
+	field    SortField
+	idColumn string
+	cursor   *pageCursor
+}
+
+// pageCursor is the decoded keyset position (sort value + id sebagai tie-breaker)
+type pageCursor struct {
+	Sort  string          `json:"s"`
+	Value json.RawMessage `json:"v"`
+	ID    uint            `json:"id"`
+}
+
+// ParsePageParams reads limit, cursor and sort query params
+// Format sort: "field" (ascending) atau "-field" (descending)
+func ParsePageParams(c *fiber.Ctx, fields map[string]SortField, idColumn, defaultSort string) (PageParams, error) {
+	params := PageParams{idColumn: idColumn}
+
+	// Limit
+	params.Limit = c.QueryInt("limit", DefaultPageLimit)
+	if params.Limit < 1 {
+		params.Limit = DefaultPageLimit
+	}
+	if params.Limit > MaxPageLimit {
+		params.Limit = MaxPageLimit
+	}
+
+	// Sort
+	sortExpr := c.Query("sort", defaultSort)
+	params.SortDesc = strings.HasPrefix(sortExpr, "-")
+	params.SortKey = strings.TrimPrefix(sortExpr, "-")
+	field, ok := fields[params.SortKey]
+	if !ok {
+		allowed := make([]string, 0, len(fields))
+		for key := range fields {
+			allowed = append(allowed, key)
+		}
+		sort.Strings(allowed)
+		return params, fmt.Errorf("invalid sort field %q, allowed: %s", params.SortKey, strings.Join(allowed, ", "))
+	}
+	params.field = field
+
+	// Cursor
+	if raw := c.Query("cursor"); raw != "" {
+		decoded, err := base64.RawURLEncoding.DecodeString(raw)
+		if err != nil {
+			return params, ErrInvalidCursor
+		}
+		var cursor pageCursor
+		if err := json.Unmarshal(decoded, &cursor); err != nil {
+			return params, ErrInvalidCursor
+		}
+		if cursor.Sort != sortExpr {
+			return params, fmt.Errorf("cursor does not match sort %q", sortExpr)
+		}
+		params.cursor = &cursor
+	}
+
+	return params, nil
+}
+
+// Sort returns the sort expression as given in the query ("-field" for descending)
+func (p PageParams) Sort() string {
+	if p.SortDesc {
+		return "-" + p.SortKey
+	}
+	return p.SortKey
+}
+
+// Apply adds keyset condition, ordering and limit (+1 untuk deteksi halaman berikutnya)
+func (p PageParams) Apply(query *gorm.DB) (*gorm.DB, error) {
+	direction, op := "ASC", ">"
+	if p.SortDesc {
+		direction, op = "DESC", "<"
+	}
+
+	if p.cursor != nil {
+		value, err := p.decodeValue(p.cursor.Value)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(
+			fmt.Sprintf("((%s %s ?) OR (%s = ? AND %s %s ?))", p.field.Column, op, p.field.Column, p.idColumn, op),
+			value, value, p.cursor.ID,
+		)
+	}
+
+	return query.
+		Order(fmt.Sprintf("%s %s, %s %s", p.field.Column, direction, p.idColumn, direction)).
+		Limit(p.Limit + 1), nil
+}
+
+// PageSlice sorts items and applies the cursor and limit (+1) in memory, sama dengan Apply
+// Untuk repository tanpa SQL (fake di test); value mengembalikan nilai field sort sebuah item
+func PageSlice[T any](p PageParams, items []T, value func(T) interface{}, id func(T) uint) ([]T, error) {
+	less := func(a, b T) bool {
+		if cmp := compareSortValues(value(a), value(b)); cmp != 0 {
+			return cmp < 0
+		}
+		return id(a) < id(b)
+	}
+	sorted := make([]T, len(items))
+	copy(sorted, items)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if p.SortDesc {
+			return less(sorted[j], sorted[i])
+		}
+		return less(sorted[i], sorted[j])
+	})
+
+	if p.cursor != nil {
+		cursorValue, err := p.decodeValue(p.cursor.Value)
+		if err != nil {
+			return nil, err
+		}
+		// Buang item sampai posisi cursor, sama dengan kondisi keyset di Apply
+		start := len(sorted)
+		for i, item := range sorted {
+			cmp := compareSortValues(value(item), cursorValue)
+			if cmp == 0 {
+				cmp = compareIDs(id(item), p.cursor.ID)
+			}
+			if (p.SortDesc && cmp < 0) || (!p.SortDesc && cmp > 0) {
+				start = i
+				break
+			}
+		}
+		sorted = sorted[start:]
+	}
+
+	if len(sorted) > p.Limit+1 {
+		sorted = sorted[:p.Limit+1]
+	}
+	return sorted, nil
+}
+
+// compareSortValues compares two sort values of the same kind (string, time, angka)
+func compareSortValues(a, b interface{}) int {
+	switch x := a.(type) {
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			return x.Compare(y)
+		}
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y)
+		}
+	}
+	x, xok := toFloat(a)
+	y, yok := toFloat(b)
+	if !xok || !yok || x == y {
+		return 0
+	}
+	if x < y {
+		return -1
+	}
+	return 1
+}
+
+// toFloat converts a numeric sort value, cursor angka selalu di-decode sebagai float64
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// compareIDs compares the tie-breaker IDs
+func compareIDs(a, b uint) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// NextCursor builds the cursor for the item after which the next page starts
+func (p PageParams) NextCursor(value interface{}, id uint) string {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	encoded, err := json.Marshal(pageCursor{Sort: p.Sort(), Value: raw, ID: id})
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+// decodeValue converts raw cursor JSON into a typed value for the sort column
+func (p PageParams) decodeValue(raw json.RawMessage) (interface{}, error) {
+	switch p.field.Kind {
+	case SortKindTime:
+		var t time.Time
+		if err := json.Unmarshal(raw, &t); err != nil {
+			return nil, ErrInvalidCursor
+		}
+		return t, nil
+	case SortKindNumber:
+		var n float64
+		if err := json.Unmarshal(raw, &n); err != nil {
+			return nil, ErrInvalidCursor
+		}
+		return n, nil
+	default:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, ErrInvalidCursor
+		}
+		return s, nil
+	}
+}
@@ -1,37 +1,58 @@
 package utils
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"attendance-system/internal/logging"
+
+	"github.com/gofiber/fiber/v2"
+)
 
 // APIResponse is the standard response structure
 type APIResponse struct {
-	Status  string      `json:"status"`  // "success" or "error"
+	Status  string      `json:"status"` // "success" or "error"
 	Message string      `json:"message"`
 	Data    interface{} `json:"data,omitempty"`
+	Meta    *PageMeta   `json:"meta,omitempty"` // Pagination info untuk list endpoint
+	// RequestID sama dengan header X-Request-ID, dicari di log server saat user melapor error
+	RequestID string `json:"request_id,omitempty"`
 }
 
 // SuccessResponse sends success response
 func SuccessResponse(c *fiber.Ctx, message string, data interface{}) error {
 	return c.Status(fiber.StatusOK).JSON(APIResponse{
-		Status:  "success",
-		Message: message,
-		Data:    data,
+		Status:    "success",
+		Message:   message,
+		Data:      data,
+		RequestID: logging.RequestID(c.UserContext()),
+	})
+}
+
+// PaginatedResponse sends success response with pagination metadata
+func PaginatedResponse(c *fiber.Ctx, message string, data interface{}, meta PageMeta) error {
+	return c.Status(fiber.StatusOK).JSON(APIResponse{
+		Status:    "success",
+		Message:   message,
+		Data:      data,
+		Meta:      &meta,
+		RequestID: logging.RequestID(c.UserContext()),
 	})
 }
 
 // CreatedResponse sends created response (201)
 func CreatedResponse(c *fiber.Ctx, message string, data interface{}) error {
 	return c.Status(fiber.StatusCreated).JSON(APIResponse{
-		Status:  "success",
-		Message: message,
-		Data:    data,
+		Status:    "success",
+		Message:   message,
+		Data:      data,
+		RequestID: logging.RequestID(c.UserContext()),
 	})
 }
 
 // ErrorResponse sends error response
 func ErrorResponse(c *fiber.Ctx, statusCode int, message string) error {
 	return c.Status(statusCode).JSON(APIResponse{
-		Status:  "error",
-		Message: message,
+		Status:    "error",
+		Message:   message,
+		RequestID: logging.RequestID(c.UserContext()),
 	})
 }
 
@@ -40,6 +61,21 @@ func BadRequestResponse(c *fiber.Ctx, message string) error {
 	return ErrorResponse(c, fiber.StatusBadRequest, message)
 }
 
+// UnauthorizedResponse sends unauthorized error (401)
+func UnauthorizedResponse(c *fiber.Ctx, message string) error {
+	return ErrorResponse(c, fiber.StatusUnauthorized, message)
+}
+
+// ForbiddenResponse sends forbidden error (403)
+func ForbiddenResponse(c *fiber.Ctx, message string) error {
+	return ErrorResponse(c, fiber.StatusForbidden, message)
+}
+
+// ConflictResponse sends conflict error (409)
+func ConflictResponse(c *fiber.Ctx, message string) error {
+	return ErrorResponse(c, fiber.StatusConflict, message)
+}
+
 // NotFoundResponse sends not found error (404)
 func NotFoundResponse(c *fiber.Ctx, message string) error {
 	return ErrorResponse(c, fiber.StatusNotFound, message)
@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"time"
+)
+
+// DateLayout is the calendar date format used in query params and reports
+const DateLayout = "2006-01-02"
+
+// DayBounds returns [start, end) of the calendar day containing t in loc
+// Pakai time.Date untuk hari berikutnya (bukan +24 jam) supaya benar saat DST
+func DayBounds(t time.Time, loc *time.Location) (time.Time, time.Time) {
+	local := t.In(loc)
+	start := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	end := time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, loc)
+	return start, end
+}
+
+// StartOfDate returns local midnight of the given YYYY-MM-DD date in loc
+func StartOfDate(date string, loc *time.Location) (time.Time, error) {
+	return time.ParseInLocation(DateLayout, date, loc)
+}
+
+// LocalDate formats t as YYYY-MM-DD in loc
+func LocalDate(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format(DateLayout)
+}
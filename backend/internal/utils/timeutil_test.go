@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata" // Zona waktu tidak tergantung tzdata di mesin test
+)
+
+func TestDayBounds(t *testing.T) {
+	tests := []struct {
+		name      string
+		zone      string
+		instant   string // RFC 3339 di UTC
+		wantDate  string
+		wantStart string
+		wantEnd   string
+		wantHours float64
+	}{
+		{"new york regular day", "America/New_York", "2024-03-09T15:00:00Z", "2024-03-09", "2024-03-09T05:00:00Z", "2024-03-10T05:00:00Z", 24},
+		{"new york spring forward (23h)", "America/New_York", "2024-03-10T12:00:00Z", "2024-03-10", "2024-03-10T05:00:00Z", "2024-03-11T04:00:00Z", 23},
+		{"new york just before the jump", "America/New_York", "2024-03-10T06:59:59Z", "2024-03-10", "2024-03-10T05:00:00Z", "2024-03-11T04:00:00Z", 23},
+		{"new york fall back (25h)", "America/New_York", "2024-11-03T12:00:00Z", "2024-11-03", "2024-11-03T04:00:00Z", "2024-11-04T05:00:00Z", 25},
+		{"new york repeated hour", "America/New_York", "2024-11-03T06:30:00Z", "2024-11-03", "2024-11-03T04:00:00Z", "2024-11-04T05:00:00Z", 25},
+		{"new york day after fall back", "America/New_York", "2024-11-04T04:30:00Z", "2024-11-03", "2024-11-03T04:00:00Z", "2024-11-04T05:00:00Z", 25},
+		{"utc+14 is already the next day", "Pacific/Kiritimati", "2024-01-01T11:00:00Z", "2024-01-02", "2024-01-01T10:00:00Z", "2024-01-02T10:00:00Z", 24},
+		{"utc+14 last instant of the day", "Pacific/Kiritimati", "2024-01-01T09:59:59Z", "2024-01-01", "2023-12-31T10:00:00Z", "2024-01-01T10:00:00Z", 24},
+		{"utc-12 is still the previous day", "Etc/GMT+12", "2024-01-02T06:00:00Z", "2024-01-01", "2024-01-01T12:00:00Z", "2024-01-02T12:00:00Z", 24},
+		{"utc-12 first instant of the day", "Etc/GMT+12", "2024-01-02T12:00:00Z", "2024-01-02", "2024-01-02T12:00:00Z", "2024-01-03T12:00:00Z", 24},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc, err := time.LoadLocation(tt.zone)
+			if err != nil {
+				t.Fatal(err)
+			}
+			instant, _ := time.Parse(time.RFC3339, tt.instant)
+			wantStart, _ := time.Parse(time.RFC3339, tt.wantStart)
+			wantEnd, _ := time.Parse(time.RFC3339, tt.wantEnd)
+
+			start, end := DayBounds(instant, loc)
+			if !start.Equal(wantStart) || !end.Equal(wantEnd) {
+				t.Fatalf("bounds [%s, %s), want [%s, %s)", start.UTC(), end.UTC(), wantStart, wantEnd)
+			}
+			if hours := end.Sub(start).Hours(); hours != tt.wantHours {
+				t.Fatalf("day is %vh, want %vh", hours, tt.wantHours)
+			}
+			if date := LocalDate(instant, loc); date != tt.wantDate {
+				t.Fatalf("local date %s, want %s", date, tt.wantDate)
+			}
+
+			// StartOfDate dari tanggal lokal harus sama dengan awal hari DayBounds
+			fromDate, err := StartOfDate(tt.wantDate, loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !fromDate.Equal(wantStart) {
+				t.Fatalf("StartOfDate(%s) = %s, want %s", tt.wantDate, fromDate.UTC(), wantStart)
+			}
+			if LocalDate(end.Add(-time.Nanosecond), loc) != tt.wantDate || LocalDate(end, loc) == tt.wantDate {
+				t.Fatalf("end %s is not the first instant of the next day", end.UTC())
+			}
+		})
+	}
+
+	if _, err := StartOfDate("2024-02-30", time.UTC); err == nil {
+		t.Fatal("invalid date accepted")
+	}
+}
@@ -3,6 +3,7 @@ import { BrowserRouter, Routes, Route } from 'react-router-dom';
 import Home from './pages/Home';
 import EmployeeRegistration from './pages/EmployeeRegistration';
 import CheckIn from './pages/CheckIn';
+import Login from './pages/Login';
 import './index.css';
 
 /**
@@ -16,6 +17,7 @@ function App() {
                 <Route path="/" element={<Home />} />
                 <Route path="/register" element={<EmployeeRegistration />} />
                 <Route path="/checkin" element={<CheckIn />} />
+                <Route path="/login" element={<Login />} />
             </Routes>
         </BrowserRouter>
     );
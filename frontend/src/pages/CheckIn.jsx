@@ -1,7 +1,7 @@
 import React, { useState, useEffect } from 'react';
 import { useNavigate } from 'react-router-dom';
 import WebcamCapture from '../components/WebcamCapture';
-import { checkIn, getEmployees } from '../services/api';
+import { checkIn, getEmployeeDirectory } from '../services/api';
 
 /**
  * CheckIn Page
@@ -25,7 +25,8 @@ const CheckIn = () => {
 
     const loadEmployees = async () => {
         try {
-            const response = await getEmployees();
+            // Kiosk tanpa login: hanya id dan nama karyawan aktif
+            const response = await getEmployeeDirectory();
             setEmployees(response.data || []);
         } catch (err) {
             console.error('Error loading employees:', err);
@@ -173,7 +174,7 @@ const CheckIn = () => {
                                         <option value="">-- Pilih Karyawan --</option>
                                         {employees.map((emp) => (
                                             <option key={emp.id} value={emp.id}>
-                                                {emp.name} ({emp.email})
+                                                {emp.name}
                                             </option>
                                         ))}
                                     </select>
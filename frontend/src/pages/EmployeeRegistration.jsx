@@ -1,11 +1,12 @@
 import React, { useState } from 'react';
-import { useNavigate } from 'react-router-dom';
+import { Navigate, useNavigate } from 'react-router-dom';
 import WebcamCapture from '../components/WebcamCapture';
-import { registerEmployee } from '../services/api';
+import { getCurrentUser, hasRole, registerEmployee } from '../services/api';
 
 /**
  * EmployeeRegistration Page
- * Form untuk mendaftarkan karyawan baru dengan foto wajah
+ * Form untuk mendaftarkan karyawan baru dengan foto wajah (HR/admin)
+ * Consent biometrik wajib dicentang, backend menolak enroll wajah tanpa consent
  */
 const EmployeeRegistration = () => {
     const navigate = useNavigate();
@@ -15,6 +16,8 @@ const EmployeeRegistration = () => {
         name: '',
         email: '',
         phone: '',
+        consent: false,
+        consent_method: 'form',
     });
 
     const [capturedImage, setCapturedImage] = useState(null);
@@ -25,9 +28,10 @@ const EmployeeRegistration = () => {
 
     // Handle input change
     const handleChange = (e) => {
+        const { name, type, checked, value } = e.target;
         setFormData({
             ...formData,
-            [e.target.name]: e.target.value,
+            [name]: type === 'checkbox' ? checked : value,
         });
         setError(null);
     };
@@ -53,6 +57,10 @@ const EmployeeRegistration = () => {
             setError('Foto wajah wajib diambil');
             return false;
         }
+        if (!formData.consent) {
+            setError('Consent biometrik karyawan wajib dicatat');
+            return false;
+        }
         return true;
     };
 
@@ -71,6 +79,8 @@ const EmployeeRegistration = () => {
             data.append('name', formData.name);
             data.append('email', formData.email);
             data.append('phone', formData.phone);
+            data.append('consent', 'true');
+            data.append('consent_method', formData.consent_method);
             data.append('face_image', imageBlob, 'face.jpg');
 
             // Submit ke API
@@ -92,6 +102,12 @@ const EmployeeRegistration = () => {
         }
     };
 
+    // Registrasi butuh login HR/admin, consent tercatat atas nama user yang login
+    if (!getCurrentUser()) {
+        return <Navigate to="/login" state={{ from: '/register' }} replace />;
+    }
+    const canRegister = hasRole('hr', 'admin');
+
     return (
         <div className="min-h-screen bg-gradient-to-br from-primary-50 to-primary-100 py-12 px-4">
             <div className="max-w-4xl mx-auto">
@@ -117,6 +133,13 @@ const EmployeeRegistration = () => {
                     </div>
                 )}
 
+                {/* Role Message */}
+                {!canRegister && (
+                    <div className="mb-6 p-4 bg-yellow-100 border border-yellow-400 text-yellow-800 rounded-lg">
+                        <span>Hanya HR atau admin yang dapat mendaftarkan karyawan.</span>
+                    </div>
+                )}
+
                 {/* Error Message */}
                 {error && (
                     <div className="mb-6 p-4 bg-red-100 border border-red-400 text-red-700 rounded-lg">
@@ -180,6 +203,35 @@ const EmployeeRegistration = () => {
                                         placeholder="08xxxxxxxxxx"
                                     />
                                 </div>
+
+                                <div>
+                                    <label className="block text-sm font-medium text-gray-700 mb-2">
+                                        Bukti Consent
+                                    </label>
+                                    <select
+                                        name="consent_method"
+                                        value={formData.consent_method}
+                                        onChange={handleChange}
+                                        className="input-field"
+                                    >
+                                        <option value="form">Formulir ini</option>
+                                        <option value="paper">Formulir kertas bertanda tangan</option>
+                                    </select>
+                                </div>
+
+                                <label className="flex items-start gap-3 text-sm text-gray-700">
+                                    <input
+                                        type="checkbox"
+                                        name="consent"
+                                        checked={formData.consent}
+                                        onChange={handleChange}
+                                        className="mt-1"
+                                    />
+                                    <span>
+                                        Karyawan menyetujui foto wajahnya disimpan dan dipakai untuk verifikasi
+                                        absensi, dan dapat menarik consent ini kapan saja. <span className="text-red-500">*</span>
+                                    </span>
+                                </label>
                             </div>
 
                             {/* Right Column - Webcam */}
@@ -207,7 +259,7 @@ const EmployeeRegistration = () => {
                             <button
                                 type="submit"
                                 className="btn-primary"
-                                disabled={isSubmitting}
+                                disabled={isSubmitting || !formData.consent || !canRegister}
                             >
                                 {isSubmitting ? (
                                     <span className="inline-flex items-center gap-2">
@@ -1,6 +1,6 @@
 import React, { useState, useEffect } from 'react';
 import { Link } from 'react-router-dom';
-import { getEmployees, getAttendances } from '../services/api';
+import { getEmployees, getAttendances, getCurrentUser, logout } from '../services/api';
 
 /**
  * Home Page
@@ -13,10 +13,23 @@ const Home = () => {
     });
     const [recentAttendances, setRecentAttendances] = useState([]);
     const [loading, setLoading] = useState(true);
+    // Statistik dan absensi terbaru butuh login, kiosk tetap bisa ke halaman check-in
+    const [user, setUser] = useState(getCurrentUser());
 
     useEffect(() => {
-        loadData();
-    }, []);
+        if (user) {
+            loadData();
+        } else {
+            setLoading(false);
+        }
+    }, [user]);
+
+    const handleLogout = () => {
+        logout();
+        setUser(null);
+        setStats({ totalEmployees: 0, todayAttendance: 0 });
+        setRecentAttendances([]);
+    };
 
     const loadData = async () => {
         try {
@@ -36,13 +49,15 @@ const Home = () => {
             }).length;
 
             setStats({
-                totalEmployees: employees.length,
+                totalEmployees: employeesRes.meta?.total ?? employees.length,
                 todayAttendance: todayCount,
             });
 
             setRecentAttendances(attendances.slice(0, 5));
         } catch (err) {
             console.error('Error loading data:', err);
+            // Token kedaluwarsa: sesi sudah dihapus oleh interceptor, tampilkan login lagi
+            setUser(getCurrentUser());
         } finally {
             setLoading(false);
         }
@@ -52,11 +67,25 @@ const Home = () => {
         <div className="min-h-screen bg-gradient-to-br from-indigo-50 via-purple-50 to-pink-50">
             {/* Header */}
             <header className="bg-white shadow-md">
-                <div className="max-w-7xl mx-auto px-4 py-6">
-                    <h1 className="text-3xl font-bold text-gray-900">
-                        📸 Promptara face verification system anjay
-                    </h1>
-                    <p className="text-gray-600 mt-1">Sistem Absensi dengan Verifikasi Wajah</p>
+                <div className="max-w-7xl mx-auto px-4 py-6 flex items-center justify-between">
+                    <div>
+                        <h1 className="text-3xl font-bold text-gray-900">
+                            📸 Promptara face verification system anjay
+                        </h1>
+                        <p className="text-gray-600 mt-1">Sistem Absensi dengan Verifikasi Wajah</p>
+                    </div>
+                    {user ? (
+                        <div className="flex items-center gap-4">
+                            <span className="text-sm text-gray-600">{user.name} ({user.role})</span>
+                            <button type="button" onClick={handleLogout} className="btn-secondary">
+                                Logout
+                            </button>
+                        </div>
+                    ) : (
+                        <Link to="/login" className="btn-primary">
+                            Login
+                        </Link>
+                    )}
                 </div>
             </header>
 
@@ -113,103 +142,116 @@ const Home = () => {
                     </Link>
                 </div>
 
+                {!user && (
+                    <div className="card text-center text-gray-600">
+                        <p className="mb-4">Login sebagai HR atau admin untuk melihat statistik dan absensi terbaru.</p>
+                        <Link to="/login" className="btn-primary">
+                            Login
+                        </Link>
+                    </div>
+                )}
+
                 {/* Statistics */}
-                <div className="grid md:grid-cols-2 gap-6 mb-12">
-                    <div className="card bg-gradient-to-br from-primary-500 to-primary-600 text-white">
-                        <div className="flex items-center justify-between">
-                            <div>
-                                <p className="text-primary-100 mb-1">Total Karyawan</p>
-                                <p className="text-4xl font-bold">{stats.totalEmployees}</p>
+                {user && (
+                    <div className="grid md:grid-cols-2 gap-6 mb-12">
+                        <div className="card bg-gradient-to-br from-primary-500 to-primary-600 text-white">
+                            <div className="flex items-center justify-between">
+                                <div>
+                                    <p className="text-primary-100 mb-1">Total Karyawan</p>
+                                    <p className="text-4xl font-bold">{stats.totalEmployees}</p>
+                                </div>
+                                <svg className="w-16 h-16 text-primary-200" fill="currentColor" viewBox="0 0 20 20">
+                                    <path d="M9 6a3 3 0 11-6 0 3 3 0 016 0zM17 6a3 3 0 11-6 0 3 3 0 016 0zM12.93 17c.046-.327.07-.66.07-1a6.97 6.97 0 00-1.5-4.33A5 5 0 0119 16v1h-6.07zM6 11a5 5 0 015 5v1H1v-1a5 5 0 015-5z" />
+                                </svg>
                             </div>
-                            <svg className="w-16 h-16 text-primary-200" fill="currentColor" viewBox="0 0 20 20">
-                                <path d="M9 6a3 3 0 11-6 0 3 3 0 016 0zM17 6a3 3 0 11-6 0 3 3 0 016 0zM12.93 17c.046-.327.07-.66.07-1a6.97 6.97 0 00-1.5-4.33A5 5 0 0119 16v1h-6.07zM6 11a5 5 0 015 5v1H1v-1a5 5 0 015-5z" />
-                            </svg>
                         </div>
-                    </div>
 
-                    <div className="card bg-gradient-to-br from-green-500 to-green-600 text-white">
-                        <div className="flex items-center justify-between">
-                            <div>
-                                <p className="text-green-100 mb-1">Absensi Hari Ini</p>
-                                <p className="text-4xl font-bold">{stats.todayAttendance}</p>
+                        <div className="card bg-gradient-to-br from-green-500 to-green-600 text-white">
+                            <div className="flex items-center justify-between">
+                                <div>
+                                    <p className="text-green-100 mb-1">Absensi Hari Ini</p>
+                                    <p className="text-4xl font-bold">{stats.todayAttendance}</p>
+                                </div>
+                                <svg className="w-16 h-16 text-green-200" fill="currentColor" viewBox="0 0 20 20">
+                                    <path fillRule="evenodd" d="M6 2a1 1 0 00-1 1v1H4a2 2 0 00-2 2v10a2 2 0 002 2h12a2 2 0 002-2V6a2 2 0 00-2-2h-1V3a1 1 0 10-2 0v1H7V3a1 1 0 00-1-1zm0 5a1 1 0 000 2h8a1 1 0 100-2H6z" clipRule="evenodd" />
+                                </svg>
                             </div>
-                            <svg className="w-16 h-16 text-green-200" fill="currentColor" viewBox="0 0 20 20">
-                                <path fillRule="evenodd" d="M6 2a1 1 0 00-1 1v1H4a2 2 0 00-2 2v10a2 2 0 002 2h12a2 2 0 002-2V6a2 2 0 00-2-2h-1V3a1 1 0 10-2 0v1H7V3a1 1 0 00-1-1zm0 5a1 1 0 000 2h8a1 1 0 100-2H6z" clipRule="evenodd" />
-                            </svg>
                         </div>
                     </div>
-                </div>
+                )}
 
                 {/* Recent Attendances */}
-                <div className="card">
-                    <h2 className="text-2xl font-bold text-gray-900 mb-6">
-                        Absensi Terbaru
-                    </h2>
-
-                    {loading ? (
-                        <div className="text-center py-8">
-                            <div className="animate-spin rounded-full h-12 w-12 border-b-2 border-primary-600 mx-auto"></div>
-                            <p className="text-gray-600 mt-4">Memuat data...</p>
-                        </div>
-                    ) : recentAttendances.length === 0 ? (
-                        <div className="text-center py-8 text-gray-500">
-                            <svg className="w-16 h-16 mx-auto mb-4 text-gray-300" fill="currentColor" viewBox="0 0 20 20">
-                                <path fillRule="evenodd" d="M10 18a8 8 0 100-16 8 8 0 000 16zM7 9a1 1 0 000 2h6a1 1 0 100-2H7z" clipRule="evenodd" />
-                            </svg>
-                            <p>Belum ada data absensi</p>
-                        </div>
-                    ) : (
-                        <div className="overflow-x-auto">
-                            <table className="w-full">
-                                <thead className="bg-gray-50">
-                                    <tr>
-                                        <th className="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase tracking-wider">
-                                            Nama
-                                        </th>
-                                        <th className="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase tracking-wider">
-                                            Waktu Check-In
-                                        </th>
-                                        <th className="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase tracking-wider">
-                                            Similarity Score
-                                        </th>
-                                        <th className="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase tracking-wider">
-                                            Status
-                                        </th>
-                                    </tr>
-                                </thead>
-                                <tbody className="bg-white divide-y divide-gray-200">
-                                    {recentAttendances.map((attendance) => (
-                                        <tr key={attendance.id} className="hover:bg-gray-50">
-                                            <td className="px-6 py-4 whitespace-nowrap">
-                                                <div className="text-sm font-medium text-gray-900">
-                                                    {attendance.user_name}
-                                                </div>
-                                            </td>
-                                            <td className="px-6 py-4 whitespace-nowrap">
-                                                <div className="text-sm text-gray-600">
-                                                    {new Date(attendance.check_in_time).toLocaleString('id-ID')}
-                                                </div>
-                                            </td>
-                                            <td className="px-6 py-4 whitespace-nowrap">
-                                                <div className="text-sm text-gray-900">
-                                                    {(attendance.similarity_score * 100).toFixed(2)}%
-                                                </div>
-                                            </td>
-                                            <td className="px-6 py-4 whitespace-nowrap">
-                                                <span className={`px-2 inline-flex text-xs leading-5 font-semibold rounded-full ${attendance.status === 'success'
-                                                    ? 'bg-green-100 text-green-800'
-                                                    : 'bg-red-100 text-red-800'
-                                                    }`}>
-                                                    {attendance.status === 'success' ? '✓ Berhasil' : '✗ Gagal'}
-                                                </span>
-                                            </td>
+                {user && (
+                    <div className="card">
+                        <h2 className="text-2xl font-bold text-gray-900 mb-6">
+                            Absensi Terbaru
+                        </h2>
+
+                        {loading ? (
+                            <div className="text-center py-8">
+                                <div className="animate-spin rounded-full h-12 w-12 border-b-2 border-primary-600 mx-auto"></div>
+                                <p className="text-gray-600 mt-4">Memuat data...</p>
+                            </div>
+                        ) : recentAttendances.length === 0 ? (
+                            <div className="text-center py-8 text-gray-500">
+                                <svg className="w-16 h-16 mx-auto mb-4 text-gray-300" fill="currentColor" viewBox="0 0 20 20">
+                                    <path fillRule="evenodd" d="M10 18a8 8 0 100-16 8 8 0 000 16zM7 9a1 1 0 000 2h6a1 1 0 100-2H7z" clipRule="evenodd" />
+                                </svg>
+                                <p>Belum ada data absensi</p>
+                            </div>
+                        ) : (
+                            <div className="overflow-x-auto">
+                                <table className="w-full">
+                                    <thead className="bg-gray-50">
+                                        <tr>
+                                            <th className="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase tracking-wider">
+                                                Nama
+                                            </th>
+                                            <th className="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase tracking-wider">
+                                                Waktu Check-In
+                                            </th>
+                                            <th className="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase tracking-wider">
+                                                Similarity Score
+                                            </th>
+                                            <th className="px-6 py-3 text-left text-xs font-medium text-gray-500 uppercase tracking-wider">
+                                                Status
+                                            </th>
                                         </tr>
-                                    ))}
-                                </tbody>
-                            </table>
-                        </div>
-                    )}
-                </div>
+                                    </thead>
+                                    <tbody className="bg-white divide-y divide-gray-200">
+                                        {recentAttendances.map((attendance) => (
+                                            <tr key={attendance.id} className="hover:bg-gray-50">
+                                                <td className="px-6 py-4 whitespace-nowrap">
+                                                    <div className="text-sm font-medium text-gray-900">
+                                                        {attendance.user_name}
+                                                    </div>
+                                                </td>
+                                                <td className="px-6 py-4 whitespace-nowrap">
+                                                    <div className="text-sm text-gray-600">
+                                                        {new Date(attendance.check_in_time).toLocaleString('id-ID')}
+                                                    </div>
+                                                </td>
+                                                <td className="px-6 py-4 whitespace-nowrap">
+                                                    <div className="text-sm text-gray-900">
+                                                        {(attendance.similarity_score * 100).toFixed(2)}%
+                                                    </div>
+                                                </td>
+                                                <td className="px-6 py-4 whitespace-nowrap">
+                                                    <span className={`px-2 inline-flex text-xs leading-5 font-semibold rounded-full ${attendance.status === 'success'
+                                                        ? 'bg-green-100 text-green-800'
+                                                        : 'bg-red-100 text-red-800'
+                                                        }`}>
+                                                        {attendance.status === 'success' ? '✓ Berhasil' : '✗ Gagal'}
+                                                    </span>
+                                                </td>
+                                            </tr>
+                                        ))}
+                                    </tbody>
+                                </table>
+                            </div>
+                        )}
+                    </div>
+                )}
             </main>
         </div>
     );
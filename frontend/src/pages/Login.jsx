@@ -0,0 +1,124 @@
+import React, { useState } from 'react';
+import { useLocation, useNavigate } from 'react-router-dom';
+import { login } from '../services/api';
+
+/**
+ * Login Page
+ * Login HR/admin/karyawan, token disimpan untuk dashboard dan registrasi karyawan
+ */
+const Login = () => {
+    const navigate = useNavigate();
+    const location = useLocation();
+
+    const [email, setEmail] = useState('');
+    const [password, setPassword] = useState('');
+    const [isSubmitting, setIsSubmitting] = useState(false);
+    const [error, setError] = useState(null);
+
+    // Handle form submit
+    const handleSubmit = async (e) => {
+        e.preventDefault();
+
+        if (!email.trim() || !password) {
+            setError('Email dan password wajib diisi');
+            return;
+        }
+
+        setIsSubmitting(true);
+        setError(null);
+
+        try {
+            await login(email.trim(), password);
+            // Kembali ke halaman yang meminta login
+            navigate(location.state?.from || '/', { replace: true });
+        } catch (err) {
+            console.error('Login error:', err);
+            setError(err.response?.data?.message || 'Gagal login. Silakan coba lagi.');
+        } finally {
+            setIsSubmitting(false);
+        }
+    };
+
+    return (
+        <div className="min-h-screen bg-gradient-to-br from-primary-50 to-primary-100 py-12 px-4">
+            <div className="max-w-md mx-auto">
+                {/* Header */}
+                <div className="text-center mb-8">
+                    <h1 className="text-4xl font-bold text-primary-900 mb-2">
+                        Login
+                    </h1>
+                    <p className="text-gray-600">
+                        Masuk untuk melihat dashboard dan mendaftarkan karyawan
+                    </p>
+                </div>
+
+                {/* Error Message */}
+                {error && (
+                    <div className="mb-6 p-4 bg-red-100 border border-red-400 text-red-700 rounded-lg">
+                        <span>{error}</span>
+                    </div>
+                )}
+
+                {/* Form */}
+                <div className="card">
+                    <form onSubmit={handleSubmit} className="space-y-6">
+                        <div>
+                            <label className="block text-sm font-medium text-gray-700 mb-2">
+                                Email
+                            </label>
+                            <input
+                                type="email"
+                                name="email"
+                                autoComplete="username"
+                                value={email}
+                                onChange={(e) => {
+                                    setEmail(e.target.value);
+                                    setError(null);
+                                }}
+                                className="input-field"
+                                placeholder="email@example.com"
+                            />
+                        </div>
+
+                        <div>
+                            <label className="block text-sm font-medium text-gray-700 mb-2">
+                                Password
+                            </label>
+                            <input
+                                type="password"
+                                name="password"
+                                autoComplete="current-password"
+                                value={password}
+                                onChange={(e) => {
+                                    setPassword(e.target.value);
+                                    setError(null);
+                                }}
+                                className="input-field"
+                            />
+                        </div>
+
+                        <div className="flex gap-4 justify-end">
+                            <button
+                                type="button"
+                                onClick={() => navigate('/')}
+                                className="btn-secondary"
+                                disabled={isSubmitting}
+                            >
+                                Batal
+                            </button>
+                            <button
+                                type="submit"
+                                className="btn-primary"
+                                disabled={isSubmitting}
+                            >
+                                {isSubmitting ? 'Memproses...' : 'Login'}
+                            </button>
+                        </div>
+                    </form>
+                </div>
+            </div>
+        </div>
+    );
+};
+
+export default Login;
@@ -13,8 +13,80 @@ const api = axios.create({
     },
 });
 
+const TOKEN_KEY = 'token';
+const USER_KEY = 'user';
+
+// Token dari POST /api/auth/login, wajib untuk daftar karyawan dan riwayat absensi
+api.interceptors.request.use((config) => {
+    const token = localStorage.getItem(TOKEN_KEY);
+    if (token) {
+        config.headers.Authorization = `Bearer ${token}`;
+    }
+    return config;
+});
+
+// Token kedaluwarsa atau user dinonaktifkan: hapus sesi supaya halaman menampilkan login lagi
+api.interceptors.response.use(
+    (response) => response,
+    (error) => {
+        if (error.response?.status === 401 && localStorage.getItem(TOKEN_KEY)) {
+            clearSession();
+        }
+        return Promise.reject(error);
+    },
+);
+
+/**
+ * Get the logged-in user saved at login
+ * @returns {Object|null} User (id, name, email, role) atau null kalau belum login
+ */
+export const getCurrentUser = () => {
+    if (!localStorage.getItem(TOKEN_KEY)) return null;
+    try {
+        return JSON.parse(localStorage.getItem(USER_KEY));
+    } catch {
+        return null;
+    }
+};
+
+/**
+ * Check whether the current user has one of the roles
+ * @param {...string} roles - employee, manager, hr, admin, auditor
+ * @returns {boolean}
+ */
+export const hasRole = (...roles) => {
+    const user = getCurrentUser();
+    return !!user && roles.includes(user.role);
+};
+
+const clearSession = () => {
+    localStorage.removeItem(TOKEN_KEY);
+    localStorage.removeItem(USER_KEY);
+};
+
 // API Service Functions
 
+/**
+ * Login dengan email dan password, token disimpan di localStorage
+ * @param {string} email
+ * @param {string} password
+ * @returns {Promise} User yang login
+ */
+export const login = async (email, password) => {
+    const response = await api.post('/api/auth/login', { email, password });
+    const { token, user } = response.data.data;
+    localStorage.setItem(TOKEN_KEY, token);
+    localStorage.setItem(USER_KEY, JSON.stringify(user));
+    return user;
+};
+
+/**
+ * Logout, hanya menghapus token di browser (token stateless)
+ */
+export const logout = () => {
+    clearSession();
+};
+
 /**
  * Register new employee dengan face image
  * @param {FormData} formData - Form data containing name, email, phone, face_image
@@ -30,11 +102,21 @@ export const registerEmployee = async (formData) => {
 };
 
 /**
- * Get all employees
+ * Get employees (paginated)
+ * @param {Object} params - Query parameters (q, department_id, team_id, manager_id, sort, limit, cursor)
+ * @returns {Promise} API response, pagination info ada di `meta`
+ */
+export const getEmployees = async (params = {}) => {
+    const response = await api.get('/api/employees', { params });
+    return response.data;
+};
+
+/**
+ * Get employee names for the check-in picker (tanpa login, hanya id dan name)
  * @returns {Promise} API response
  */
-export const getEmployees = async () => {
-    const response = await api.get('/api/employees');
+export const getEmployeeDirectory = async () => {
+    const response = await api.get('/api/employees/directory');
     return response.data;
 };
 
@@ -69,7 +151,7 @@ export const checkIn = async (userId, imageBlob) => {
 
 /**
  * Get attendance history
- * @param {Object} params - Query parameters (user_id, limit)
+ * @param {Object} params - Query parameters (user_id, from, to, status, site_id, device_id, department_id, q, sort, limit, cursor)
  * @returns {Promise} API response
  */
 export const getAttendances = async (params = {}) => {